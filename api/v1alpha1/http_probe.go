@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Limits of an HTTPProbe, so that the probes of a single Manifest cannot block a reconciliation for long.
+const (
+	MaxProbeTimeoutSeconds = 10
+	MaxProbeAttempts       = 5
+	MaxProbePeriodSeconds  = 10
+)
+
+// ErrProbeNotInCluster is returned for probe URLs that do not address a Service of the target cluster.
+var ErrProbeNotInCluster = errors.New("probe url has to address a service of the cluster, " +
+	"e.g. http://gateway.istio-system.svc:15021/healthz/ready")
+
+// ProbeTarget is the Service of the target cluster an HTTPProbe requests through the service proxy of the
+// API server.
+type ProbeTarget struct {
+	Scheme, Service, Namespace, Port string
+	// Path is the escaped path of the request including its query, if any.
+	Path string
+}
+
+// ServiceTarget returns the Service addressed by the URL of the probe, which has to have the form
+// http(s)://<service>.<namespace>.svc[.cluster.local][:<port>][/<path>].
+func (p HTTPProbe) ServiceTarget() (ProbeTarget, error) {
+	parsed, err := url.Parse(p.URL)
+	if err != nil {
+		return ProbeTarget{}, fmt.Errorf("%w: %s", ErrProbeNotInCluster, err.Error())
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.User != nil || parsed.Opaque != "" {
+		return ProbeTarget{}, ErrProbeNotInCluster
+	}
+	labels := strings.SplitN(parsed.Hostname(), ".", 3)
+	if len(labels) < 3 || (labels[2] != "svc" && labels[2] != "svc.cluster.local") {
+		return ProbeTarget{}, ErrProbeNotInCluster
+	}
+	for _, label := range labels[:2] {
+		if len(validation.IsDNS1123Label(label)) > 0 {
+			return ProbeTarget{}, ErrProbeNotInCluster
+		}
+	}
+	port := parsed.Port()
+	if number, err := strconv.Atoi(port); port != "" && (err != nil || number < 1 || number > 65535) {
+		return ProbeTarget{}, ErrProbeNotInCluster
+	}
+	path := parsed.EscapedPath()
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	return ProbeTarget{
+		Scheme: parsed.Scheme, Service: labels[0], Namespace: labels[1], Port: port, Path: path,
+	}, nil
+}
@@ -0,0 +1,45 @@
+package v1alpha1
+
+import (
+	"github.com/kyma-project/module-manager/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Hub = &Manifest{}
+
+// Hub marks v1alpha1 as the hub of the conversions between the served versions of Manifest.
+// It is the storage version, so the reconciler only handles v1alpha1 Manifests independent of the
+// version they were created with.
+func (m *Manifest) Hub() {}
+
+// ConvertLegacySpec moves the fields of the former spec layout to their current counterparts:
+// PreInstallCRDs to CRDs and StateCR to Resource. Fields of the current layout take precedence,
+// the legacy fields are cleared in any case. It returns true if the Manifest was changed.
+func (m *Manifest) ConvertLegacySpec() bool {
+	converted := false
+	if m.Spec.PreInstallCRDs != nil {
+		if isEmptyImageSpec(m.Spec.CRDs) {
+			m.Spec.CRDs = *m.Spec.PreInstallCRDs
+		}
+		m.Spec.PreInstallCRDs = nil
+		converted = true
+	}
+	if m.Spec.StateCR != nil {
+		if m.Spec.Resource == nil {
+			m.Spec.Resource = m.Spec.StateCR
+		}
+		m.Spec.StateCR = nil
+		converted = true
+	}
+	return converted
+}
+
+// HasLegacySpec returns true if the Manifest still uses fields of the former spec layout.
+func (m *Manifest) HasLegacySpec() bool {
+	return m.Spec.PreInstallCRDs != nil || m.Spec.StateCR != nil
+}
+
+func isEmptyImageSpec(spec types.ImageSpec) bool {
+	return spec.Repo == "" && spec.Name == "" && spec.Ref == "" && spec.Type == "" &&
+		spec.CredSecretSelector == nil && spec.LayerSelector == nil
+}
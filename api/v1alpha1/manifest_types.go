@@ -37,6 +37,113 @@ type InstallInfo struct {
 
 	// Name specifies a unique install name for Manifest
 	Name string `json:"name"`
+
+	// NameOverride opts in to setting the nameOverride value of helm charts to <manifest name>-<install name>,
+	// unless the values already contain a nameOverride. By default, charts use their own naming.
+	// +optional
+	NameOverride bool `json:"nameOverride,omitempty"`
+
+	// ValuesFrom overrides values of the install with secrets resolved at render time.
+	// +optional
+	ValuesFrom []ValuesReference `json:"valuesFrom,omitempty"`
+
+	// GeneratedValues overrides values of the install with values that are generated once, e.g. passwords,
+	// and stored in secrets of the target cluster, so that they are stable across renders.
+	// +optional
+	GeneratedValues []declarative.GeneratedValue `json:"generatedValues,omitempty"`
+
+	// Wait determines how the readiness of the resources of the install is awaited, e.g. with a timeout.
+	// +optional
+	Wait *declarative.WaitStrategy `json:"wait,omitempty"`
+}
+
+// ValuesReference resolves a value of an install from a secret provider configured in the module-manager,
+// so that secrets are neither stored in the Manifest nor in config layers.
+type ValuesReference struct {
+	// Provider is the name of the secret provider, e.g. "vault" or "exec".
+	Provider string `json:"provider"`
+
+	// Path identifies the secret in the provider, e.g. "secret/data/modules/keda" for vault.
+	Path string `json:"path"`
+
+	// Key selects the value in the secret.
+	Key string `json:"key"`
+
+	// TargetPath is the dot separated path of the value that is overridden, e.g. "database.password".
+	TargetPath string `json:"targetPath"`
+}
+
+// CustomState describes a resource in the target cluster whose state has to be ready for the Manifest to be ready.
+type CustomState struct {
+	// APIVersion defines the API version of the resource, e.g. "operator.kyma-project.io/v1alpha1"
+	APIVersion string `json:"apiVersion"`
+
+	// Kind defines the kind of the resource
+	Kind string `json:"kind"`
+
+	// Name defines the name of the resource
+	Name string `json:"name"`
+
+	// Namespace defines the namespace of the resource, empty for cluster scoped resources
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Path defines the dot separated path of the state in the resource, "status.state" if empty
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+
+	// Value defines the state of the resource once it is ready, "Ready" if empty
+	// +kubebuilder:validation:Optional
+	Value string `json:"value,omitempty"`
+}
+
+// ReadinessRule decides the readiness of all installed resources of a kind with an expression,
+// for modules whose resources follow status conventions the built-in checks do not understand.
+type ReadinessRule struct {
+	// APIVersion defines the API version of the resources, e.g. "apps.example.com/v1"
+	APIVersion string `json:"apiVersion"`
+
+	// Kind defines the kind of the resources
+	Kind string `json:"kind"`
+
+	// Expression is a CEL expression over the fields of a resource that is true once it is ready,
+	// e.g. `status.phase == "Running"`
+	Expression string `json:"expression"`
+}
+
+// HTTPProbe describes an HTTP GET request to a Service of the target cluster evaluated by the controller after the
+// installation, Manifest is only ready once it responds with the expected status.
+type HTTPProbe struct {
+	// Name identifies the probe in the conditions of Manifest
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][-A-Za-z0-9_.]*$`
+	Name string `json:"name"`
+
+	// URL defines the HTTP(S) URL of a Service in the target cluster,
+	// e.g. http://gateway.istio-system.svc:15021/healthz/ready. It is requested through the service proxy
+	// of the API server of the target cluster, redirects are not followed.
+	URL string `json:"url"`
+
+	// ExpectedStatus defines the expected status code of the response, 200 if 0
+	// +kubebuilder:validation:Optional
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+
+	// TimeoutSeconds defines the timeout of a single request, 5 seconds if 0
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+
+	// Attempts defines the number of requests made in a readiness check before the probe fails, 1 if 0
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	Attempts int `json:"attempts,omitempty"`
+
+	// PeriodSeconds defines the delay between the attempts of a readiness check, 1 second if 0
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=10
+	PeriodSeconds int `json:"periodSeconds,omitempty"`
 }
 
 // ManifestSpec defines the specification of Manifest.
@@ -56,8 +163,42 @@ type ManifestSpec struct {
 	// Resource specifies a resource to be watched for state updates
 	Resource *unstructured.Unstructured `json:"resource,omitempty"`
 
+	// CustomStates specifies further resources whose state determines the readiness of Manifest
+	// +kubebuilder:validation:Optional
+	CustomStates []CustomState `json:"customStates,omitempty"`
+
+	// Probes specifies endpoints that have to respond before Manifest is ready, e.g. health endpoints of gateways
+	// +kubebuilder:validation:Optional
+	Probes []HTTPProbe `json:"probes,omitempty"`
+
+	// ReadinessRules specifies expressions that the installed resources of their kind have to fulfill
+	// before Manifest is ready, they take precedence over the readiness rules of the controller
+	// +kubebuilder:validation:Optional
+	ReadinessRules []ReadinessRule `json:"readinessRules,omitempty"`
+
+	// Remediation defines how consistency checks handle installed resources that drifted in the cluster:
+	// "Enforce" reports and reverts the drift, "Detect" only reports it and "Off" disables both.
+	// Drift is reverted without being reported if empty
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Enforce;Detect;Off
+	Remediation string `json:"remediation,omitempty"`
+
+	// RollbackOnFailure restores the resources applied last if applying the resources of an update fails,
+	// instead of leaving them partially applied. Rollbacks are reported in the Rollback condition
+	// +kubebuilder:validation:Optional
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+
 	// CRDs specifies the custom resource definitions' ImageSpec
 	CRDs types.ImageSpec `json:"crds,omitempty"`
+
+	// Deprecated: PreInstallCRDs is the former layout of CRDs and is moved to CRDs by ConvertLegacySpec.
+	PreInstallCRDs *types.ImageSpec `json:"preInstallCRDs,omitempty"`
+
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+kubebuilder:validation:XEmbeddedResource
+	//+nullable
+	// Deprecated: StateCR is the former layout of Resource and is moved to Resource by ConvertLegacySpec.
+	StateCR *unstructured.Unstructured `json:"stateCR,omitempty"`
 }
 
 // ManifestStatus defines the observed state of Manifest.
@@ -69,7 +210,8 @@ type InstallItem struct {
 	// +kubebuilder:validation:Optional
 	ChartName string `json:"chartName"`
 
-	// ClientConfig defines the client config for InstallItem
+	// ClientConfig defines the client config for InstallItem in the strvals format, only the flags
+	// Description, Devel, SkipCRDs, SubNotes and Version of the helm install action are supported
 	// +kubebuilder:validation:Optional
 	ClientConfig string `json:"clientConfig"`
 
@@ -80,6 +222,7 @@ type InstallItem struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:printcolumn:name="State",type=string,JSONPath=".status.state"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
@@ -17,6 +17,11 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -25,9 +30,13 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
 	"github.com/kyma-project/module-manager/pkg/types"
 )
 
+// ErrDeletionProtected is returned for deletions of Manifests with declarative.DeletionProtectionAnnotation.
+var ErrDeletionProtected = errors.New("manifest is protected against deletion")
+
 // log is for logging in this package.
 var manifestlog = logf.Log.WithName("manifest-resource") //nolint:gochecknoglobals
 
@@ -37,6 +46,15 @@ func (m *Manifest) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		Complete()
 }
 
+// SetupWebhookWithValidator registers the webhooks of Manifest with the validation of ManifestValidator
+// instead of the validation of Manifest.
+func (m *Manifest) SetupWebhookWithValidator(mgr ctrl.Manager, validator *ManifestValidator) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(m).
+		WithValidator(validator).
+		Complete()
+}
+
 //nolint:lll
 //+kubebuilder:webhook:path=/mutate-operator-kyma-project-io-v1alpha1-manifest,mutating=true,failurePolicy=fail,sideEffects=None,groups=operator.kyma-project.io,resources=manifests,verbs=create;update,versions=v1alpha1,name=mmanifest.kb.io,admissionReviewVersions=v1
 
@@ -44,6 +62,8 @@ var _ webhook.Defaulter = &Manifest{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type.
 func (m *Manifest) Default() {
+	m.ConvertLegacySpec()
+
 	var emptyImageSpec types.ImageSpec
 	if m.Spec.Config == emptyImageSpec {
 		m.Spec.Config = types.ImageSpec{}
@@ -55,7 +75,7 @@ func (m *Manifest) Default() {
 }
 
 //nolint:lll
-//+kubebuilder:webhook:path=/validate-operator-kyma-project-io-v1alpha1-manifest, mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.kyma-project.io,resources=manifests,verbs=create;update,versions=v1alpha1,name=vmanifest.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-operator-kyma-project-io-v1alpha1-manifest, mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.kyma-project.io,resources=manifests,verbs=create;update;delete,versions=v1alpha1,name=vmanifest.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &Manifest{}
 
@@ -63,19 +83,41 @@ var _ webhook.Validator = &Manifest{}
 func (m *Manifest) ValidateCreate() error {
 	manifestlog.Info("validate create", "name", m.Name)
 
-	return m.validateInstalls()
+	if err := m.validateInstalls(); err != nil {
+		return err
+	}
+	if err := m.validateReadinessRules(); err != nil {
+		return err
+	}
+	return m.validateProbes()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type.
 func (m *Manifest) ValidateUpdate(old runtime.Object) error {
 	manifestlog.Info("validate update", "name", m.Name)
 
-	return m.validateInstalls()
+	if err := m.validateInstalls(); err != nil {
+		return err
+	}
+	if err := m.validateReadinessRules(); err != nil {
+		return err
+	}
+	return m.validateProbes()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type.
+// Manifests protected by declarative.DeletionProtectionAnnotation can only be deleted once the deletion
+// is confirmed with declarative.ConfirmDeletionAnnotation.
 func (m *Manifest) ValidateDelete() error {
 	manifestlog.Info("validate delete", "name", m.Name)
+
+	if declarative.IsDeletionBlocked(m) {
+		return apierrors.NewForbidden(
+			schema.GroupResource{Group: GroupVersion.Group, Resource: "manifests"}, m.Name,
+			fmt.Errorf("%w: set %s to \"true\" before deleting", ErrDeletionProtected,
+				declarative.ConfirmDeletionAnnotation),
+		)
+	}
 	return nil
 }
 
@@ -90,20 +132,22 @@ func (m *Manifest) validateInstalls() error {
 	}
 
 	if len(fieldErrors) == 0 {
-		for _, install := range m.Spec.Installs {
+		for i, install := range m.Spec.Installs {
+			sourcePath := field.NewPath("spec").Child("installs").Index(i).Child("source")
 			specType, err := types.GetSpecType(install.Source.Raw)
 			if err != nil {
 				fieldErrors = append(fieldErrors,
-					field.Invalid(field.NewPath("spec").Child("installs"),
-						install.Source.Raw, err.Error()))
+					field.Invalid(sourcePath, string(install.Source.Raw), err.Error()))
 				continue
 			}
 
 			err = codec.Validate(install.Source.Raw, specType)
-			if err != nil {
+			var validationErr *types.ValidationError
+			if errors.As(err, &validationErr) {
+				fieldErrors = append(fieldErrors, sourceFieldErrors(sourcePath, validationErr)...)
+			} else if err != nil {
 				fieldErrors = append(fieldErrors,
-					field.Invalid(field.NewPath("spec").Child("installs"),
-						install.Source.Raw, err.Error()))
+					field.Invalid(sourcePath, string(install.Source.Raw), err.Error()))
 			}
 		}
 	}
@@ -116,3 +160,120 @@ func (m *Manifest) validateInstalls() error {
 
 	return nil
 }
+
+// validateReadinessRules rejects readiness rules whose expressions cannot be compiled.
+func (m *Manifest) validateReadinessRules() error {
+	fieldErrors := make(field.ErrorList, 0)
+	for i, rule := range m.Spec.ReadinessRules {
+		if _, err := declarative.CompileReadinessExpression(rule.Expression); err != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(
+				field.NewPath("spec").Child("readinessRules").Index(i).Child("expression"),
+				rule.Expression, err.Error()))
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: ManifestKind},
+			m.Name, fieldErrors)
+	}
+	return nil
+}
+
+// validateProbes rejects probes that do not address a Service of the cluster or exceed the limits of a probe.
+func (m *Manifest) validateProbes() error {
+	fieldErrors := make(field.ErrorList, 0)
+	for i, probe := range m.Spec.Probes {
+		probePath := field.NewPath("spec").Child("probes").Index(i)
+		if _, err := probe.ServiceTarget(); err != nil {
+			fieldErrors = append(fieldErrors, field.Invalid(probePath.Child("url"), probe.URL, err.Error()))
+		}
+		for _, limit := range []struct {
+			name       string
+			value, max int
+		}{
+			{"timeoutSeconds", probe.TimeoutSeconds, MaxProbeTimeoutSeconds},
+			{"attempts", probe.Attempts, MaxProbeAttempts},
+			{"periodSeconds", probe.PeriodSeconds, MaxProbePeriodSeconds},
+		} {
+			if limit.value < 0 || limit.value > limit.max {
+				fieldErrors = append(fieldErrors, field.Invalid(probePath.Child(limit.name), limit.value,
+					fmt.Sprintf("must be between 0 and %d", limit.max)))
+			}
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: GroupVersion.Group, Kind: ManifestKind},
+			m.Name, fieldErrors)
+	}
+	return nil
+}
+
+// sourceFieldErrors reports every violation of the schema of an install source on the path of its field.
+func sourceFieldErrors(sourcePath *field.Path, validationErr *types.ValidationError) field.ErrorList {
+	fieldErrors := make(field.ErrorList, 0, len(validationErr.Fields))
+	for _, violation := range validationErr.Fields {
+		fieldPath := sourcePath
+		if violation.Field != "" {
+			for _, name := range strings.Split(violation.Field, ".") {
+				fieldPath = fieldPath.Child(name)
+			}
+		}
+		switch violation.Type {
+		case types.FieldErrorRequired:
+			fieldErrors = append(fieldErrors, field.Required(fieldPath, violation.Expected))
+		case types.FieldErrorNotAllowed:
+			fieldErrors = append(fieldErrors, field.Forbidden(fieldPath, violation.Expected))
+		default:
+			fieldErrors = append(fieldErrors, field.Invalid(fieldPath, violation.Value, violation.Expected))
+		}
+	}
+	return fieldErrors
+}
+
+// ManifestValidator validates Manifests like Manifest does and additionally rejects Manifests
+// that the controller is configured not to reconcile.
+type ManifestValidator struct {
+	// RemoteDisabled rejects Manifests with Spec.Remote in single-cluster installations.
+	RemoteDisabled bool
+}
+
+var _ webhook.CustomValidator = &ManifestValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ManifestValidator) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	manifest := obj.(*Manifest)
+	if err := manifest.ValidateCreate(); err != nil {
+		return err
+	}
+	return v.validateRemote(manifest)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ManifestValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) error {
+	manifest := newObj.(*Manifest)
+	if err := manifest.ValidateUpdate(oldObj); err != nil {
+		return err
+	}
+	// existing remote Manifests can still be updated, e.g. to remove their finalizers
+	if oldManifest, ok := oldObj.(*Manifest); ok && oldManifest.Spec.Remote {
+		return nil
+	}
+	return v.validateRemote(manifest)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type.
+func (v *ManifestValidator) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	return obj.(*Manifest).ValidateDelete()
+}
+
+func (v *ManifestValidator) validateRemote(manifest *Manifest) error {
+	if !v.RemoteDisabled || !manifest.Spec.Remote {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: GroupVersion.Group, Kind: ManifestKind},
+		manifest.Name, field.ErrorList{field.Forbidden(field.NewPath("spec").Child("remote"),
+			"remote installations are disabled in this single-cluster installation, set spec.remote to false")},
+	)
+}
@@ -23,14 +23,62 @@ package v1alpha1
 
 import (
 	"github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/kyma-project/module-manager/pkg/types"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomState) DeepCopyInto(out *CustomState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomState.
+func (in *CustomState) DeepCopy() *CustomState {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPProbe) DeepCopyInto(out *HTTPProbe) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPProbe.
+func (in *HTTPProbe) DeepCopy() *HTTPProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstallInfo) DeepCopyInto(out *InstallInfo) {
 	*out = *in
 	in.Source.DeepCopyInto(&out.Source)
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValuesReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.GeneratedValues != nil {
+		in, out := &in.GeneratedValues, &out.GeneratedValues
+		*out = make([]v2.GeneratedValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(v2.WaitStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallInfo.
@@ -132,7 +180,31 @@ func (in *ManifestSpec) DeepCopyInto(out *ManifestSpec) {
 		in, out := &in.Resource, &out.Resource
 		*out = (*in).DeepCopy()
 	}
+	if in.CustomStates != nil {
+		in, out := &in.CustomStates, &out.CustomStates
+		*out = make([]CustomState, len(*in))
+		copy(*out, *in)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = make([]HTTPProbe, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadinessRules != nil {
+		in, out := &in.ReadinessRules, &out.ReadinessRules
+		*out = make([]ReadinessRule, len(*in))
+		copy(*out, *in)
+	}
 	in.CRDs.DeepCopyInto(&out.CRDs)
+	if in.PreInstallCRDs != nil {
+		in, out := &in.PreInstallCRDs, &out.PreInstallCRDs
+		*out = new(types.ImageSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StateCR != nil {
+		in, out := &in.StateCR, &out.StateCR
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestSpec.
@@ -161,6 +233,23 @@ func (in *ManifestStatus) DeepCopyInto(out *ManifestStatus) {
 		copy(*out, *in)
 	}
 	in.LastOperation.DeepCopyInto(&out.LastOperation)
+	if in.Installs != nil {
+		in, out := &in.Installs, &out.Installs
+		*out = make([]v2.InstallStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Provenance != nil {
+		in, out := &in.Provenance, &out.Provenance
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Journal != nil {
+		in, out := &in.Journal, &out.Journal
+		*out = new(v2.OperationJournal)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestStatus.
@@ -172,3 +261,33 @@ func (in *ManifestStatus) DeepCopy() *ManifestStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessRule) DeepCopyInto(out *ReadinessRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessRule.
+func (in *ReadinessRule) DeepCopy() *ReadinessRule {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValuesReference) DeepCopyInto(out *ValuesReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValuesReference.
+func (in *ValuesReference) DeepCopy() *ValuesReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ValuesReference)
+	in.DeepCopyInto(out)
+	return out
+}
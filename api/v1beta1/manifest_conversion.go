@@ -0,0 +1,51 @@
+package v1beta1
+
+import (
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = &Manifest{}
+
+// ConvertTo converts the Manifest to the v1alpha1 Manifest, which is the storage version and the hub of conversions.
+func (m *Manifest) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1alpha1.Manifest)
+	dst.ObjectMeta = *m.ObjectMeta.DeepCopy()
+	spec := m.Spec.DeepCopy()
+	dst.Spec = v1alpha1.ManifestSpec{
+		Remote:            spec.Remote,
+		Config:            spec.Config,
+		Installs:          spec.Installs,
+		Resource:          spec.Resource,
+		CustomStates:      spec.CustomStates,
+		Probes:            spec.Probes,
+		ReadinessRules:    spec.ReadinessRules,
+		Remediation:       spec.Remediation,
+		RollbackOnFailure: spec.RollbackOnFailure,
+		CRDs:              spec.CRDs,
+	}
+	dst.Status = *m.Status.DeepCopy()
+	return nil
+}
+
+// ConvertFrom converts the v1alpha1 Manifest to the Manifest, fields of the former spec layout are moved
+// to their current counterparts with ConvertLegacySpec.
+func (m *Manifest) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1alpha1.Manifest).DeepCopy()
+	src.ConvertLegacySpec()
+	m.ObjectMeta = src.ObjectMeta
+	m.Spec = ManifestSpec{
+		Remote:            src.Spec.Remote,
+		Config:            src.Spec.Config,
+		Installs:          src.Spec.Installs,
+		Resource:          src.Spec.Resource,
+		CustomStates:      src.Spec.CustomStates,
+		Probes:            src.Spec.Probes,
+		ReadinessRules:    src.Spec.ReadinessRules,
+		Remediation:       src.Spec.Remediation,
+		RollbackOnFailure: src.Spec.RollbackOnFailure,
+		CRDs:              src.Spec.CRDs,
+	}
+	m.Status = src.Status
+	return nil
+}
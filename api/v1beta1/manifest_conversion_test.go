@@ -0,0 +1,93 @@
+package v1beta1_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/api/v1beta1"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestManifestConversion(t *testing.T) {
+	t.Parallel()
+	crds := types.ImageSpec{Repo: "europe-docker.pkg.dev/kyma", Name: "keda-crds", Ref: "sha256:crds"}
+	resource := &unstructured.Unstructured{}
+	resource.SetName("keda")
+	hub := &v1alpha1.Manifest{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda", Namespace: "kcp-system", Generation: 2},
+		Spec: v1alpha1.ManifestSpec{
+			Remote:         true,
+			Installs:       []v1alpha1.InstallInfo{{Name: "keda"}},
+			PreInstallCRDs: &crds,
+			StateCR:        resource,
+		},
+		Status: v1alpha1.ManifestStatus{State: declarative.StateReady},
+	}
+
+	spoke := &v1beta1.Manifest{}
+	require.NoError(t, spoke.ConvertFrom(hub))
+	assert.Equal(t, crds, spoke.Spec.CRDs, "legacy fields are moved to their current counterparts")
+	assert.Equal(t, resource, spoke.Spec.Resource)
+	assert.Equal(t, declarative.StateReady, spoke.Status.State)
+	assert.NotNil(t, hub.Spec.PreInstallCRDs, "the hub is not modified")
+
+	converted := &v1alpha1.Manifest{}
+	require.NoError(t, spoke.ConvertTo(converted))
+	assert.Equal(t, hub.ObjectMeta, converted.ObjectMeta)
+	assert.True(t, converted.Spec.Remote)
+	assert.Equal(t, crds, converted.Spec.CRDs)
+	assert.Nil(t, converted.Spec.PreInstallCRDs)
+	assert.Equal(t, hub.Spec.Installs, converted.Spec.Installs)
+}
+
+func TestManifestConversionRoundTrip(t *testing.T) {
+	t.Parallel()
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion("operator.kyma-project.io/v1alpha1")
+	resource.SetKind("Keda")
+	resource.SetName("default")
+	spoke := &v1beta1.Manifest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "keda", Namespace: "kcp-system", Generation: 3,
+			Labels: map[string]string{"operator.kyma-project.io/kyma-name": "kyma"},
+		},
+		Spec: v1beta1.ManifestSpec{
+			Remote: true,
+			Config: types.ImageSpec{Repo: "europe-docker.pkg.dev/kyma", Name: "keda-config", Ref: "sha256:config"},
+			Installs: []v1alpha1.InstallInfo{{
+				Name: "keda", NameOverride: true, Source: runtime.RawExtension{Raw: []byte(`{"name":"keda"}`)},
+			}},
+			Resource: resource,
+			CustomStates: []v1alpha1.CustomState{{
+				APIVersion: "apps/v1", Kind: "Deployment", Name: "keda-operator", Namespace: "keda",
+				Path: "status.phase", Value: "Running",
+			}},
+			Probes: []v1alpha1.HTTPProbe{{
+				Name: "metrics", URL: "http://keda-metrics.keda.svc:8080/healthz", ExpectedStatus: 204,
+			}},
+			ReadinessRules: []v1alpha1.ReadinessRule{{
+				APIVersion: "keda.sh/v1alpha1", Kind: "ScaledObject", Expression: `status.health == "Healthy"`,
+			}},
+			Remediation:       "Detect",
+			RollbackOnFailure: true,
+			CRDs:              types.ImageSpec{Repo: "europe-docker.pkg.dev/kyma", Name: "keda-crds", Ref: "sha256:crds"},
+		},
+		Status: v1alpha1.ManifestStatus{State: declarative.StateProcessing, ReleaseName: "keda"},
+	}
+
+	hub := &v1alpha1.Manifest{}
+	require.NoError(t, spoke.ConvertTo(hub))
+	converted := &v1beta1.Manifest{}
+	require.NoError(t, converted.ConvertFrom(hub))
+	assert.Equal(t, spoke, converted, "v1beta1 Manifests are preserved by the conversion to the hub and back")
+
+	convertedHub := &v1alpha1.Manifest{}
+	require.NoError(t, converted.ConvertTo(convertedHub))
+	assert.Equal(t, hub, convertedHub, "v1alpha1 Manifests are preserved by the conversion to v1beta1 and back")
+}
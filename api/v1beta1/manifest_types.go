@@ -0,0 +1,104 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+)
+
+// ManifestSpec defines the specification of Manifest.
+// It is the specification of v1alpha1 without the fields of the former spec layout.
+type ManifestSpec struct {
+	// Remote indicates if Manifest should be installed on a remote cluster
+	Remote bool `json:"remote"`
+
+	// Config specifies OCI image configuration for Manifest
+	Config types.ImageSpec `json:"config,omitempty"`
+
+	// Installs specifies a list of installations for Manifest
+	Installs []v1alpha1.InstallInfo `json:"installs"`
+
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+kubebuilder:validation:XEmbeddedResource
+	//+nullable
+	// Resource specifies a resource to be watched for state updates
+	Resource *unstructured.Unstructured `json:"resource,omitempty"`
+
+	// CustomStates specifies further resources whose state determines the readiness of Manifest
+	// +kubebuilder:validation:Optional
+	CustomStates []v1alpha1.CustomState `json:"customStates,omitempty"`
+
+	// Probes specifies endpoints that have to respond before Manifest is ready, e.g. health endpoints of gateways
+	// +kubebuilder:validation:Optional
+	Probes []v1alpha1.HTTPProbe `json:"probes,omitempty"`
+
+	// ReadinessRules specifies expressions that the installed resources of their kind have to fulfill
+	// before Manifest is ready, they take precedence over the readiness rules of the controller
+	// +kubebuilder:validation:Optional
+	ReadinessRules []v1alpha1.ReadinessRule `json:"readinessRules,omitempty"`
+
+	// Remediation defines how consistency checks handle installed resources that drifted in the cluster:
+	// "Enforce" reports and reverts the drift, "Detect" only reports it and "Off" disables both.
+	// Drift is reverted without being reported if empty
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Enforce;Detect;Off
+	Remediation string `json:"remediation,omitempty"`
+
+	// RollbackOnFailure restores the resources applied last if applying the resources of an update fails,
+	// instead of leaving them partially applied. Rollbacks are reported in the Rollback condition
+	// +kubebuilder:validation:Optional
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+
+	// CRDs specifies the custom resource definitions' ImageSpec
+	CRDs types.ImageSpec `json:"crds,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="State",type=string,JSONPath=".status.state"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// Manifest is the Schema for the manifests API.
+type Manifest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec specifies the content and configuration for Manifest
+	Spec ManifestSpec `json:"spec,omitempty"`
+
+	// Status signifies the current status of the Manifest
+	// +kubebuilder:validation:Optional
+	Status v1alpha1.ManifestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ManifestList contains a list of Manifest.
+type ManifestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []Manifest `json:"items"`
+}
+
+//nolint:gochecknoinits
+func init() {
+	SchemeBuilder.Register(&Manifest{}, &ManifestList{})
+}
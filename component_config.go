@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kyma-project/module-manager/internal/config"
+)
+
+var ErrUnknownFeatureGate = errors.New("unknown feature gate")
+
+// featureGates are the boolean flags that can be set through the FeatureGates of the ComponentConfig.
+var featureGates = map[string]bool{
+	"enable-listener":            true,
+	"check-ready-states":         true,
+	"custom-state-check":         true,
+	"insecure-registry":          true,
+	"require-image-digests":      true,
+	"enable-metadata-informers":  true,
+	"wait-for-webhooks":          true,
+	"strict-field-validation":    true,
+	"cluster-readiness-check":    true,
+	"stable-names":               true,
+	"last-applied-configuration": true,
+	"disable-remote":             true,
+	"allow-insecure-remote-tls":  true,
+	"enable-webhooks":            true,
+	"enable-pprof":               true,
+	"kustomize-enable-helm":      true,
+	"kustomize-enable-functions": true,
+	"kustomize-function-network": true,
+	"kustomize-enable-exec":      true,
+}
+
+// applyComponentConfig loads the ComponentConfig file and overrides all flags
+// that have not been set explicitly on the command line with the values from the file.
+func applyComponentConfig(path string) error {
+	componentConfig, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+
+	explicitlySet := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicitlySet[f.Name] = true })
+
+	values, err := flagValuesFromComponentConfig(componentConfig)
+	if err != nil {
+		return fmt.Errorf("applying %s failed: %w", path, err)
+	}
+	for name, value := range values {
+		if explicitlySet[name] {
+			continue
+		}
+		if err := flag.Set(name, value); err != nil {
+			return fmt.Errorf("applying %s from %s failed: %w", name, path, err)
+		}
+	}
+	return nil
+}
+
+func flagValuesFromComponentConfig(componentConfig *config.ModuleManagerConfiguration) (map[string]string, error) {
+	values := make(map[string]string)
+
+	setString := func(name, value string) {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	setInt := func(name string, value *int) {
+		if value != nil {
+			values[name] = strconv.Itoa(*value)
+		}
+	}
+
+	setString("health-probe-bind-address", componentConfig.Health.HealthProbeBindAddress)
+	setString("metrics-bind-address", componentConfig.Metrics.BindAddress)
+	if leaderElection := componentConfig.LeaderElection; leaderElection != nil && leaderElection.LeaderElect != nil {
+		values["leader-elect"] = strconv.FormatBool(*leaderElection.LeaderElect)
+	}
+	if componentConfig.RequeueSuccessInterval != nil {
+		values["requeue-success-interval"] = componentConfig.RequeueSuccessInterval.Duration.String()
+	}
+	setInt("max-concurrent-reconciles", componentConfig.MaxConcurrentReconciles)
+	setInt("workers-concurrent-manifest", componentConfig.WorkersConcurrentManifests)
+	if rateLimiter := componentConfig.RateLimiter; rateLimiter != nil {
+		setInt("rate-limiter-burst", rateLimiter.Burst)
+		setInt("rate-limiter-frequency", rateLimiter.Frequency)
+		if rateLimiter.FailureBaseDelay != nil {
+			values["failure-base-delay"] = rateLimiter.FailureBaseDelay.Duration.String()
+		}
+		if rateLimiter.FailureMaxDelay != nil {
+			values["failure-max-delay"] = rateLimiter.FailureMaxDelay.Duration.String()
+		}
+	}
+	if clientConfig := componentConfig.Client; clientConfig != nil {
+		if clientConfig.QPS != nil {
+			values["k8s-client-qps"] = strconv.FormatFloat(*clientConfig.QPS, 'f', -1, 64)
+		}
+		setInt("k8s-client-burst", clientConfig.Burst)
+	}
+	if componentConfig.CacheSyncTimeout != nil {
+		values["cache-sync-timeout"] = componentConfig.CacheSyncTimeout.Duration.String()
+	}
+	if componentConfig.OperationTimeout != nil {
+		values["operation-timeout"] = componentConfig.OperationTimeout.Duration.String()
+	}
+	if componentConfig.InstallOperationTimeout != nil {
+		values["install-operation-timeout"] = componentConfig.InstallOperationTimeout.Duration.String()
+	}
+	if componentConfig.InstallRequeueInterval != nil {
+		values["install-requeue-interval"] = componentConfig.InstallRequeueInterval.Duration.String()
+	}
+	if componentConfig.DependencyRequeueInterval != nil {
+		values["dependency-requeue-interval"] = componentConfig.DependencyRequeueInterval.Duration.String()
+	}
+	if componentConfig.VersionResyncInterval != nil {
+		values["version-resync-interval"] = componentConfig.VersionResyncInterval.Duration.String()
+	}
+	setInt("max-concurrent-extractions", componentConfig.MaxConcurrentExtractions)
+	setInt("extraction-disk-budget", componentConfig.ExtractionDiskBudget)
+	if renderLimits := componentConfig.RenderLimits; renderLimits != nil {
+		setInt("max-rendered-bytes", renderLimits.MaxBytes)
+		setInt("max-rendered-objects", renderLimits.MaxObjects)
+	}
+	setString("cache-dir", componentConfig.CacheDir)
+	setString("helm-keyring", componentConfig.HelmKeyring)
+	setString("global-values-file", componentConfig.GlobalValuesFile)
+	setString("readiness-rules-file", componentConfig.ReadinessRulesFile)
+	setString("audit-log", componentConfig.AuditLog)
+	if prePull := componentConfig.PrePull; prePull != nil {
+		setString("pre-pull-file", prePull.File)
+		if prePull.Interval != nil {
+			values["pre-pull-interval"] = prePull.Interval.Duration.String()
+		}
+	}
+	setString("secret-label-selector", componentConfig.SecretLabelSelector)
+	setString("kustomize-mirror", componentConfig.KustomizeMirror)
+	setString("kustomize-helm-command", componentConfig.KustomizeHelmCommand)
+	setString("release-name-template", componentConfig.ReleaseNameTemplate)
+	setString("vault-address", componentConfig.VaultAddress)
+	setString("vault-token-file", componentConfig.VaultTokenFile)
+	setString("vault-path-prefix", componentConfig.VaultPathPrefix)
+	setString("secret-exec-command", componentConfig.SecretExecCommand)
+	setString("redact-keys", strings.Join(componentConfig.RedactKeys, ","))
+	setString("redact-allowed-keys", strings.Join(componentConfig.RedactAllowedKeys, ","))
+	setString("listener-address", componentConfig.ListenerAddress)
+	setString("listener-path", componentConfig.ListenerPath)
+	if componentConfig.SecretCacheTTL != nil {
+		values["secret-cache-ttl"] = componentConfig.SecretCacheTTL.Duration.String()
+	}
+	if componentConfig.ListenerStalenessTimeout != nil {
+		values["listener-staleness-timeout"] = componentConfig.ListenerStalenessTimeout.Duration.String()
+	}
+	for name, enabled := range componentConfig.FeatureGates {
+		if !featureGates[name] {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownFeatureGate, name)
+		}
+		values[name] = strconv.FormatBool(enabled)
+	}
+	setInt("log-level", componentConfig.LogLevel)
+	if logSampling := componentConfig.LogSampling; logSampling != nil {
+		setInt("log-sampling-initial", logSampling.Initial)
+		setInt("log-sampling-thereafter", logSampling.Thereafter)
+	}
+
+	return values, nil
+}
@@ -1,37 +1,248 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"text/template"
 	"time"
 
 	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal"
 	internalv1alpha1 "github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
 	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
 	"github.com/kyma-project/module-manager/pkg/labels"
 	"github.com/kyma-project/module-manager/pkg/types"
+	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
+// ReconcilerSettings contains the settings of the Manifest reconciler that are determined on startup.
+type ReconcilerSettings struct {
+	// Insecure indicates if insecure (http) responses are expected from image registries.
+	Insecure bool
+	// CacheDir is the directory in which charts and rendered manifests are cached.
+	CacheDir string
+	// CheckInterval determines the interval of the consistency check and is evaluated on every reconciliation.
+	CheckInterval func() time.Duration
+	// ActiveReconciles optionally limits the number of active reconciliations below MaxConcurrentReconciles
+	// and is evaluated on every reconciliation.
+	ActiveReconciles func() int
+	// InstallWorkers and ConsistencyWorkers limit the active reconciliations that install or check Manifests,
+	// so that the remaining workers are kept for deletions, see internal.OperationPoolReconciler. 0 disables a limit.
+	InstallWorkers     int
+	ConsistencyWorkers int
+	// OperationTimeout bounds the operations of a single reconciliation, 0 disables the timeout.
+	OperationTimeout time.Duration
+	// InstallTimeout replaces the OperationTimeout of Manifests that were never ready, 0 keeps the OperationTimeout.
+	InstallTimeout time.Duration
+	// InstallInterval requeues Manifests that were never ready while they wait for their resources,
+	// 0 keeps the backoff of the rate limiter.
+	InstallInterval time.Duration
+	// DependencyInterval requeues Manifests that wait for CRDs or namespaces of other modules.
+	DependencyInterval time.Duration
+	// RetryBudgetFailures stops retrying Manifests after as many consecutive failures within the RetryBudgetWindow
+	// after the first of them, see declarative.WithRetryBudget. 0 retries forever.
+	RetryBudgetFailures int
+	RetryBudgetWindow   time.Duration
+	// MaxRenderedBytes and MaxRenderedObjects reject rendered manifests above the limits, 0 disables a limit.
+	MaxRenderedBytes   int
+	MaxRenderedObjects int
+	// SharedRenderCacheBytes is the size of the declarative.SharedRenderCache, 0 disables it.
+	SharedRenderCacheBytes int
+	// FailureRateLimiter optionally observes the Manifests for per-object overrides of the failure backoff,
+	// it has to be part of the rate limiter of the controller.
+	FailureRateLimiter *internal.AnnotatedFailureRateLimiter
+	// SecretProviders resolve the ValuesFrom of installs by their name.
+	SecretProviders map[string]internal.SecretProvider
+	// SecretCacheTTL bounds the time resolved secrets are cached for, 0 keeps internal.DefaultSecretTTL.
+	SecretCacheTTL time.Duration
+	// RequireImageDigests rejects OCI images of Manifests that are referenced by tag instead of digest.
+	RequireImageDigests bool
+	// GlobalValues are set for every install below the values of the install.
+	GlobalValues map[string]any
+	// ReadinessRules decide the readiness of installed resources of their kind unless a Manifest overrides them.
+	ReadinessRules []v1alpha1.ReadinessRule
+	// HelmKeyring is the path to the public keyring used to verify the provenance of repository charts.
+	HelmKeyring string
+	// KustomizeMirror optionally contains git mirrors of kustomize remotes at <host>/<path>, e.g. for offline use.
+	KustomizeMirror string
+	// KustomizePlugins enables plugins of kustomizations, all of them are disabled by default.
+	KustomizePlugins declarative.KustomizePlugins
+	// RemoteDisabled rejects Manifests with Spec.Remote and drops the watches that only serve remote clusters.
+	RemoteDisabled bool
+	// ListenerHealth records the events of the watchers of remote clusters if the listener is enabled,
+	// Manifests report watchers without recent events in the ListenerStale condition.
+	ListenerHealth *internal.ListenerHealth
+	// AllowInsecureRemoteTLS allows kubeconfig secrets of remote clusters to skip the TLS verification.
+	AllowInsecureRemoteTLS bool
+	// SecretSelector optionally restricts the watched Secrets, e.g. to kubeconfig secrets labeled with the Kyma name.
+	SecretSelector *metav1.LabelSelector
+	// MetadataInformers serves the consistency checks from informers of the target clusters.
+	MetadataInformers bool
+	// WaitForWebhooks delays the ready state until the webhooks of the rendered resources are serving.
+	WaitForWebhooks bool
+	// StrictFieldValidation rejects rendered resources with unknown fields,
+	// see declarative.WithStrictFieldValidation.
+	StrictFieldValidation bool
+	// ClusterReadiness holds the first install to a cluster until it is bootstrapped,
+	// see declarative.NewBootstrapReadinessCheck.
+	ClusterReadiness bool
+	// StableNames removes versions from the names of rendered resources, see declarative.WithStableNames.
+	StableNames bool
+	// LastAppliedConfiguration records the applied resources of every Manifest in a Secret next to it,
+	// see declarative.WithLastAppliedConfiguration.
+	LastAppliedConfiguration bool
+	// RenderOnly replaces the Manifest controller with a RenderWorker of the RenderShard of RenderShards.
+	RenderOnly                bool
+	RenderShard, RenderShards int
+	// MetadataPropagation selects the labels and annotations of Manifests that are copied onto their default CR
+	// and workloads, see declarative.WithMetadataPropagation.
+	MetadataPropagation declarative.MetadataPropagation
+	// ReleaseNameTemplate optionally replaces declarative.DefaultReleaseNameTemplate.
+	ReleaseNameTemplate *template.Template
+	// AuditLog optionally records the operations of Manifests, see NewAuditLog.
+	AuditLog declarative.AuditLog
+	// Version is recorded in the status of every applied Manifest, Manifests applied by another version
+	// are resynced once every VersionResyncInterval after an upgrade. An empty Version disables the resync.
+	Version               string
+	VersionResyncInterval time.Duration
+	// PrePullFile optionally contains Manifests whose artifacts are pre-pulled into the cache whenever it changes,
+	// it is checked for changes in the PrePullInterval. Pre-pulling is disabled if PrePullInterval is not positive.
+	PrePullFile     string
+	PrePullInterval time.Duration
+}
+
+const (
+	// AuditLogEvents records the operations of Manifests as annotated events for an event exporter.
+	AuditLogEvents = "events"
+	// AuditLogConfigMap records the operations of Manifests in a ConfigMap next to every Manifest.
+	AuditLogConfigMap = "configmap"
+)
+
+var ErrUnknownAuditLog = errors.New("unknown audit log")
+
+// NewAuditLog returns the AuditLog of the given backend, it returns nil if backend is empty.
+func NewAuditLog(mgr manager.Manager, backend string) (declarative.AuditLog, error) {
+	switch backend {
+	case "":
+		return nil, nil //nolint:nilnil
+	case AuditLogEvents:
+		return &declarative.EventAuditLog{EventRecorder: mgr.GetEventRecorderFor(declarative.EventRecorderDefault)}, nil
+	case AuditLogConfigMap:
+		return &declarative.ConfigMapAuditLog{Client: mgr.GetClient()}, nil
+	default:
+		return nil, fmt.Errorf("%w: %q, expected %q or %q", ErrUnknownAuditLog, backend,
+			AuditLogEvents, AuditLogConfigMap)
+	}
+}
+
+// ControllerName names the Manifest controller, e.g. in the workqueue metrics, the queue of the events of remote
+// clusters is named ControllerName with internal.ListenerQueueSuffix.
+const ControllerName = "manifest"
+
 func SetupWithManager(
 	mgr manager.Manager,
 	eventChannel source.Source,
 	codec *types.Codec,
 	options controller.Options,
-	insecure bool,
-	checkInterval time.Duration,
+	settings ReconcilerSettings,
 ) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&v1alpha1.Manifest{}).
-		Watches(&source.Kind{Type: &v1.Secret{}}, handler.Funcs{}).
-		Watches(
+	manifestReconciler := ManifestReconciler(mgr, codec, settings)
+	if settings.RenderOnly {
+		return mgr.Add(&RenderWorker{
+			Reconciler: manifestReconciler,
+			Client:     mgr.GetClient(),
+			Interval:   RenderWorkerInterval,
+			Workers:    options.MaxConcurrentReconciles,
+			Shard:      settings.RenderShard,
+			Shards:     settings.RenderShards,
+			Log:        ctrl.Log.WithName("render-worker"),
+		})
+	}
+	var reconciler reconcile.Reconciler = manifestReconciler
+	if settings.InstallWorkers > 0 || settings.ConsistencyWorkers > 0 {
+		reconciler = internal.NewOperationPoolReconciler(reconciler, manifestOperation(mgr.GetClient()),
+			map[internal.Operation]int{
+				internal.OperationInstall:     settings.InstallWorkers,
+				internal.OperationConsistency: settings.ConsistencyWorkers,
+			})
+	}
+	if settings.ActiveReconciles != nil {
+		reconciler = internal.NewConcurrencyLimitedReconciler(reconciler, settings.ActiveReconciles)
+	}
+	tracker := internal.NewQueueTracker(targetClusterOf)
+	reconciler = tracker.Reconciler(reconciler)
+	if err := mgr.AddMetricsExtraHandler(internal.DefaultQueueStatePath, tracker); err != nil {
+		return err
+	}
+	if settings.PrePullFile != "" && settings.PrePullInterval > 0 {
+		// the artifacts are pre-pulled with the resolver of the reconciler, so that they are cached for it
+		if err := mgr.Add(&internalv1alpha1.PrePuller{
+			Path:     settings.PrePullFile,
+			Interval: settings.PrePullInterval,
+			Resolver: manifestReconciler.SpecResolver.(*internalv1alpha1.ManifestSpecResolver),
+			Log:      ctrl.Log.WithName("pre-pull"),
+		}); err != nil {
+			return err
+		}
+	}
+
+	// predicates are evaluated in order until one drops the event, the failure delays are observed from all events,
+	// while the tracker only observes events that are not dropped because of unchanged specs.
+	var predicates []predicate.Predicate
+	if settings.FailureRateLimiter != nil {
+		predicates = append(predicates, settings.FailureRateLimiter.Predicate())
+	}
+	specChanged := declarative.DefaultSpecChangedPredicate()
+	specChanged.Labels = append(specChanged.Labels, labels.KymaName)
+	predicates = append(predicates, specChanged, tracker.Predicate())
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		Named(ControllerName).
+		For(&v1alpha1.Manifest{}, ctrlbuilder.WithPredicates(predicates...))
+	// kubeconfig secrets are only relevant if Manifests can be installed remotely
+	if !settings.RemoteDisabled {
+		var secretPredicates []predicate.Predicate
+		if settings.SecretSelector != nil {
+			selected, err := kubeConfigSecretPredicate(mgr.GetClient(), *settings.SecretSelector)
+			if err != nil {
+				return err
+			}
+			secretPredicates = append(secretPredicates, selected)
+		}
+		builder = builder.Watches(
+			&source.Kind{Type: &v1.Secret{}}, invalidateClientOnSecretChange(manifestReconciler),
+			ctrlbuilder.WithPredicates(secretPredicates...),
+		)
+	}
+	// eventChannel is nil if the listener for events of remote clusters is not enabled
+	if eventChannel != nil {
+		listenerQueue := internal.NewListenerQueue(ControllerName + internal.ListenerQueueSuffix)
+		if err := mgr.Add(listenerQueue); err != nil {
+			return err
+		}
+		if settings.ListenerHealth != nil {
+			if err := mgr.AddMetricsExtraHandler(internal.DefaultListenerHealthPath, settings.ListenerHealth); err != nil {
+				return err
+			}
+			builder = builder.Watches(&source.Kind{Type: &v1alpha1.Manifest{}},
+				forgetListenerClusterOnDelete(mgr.GetClient(), settings.ListenerHealth))
+		}
+		builder = builder.Watches(
 			eventChannel, &handler.Funcs{
 				GenericFunc: func(event event.GenericEvent, queue workqueue.RateLimitingInterface) {
 					ctrl.Log.WithName("listener").Info(
@@ -40,31 +251,224 @@ func SetupWithManager(
 							client.ObjectKeyFromObject(event.Object).String(),
 						),
 					)
-					queue.Add(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(event.Object)})
+					listenerQueue.Add(client.ObjectKeyFromObject(event.Object), queue)
+					tracker.Enqueued(client.ObjectKeyFromObject(event.Object), time.Now())
+					if settings.ListenerHealth != nil {
+						settings.ListenerHealth.Observed(listenerEventCluster(mgr.GetClient(), event.Object), time.Now())
+					}
 				},
 			},
-		).WithOptions(options).Complete(ManifestReconciler(mgr, codec, insecure, checkInterval))
+		)
+	}
+	return builder.WithOptions(options).Complete(reconciler)
+}
+
+// forgetListenerClusterOnDelete drops the listener health of a remote cluster, including its metric series,
+// once the last Manifest of the cluster was deleted.
+func forgetListenerClusterOnDelete(reader client.Reader, health *internal.ListenerHealth) handler.Funcs {
+	return handler.Funcs{
+		DeleteFunc: func(event event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			cluster := event.Object.GetLabels()[labels.KymaName]
+			if cluster == "" {
+				return
+			}
+			remaining := &v1alpha1.ManifestList{}
+			if err := reader.List(context.Background(), remaining,
+				client.MatchingLabels{labels.KymaName: cluster}); err != nil {
+				return
+			}
+			for i := range remaining.Items {
+				if remaining.Items[i].GetUID() != event.Object.GetUID() {
+					return
+				}
+			}
+			health.Forget(cluster)
+		},
+	}
+}
+
+// controlPlaneCluster identifies Manifests installed into the control plane in the QueueState.
+const controlPlaneCluster = "control-plane"
+
+// targetClusterOf groups Manifests by the Kyma they are installed to, matching the client cache key.
+func targetClusterOf(obj client.Object) string {
+	if manifest, ok := obj.(*v1alpha1.Manifest); ok && !manifest.Spec.Remote {
+		return controlPlaneCluster
+	}
+	return obj.GetLabels()[labels.KymaName]
+}
+
+// listenerEventCluster determines the remote cluster that sent an event from the labels of the Manifest,
+// the events of the watchers only identify the Manifest.
+func listenerEventCluster(reader client.Reader, obj client.Object) string {
+	if cluster := obj.GetLabels()[labels.KymaName]; cluster != "" {
+		return cluster
+	}
+	manifest := &v1alpha1.Manifest{}
+	if err := reader.Get(context.Background(), client.ObjectKeyFromObject(obj), manifest); err != nil {
+		return ""
+	}
+	return manifest.GetLabels()[labels.KymaName]
+}
+
+// manifestOperation classifies reconciliations by the cached Manifest. Manifests that are gone or cannot be read
+// are treated as deletions, as their reconciliation only cleans up or fails fast.
+func manifestOperation(reader client.Reader) internal.OperationClassifier {
+	return func(ctx context.Context, req ctrl.Request) internal.Operation {
+		manifest := &v1alpha1.Manifest{}
+		if err := reader.Get(ctx, req.NamespacedName, manifest); err != nil {
+			return internal.OperationDelete
+		}
+		status := manifest.Status
+		switch {
+		case !manifest.GetDeletionTimestamp().IsZero():
+			return internal.OperationDelete
+		case status.State == declarative.StateReady && status.ObservedGeneration == manifest.GetGeneration():
+			return internal.OperationConsistency
+		default:
+			return internal.OperationInstall
+		}
+	}
 }
 
 func ManifestReconciler(
-	mgr manager.Manager, codec *types.Codec, insecure bool,
-	checkInterval time.Duration,
+	mgr manager.Manager, codec *types.Codec, settings ReconcilerSettings,
 ) *declarative.Reconciler {
-	return declarative.NewFromManager(
-		mgr, &v1alpha1.Manifest{},
-		declarative.WithSpecResolver(
-			internalv1alpha1.NewManifestSpecResolver(codec, insecure),
-		),
-		declarative.WithCustomReadyCheck(internalv1alpha1.NewManifestCustomResourceReadyCheck()),
-		declarative.WithRemoteTargetCluster(
-			(&internalv1alpha1.RemoteClusterLookup{KCP: &types.ClusterInfo{
-				Client: mgr.GetClient(),
-				Config: mgr.GetConfig(),
-			}}).ConfigResolver,
-		),
+	cacheDir := settings.CacheDir
+	if cacheDir == "" {
+		cacheDir = os.TempDir()
+	}
+	specResolver := internalv1alpha1.NewManifestSpecResolver(codec, settings.Insecure)
+	specResolver.KCP = mgr.GetClient()
+	specResolver.ChartCache = cacheDir
+	specResolver.RepoIndexCache.CacheDir = cacheDir
+	specResolver.Keyring = settings.HelmKeyring
+	specResolver.GlobalValues = settings.GlobalValues
+	specResolver.RequireDigests = settings.RequireImageDigests
+	specResolver.EventRecorder = mgr.GetEventRecorderFor(declarative.EventRecorderDefault)
+	specResolver.KustomizeRemotes.CacheDir = cacheDir
+	specResolver.KustomizeRemotes.MirrorDir = settings.KustomizeMirror
+	specResolver.KustomizeRemotes.ResolveInterval = settings.CheckInterval
+	specResolver.RawManifests.CacheDir = cacheDir
+	if len(settings.SecretProviders) > 0 {
+		specResolver.SecretResolver = internal.NewSecretResolver(settings.SecretProviders)
+		if settings.SecretCacheTTL > 0 {
+			specResolver.SecretResolver.TTL = settings.SecretCacheTTL
+		}
+	}
+	clusterLookup := &internalv1alpha1.RemoteClusterLookup{KCP: &types.ClusterInfo{
+		Client: mgr.GetClient(),
+		Config: mgr.GetConfig(),
+	}, RemoteDisabled: settings.RemoteDisabled, AllowInsecureTLS: settings.AllowInsecureRemoteTLS}
+	readyCheck := internalv1alpha1.NewManifestCustomResourceReadyCheck()
+	readyCheck.ReadinessRules = settings.ReadinessRules
+	options := []declarative.Option{
+		declarative.WithSpecResolver(specResolver),
+		declarative.WithCustomReadyCheck(readyCheck),
+		declarative.WithRemoteTargetCluster(clusterLookup.ConfigResolver),
+		declarative.WithClusterVersion(clusterLookup.ClusterVersion),
 		declarative.WithClientCacheKeyFromLabelOrResource(labels.KymaName),
-		declarative.WithPostRun{internalv1alpha1.PostRunCreateCR},
+		declarative.WithPostRun{internalv1alpha1.PostRunCreateCRWithMetadata(settings.MetadataPropagation)},
 		declarative.WithPreDelete{internalv1alpha1.PreDeleteDeleteCR},
-		declarative.WithPeriodicConsistencyCheck(checkInterval),
-	)
+		declarative.WithDynamicConsistencyCheck(settings.CheckInterval),
+		declarative.WithManifestCache(cacheDir),
+		declarative.WithOperationTimeout(settings.OperationTimeout),
+		declarative.WithInstallOperationTimeout(settings.InstallTimeout),
+		declarative.WithInstallRequeueInterval(settings.InstallInterval),
+		declarative.WithDependencyRequeueInterval(settings.DependencyInterval),
+		declarative.WithRetryBudget(settings.RetryBudgetFailures, settings.RetryBudgetWindow),
+		declarative.WithRenderLimits(settings.MaxRenderedBytes, settings.MaxRenderedObjects),
+		declarative.WithMetadataDriftCheck(true),
+		declarative.WithRemediation(func(obj declarative.Object) declarative.RemediationMode {
+			return declarative.RemediationMode(obj.(*v1alpha1.Manifest).Spec.Remediation)
+		}),
+		declarative.WithRollbackOnFailure(func(obj declarative.Object) bool {
+			return obj.(*v1alpha1.Manifest).Spec.RollbackOnFailure
+		}),
+		declarative.WithKustomizePlugins(settings.KustomizePlugins),
+		declarative.WithWaitForWebhooks(settings.WaitForWebhooks),
+		declarative.WithStrictFieldValidation(settings.StrictFieldValidation),
+	}
+	if settings.SharedRenderCacheBytes > 0 {
+		options = append(options, declarative.WithSharedRenderCache(
+			declarative.NewSharedRenderCache(int64(settings.SharedRenderCacheBytes)),
+		))
+	}
+	if settings.ListenerHealth != nil {
+		options = append(options, declarative.WithPostRun{
+			internalv1alpha1.PostRunListenerStaleness(settings.ListenerHealth),
+		})
+	}
+	if settings.MetadataInformers {
+		options = append(options, declarative.WithMetadataInformerCache(declarative.NewMetadataInformerCache()))
+	}
+	if settings.ClusterReadiness {
+		options = append(options, declarative.WithClusterReadinessCheck(declarative.NewBootstrapReadinessCheck()))
+	}
+	if len(settings.MetadataPropagation.Labels) > 0 || len(settings.MetadataPropagation.Annotations) > 0 {
+		options = append(options, declarative.WithMetadataPropagation(settings.MetadataPropagation))
+	}
+	if settings.StableNames {
+		options = append(options, declarative.WithStableNames())
+	}
+	if settings.LastAppliedConfiguration {
+		options = append(options, declarative.WithLastAppliedConfiguration(true))
+	}
+	if settings.ReleaseNameTemplate != nil {
+		options = append(options, declarative.WithReleaseNameTemplate(settings.ReleaseNameTemplate))
+	}
+	if settings.Version != "" {
+		var limiter *rate.Limiter
+		if settings.VersionResyncInterval > 0 {
+			limiter = rate.NewLimiter(rate.Every(settings.VersionResyncInterval), 1)
+		}
+		options = append(options, declarative.WithReconcilerVersion(settings.Version, limiter))
+	}
+	if settings.AuditLog != nil {
+		options = append(options, declarative.WithAuditLog(settings.AuditLog))
+	}
+	return declarative.NewFromManager(mgr, &v1alpha1.Manifest{}, options...)
+}
+
+// kubeConfigSecretPredicate passes the Secrets matching selector. As kubeconfig secrets are looked up by the name
+// of their Kyma if no Secret is labeled with it, Secrets named like the Kyma of a Manifest in their namespace pass
+// as well, so that their changes still invalidate the clients.
+func kubeConfigSecretPredicate(clnt client.Reader, selector metav1.LabelSelector) (predicate.Predicate, error) {
+	selected, err := predicate.LabelSelectorPredicate(selector)
+	if err != nil {
+		return nil, err
+	}
+	named := predicate.NewPredicateFuncs(func(secret client.Object) bool {
+		manifests := &v1alpha1.ManifestList{}
+		if err := clnt.List(context.Background(), manifests, client.InNamespace(secret.GetNamespace()),
+			client.MatchingLabels{labels.KymaName: secret.GetName()}, client.Limit(1)); err != nil {
+			// invalidating a client that is still valid only recreates it
+			return true
+		}
+		return len(manifests.Items) > 0
+	})
+	return predicate.Or(selected, named), nil
+}
+
+// invalidateClientOnSecretChange drops the cached client of a remote cluster once its kubeconfig secret
+// is updated or deleted. The cache key matches declarative.WithClientCacheKeyFromLabelOrResource(labels.KymaName),
+// as kubeconfig secrets are either labeled with the Kyma name or named after it.
+func invalidateClientOnSecretChange(reconciler *declarative.Reconciler) handler.Funcs {
+	invalidate := func(secret client.Object) {
+		kymaName, found := secret.GetLabels()[labels.KymaName]
+		if !found {
+			kymaName = secret.GetName()
+		}
+		reconciler.InvalidateClient(client.ObjectKey{Name: kymaName, Namespace: secret.GetNamespace()})
+	}
+	return handler.Funcs{
+		UpdateFunc: func(event event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+			if event.ObjectOld.GetResourceVersion() != event.ObjectNew.GetResourceVersion() {
+				invalidate(event.ObjectNew)
+			}
+		},
+		DeleteFunc: func(event event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			invalidate(event.Object)
+		},
+	}
 }
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RenderWorkerInterval is the interval in which a RenderWorker looks for Manifests to prerender.
+const RenderWorkerInterval = 10 * time.Second
+
+// RenderWorker runs in render-only instances that scale the pulling and rendering of large fleets horizontally.
+// It prerenders the Manifests that wait for their install into the manifest cache, which has to be a volume
+// shared with the reconciling instance and mounted at the same path, see declarative.Reconciler.Prerender.
+// The pending Manifests are the queue shared by all render-only instances, every instance renders the Manifests
+// of its Shard. As it never writes to the Manifests, it implements manager.Runnable without leader election.
+type RenderWorker struct {
+	Reconciler *declarative.Reconciler
+	Client     client.Reader
+	Interval   time.Duration
+	// Workers is the number of Manifests rendered concurrently.
+	Workers int
+	// Shard is the index of this instance in the Shards render-only instances.
+	Shard, Shards int
+	Log           logr.Logger
+
+	// rendered holds the generation of every Manifest that was prerendered successfully.
+	rendered map[client.ObjectKey]int64
+}
+
+func (w *RenderWorker) Start(ctx context.Context) error {
+	w.rendered = make(map[client.ObjectKey]int64)
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		w.prerender(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *RenderWorker) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *RenderWorker) prerender(ctx context.Context) {
+	manifests := &v1alpha1.ManifestList{}
+	if err := w.Client.List(ctx, manifests); err != nil {
+		w.Log.Error(err, "listing manifests to prerender failed")
+		return
+	}
+
+	// Manifests that are no longer pending are dropped, so that they are rendered again once they are updated
+	rendered := make(map[client.ObjectKey]int64)
+	var mu sync.Mutex
+	group, groupCtx := errgroup.WithContext(ctx)
+	if w.Workers > 0 {
+		group.SetLimit(w.Workers)
+	}
+	for i := range manifests.Items {
+		manifest := &manifests.Items[i]
+		key, generation := client.ObjectKeyFromObject(manifest), manifest.GetGeneration()
+		if !w.owns(key) || !isPendingInstall(manifest) {
+			continue
+		}
+		if last, found := w.rendered[key]; found && last == generation {
+			mu.Lock()
+			rendered[key] = generation
+			mu.Unlock()
+			continue
+		}
+		group.Go(func() error {
+			logger := w.Log.WithValues("manifest", key)
+			prerendered, err := w.Reconciler.Prerender(log.IntoContext(groupCtx, logger), manifest)
+			if err != nil {
+				// failed prerenders are retried with the next interval and by the reconciling instance
+				logger.V(internal.DebugLogLevel).Info("prerender failed", "error", err.Error())
+				return nil
+			}
+			if !prerendered {
+				// skipped prerenders are retried once the reconciling instance prepared the Manifest
+				return nil
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			rendered[key] = generation
+			return nil
+		})
+	}
+	_ = group.Wait()
+	w.Log.V(internal.DebugLogLevel).Info("manifests prerendered", "rendered", len(rendered))
+	w.rendered = rendered
+}
+
+// owns determines if the Manifest of key belongs to the Shard of the worker.
+func (w *RenderWorker) owns(key client.ObjectKey) bool {
+	if w.Shards <= 1 {
+		return true
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(key.String()))
+	return int(hash.Sum32()%uint32(w.Shards)) == w.Shard
+}
+
+// isPendingInstall is true for Manifests whose current generation was not applied yet.
+func isPendingInstall(manifest *v1alpha1.Manifest) bool {
+	return manifest.GetDeletionTimestamp().IsZero() &&
+		(manifest.Status.State != declarative.StateReady ||
+			manifest.Status.ObservedGeneration != manifest.GetGeneration())
+}
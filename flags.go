@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+)
+
+var ErrInvalidFlag = errors.New("invalid flag")
+
+// Validate checks the flags after they were parsed and merged with the ComponentConfig, so that invalid values
+// fail the startup with all violations at once instead of panics or busy loops once the manager is running.
+func (f *FlagVar) Validate() error {
+	var errs []error
+	nonNegative := func(name string, value int) {
+		if value < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s must not be negative, got %d", ErrInvalidFlag, name, value))
+		}
+	}
+	nonNegativeDuration := func(name string, value time.Duration) {
+		if value < 0 {
+			errs = append(errs, fmt.Errorf("%w: %s must not be negative, got %s", ErrInvalidFlag, name, value))
+		}
+	}
+
+	nonNegative("install-workers", f.installWorkers)
+	nonNegative("consistency-workers", f.consistencyWorkers)
+	nonNegative("retry-budget-failures", f.retryBudgetFailures)
+	nonNegative("max-rendered-bytes", f.maxRenderedBytes)
+	nonNegative("max-rendered-objects", f.maxRenderedObjects)
+	nonNegative("shared-render-cache-bytes", f.sharedRenderCacheBytes)
+	nonNegative("max-concurrent-extractions", f.maxConcurrentExtractions)
+	nonNegative("extraction-disk-budget", f.extractionDiskBudget)
+	nonNegative("log-sampling-initial", f.logSamplingInitial)
+	nonNegative("log-sampling-thereafter", f.logSamplingThereafter)
+
+	nonNegativeDuration("secret-cache-ttl", f.secretCacheTTL)
+	nonNegativeDuration("retry-budget-window", f.retryBudgetWindow)
+	nonNegativeDuration("operation-timeout", f.operationTimeout)
+	nonNegativeDuration("install-operation-timeout", f.installOperationTimeout)
+	nonNegativeDuration("install-requeue-interval", f.installRequeueInterval)
+	nonNegativeDuration("dependency-requeue-interval", f.dependencyRequeueInterval)
+	nonNegativeDuration("version-resync-interval", f.versionResyncInterval)
+	nonNegativeDuration("pre-pull-interval", f.prePullInterval)
+	nonNegativeDuration("listener-staleness-timeout", f.listenerStalenessTimeout)
+
+	if f.vaultAddress != "" && strings.Trim(f.vaultPathPrefix, "/") == "" {
+		errs = append(errs, fmt.Errorf("%w: vault-path-prefix is required with vault-address", ErrInvalidFlag))
+	}
+
+	if f.renderOnly && (f.renderShards < 1 || f.renderShard < 0 || f.renderShard >= f.renderShards) {
+		errs = append(errs, fmt.Errorf("%w: render-shard must be between 0 and render-shards - 1, got %d of %d",
+			ErrInvalidFlag, f.renderShard, f.renderShards))
+	}
+
+	if len(errs) > 0 {
+		return types.NewMultiError(errs)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+// generate-monitoring writes the Grafana dashboard and the PrometheusRule of the operator metrics below config,
+// run it after metrics were added or renamed:
+//
+//	go run ./hack/generate-monitoring
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kyma-project/module-manager/internal/monitoring"
+)
+
+func main() {
+	configDir := flag.String("config-dir", "config", "the kustomize config directory the files are written to")
+	flag.Parse()
+
+	dashboard, err := monitoring.Dashboard()
+	if err != nil {
+		fail(err)
+	}
+	rule, err := monitoring.PrometheusRule()
+	if err != nil {
+		fail(err)
+	}
+	for file, data := range map[string][]byte{
+		monitoring.DashboardFile:      dashboard,
+		monitoring.PrometheusRuleFile: rule,
+	} {
+		if err := os.WriteFile(filepath.Join(*configDir, file), data, 0o600); err != nil { //nolint:gomnd
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
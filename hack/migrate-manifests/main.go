@@ -0,0 +1,47 @@
+// migrate-manifests converts stored Manifests from the former spec layout (spec.preInstallCRDs, spec.stateCR)
+// to the current one (spec.crds, spec.resource). Run it after the Manifest CRD containing both layouts was applied
+// and before a CRD without the legacy fields is rolled out, as the API server prunes unknown fields on read:
+//
+//	go run ./hack/migrate-manifests --dry-run
+//	go run ./hack/migrate-manifests
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	manifestv1alpha1 "github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only list the Manifests that use the former spec layout")
+	flag.Parse()
+
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		fail(err)
+	}
+	clnt, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fail(err)
+	}
+
+	migrated, err := manifestv1alpha1.MigrateLegacyManifests(context.Background(), clnt, *dryRun)
+	for _, key := range migrated {
+		fmt.Println(key) //nolint:forbidigo
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
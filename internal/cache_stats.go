@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Names and labels of the cache metrics, they are referenced by the generated dashboards.
+const (
+	MetricCacheEntries     = "module_manager_cache_entries"
+	MetricCacheSize        = "module_manager_cache_size_bytes"
+	MetricCacheOldestEntry = "module_manager_cache_oldest_entry_age_seconds"
+	MetricCacheHits        = "module_manager_cache_hits_total"
+	MetricCacheMisses      = "module_manager_cache_misses_total"
+
+	MetricLabelCache = "cache"
+)
+
+//nolint:gochecknoglobals
+var (
+	cacheRegistry = &cacheStatsRegistry{caches: make(map[string]*CacheStats)}
+
+	cacheEntriesDesc = prometheus.NewDesc(MetricCacheEntries,
+		"Number of entries by cache", []string{MetricLabelCache}, nil)
+	cacheSizeDesc = prometheus.NewDesc(MetricCacheSize,
+		"Size of the entries by cache, for caches that know the size of their entries", []string{MetricLabelCache}, nil)
+	cacheOldestDesc = prometheus.NewDesc(MetricCacheOldestEntry,
+		"Age of the oldest entry by cache", []string{MetricLabelCache}, nil)
+	cacheHitsDesc = prometheus.NewDesc(MetricCacheHits,
+		"Number of lookups that were served from the cache by cache", []string{MetricLabelCache}, nil)
+	cacheMissesDesc = prometheus.NewDesc(MetricCacheMisses,
+		"Number of lookups that were not served from the cache by cache", []string{MetricLabelCache}, nil)
+)
+
+//nolint:gochecknoinits
+func init() {
+	ctrlmetrics.Registry.MustRegister(cacheRegistry)
+}
+
+// CacheSnapshot describes the content of a cache at the time it was taken.
+type CacheSnapshot struct {
+	Entries int
+	// SizeBytes is 0 for caches that do not know the size of their entries.
+	SizeBytes int64
+	// Oldest is the time the oldest entry was added or refreshed, zero for empty caches.
+	Oldest time.Time
+}
+
+// CacheStats counts the lookups of a cache and takes snapshots of its content when metrics are collected
+// or the caches are dumped. A nil CacheStats ignores all lookups, so that caches work without being registered.
+type CacheStats struct {
+	name     string
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+	snapshot atomic.Pointer[func() CacheSnapshot]
+}
+
+// RegisterCache returns the CacheStats of the cache with name and takes snapshots with snapshot from now on.
+// Caches registered again under the same name, e.g. after their owner was recreated, keep their lookup counts.
+func RegisterCache(name string, snapshot func() CacheSnapshot) *CacheStats {
+	return cacheRegistry.register(name, snapshot)
+}
+
+// Hit records a lookup that was served from the cache.
+func (s *CacheStats) Hit() {
+	if s != nil {
+		s.hits.Add(1)
+	}
+}
+
+// Miss records a lookup that was not served from the cache.
+func (s *CacheStats) Miss() {
+	if s != nil {
+		s.misses.Add(1)
+	}
+}
+
+type cacheStatsRegistry struct {
+	mu     sync.Mutex
+	caches map[string]*CacheStats
+}
+
+func (r *cacheStatsRegistry) register(name string, snapshot func() CacheSnapshot) *CacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats, found := r.caches[name]
+	if !found {
+		stats = &CacheStats{name: name}
+		r.caches[name] = stats
+	}
+	stats.snapshot.Store(&snapshot)
+	return stats
+}
+
+func (r *cacheStatsRegistry) list() []*CacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	caches := make([]*CacheStats, 0, len(r.caches))
+	for _, stats := range r.caches {
+		caches = append(caches, stats)
+	}
+	sort.Slice(caches, func(i, j int) bool { return caches[i].name < caches[j].name })
+	return caches
+}
+
+func (r *cacheStatsRegistry) Describe(descs chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		cacheEntriesDesc, cacheSizeDesc, cacheOldestDesc, cacheHitsDesc, cacheMissesDesc,
+	} {
+		descs <- desc
+	}
+}
+
+func (r *cacheStatsRegistry) Collect(metrics chan<- prometheus.Metric) {
+	for _, dump := range r.dump() {
+		metrics <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue,
+			float64(dump.Entries), dump.Name)
+		metrics <- prometheus.MustNewConstMetric(cacheSizeDesc, prometheus.GaugeValue,
+			float64(dump.SizeBytes), dump.Name)
+		metrics <- prometheus.MustNewConstMetric(cacheOldestDesc, prometheus.GaugeValue,
+			dump.OldestAgeSeconds, dump.Name)
+		metrics <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue,
+			float64(dump.Hits), dump.Name)
+		metrics <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue,
+			float64(dump.Misses), dump.Name)
+	}
+}
+
+// CacheDump is the state of a registered cache as served by CacheDumpHandler.
+type CacheDump struct {
+	Name             string  `json:"name"`
+	Entries          int     `json:"entries"`
+	SizeBytes        int64   `json:"sizeBytes"`
+	OldestAgeSeconds float64 `json:"oldestAgeSeconds"`
+	Hits             uint64  `json:"hits"`
+	Misses           uint64  `json:"misses"`
+	// HitRatio is the share of lookups served from the cache, 0 without lookups.
+	HitRatio float64 `json:"hitRatio"`
+}
+
+func (r *cacheStatsRegistry) dump() []CacheDump {
+	caches := r.list()
+	dumps := make([]CacheDump, 0, len(caches))
+	for _, stats := range caches {
+		snapshot := (*stats.snapshot.Load())()
+		dump := CacheDump{
+			Name:      stats.name,
+			Entries:   snapshot.Entries,
+			SizeBytes: snapshot.SizeBytes,
+			Hits:      stats.hits.Load(),
+			Misses:    stats.misses.Load(),
+		}
+		if !snapshot.Oldest.IsZero() {
+			dump.OldestAgeSeconds = time.Since(snapshot.Oldest).Seconds()
+		}
+		if lookups := dump.Hits + dump.Misses; lookups > 0 {
+			dump.HitRatio = float64(dump.Hits) / float64(lookups)
+		}
+		dumps = append(dumps, dump)
+	}
+	return dumps
+}
+
+// DumpCaches returns the state of all registered caches ordered by name.
+func DumpCaches() []CacheDump {
+	return cacheRegistry.dump()
+}
+
+// CacheDumpHandler serves DumpCaches as JSON for debugging.
+func CacheDumpHandler() http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(writer).Encode(DumpCaches()); err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStats(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	oldest := time.Now().Add(-time.Minute)
+	stats := internal.RegisterCache("test-cache", func() internal.CacheSnapshot {
+		return internal.CacheSnapshot{Entries: 2, SizeBytes: 42, Oldest: oldest}
+	})
+	stats.Hit()
+	stats.Hit()
+	stats.Hit()
+	stats.Miss()
+	for _, dump := range internal.DumpCaches() {
+		if dump.Name == "test-cache" {
+			asserts.Equal(2, dump.Entries)
+			asserts.Equal(int64(42), dump.SizeBytes)
+			asserts.InDelta(time.Minute.Seconds(), dump.OldestAgeSeconds, 5)
+		}
+	}
+
+	again := internal.RegisterCache("test-cache", func() internal.CacheSnapshot { return internal.CacheSnapshot{} })
+	asserts.Same(stats, again, "caches registered again keep their counts")
+	again.Miss()
+
+	var unregistered *internal.CacheStats
+	unregistered.Hit()
+	unregistered.Miss()
+
+	recorder := httptest.NewRecorder()
+	internal.CacheDumpHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/caches", nil))
+	var dumps []internal.CacheDump
+	asserts.NoError(json.Unmarshal(recorder.Body.Bytes(), &dumps))
+	var dump *internal.CacheDump
+	for i := range dumps {
+		if dumps[i].Name == "test-cache" {
+			dump = &dumps[i]
+		}
+	}
+	if asserts.NotNil(dump) {
+		asserts.Equal(uint64(3), dump.Hits)
+		asserts.Equal(uint64(2), dump.Misses)
+		asserts.InDelta(0.6, dump.HitRatio, 0.001)
+		asserts.Zero(dump.Entries, "the latest snapshot function is used")
+		asserts.Zero(dump.OldestAgeSeconds)
+	}
+}
@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const sha256DigestPrefix = "sha256:"
+
+var (
+	ErrChartDigestMismatch    = errors.New("chart digest mismatch")
+	ErrUnsupportedChartDigest = errors.New("unsupported chart digest")
+)
+
+// VerifyChartDigest compares the sha256 digest of the chart archive at chartPath with the pinned digest,
+// which is given either as "sha256:<hex>" or as plain hex encoded sha256 sum.
+func VerifyChartDigest(chartPath, digest string) error {
+	expected := strings.ToLower(strings.TrimPrefix(digest, sha256DigestPrefix))
+	if strings.Contains(expected, ":") {
+		return fmt.Errorf("%w: %s, only sha256 is supported", ErrUnsupportedChartDigest, digest)
+	}
+
+	chart, err := os.Open(chartPath)
+	if err != nil {
+		return err
+	}
+	defer chart.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, chart); err != nil {
+		return fmt.Errorf("calculating digest of chart %s: %w", chartPath, err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: chart %s has digest %s%s, expected %s",
+			ErrChartDigestMismatch, chartPath, sha256DigestPrefix, actual, digest)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package internal_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_VerifyChartDigest(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	chartPath := filepath.Join(t.TempDir(), "chart-0.1.0.tgz")
+	content := []byte("chart archive")
+	asserts.NoError(os.WriteFile(chartPath, content, internal.DefaultFilePermission))
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	asserts.NoError(internal.VerifyChartDigest(chartPath, digest))
+	asserts.NoError(internal.VerifyChartDigest(chartPath, "sha256:"+digest))
+	asserts.ErrorIs(internal.VerifyChartDigest(chartPath, "sha256:"+digest[1:]+"0"), internal.ErrChartDigestMismatch)
+	asserts.ErrorIs(internal.VerifyChartDigest(chartPath, "sha512:"+digest), internal.ErrUnsupportedChartDigest)
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ConcurrencyLimitRequeueDelay is the delay after which a reconciliation that exceeded the limit is retried.
+const ConcurrencyLimitRequeueDelay = time.Second
+
+// ConcurrencyLimitedReconciler limits the number of active reconciliations.
+// In contrast to MaxConcurrentReconciles of the controller, the limit is evaluated for every reconciliation
+// and can thus be changed at runtime. It can never exceed MaxConcurrentReconciles of the controller.
+// Reconciliations above the limit are not blocked but requeued, so that workers are freed immediately.
+type ConcurrencyLimitedReconciler struct {
+	reconcile.Reconciler
+	limit  func() int
+	active atomic.Int64
+}
+
+func NewConcurrencyLimitedReconciler(
+	reconciler reconcile.Reconciler, limit func() int,
+) *ConcurrencyLimitedReconciler {
+	return &ConcurrencyLimitedReconciler{Reconciler: reconciler, limit: limit}
+}
+
+func (r *ConcurrencyLimitedReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	defer r.active.Add(-1)
+	if active, limit := r.active.Add(1), r.limit(); limit > 0 && active > int64(limit) {
+		log.FromContext(ctx).V(DebugLogLevel).Info(
+			"concurrency limit reached, requeue reconciliation", "limit", limit,
+		)
+		return ctrl.Result{RequeueAfter: ConcurrencyLimitRequeueDelay}, nil
+	}
+	return r.Reconciler.Reconcile(ctx, req)
+}
@@ -0,0 +1,45 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_ConcurrencyLimitedReconciler(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	limit := 1
+	release := make(chan struct{})
+	started := make(chan struct{})
+	blocking := reconcile.Func(func(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+		started <- struct{}{}
+		<-release
+		return ctrl.Result{}, nil
+	})
+	limited := internal.NewConcurrencyLimitedReconciler(blocking, func() int { return limit })
+
+	done := make(chan ctrl.Result)
+	go func() {
+		result, _ := limited.Reconcile(context.Background(), ctrl.Request{})
+		done <- result
+	}()
+	<-started
+
+	result, err := limited.Reconcile(context.Background(), ctrl.Request{})
+	assertions.NoError(err)
+	assertions.Equal(internal.ConcurrencyLimitRequeueDelay, result.RequeueAfter)
+
+	close(release)
+	assertions.Equal(ctrl.Result{}, <-done)
+
+	go func() { <-started }()
+	result, err = limited.Reconcile(context.Background(), ctrl.Request{})
+	assertions.NoError(err)
+	assertions.Equal(ctrl.Result{}, result)
+}
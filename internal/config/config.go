@@ -0,0 +1,203 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cfg "sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	APIVersion = "config.operator.kyma-project.io/v1alpha1"
+	Kind       = "ModuleManagerConfiguration"
+)
+
+var ErrUnsupportedConfiguration = errors.New("unsupported configuration")
+
+// ModuleManagerConfiguration is the versioned ComponentConfig of the module-manager.
+// Every field is optional, unset fields keep the value of the corresponding command line flag.
+// Flags that are set explicitly on the command line always take precedence over the file.
+type ModuleManagerConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// ControllerManagerConfigurationSpec contains the generic manager settings
+	// (health, metrics, webhook and leader election).
+	cfg.ControllerManagerConfigurationSpec `json:",inline"`
+
+	// RequeueSuccessInterval determines the interval of the consistency check of ready Manifests.
+	// It is safe to change at runtime.
+	RequeueSuccessInterval *metav1.Duration `json:"requeueSuccessInterval,omitempty"`
+
+	// MaxConcurrentReconciles determines the number of concurrent reconciliations.
+	// It can be lowered at runtime, but values above the startup value require a restart.
+	MaxConcurrentReconciles *int `json:"maxConcurrentReconciles,omitempty"`
+
+	// WorkersConcurrentManifests determines the number of concurrent operations for a single Manifest.
+	WorkersConcurrentManifests *int `json:"workersConcurrentManifests,omitempty"`
+
+	// RateLimiter configures the rate limiter of the Manifest workqueue.
+	RateLimiter *RateLimiterConfiguration `json:"rateLimiter,omitempty"`
+
+	// Client configures the kubernetes client used against the control plane.
+	Client *ClientConfiguration `json:"client,omitempty"`
+
+	// CacheSyncTimeout determines the timeout for the initial informer cache sync.
+	CacheSyncTimeout *metav1.Duration `json:"cacheSyncTimeout,omitempty"`
+
+	// OperationTimeout bounds the operations of a single reconciliation of a Manifest.
+	OperationTimeout *metav1.Duration `json:"operationTimeout,omitempty"`
+
+	// InstallOperationTimeout replaces the OperationTimeout for Manifests that were never ready.
+	InstallOperationTimeout *metav1.Duration `json:"installOperationTimeout,omitempty"`
+
+	// InstallRequeueInterval requeues Manifests that were never ready while their resources become ready.
+	InstallRequeueInterval *metav1.Duration `json:"installRequeueInterval,omitempty"`
+
+	// DependencyRequeueInterval requeues Manifests that wait for CRDs or namespaces of other modules.
+	DependencyRequeueInterval *metav1.Duration `json:"dependencyRequeueInterval,omitempty"`
+
+	// VersionResyncInterval resyncs ready Manifests applied by a previous version one at a time in the interval.
+	VersionResyncInterval *metav1.Duration `json:"versionResyncInterval,omitempty"`
+
+	// MaxConcurrentExtractions limits the number of chart layers that are extracted concurrently.
+	MaxConcurrentExtractions *int `json:"maxConcurrentExtractions,omitempty"`
+
+	// ExtractionDiskBudget limits the bytes of disk space used by the extracted charts and extractions in progress.
+	ExtractionDiskBudget *int `json:"extractionDiskBudget,omitempty"`
+
+	// RenderLimits rejects rendered manifests that exceed the limits.
+	RenderLimits *RenderLimitsConfiguration `json:"renderLimits,omitempty"`
+
+	// CacheDir determines the directory in which charts and rendered manifests are cached.
+	CacheDir string `json:"cacheDir,omitempty"`
+
+	// GlobalValuesFile is the path to a values file whose values are set for every install.
+	GlobalValuesFile string `json:"globalValuesFile,omitempty"`
+
+	// ReadinessRulesFile is the path to a file with readiness rules that apply to the resources of every Manifest.
+	ReadinessRulesFile string `json:"readinessRulesFile,omitempty"`
+
+	// AuditLog is the backend recording the operations of Manifests, "events" or "configmap".
+	AuditLog string `json:"auditLog,omitempty"`
+
+	// PrePull pre-pulls the artifacts of the Manifests in a file into the cache.
+	PrePull *PrePullConfiguration `json:"prePull,omitempty"`
+
+	// SecretLabelSelector restricts the watched Secrets whose changes invalidate the clients of remote clusters.
+	SecretLabelSelector string `json:"secretLabelSelector,omitempty"`
+
+	// HelmKeyring is the path to the public keyring used to verify the provenance of repository charts.
+	HelmKeyring string `json:"helmKeyring,omitempty"`
+
+	// KustomizeMirror is the directory with git mirrors of kustomize remotes.
+	KustomizeMirror string `json:"kustomizeMirror,omitempty"`
+
+	// KustomizeHelmCommand is the helm binary used to inflate the helmCharts of kustomizations.
+	// The plugins themselves are enabled with the "kustomize-enable-*" feature gates.
+	KustomizeHelmCommand string `json:"kustomizeHelmCommand,omitempty"`
+
+	// ReleaseNameTemplate determines the helm release names of installs, e.g. "{{ .ManifestName }}-{{ .Hash }}".
+	ReleaseNameTemplate string `json:"releaseNameTemplate,omitempty"`
+
+	// VaultAddress enables the vault secret provider for the valuesFrom of installs.
+	VaultAddress string `json:"vaultAddress,omitempty"`
+
+	// VaultTokenFile is the file containing the token of the vault secret provider.
+	VaultTokenFile string `json:"vaultTokenFile,omitempty"`
+
+	// VaultPathPrefix restricts the vault paths that can be referenced by installs, it is required with VaultAddress.
+	VaultPathPrefix string `json:"vaultPathPrefix,omitempty"`
+
+	// SecretCacheTTL is the maximum time resolved secrets are cached for.
+	SecretCacheTTL *metav1.Duration `json:"secretCacheTTL,omitempty"`
+
+	// SecretExecCommand enables the exec secret provider for the valuesFrom of installs.
+	SecretExecCommand string `json:"secretExecCommand,omitempty"`
+
+	// RedactKeys are key fragments whose values are masked in logs, events and status
+	// in addition to the built-in sensitive keys.
+	RedactKeys []string `json:"redactKeys,omitempty"`
+
+	// RedactAllowedKeys are keys whose values are never masked.
+	RedactAllowedKeys []string `json:"redactAllowedKeys,omitempty"`
+
+	// ListenerAddress determines the address the listener for runtime events binds to.
+	ListenerAddress string `json:"listenerAddress,omitempty"`
+
+	// ListenerPath determines the path the listener receives events of remote cluster watchers on.
+	ListenerPath string `json:"listenerPath,omitempty"`
+
+	// ListenerStalenessTimeout reports remote Manifests whose watcher sent no events within the timeout.
+	ListenerStalenessTimeout *metav1.Duration `json:"listenerStalenessTimeout,omitempty"`
+
+	// FeatureGates enables or disables optional controller features by flag name,
+	// e.g. "check-ready-states", "insecure-registry", "enable-webhooks" or "enable-pprof".
+	// Unknown gates fail the startup, the deprecated "custom-state-check" is still accepted but has no effect.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// LogLevel determines the verbosity of the controller logs.
+	// It is safe to change at runtime.
+	LogLevel *int `json:"logLevel,omitempty"`
+
+	// LogSampling configures sampling of identical log entries, e.g. from frequent consistency checks.
+	LogSampling *LogSamplingConfiguration `json:"logSampling,omitempty"`
+}
+
+// LogSamplingConfiguration configures how many identical log entries per second are logged.
+type LogSamplingConfiguration struct {
+	Initial    *int `json:"initial,omitempty"`
+	Thereafter *int `json:"thereafter,omitempty"`
+}
+
+// RateLimiterConfiguration configures the bucket and failure rate limiting of the workqueue.
+type RateLimiterConfiguration struct {
+	Burst            *int             `json:"burst,omitempty"`
+	Frequency        *int             `json:"frequency,omitempty"`
+	FailureBaseDelay *metav1.Duration `json:"failureBaseDelay,omitempty"`
+	FailureMaxDelay  *metav1.Duration `json:"failureMaxDelay,omitempty"`
+}
+
+// RenderLimitsConfiguration limits the size of rendered manifests, 0 disables a limit.
+type RenderLimitsConfiguration struct {
+	MaxBytes   *int `json:"maxBytes,omitempty"`
+	MaxObjects *int `json:"maxObjects,omitempty"`
+}
+
+// PrePullConfiguration configures the pre-pull of the artifacts of Manifests.
+type PrePullConfiguration struct {
+	File     string           `json:"file,omitempty"`
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ClientConfiguration configures the kubernetes client.
+type ClientConfiguration struct {
+	QPS   *float64 `json:"qps,omitempty"`
+	Burst *int     `json:"burst,omitempty"`
+}
+
+// Load reads and validates the ModuleManagerConfiguration at the given path.
+func Load(path string) (*ModuleManagerConfiguration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading configuration file %s: %w", path, err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes and validates a ModuleManagerConfiguration, rejecting unknown fields.
+func Parse(data []byte) (*ModuleManagerConfiguration, error) {
+	config := &ModuleManagerConfiguration{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("decoding configuration: %w", err)
+	}
+	if config.APIVersion != APIVersion || config.Kind != Kind {
+		return nil, fmt.Errorf(
+			"%w: expected %s %s but got %s %s", ErrUnsupportedConfiguration,
+			APIVersion, Kind, config.APIVersion, config.Kind,
+		)
+	}
+	return config, nil
+}
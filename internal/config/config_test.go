@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/module-manager/internal/config"
+	"github.com/kyma-project/module-manager/pkg/log"
+	"github.com/stretchr/testify/assert"
+)
+
+const validConfig = `apiVersion: config.operator.kyma-project.io/v1alpha1
+kind: ModuleManagerConfiguration
+health:
+  healthProbeBindAddress: :8081
+requeueSuccessInterval: 1m
+rateLimiter:
+  burst: 10
+featureGates:
+  check-ready-states: true
+`
+
+func Test_Parse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"valid configuration", validConfig, false},
+		{"wrong kind", "apiVersion: config.operator.kyma-project.io/v1alpha1\nkind: Other\n", true},
+		{"wrong version", "apiVersion: v1\nkind: ModuleManagerConfiguration\n", true},
+		{"unknown field", validConfig + "unknown: true\n", true},
+	}
+	for _, testCase := range tests {
+		tcase := testCase
+		t.Run(tcase.name, func(t *testing.T) {
+			t.Parallel()
+			assertions := assert.New(t)
+			parsed, err := config.Parse([]byte(tcase.data))
+			if tcase.wantErr {
+				assertions.Error(err)
+				return
+			}
+			assertions.NoError(err)
+			assertions.Equal(":8081", parsed.Health.HealthProbeBindAddress)
+			assertions.Equal(time.Minute, parsed.RequeueSuccessInterval.Duration)
+			assertions.Equal(10, *parsed.RateLimiter.Burst)
+			assertions.True(parsed.FeatureGates["check-ready-states"])
+		})
+	}
+}
+
+func Test_Reloader(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assertions.NoError(os.WriteFile(path, []byte(validConfig), 0o600))
+
+	settings := config.NewSettings(time.Second, 4, log.NewAtomicLevel(0))
+	reloader := &config.Reloader{Path: path, Interval: 10 * time.Millisecond, Settings: settings, Log: logr.Discard()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = reloader.Start(ctx) }()
+
+	assertions.Equal(time.Second, settings.RequeueSuccessInterval())
+
+	changed := []byte("apiVersion: config.operator.kyma-project.io/v1alpha1\n" +
+		"kind: ModuleManagerConfiguration\nrequeueSuccessInterval: 5m\nmaxConcurrentReconciles: 10\nlogLevel: 2\n")
+	assertions.NoError(os.WriteFile(path, []byte("invalid: [\n"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+	assertions.Equal(time.Second, settings.RequeueSuccessInterval())
+
+	assertions.NoError(os.WriteFile(path, changed, 0o600))
+	assertions.Eventually(func() bool {
+		return settings.RequeueSuccessInterval() == 5*time.Minute
+	}, time.Second, 10*time.Millisecond)
+	assertions.Equal(4, settings.ActiveReconciles(), "worker pool size must not be exceeded")
+	assertions.Equal("Level(-2)", settings.LogLevel())
+}
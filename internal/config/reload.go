@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/module-manager/pkg/log"
+	"go.uber.org/zap"
+)
+
+const DefaultReloadInterval = 10 * time.Second
+
+// Settings holds all values that are safe to change while the controller is running.
+// The zero value is not usable, use NewSettings instead.
+type Settings struct {
+	requeueSuccessInterval  atomic.Int64
+	activeReconciles        atomic.Int64
+	maxConcurrentReconciles int
+	logLevel                zap.AtomicLevel
+}
+
+// NewSettings creates Settings from the startup values.
+// maxConcurrentReconciles is the size of the worker pool of the controller and cannot be exceeded at runtime,
+// while logLevel is the level used by the controller logger.
+func NewSettings(
+	requeueSuccessInterval time.Duration, maxConcurrentReconciles int, logLevel zap.AtomicLevel,
+) *Settings {
+	settings := &Settings{maxConcurrentReconciles: maxConcurrentReconciles, logLevel: logLevel}
+	settings.requeueSuccessInterval.Store(int64(requeueSuccessInterval))
+	settings.activeReconciles.Store(int64(maxConcurrentReconciles))
+	return settings
+}
+
+// RequeueSuccessInterval returns the current interval of the consistency check of ready Manifests.
+func (s *Settings) RequeueSuccessInterval() time.Duration {
+	return time.Duration(s.requeueSuccessInterval.Load())
+}
+
+// ActiveReconciles returns the current limit of active reconciliations.
+func (s *Settings) ActiveReconciles() int {
+	return int(s.activeReconciles.Load())
+}
+
+// LogLevel returns the current log level.
+func (s *Settings) LogLevel() string {
+	return s.logLevel.String()
+}
+
+// Apply takes over all safe-to-change values that are set in the configuration.
+// The number of active reconciliations is capped to the worker pool size determined on startup.
+func (s *Settings) Apply(config *ModuleManagerConfiguration) {
+	if config.RequeueSuccessInterval != nil {
+		s.requeueSuccessInterval.Store(int64(config.RequeueSuccessInterval.Duration))
+	}
+	if config.MaxConcurrentReconciles != nil && *config.MaxConcurrentReconciles > 0 {
+		active := *config.MaxConcurrentReconciles
+		if active > s.maxConcurrentReconciles {
+			active = s.maxConcurrentReconciles
+		}
+		s.activeReconciles.Store(int64(active))
+	}
+	if config.LogLevel != nil {
+		s.logLevel.SetLevel(log.ToZapLevel(int8(*config.LogLevel)))
+	}
+}
+
+// Reloader periodically reads the configuration file and applies changed values to Settings.
+// Polling is used instead of file events as ConfigMap volumes are updated through symlink swaps.
+// It implements manager.Runnable and runs independently of leader election.
+type Reloader struct {
+	Path     string
+	Interval time.Duration
+	Settings *Settings
+	Log      logr.Logger
+
+	lastRead []byte
+}
+
+func (r *Reloader) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	r.lastRead, _ = os.ReadFile(r.Path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.reload()
+		}
+	}
+}
+
+func (r *Reloader) NeedLeaderElection() bool {
+	return false
+}
+
+func (r *Reloader) reload() {
+	data, err := os.ReadFile(r.Path)
+	if err != nil {
+		r.Log.Error(err, "reading configuration for reload failed", "path", r.Path)
+		return
+	}
+	if bytes.Equal(data, r.lastRead) {
+		return
+	}
+	config, err := Parse(data)
+	if err != nil {
+		r.Log.Error(err, "changed configuration is invalid and is ignored", "path", r.Path)
+		return
+	}
+	r.lastRead = data
+	r.Settings.Apply(config)
+	r.Log.Info("configuration reloaded", "path", r.Path,
+		"requeueSuccessInterval", r.Settings.RequeueSuccessInterval(),
+		"activeReconciles", r.Settings.ActiveReconciles(),
+		"logLevel", r.Settings.LogLevel())
+}
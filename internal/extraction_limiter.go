@@ -0,0 +1,278 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/semaphore"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	ExtractionWaitConcurrency = "concurrency"
+	ExtractionWaitDiskBudget  = "disk-budget"
+
+	// ExtractionCacheMeasureInterval is the interval in which the size of the chart cache is measured again,
+	// so that charts removed from the cache free their budget.
+	ExtractionCacheMeasureInterval = 5 * time.Minute
+	// extractionTempMarker is part of the name of the directories extractions in progress write to,
+	// their bytes are accounted by the reservations instead.
+	extractionTempMarker = ".tmp-"
+)
+
+// Names and labels of the extraction metrics, they are referenced by the generated dashboards.
+const (
+	MetricExtractionWaits         = "module_manager_chart_extraction_waits_total"
+	MetricExtractionsWaiting      = "module_manager_chart_extractions_waiting"
+	MetricExtractionBytesInFlight = "module_manager_chart_extraction_bytes_in_flight"
+
+	MetricLabelReason = "reason"
+)
+
+var ErrExtractionBudgetExceeded = errors.New("chart extraction exceeds the disk usage budget")
+
+var (
+	// ExtractionWaits counts chart extractions that had to wait for a free slot or disk budget.
+	ExtractionWaits = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals
+		Name: MetricExtractionWaits,
+		Help: "Number of chart extractions that waited for a free extraction slot or disk budget by reason",
+	}, []string{MetricLabelReason})
+	// ExtractionsWaiting is the number of chart extractions currently waiting.
+	ExtractionsWaiting = prometheus.NewGauge(prometheus.GaugeOpts{ //nolint:gochecknoglobals
+		Name: MetricExtractionsWaiting,
+		Help: "Number of chart extractions currently waiting for a free extraction slot or disk budget",
+	})
+	// ExtractionBytesInFlight is the disk space reserved by chart extractions in progress.
+	ExtractionBytesInFlight = prometheus.NewGauge(prometheus.GaugeOpts{ //nolint:gochecknoglobals
+		Name: MetricExtractionBytesInFlight,
+		Help: "Bytes written or reserved by chart extractions in progress",
+	})
+
+	currentExtractionLimiter atomic.Pointer[ExtractionLimiter] //nolint:gochecknoglobals
+)
+
+//nolint:gochecknoinits
+func init() {
+	ctrlmetrics.Registry.MustRegister(ExtractionWaits, ExtractionsWaiting, ExtractionBytesInFlight)
+	currentExtractionLimiter.Store(NewExtractionLimiter(0, 0, ""))
+}
+
+// ExtractionLimiter bounds the number of concurrent chart extractions and the disk space used by the chart cache
+// in cacheDir together with the extractions in progress, so that simultaneous extractions of big charts cannot fill
+// the disk of the node. Extractions wait for a free slot and for the compressed size of their layer before they
+// start. If they grow beyond the reservation and the budget is exhausted, they fail with ErrExtractionBudgetExceeded
+// instead of waiting, as waiting while holding a reservation could block all extractions. Extractions also fail
+// if the cache alone leaves no room for them, as only removing charts from the cache frees that budget.
+// Committed extractions stay accounted as part of the cache, which is measured again in the
+// ExtractionCacheMeasureInterval.
+type ExtractionLimiter struct {
+	slots    *semaphore.Weighted
+	budget   *semaphore.Weighted
+	maxBytes int64
+	cacheDir string
+
+	mu sync.Mutex
+	// cacheBytes is the size of the cache, of which cacheHeld bytes are acquired from the budget.
+	// The cache can exceed the budget or grow while extractions hold the budget, so the held bytes can be less.
+	cacheBytes, cacheHeld int64
+	measured              time.Time
+}
+
+// NewExtractionLimiter creates an ExtractionLimiter, a maxConcurrent or maxBytes of 0 disables the respective limit.
+// The size of cacheDir is part of the budget, an empty cacheDir only limits the extractions in progress.
+func NewExtractionLimiter(maxConcurrent int, maxBytes int64, cacheDir string) *ExtractionLimiter {
+	limiter := &ExtractionLimiter{maxBytes: maxBytes, cacheDir: cacheDir}
+	if maxConcurrent > 0 {
+		limiter.slots = semaphore.NewWeighted(int64(maxConcurrent))
+	}
+	if maxBytes > 0 {
+		limiter.budget = semaphore.NewWeighted(maxBytes)
+	}
+	return limiter
+}
+
+// ConfigureExtraction replaces the ExtractionLimiter used for all chart extractions into cacheDir.
+// It is expected to be called once on startup.
+func ConfigureExtraction(maxConcurrent int, maxBytes int64, cacheDir string) {
+	currentExtractionLimiter.Store(NewExtractionLimiter(maxConcurrent, maxBytes, cacheDir))
+}
+
+type extractionWaitKey struct{}
+
+// WithExtractionWaitHandler notifies onWait with the reason if a chart extraction started with ctx has to wait.
+func WithExtractionWaitHandler(ctx context.Context, onWait func(reason string)) context.Context {
+	return context.WithValue(ctx, extractionWaitKey{}, onWait)
+}
+
+func notifyExtractionWait(ctx context.Context, reason string) {
+	ExtractionWaits.WithLabelValues(reason).Inc()
+	if onWait, ok := ctx.Value(extractionWaitKey{}).(func(string)); ok {
+		onWait(reason)
+	}
+}
+
+// Acquire waits for a free slot and size bytes of the budget. The returned reservation has to be released.
+func (l *ExtractionLimiter) Acquire(ctx context.Context, size int64) (*ExtractionReservation, error) {
+	if l.budget != nil && size > l.maxBytes {
+		return nil, fmt.Errorf("%w: layer of %d bytes is larger than the budget of %d bytes",
+			ErrExtractionBudgetExceeded, size, l.maxBytes)
+	}
+	if cached := l.measureCache(); l.budget != nil && cached+size > l.maxBytes {
+		return nil, fmt.Errorf("%w: layer of %d bytes does not fit the budget of %d bytes next to %d cached bytes",
+			ErrExtractionBudgetExceeded, size, l.maxBytes, cached)
+	}
+	if err := l.acquire(ctx, l.slots, 1, ExtractionWaitConcurrency); err != nil {
+		return nil, err
+	}
+	if err := l.acquire(ctx, l.budget, size, ExtractionWaitDiskBudget); err != nil {
+		if l.slots != nil {
+			l.slots.Release(1)
+		}
+		return nil, err
+	}
+	ExtractionBytesInFlight.Add(float64(size))
+	return &ExtractionReservation{limiter: l, reserved: size}, nil
+}
+
+// measureCache returns the size of the cache, measuring it again after the ExtractionCacheMeasureInterval,
+// and holds as much of it in the budget as is available.
+func (l *ExtractionLimiter) measureCache() int64 {
+	if l.budget == nil || l.cacheDir == "" {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Since(l.measured) >= ExtractionCacheMeasureInterval {
+		l.cacheBytes = dirSize(l.cacheDir)
+		l.measured = time.Now()
+	}
+	l.holdCache()
+	return l.cacheBytes
+}
+
+// holdCache acquires or releases the budget of the cache, l.mu has to be held.
+func (l *ExtractionLimiter) holdCache() {
+	if l.cacheHeld > l.cacheBytes {
+		l.budget.Release(l.cacheHeld - l.cacheBytes)
+		l.cacheHeld = l.cacheBytes
+		return
+	}
+	if missing := l.cacheBytes - l.cacheHeld; missing > 0 && l.budget.TryAcquire(missing) {
+		l.cacheHeld = l.cacheBytes
+	}
+}
+
+// dirSize sums the size of the files in dir, skipping the directories of extractions in progress.
+// Files that cannot be read, e.g. because they were removed concurrently, are not counted.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if entry.IsDir() {
+			if path != dir && strings.Contains(entry.Name(), extractionTempMarker) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+func (l *ExtractionLimiter) acquire(ctx context.Context, sem *semaphore.Weighted, n int64, reason string) error {
+	if sem == nil || n <= 0 || sem.TryAcquire(n) {
+		return nil
+	}
+	notifyExtractionWait(ctx, reason)
+	ExtractionsWaiting.Inc()
+	defer ExtractionsWaiting.Dec()
+	return sem.Acquire(ctx, n)
+}
+
+// ExtractionReservation tracks the disk space used by a single extraction.
+type ExtractionReservation struct {
+	limiter   *ExtractionLimiter
+	reserved  int64
+	used      int64
+	committed bool
+}
+
+// Commit marks the extraction as added to the cache, so that its bytes stay accounted by the cache once released.
+func (r *ExtractionReservation) Commit() {
+	r.committed = true
+}
+
+// Use accounts n written bytes and extends the reservation without waiting if necessary.
+func (r *ExtractionReservation) Use(n int64) error {
+	r.used += n
+	if r.used <= r.reserved {
+		return nil
+	}
+	grow := r.used - r.reserved
+	if r.limiter.budget != nil && !r.limiter.budget.TryAcquire(grow) {
+		return fmt.Errorf("%w: %d bytes extracted with %d bytes reserved",
+			ErrExtractionBudgetExceeded, r.used, r.reserved)
+	}
+	r.reserved += grow
+	ExtractionBytesInFlight.Add(float64(grow))
+	return nil
+}
+
+// Release frees the slot and the reserved bytes once the extraction finished. The bytes of a committed extraction
+// are handed over to the cache instead of being freed.
+func (r *ExtractionReservation) Release() {
+	if r.limiter.slots != nil {
+		r.limiter.slots.Release(1)
+	}
+	ExtractionBytesInFlight.Sub(float64(r.reserved))
+	if r.limiter.budget == nil {
+		return
+	}
+	released := r.reserved
+	if r.committed && r.limiter.cacheDir != "" {
+		cached := r.used
+		if cached > r.reserved {
+			cached = r.reserved
+		}
+		released -= cached
+		r.limiter.mu.Lock()
+		r.limiter.cacheBytes += r.used
+		r.limiter.cacheHeld += cached
+		r.limiter.mu.Unlock()
+	}
+	if released > 0 {
+		r.limiter.budget.Release(released)
+	}
+}
+
+// Reader accounts all bytes read from reader.
+func (r *ExtractionReservation) Reader(reader io.Reader) io.Reader {
+	return &reservedReader{Reader: reader, reservation: r}
+}
+
+type reservedReader struct {
+	io.Reader
+	reservation *ExtractionReservation
+}
+
+func (r *reservedReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if useErr := r.reservation.Use(int64(n)); useErr != nil {
+		return n, useErr
+	}
+	return n, err
+}
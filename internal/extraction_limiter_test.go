@@ -0,0 +1,87 @@
+package internal_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractionLimiter(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	ctx := context.Background()
+
+	limiter := internal.NewExtractionLimiter(1, 100, "")
+	_, err := limiter.Acquire(ctx, 101)
+	asserts.ErrorIs(err, internal.ErrExtractionBudgetExceeded)
+
+	first, err := limiter.Acquire(ctx, 10)
+	asserts.NoError(err)
+
+	var reasons []string
+	waitCtx, cancel := context.WithTimeout(
+		internal.WithExtractionWaitHandler(ctx, func(reason string) { reasons = append(reasons, reason) }),
+		50*time.Millisecond,
+	)
+	defer cancel()
+	_, err = limiter.Acquire(waitCtx, 10)
+	asserts.ErrorIs(err, context.DeadlineExceeded)
+	asserts.Equal([]string{internal.ExtractionWaitConcurrency}, reasons)
+
+	// extractions may grow beyond their reservation as long as the budget is not exhausted
+	_, err = io.Copy(io.Discard, first.Reader(bytes.NewReader(make([]byte, 100))))
+	asserts.NoError(err)
+	_, err = io.Copy(io.Discard, first.Reader(bytes.NewReader(make([]byte, 1))))
+	asserts.ErrorIs(err, internal.ErrExtractionBudgetExceeded)
+	first.Release()
+
+	second, err := limiter.Acquire(ctx, 100)
+	asserts.NoError(err, "released slots and bytes are available again")
+	second.Release()
+
+	unlimited := internal.NewExtractionLimiter(0, 0, "")
+	reservation, err := unlimited.Acquire(ctx, 1<<40)
+	asserts.NoError(err)
+	asserts.NoError(reservation.Use(1 << 40))
+	reservation.Release()
+}
+
+func TestExtractionLimiterCountsCache(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	ctx := context.Background()
+
+	cacheDir := t.TempDir()
+	asserts.NoError(os.WriteFile(filepath.Join(cacheDir, "chart.yaml"), make([]byte, 60), 0o600))
+	inProgress := filepath.Join(cacheDir, "keda.tmp-1")
+	asserts.NoError(os.Mkdir(inProgress, 0o700))
+	asserts.NoError(os.WriteFile(filepath.Join(inProgress, "chart.yaml"), make([]byte, 60), 0o600))
+
+	limiter := internal.NewExtractionLimiter(0, 100, cacheDir)
+	_, err := limiter.Acquire(ctx, 50)
+	asserts.ErrorIs(err, internal.ErrExtractionBudgetExceeded, "the cache is part of the budget")
+
+	reservation, err := limiter.Acquire(ctx, 30)
+	asserts.NoError(err, "extractions in progress are not counted as cached")
+	asserts.NoError(reservation.Use(30))
+	reservation.Commit()
+	reservation.Release()
+
+	_, err = limiter.Acquire(ctx, 20)
+	asserts.ErrorIs(err, internal.ErrExtractionBudgetExceeded, "committed extractions are counted as cached")
+	reservation, err = limiter.Acquire(ctx, 10)
+	asserts.NoError(err)
+	asserts.NoError(reservation.Use(5))
+	reservation.Release()
+
+	reservation, err = limiter.Acquire(ctx, 10)
+	asserts.NoError(err, "extractions that are not committed free their budget")
+	reservation.Release()
+}
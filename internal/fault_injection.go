@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// FaultInjectionEnv is the environment variable used to configure faults on controller startup.
+// The format is a comma separated list of point=kind[:argument][@every], e.g.
+// "registry-pull=timeout,server-side-apply=too-many-requests@3,render=delay:5s".
+const FaultInjectionEnv = "MODULE_MANAGER_FAULT_INJECTION"
+
+// FaultPoint identifies a location in the reconciliation at which a fault can be injected.
+type FaultPoint string
+
+const (
+	FaultPointRegistryPull    FaultPoint = "registry-pull"
+	FaultPointServerSideApply FaultPoint = "server-side-apply"
+	FaultPointRender          FaultPoint = "render"
+)
+
+// faultPoints are the points at which InjectFault is called, faults for other points would never trigger.
+var faultPoints = []FaultPoint{ //nolint:gochecknoglobals
+	FaultPointRegistryPull, FaultPointServerSideApply, FaultPointRender,
+}
+
+const (
+	faultKindTimeout         = "timeout"
+	faultKindTooManyRequests = "too-many-requests"
+	faultKindDelay           = "delay"
+	tooManyRequestsRetry     = 1
+)
+
+var (
+	ErrInvalidFaultSetting = errors.New("invalid fault injection setting")
+
+	faults sync.Map //nolint:gochecknoglobals
+)
+
+// Fault describes the behavior injected at a FaultPoint.
+// If Delay is set, the call is slowed down before Err is returned.
+// If Every is greater than 1, the fault only triggers on every n-th call, making the injection deterministic.
+type Fault struct {
+	Err   error
+	Delay time.Duration
+	Every int64
+
+	calls atomic.Int64
+}
+
+// RegisterFault activates the fault for the given point, replacing any previously registered fault.
+func RegisterFault(point FaultPoint, fault *Fault) {
+	faults.Store(point, fault)
+}
+
+// ClearFaults removes all registered faults.
+func ClearFaults() {
+	faults.Range(func(key, _ any) bool {
+		faults.Delete(key)
+		return true
+	})
+}
+
+// InjectFault evaluates the fault registered for the point (if any).
+// It is a no-op unless faults have been registered, so it is safe to call in production code paths.
+func InjectFault(ctx context.Context, point FaultPoint) error {
+	value, ok := faults.Load(point)
+	if !ok {
+		return nil
+	}
+	fault := value.(*Fault)
+
+	if calls := fault.calls.Add(1); fault.Every > 1 && calls%fault.Every != 0 {
+		return nil
+	}
+
+	if fault.Delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(fault.Delay):
+		}
+	}
+
+	return fault.Err
+}
+
+// LoadFaultsFromEnv registers all faults configured in FaultInjectionEnv and returns the configured points.
+func LoadFaultsFromEnv() ([]FaultPoint, error) {
+	setting, ok := os.LookupEnv(FaultInjectionEnv)
+	if !ok || setting == "" {
+		return nil, nil
+	}
+	parsed, err := ParseFaults(setting)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]FaultPoint, 0, len(parsed))
+	for point, fault := range parsed {
+		RegisterFault(point, fault)
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// ParseFaults parses a fault setting in the format described by FaultInjectionEnv.
+// Settings for unknown points fail, e.g. because of a typo, instead of silently injecting nothing.
+func ParseFaults(setting string) (map[FaultPoint]*Fault, error) {
+	parsed := make(map[FaultPoint]*Fault)
+	for _, entry := range strings.Split(setting, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		point, definition, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("%w: %q is missing a fault kind", ErrInvalidFaultSetting, entry)
+		}
+		if !isFaultPoint(FaultPoint(point)) {
+			return nil, fmt.Errorf("%w: %q has an unknown fault point, known points are %s",
+				ErrInvalidFaultSetting, entry, knownFaultPoints())
+		}
+		fault, err := parseFault(definition)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s", ErrInvalidFaultSetting, entry, err.Error())
+		}
+		parsed[FaultPoint(point)] = fault
+	}
+	return parsed, nil
+}
+
+func isFaultPoint(point FaultPoint) bool {
+	for _, known := range faultPoints {
+		if point == known {
+			return true
+		}
+	}
+	return false
+}
+
+func knownFaultPoints() string {
+	names := make([]string, 0, len(faultPoints))
+	for _, point := range faultPoints {
+		names = append(names, string(point))
+	}
+	return strings.Join(names, ", ")
+}
+
+func parseFault(definition string) (*Fault, error) {
+	fault := &Fault{}
+
+	definition, every, hasEvery := strings.Cut(definition, "@")
+	if hasEvery {
+		n, err := strconv.ParseInt(every, 10, 64)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("every must be a positive number but was %q", every)
+		}
+		fault.Every = n
+	}
+
+	kind, argument, _ := strings.Cut(definition, ":")
+	switch kind {
+	case faultKindTimeout:
+		fault.Err = fmt.Errorf("injected fault: %w", context.DeadlineExceeded)
+	case faultKindTooManyRequests:
+		fault.Err = apierrors.NewTooManyRequests("injected fault", tooManyRequestsRetry)
+	case faultKindDelay:
+		delay, err := time.ParseDuration(argument)
+		if err != nil {
+			return nil, err
+		}
+		fault.Delay = delay
+	default:
+		return nil, fmt.Errorf("unknown fault kind %q", kind)
+	}
+
+	return fault, nil
+}
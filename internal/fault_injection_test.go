@@ -0,0 +1,97 @@
+//nolint:paralleltest // faults are registered globally
+package internal_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func Test_ParseFaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting string
+		wantErr bool
+		check   func(*assert.Assertions, map[internal.FaultPoint]*internal.Fault)
+	}{
+		{
+			"empty setting",
+			"",
+			false,
+			func(assertions *assert.Assertions, faults map[internal.FaultPoint]*internal.Fault) {
+				assertions.Empty(faults)
+			},
+		},
+		{
+			"all kinds",
+			"registry-pull=timeout, server-side-apply=too-many-requests@3,render=delay:5s",
+			false,
+			func(assertions *assert.Assertions, faults map[internal.FaultPoint]*internal.Fault) {
+				assertions.Len(faults, 3)
+				assertions.ErrorIs(faults[internal.FaultPointRegistryPull].Err, context.DeadlineExceeded)
+				assertions.True(apierrors.IsTooManyRequests(faults[internal.FaultPointServerSideApply].Err))
+				assertions.Equal(int64(3), faults[internal.FaultPointServerSideApply].Every)
+				assertions.Equal(5*time.Second, faults[internal.FaultPointRender].Delay)
+			},
+		},
+		{"missing kind", "render", true, nil},
+		{"unknown kind", "render=explode", true, nil},
+		{"unknown point", "registry-push=timeout", true, nil},
+		{"invalid delay", "render=delay:soon", true, nil},
+		{"invalid every", "render=timeout@0", true, nil},
+	}
+	for _, testCase := range tests {
+		tcase := testCase
+		t.Run(tcase.name, func(t *testing.T) {
+			assertions := assert.New(t)
+			faults, err := internal.ParseFaults(tcase.setting)
+			if tcase.wantErr {
+				assertions.ErrorIs(err, internal.ErrInvalidFaultSetting)
+				return
+			}
+			assertions.NoError(err)
+			tcase.check(assertions, faults)
+		})
+	}
+}
+
+func Test_InjectFault(t *testing.T) {
+	t.Cleanup(internal.ClearFaults)
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	assertions.NoError(internal.InjectFault(ctx, internal.FaultPointRender))
+
+	injected := errors.New("injected")
+	internal.RegisterFault(internal.FaultPointRender, &internal.Fault{Err: injected, Every: 2})
+	assertions.NoError(internal.InjectFault(ctx, internal.FaultPointRender))
+	assertions.ErrorIs(internal.InjectFault(ctx, internal.FaultPointRender), injected)
+	assertions.NoError(internal.InjectFault(ctx, internal.FaultPointRender))
+	assertions.NoError(internal.InjectFault(ctx, internal.FaultPointRegistryPull))
+
+	internal.RegisterFault(internal.FaultPointRender, &internal.Fault{Delay: time.Hour})
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assertions.ErrorIs(internal.InjectFault(cancelled, internal.FaultPointRender), context.Canceled)
+
+	internal.ClearFaults()
+	assertions.NoError(internal.InjectFault(ctx, internal.FaultPointRender))
+}
+
+func Test_InjectedRegistryPullFaultIsDownloadError(t *testing.T) {
+	t.Cleanup(internal.ClearFaults)
+	assertions := assert.New(t)
+	internal.RegisterFault(internal.FaultPointRegistryPull, &internal.Fault{Err: errors.New("registry blip")})
+
+	imageSpec := types.ImageSpec{Repo: "registry.local", Name: "fault-injection-" + t.Name(), Ref: "sha256:abc"}
+	_, err := internal.GetPathFromExtractedTarGz(context.Background(), imageSpec, true, authn.DefaultKeychain)
+	assertions.True(types.IsDownloadError(err))
+	assertions.NoDirExists(internal.GetFsChartPath(imageSpec), "failed downloads must not be cached")
+}
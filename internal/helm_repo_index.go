@@ -0,0 +1,282 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"golang.org/x/sync/singleflight"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const (
+	DefaultHelmRepoIndexTTL = 5 * time.Minute
+	// HelmRepoIndexTimeout bounds the download of a single index.
+	HelmRepoIndexTimeout = time.Minute
+	helmRepoIndexFolder  = "helm-repo-index"
+)
+
+// HelmRepoIndexCache caches index.yaml files of helm repositories keyed by the repository URL.
+// Indexes younger than TTL are served from memory, older ones of HTTP repositories are revalidated with a
+// conditional GET based on the ETag and Last-Modified headers of the previous response. Indexes that were fetched
+// again are only parsed again if their digest changed. Concurrent fetches of the same repository are deduplicated,
+// while other repositories are fetched in parallel.
+type HelmRepoIndexCache struct {
+	TTL      time.Duration
+	CacheDir string
+	// HTTPClient fetches the indexes of HTTP repositories, http.DefaultClient is used if nil.
+	HTTPClient *http.Client
+	// Getters fetch the indexes of repositories with other schemes, so that getter plugins apply as for chart
+	// downloads. The getters of the helm settings in CacheDir are used if nil.
+	Getters getter.Providers
+
+	mu      sync.Mutex
+	entries map[string]*helmRepoIndexEntry
+	fetches singleflight.Group
+	stats   *CacheStats
+}
+
+type helmRepoIndexEntry struct {
+	index        *repo.IndexFile
+	digest       string
+	etag         string
+	lastModified string
+	fetchedAt    time.Time
+}
+
+// NewHelmRepoIndexCache creates a HelmRepoIndexCache, registered as the "helm-repo-indexes" cache.
+func NewHelmRepoIndexCache(ttl time.Duration, cacheDir string) *HelmRepoIndexCache {
+	indexCache := &HelmRepoIndexCache{
+		TTL:        ttl,
+		CacheDir:   cacheDir,
+		HTTPClient: http.DefaultClient,
+		entries:    make(map[string]*helmRepoIndexEntry),
+	}
+	indexCache.stats = RegisterCache("helm-repo-indexes", indexCache.snapshot)
+	return indexCache
+}
+
+func (c *HelmRepoIndexCache) snapshot() CacheSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := CacheSnapshot{Entries: len(c.entries)}
+	for _, entry := range c.entries {
+		if snapshot.Oldest.IsZero() || entry.fetchedAt.Before(snapshot.Oldest) {
+			snapshot.Oldest = entry.fetchedAt
+		}
+	}
+	return snapshot
+}
+
+// FindChartInRepoURL is the cached equivalent of repo.FindChartInRepoURL for unauthenticated repositories.
+func (c *HelmRepoIndexCache) FindChartInRepoURL(
+	ctx context.Context, repoURL, chartName, chartVersion string,
+) (string, error) {
+	index, err := c.Get(ctx, repoURL)
+	if err != nil {
+		return "", err
+	}
+	chartVersionInRepo, err := index.Get(chartName, chartVersion)
+	if err != nil {
+		return "", types.NewClassifiedError(types.ErrChartNotFound,
+			fmt.Errorf("chart %q not found in %s repository: %w", chartName, repoURL, err))
+	}
+	if len(chartVersionInRepo.URLs) == 0 {
+		return "", fmt.Errorf("chart %q in %s repository has no downloadable URLs", chartName, repoURL)
+	}
+	return repo.ResolveReferenceURL(repoURL, chartVersionInRepo.URLs[0])
+}
+
+// Get returns the index of the repository, fetching it only if the cached index expired.
+// A deduplicated fetch runs with the context of the caller that started it, while every caller stops waiting
+// for it once its own context is done.
+func (c *HelmRepoIndexCache) Get(ctx context.Context, repoURL string) (*repo.IndexFile, error) {
+	c.mu.Lock()
+	entry, found := c.entries[repoURL]
+	fresh := found && time.Since(entry.fetchedAt) < c.TTL
+	c.mu.Unlock()
+	if fresh {
+		c.stats.Hit()
+		return entry.index, nil
+	}
+	c.stats.Miss()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	fetched := c.fetches.DoChan(repoURL, func() (any, error) {
+		return c.fetch(ctx, repoURL)
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-fetched:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*repo.IndexFile), nil
+	}
+}
+
+// fetch downloads the index of the repository, an index that was not modified or has the digest of the
+// cached one is not parsed again.
+func (c *HelmRepoIndexCache) fetch(ctx context.Context, repoURL string) (*repo.IndexFile, error) {
+	parsedURL, err := url.Parse(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing url of helm repository %s: %w", repoURL, err)
+	}
+	c.mu.Lock()
+	var cached helmRepoIndexEntry
+	if entry, found := c.entries[repoURL]; found {
+		cached = *entry
+	}
+	c.mu.Unlock()
+
+	var body *bytes.Buffer
+	var validators helmRepoIndexEntry
+	if parsedURL.Scheme == "http" || parsedURL.Scheme == "https" {
+		body, validators, err = c.fetchHTTP(ctx, repoURL, cached)
+	} else {
+		body, err = c.fetchWithGetter(repoURL, parsedURL.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if body == nil {
+		c.mu.Lock()
+		if entry, found := c.entries[repoURL]; found {
+			entry.fetchedAt = time.Now()
+		}
+		c.mu.Unlock()
+		return cached.index, nil
+	}
+
+	sum := sha256.Sum256(body.Bytes())
+	digest := hex.EncodeToString(sum[:])
+	index := cached.index
+	if index == nil || cached.digest != digest {
+		if index, err = c.loadIndex(repoURL, body); err != nil {
+			return nil, err
+		}
+	}
+	c.mu.Lock()
+	c.entries[repoURL] = &helmRepoIndexEntry{
+		index: index, digest: digest, etag: validators.etag, lastModified: validators.lastModified,
+		fetchedAt: time.Now(),
+	}
+	c.mu.Unlock()
+	return index, nil
+}
+
+// fetchHTTP downloads the index of an HTTP repository with a conditional GET if the cached entry has validators.
+// It returns no body if the index was not modified, and the validators of the response otherwise.
+func (c *HelmRepoIndexCache) fetchHTTP(
+	ctx context.Context, repoURL string, cached helmRepoIndexEntry,
+) (*bytes.Buffer, helmRepoIndexEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, HelmRepoIndexTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, indexURL(repoURL), nil)
+	if err != nil {
+		return nil, cached, fmt.Errorf("fetching index of helm repository %s: %w", repoURL, err)
+	}
+	if cached.etag != "" {
+		request.Header.Set("If-None-Match", cached.etag)
+	}
+	if cached.lastModified != "" {
+		request.Header.Set("If-Modified-Since", cached.lastModified)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, cached, fmt.Errorf("fetching index of helm repository %s: %w", repoURL, err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotModified && cached.index != nil:
+		return nil, cached, nil
+	case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+		return nil, cached, fmt.Errorf("fetching index of helm repository %s: %w: %s",
+			repoURL, types.ErrRegistryUnauthorized, response.Status)
+	case response.StatusCode != http.StatusOK:
+		return nil, cached, fmt.Errorf("fetching index of helm repository %s: %s", repoURL, response.Status)
+	}
+	body := &bytes.Buffer{}
+	if _, err := body.ReadFrom(response.Body); err != nil {
+		return nil, cached, fmt.Errorf("fetching index of helm repository %s: %w", repoURL, err)
+	}
+	return body, helmRepoIndexEntry{
+		etag: response.Header.Get("ETag"), lastModified: response.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// fetchWithGetter downloads the index with the getter of the scheme of the repository. Getters do not support
+// conditional requests nor contexts, so the download is only bounded by HelmRepoIndexTimeout.
+func (c *HelmRepoIndexCache) fetchWithGetter(repoURL, scheme string) (*bytes.Buffer, error) {
+	getters := c.Getters
+	if getters == nil {
+		getters = getter.All(NewHelmEnvSettings(c.CacheDir))
+	}
+	indexGetter, err := getters.ByScheme(scheme)
+	if err != nil {
+		return nil, fmt.Errorf("fetching index of helm repository %s: %w", repoURL, err)
+	}
+	body, err := indexGetter.Get(indexURL(repoURL), getter.WithURL(repoURL), getter.WithTimeout(HelmRepoIndexTimeout))
+	if err != nil {
+		if isUnauthorizedFetch(err) {
+			return nil, fmt.Errorf("fetching index of helm repository %s: %w: %s",
+				repoURL, types.ErrRegistryUnauthorized, err.Error())
+		}
+		return nil, fmt.Errorf("fetching index of helm repository %s: %w", repoURL, err)
+	}
+	return body, nil
+}
+
+// isUnauthorizedFetch detects rejected credentials in the errors of the helm HTTP getter,
+// which only report the status of the response.
+func isUnauthorizedFetch(err error) bool {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		if strings.Contains(err.Error(), fmt.Sprintf("%d %s", status, http.StatusText(status))) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadIndex stores the index in the cache directory so that it can be validated and loaded by helm.
+func (c *HelmRepoIndexCache) loadIndex(repoURL string, body io.Reader) (*repo.IndexFile, error) {
+	hash := sha256.Sum256([]byte(repoURL))
+	indexPath := filepath.Join(c.CacheDir, helmRepoIndexFolder, hex.EncodeToString(hash[:])+".yaml")
+	if err := os.MkdirAll(filepath.Dir(indexPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+	if err := WriteFileAtomically(indexPath, body, DefaultFilePermission); err != nil {
+		return nil, fmt.Errorf("caching index of helm repository %s: %w", repoURL, err)
+	}
+	index, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading index of helm repository %s: %w", repoURL, err)
+	}
+	return index, nil
+}
+
+func indexURL(repoURL string) string {
+	return strings.TrimSuffix(repoURL, "/") + "/index.yaml"
+}
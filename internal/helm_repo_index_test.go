@@ -0,0 +1,124 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRepoIndex = `apiVersion: v1
+entries:
+  nginx:
+  - apiVersion: v2
+    name: nginx
+    version: 1.2.3
+    urls:
+    - charts/nginx-1.2.3.tgz
+`
+
+func TestHelmRepoIndexCache(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		downloads.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		_, _ = writer.Write([]byte(testRepoIndex))
+	}))
+	defer server.Close()
+
+	cache := internal.NewHelmRepoIndexCache(time.Hour, t.TempDir())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chartURL, err := cache.FindChartInRepoURL(ctx, server.URL, "nginx", "")
+			assertions.NoError(err)
+			assertions.Equal(server.URL+"/charts/nginx-1.2.3.tgz", chartURL)
+		}()
+	}
+	wg.Wait()
+	assertions.Equal(int32(1), downloads.Load(), "concurrent fetches of a repository should be deduplicated")
+
+	_, err := cache.FindChartInRepoURL(ctx, server.URL, "nginx", "")
+	assertions.NoError(err)
+	assertions.Equal(int32(1), downloads.Load(), "index should be served from cache within TTL")
+
+	index, err := cache.Get(ctx, server.URL)
+	assertions.NoError(err)
+	cache.TTL = 0
+	refetched, err := cache.Get(ctx, server.URL)
+	assertions.NoError(err)
+	assertions.Equal(int32(2), downloads.Load(), "expired index should be fetched again")
+	assertions.Same(index, refetched, "unchanged index should not be parsed again")
+
+	_, err = cache.FindChartInRepoURL(ctx, server.URL, "missing", "")
+	assertions.ErrorIs(err, types.ErrChartNotFound)
+}
+
+func TestHelmRepoIndexCacheRevalidates(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	var requests, downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		writer.Header().Set("ETag", `"v1"`)
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		_, _ = writer.Write([]byte(testRepoIndex))
+	}))
+	defer server.Close()
+
+	cache := internal.NewHelmRepoIndexCache(0, t.TempDir())
+	index, err := cache.Get(context.Background(), server.URL)
+	assertions.NoError(err)
+	revalidated, err := cache.Get(context.Background(), server.URL)
+	assertions.NoError(err)
+	assertions.Equal(int32(2), requests.Load(), "expired index should be revalidated")
+	assertions.Equal(int32(1), downloads.Load(), "unmodified index should not be downloaded again")
+	assertions.Same(index, revalidated)
+}
+
+func TestHelmRepoIndexCacheCancelled(t *testing.T) {
+	t.Parallel()
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-release:
+		case <-request.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := internal.NewHelmRepoIndexCache(time.Hour, t.TempDir()).Get(ctx, server.URL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHelmRepoIndexCacheUnauthorized(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := internal.NewHelmRepoIndexCache(time.Hour, t.TempDir()).
+		FindChartInRepoURL(context.Background(), server.URL, "nginx", "")
+	assert.ErrorIs(t, err, types.ErrRegistryUnauthorized)
+}
@@ -0,0 +1,23 @@
+package internal
+
+import (
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+const helmSettingsFolder = "helm"
+
+// NewHelmEnvSettings returns helm settings whose repository file, repository cache and registry config are
+// isolated in cacheDir instead of the locations of the process-global HELM_* environment variables.
+// Plugins of the environment are not loaded, so that getters only support the built-in protocols.
+// Settings are created per operation, as they are not safe to be modified concurrently.
+func NewHelmEnvSettings(cacheDir string) *cli.EnvSettings {
+	helmHome := filepath.Join(cacheDir, helmSettingsFolder)
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(helmHome, "repositories.yaml")
+	settings.RepositoryCache = filepath.Join(helmHome, "repository")
+	settings.RegistryConfig = filepath.Join(helmHome, "registry", "config.json")
+	settings.PluginsDirectory = ""
+	return settings
+}
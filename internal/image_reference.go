@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/kyma-project/module-manager/pkg/types"
+)
+
+var (
+	ErrInvalidImageReference = errors.New("invalid image reference")
+	ErrImageDigestRequired   = errors.New("image reference must be a digest")
+	ErrAmbiguousImageLayer   = errors.New("ambiguous image layer")
+	ErrImageLayerNotFound    = errors.New("no matching image layer")
+)
+
+const (
+	// LayerTitleAnnotation is the OCI annotation with the title of a layer, e.g. set by oras for pushed files.
+	LayerTitleAnnotation = "org.opencontainers.image.title"
+	// ChartLayerTitle is the default title of the chart layer of an image with several layers.
+	ChartLayerTitle = "chart"
+	// ConfigLayerTitle is the default title of the config layer of an image with several layers.
+	ConfigLayerTitle = "config"
+	// ImageDigestTTL is the time the digest resolved for a tag is reused, so that every reconciliation of
+	// a Manifest referencing a tag does not fetch the OCI manifest of the image again.
+	ImageDigestTTL = time.Minute
+)
+
+// resolvedDigests caches the layer digests resolved for tags by imageDigestKey until they expire.
+var resolvedDigests = struct { //nolint:gochecknoglobals
+	sync.Mutex
+	entries map[string]resolvedDigest
+}{entries: make(map[string]resolvedDigest)}
+
+type resolvedDigest struct {
+	digest    string
+	expiresAt time.Time
+}
+
+// ImageReference parses the reference of imageSpec. Ref is either the digest of a layer, e.g. "sha256:<hex>",
+// or the tag of an image, as tags cannot contain a colon.
+func ImageReference(imageSpec types.ImageSpec) (name.Reference, error) {
+	repository := fmt.Sprintf("%s/%s", imageSpec.Repo, imageSpec.Name)
+	if imageSpec.Ref == "" {
+		// an empty tag would silently default to latest
+		return nil, fmt.Errorf("%w: empty reference of %s", ErrInvalidImageReference, repository)
+	}
+	separator := ":"
+	if strings.Contains(imageSpec.Ref, ":") {
+		separator = "@"
+	}
+	reference, err := name.ParseReference(repository + separator + imageSpec.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q of %s: %v", ErrInvalidImageReference, imageSpec.Ref, repository, err)
+	}
+	return reference, nil
+}
+
+// NormalizeImageSpec returns imageSpec with the tag in Ref replaced by the digest of the layer of the tagged image
+// selected by SelectLayer, so that caches keyed by Ref are not served stale content once the tag is moved.
+// defaultTitle is the title of the layer selected from images with several layers if imageSpec has no
+// LayerSelector. With requireDigest, tags are rejected with ErrImageDigestRequired instead.
+// Resolved digests are reused for the ImageDigestTTL, so a moved tag is picked up once it expired.
+func NormalizeImageSpec(
+	ctx context.Context, imageSpec types.ImageSpec, insecureRegistry bool, keyChain authn.Keychain,
+	requireDigest bool, defaultTitle string,
+) (types.ImageSpec, error) {
+	reference, err := ImageReference(imageSpec)
+	if err != nil {
+		return imageSpec, err
+	}
+	if _, isDigest := reference.(name.Digest); isDigest {
+		return imageSpec, nil
+	}
+	if requireDigest {
+		return imageSpec, fmt.Errorf("%w: %s is referenced by tag", ErrImageDigestRequired, reference)
+	}
+
+	key := imageDigestKey(reference, imageSpec.LayerSelector, defaultTitle, insecureRegistry)
+	resolvedDigests.Lock()
+	resolved, cached := resolvedDigests.entries[key]
+	resolvedDigests.Unlock()
+	if cached && time.Now().Before(resolved.expiresAt) {
+		imageSpec.Ref = resolved.digest
+		return imageSpec, nil
+	}
+
+	digest, err := layerDigestOfTag(ctx, reference, imageSpec.LayerSelector, defaultTitle, insecureRegistry, keyChain)
+	if err != nil {
+		return imageSpec, err
+	}
+	resolvedDigests.Lock()
+	for cachedKey, entry := range resolvedDigests.entries {
+		if time.Now().After(entry.expiresAt) {
+			delete(resolvedDigests.entries, cachedKey)
+		}
+	}
+	resolvedDigests.entries[key] = resolvedDigest{digest: digest, expiresAt: time.Now().Add(ImageDigestTTL)}
+	resolvedDigests.Unlock()
+	imageSpec.Ref = digest
+	return imageSpec, nil
+}
+
+// imageDigestKey identifies the layer selected from the image of a tag.
+func imageDigestKey(
+	reference name.Reference, selector *types.LayerSelector, defaultTitle string, insecureRegistry bool,
+) string {
+	key := fmt.Sprintf("%s|%s|%t", reference, defaultTitle, insecureRegistry)
+	if selector != nil {
+		key += fmt.Sprintf("|%s|%v", selector.Title, selector.Annotations)
+	}
+	return key
+}
+
+func layerDigestOfTag(
+	ctx context.Context, reference name.Reference, selector *types.LayerSelector, defaultTitle string,
+	insecureRegistry bool, keyChain authn.Keychain,
+) (string, error) {
+	options := []crane.Option{crane.WithAuthFromKeychain(keyChain), crane.WithContext(ctx)}
+	if insecureRegistry {
+		options = append(options, crane.Insecure)
+	}
+	rawManifest, err := crane.Manifest(reference.String(), options...)
+	if err != nil {
+		return "", &types.DownloadError{Ref: reference.String(), Err: classifyRegistryError(err)}
+	}
+	manifest, err := v1.ParseManifest(bytes.NewReader(rawManifest))
+	if err != nil {
+		return "", &types.DownloadError{Ref: reference.String(), Err: err}
+	}
+	layer, err := SelectLayer(manifest.Layers, selector, defaultTitle)
+	if err != nil {
+		return "", fmt.Errorf("selecting layer of %s: %w", reference, err)
+	}
+	return layer.Digest.String(), nil
+}
+
+// SelectLayer returns the single layer matching selector. Without selector, the only layer of images with a
+// single layer is selected, or the layer titled defaultTitle of images with several layers.
+// It fails with ErrImageLayerNotFound if no layer matches and with ErrAmbiguousImageLayer if several layers match.
+func SelectLayer(layers []v1.Descriptor, selector *types.LayerSelector, defaultTitle string) (v1.Descriptor, error) {
+	if selector == nil {
+		if len(layers) == 1 {
+			return layers[0], nil
+		}
+		selector = &types.LayerSelector{Title: defaultTitle}
+	}
+
+	var matching []v1.Descriptor
+	for _, layer := range layers {
+		if layerMatches(layer, selector) {
+			matching = append(matching, layer)
+		}
+	}
+	switch len(matching) {
+	case 1:
+		return matching[0], nil
+	case 0:
+		return v1.Descriptor{}, fmt.Errorf("%w for %s among the layers %s, set a layerSelector",
+			ErrImageLayerNotFound, describeSelector(selector), layerTitles(layers))
+	default:
+		return v1.Descriptor{}, fmt.Errorf("%w: %d layers match %s, restrict the layerSelector",
+			ErrAmbiguousImageLayer, len(matching), describeSelector(selector))
+	}
+}
+
+func layerMatches(layer v1.Descriptor, selector *types.LayerSelector) bool {
+	if selector.Title != "" && layer.Annotations[LayerTitleAnnotation] != selector.Title {
+		return false
+	}
+	for key, value := range selector.Annotations {
+		if actual, found := layer.Annotations[key]; !found || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+func describeSelector(selector *types.LayerSelector) string {
+	var terms []string
+	if selector.Title != "" {
+		terms = append(terms, fmt.Sprintf("title %q", selector.Title))
+	}
+	for key, value := range selector.Annotations {
+		terms = append(terms, fmt.Sprintf("%s=%q", key, value))
+	}
+	sort.Strings(terms)
+	if len(terms) == 0 {
+		return "an empty selector"
+	}
+	return strings.Join(terms, ", ")
+}
+
+func layerTitles(layers []v1.Descriptor) string {
+	titles := make([]string, 0, len(layers))
+	for _, layer := range layers {
+		title, found := layer.Annotations[LayerTitleAnnotation]
+		if !found {
+			title = layer.Digest.String()
+		}
+		titles = append(titles, title)
+	}
+	return "[" + strings.Join(titles, ", ") + "]"
+}
@@ -0,0 +1,126 @@
+package internal_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const layerDigest = "sha256:6e9f7f2a0c6d3b0e44e4a4b2d3b1d4b5a8f1c2d3e4f5a6b7c8d9e0f1a2b3c4d5"
+
+func TestImageReference(t *testing.T) {
+	t.Parallel()
+	spec := types.ImageSpec{Repo: "registry.example.com/modules", Name: "keda", Ref: layerDigest}
+
+	reference, err := internal.ImageReference(spec)
+	require.NoError(t, err)
+	assert.IsType(t, name.Digest{}, reference)
+	assert.Equal(t, "registry.example.com/modules/keda@"+layerDigest, reference.String())
+
+	spec.Ref = "2.8.1"
+	reference, err = internal.ImageReference(spec)
+	require.NoError(t, err)
+	assert.IsType(t, name.Tag{}, reference)
+	assert.Equal(t, "registry.example.com/modules/keda:2.8.1", reference.String())
+
+	for _, ref := range []string{"", "sha256:short", "invalid tag"} {
+		spec.Ref = ref
+		_, err = internal.ImageReference(spec)
+		assert.ErrorIs(t, err, internal.ErrInvalidImageReference, ref)
+	}
+}
+
+func TestNormalizeImageSpec(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	image, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	tag, err := name.NewTag(serverURL.Host + "/modules/keda:2.8.1")
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, image))
+	layers, err := image.Layers()
+	require.NoError(t, err)
+	digest, err := layers[0].Digest()
+	require.NoError(t, err)
+
+	spec := types.ImageSpec{Repo: serverURL.Host + "/modules", Name: "keda", Ref: "2.8.1", Type: types.OciRefType}
+	normalized, err := internal.NormalizeImageSpec(ctx, spec, true, authn.DefaultKeychain, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), normalized.Ref)
+	assert.Equal(t, "2.8.1", spec.Ref, "the given spec must not be modified")
+
+	normalized, err = internal.NormalizeImageSpec(ctx, normalized, true, authn.DefaultKeychain, true, "")
+	require.NoError(t, err, "digests are kept")
+	assert.Equal(t, digest.String(), normalized.Ref)
+
+	_, err = internal.NormalizeImageSpec(ctx, spec, true, authn.DefaultKeychain, true, "")
+	assert.ErrorIs(t, err, internal.ErrImageDigestRequired)
+
+	moved, err := random.Image(1024, 1)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(tag, moved))
+	normalized, err = internal.NormalizeImageSpec(ctx, spec, true, authn.DefaultKeychain, false, "")
+	require.NoError(t, err)
+	assert.Equal(t, digest.String(), normalized.Ref, "the digest is reused until the ImageDigestTTL expired")
+}
+
+func TestSelectLayer(t *testing.T) {
+	t.Parallel()
+	layer := func(title string, annotations ...string) v1.Descriptor {
+		descriptor := v1.Descriptor{
+			Digest:      v1.Hash{Algorithm: "sha256", Hex: title},
+			Annotations: map[string]string{internal.LayerTitleAnnotation: title},
+		}
+		for i := 0; i+1 < len(annotations); i += 2 {
+			descriptor.Annotations[annotations[i]] = annotations[i+1]
+		}
+		return descriptor
+	}
+	chart, config := layer("chart", "kind", "helm"), layer("config", "kind", "values")
+	crds := layer("crds", "kind", "helm")
+
+	selected, err := internal.SelectLayer([]v1.Descriptor{crds}, nil, internal.ChartLayerTitle)
+	require.NoError(t, err)
+	assert.Equal(t, crds, selected, "single layers are selected regardless of their title")
+
+	layers := []v1.Descriptor{crds, chart, config}
+	selected, err = internal.SelectLayer(layers, nil, internal.ChartLayerTitle)
+	require.NoError(t, err)
+	assert.Equal(t, chart, selected)
+
+	selected, err = internal.SelectLayer(layers, &types.LayerSelector{Title: "crds"}, internal.ChartLayerTitle)
+	require.NoError(t, err)
+	assert.Equal(t, crds, selected)
+
+	selected, err = internal.SelectLayer(layers, &types.LayerSelector{
+		Annotations: map[string]string{"kind": "values"},
+	}, internal.ChartLayerTitle)
+	require.NoError(t, err)
+	assert.Equal(t, config, selected)
+
+	_, err = internal.SelectLayer(layers, &types.LayerSelector{
+		Annotations: map[string]string{"kind": "helm"},
+	}, internal.ChartLayerTitle)
+	assert.ErrorIs(t, err, internal.ErrAmbiguousImageLayer)
+
+	_, err = internal.SelectLayer([]v1.Descriptor{crds, config}, nil, internal.ChartLayerTitle)
+	assert.ErrorIs(t, err, internal.ErrImageLayerNotFound)
+	assert.ErrorContains(t, err, "[crds, config]", "the available layers are listed")
+}
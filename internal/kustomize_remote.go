@@ -0,0 +1,444 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+)
+
+const (
+	kustomizeRemoteFolder = "kustomize-remote"
+	githubHost            = "github.com"
+)
+
+var (
+	ErrKustomizeCommitMismatch = errors.New("kustomize remote commit mismatch")
+	ErrInvalidKustomizeURL     = errors.New("invalid kustomize remote URL")
+	ErrInvalidGitPath          = errors.New("path outside of git repository")
+)
+
+// GitCredentials authenticate fetches of private kustomize remotes.
+// Username and Password (e.g. a personal access token) are used for HTTPS,
+// SSHKey and KnownHosts for SSH remotes such as git@github.com:org/repo.
+type GitCredentials struct {
+	Username   string
+	Password   string
+	SSHKey     []byte
+	KnownHosts []byte
+}
+
+// KustomizeRemote describes a remote kustomization in the URL format of kustomize,
+// e.g. "github.com/org/repo//overlays/prod?ref=v1.0.0".
+type KustomizeRemote struct {
+	URL string
+	// Commit pins the full or abbreviated commit sha the fetched ref has to resolve to.
+	// If no ref is part of the URL, the commit itself is checked out.
+	Commit      string
+	Credentials *GitCredentials
+}
+
+// GitRemote describes a path in a git repository, e.g. of a chart or kustomization.
+type GitRemote struct {
+	// URL is the repository, e.g. "https://github.com/org/repo" or "git@github.com:org/repo",
+	// HTTPS is used if no scheme is given.
+	URL string
+	// Ref is the branch, tag or commit to check out, the default branch if empty.
+	Ref string
+	// Path is the slash separated path inside the repository, the root if empty.
+	Path string
+	// Commit pins the full or abbreviated commit sha the fetched ref has to resolve to.
+	Commit      string
+	Credentials *GitCredentials
+}
+
+// KustomizeRemoteFetcher clones remote kustomizations with the git CLI into CacheDir,
+// so that kustomize renders them from disk. Pinned commits that were fetched before are served from the cache
+// without any network access. Refs such as branches and tags are cached by the commit they resolved to and
+// resolved again with git ls-remote once the ResolveInterval passed, so that they are only cloned on new commits.
+type KustomizeRemoteFetcher struct {
+	CacheDir string
+	// MirrorDir optionally contains git repositories at <host>/<path> of their remote, e.g.
+	// <MirrorDir>/github.com/org/repo, which are used instead of the remote for offline installations.
+	MirrorDir string
+	// ResolveInterval is the time a ref is served from the commit it resolved to, e.g. the requeue interval
+	// of Manifests. Refs are resolved again on every fetch if it is nil.
+	ResolveInterval func() time.Duration
+
+	mu       sync.Mutex
+	resolved map[string]resolvedRef
+}
+
+// resolvedRef is the commit a ref of a repository resolved to at resolvedAt.
+type resolvedRef struct {
+	commit     string
+	resolvedAt time.Time
+}
+
+// Fetch returns the local path of the kustomization described by remote and the commit it was fetched at.
+func (f *KustomizeRemoteFetcher) Fetch(ctx context.Context, remote KustomizeRemote) (string, string, error) {
+	repo, subPath, ref, err := ParseKustomizeURL(remote.URL)
+	if err != nil {
+		return "", "", err
+	}
+	return f.fetch(ctx, remote.URL, repo, subPath, ref, remote.Commit, remote.Credentials)
+}
+
+// FetchGit returns the local path of remote.Path in the repository of remote and the commit it was fetched at.
+// Repositories are cached and mirrored like remote kustomizations.
+func (f *KustomizeRemoteFetcher) FetchGit(ctx context.Context, remote GitRemote) (string, string, error) {
+	subPath := filepath.Clean(filepath.FromSlash(remote.Path))
+	if filepath.IsAbs(subPath) || subPath == ".." || strings.HasPrefix(subPath, ".."+string(filepath.Separator)) {
+		return "", "", fmt.Errorf("%w: %s", ErrInvalidGitPath, remote.Path)
+	}
+	repo := remote.URL
+	if !strings.Contains(repo, "://") && !strings.HasPrefix(repo, "git@") {
+		repo = "https://" + repo
+	}
+	return f.fetch(ctx, remote.URL, repo, subPath, remote.Ref, remote.Commit, remote.Credentials)
+}
+
+// fetch checks out ref or commit of repo and returns the local path of subPath, rawURL is the URL
+// the repository was referenced by, which is used in errors.
+func (f *KustomizeRemoteFetcher) fetch(
+	ctx context.Context, rawURL, repo, subPath, ref, pinnedCommit string, credentials *GitCredentials,
+) (string, string, error) {
+	if mirror := f.mirrorOf(repo); mirror != "" {
+		repo = mirror
+	}
+
+	cacheDir := filepath.Join(f.CacheDir, kustomizeRemoteFolder, repoCacheKey(repo))
+	if ref == "" && isFullCommit(pinnedCommit) {
+		checkout := filepath.Join(cacheDir, strings.ToLower(pinnedCommit))
+		if _, err := os.Stat(checkout); err == nil {
+			return filepath.Join(checkout, subPath), pinnedCommit, nil
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return "", "", err
+	}
+
+	// refs are resolved to their commit, pinned commits without a ref are checked out directly
+	resolveKey := ""
+	if ref != "" || pinnedCommit == "" {
+		resolveKey = repoCacheKey(repo) + "@" + ref
+		if commit := f.cachedRef(ctx, cacheDir, resolveKey, repo, ref, credentials); commit != "" {
+			if err := verifyCommit(rawURL, commit, pinnedCommit); err != nil {
+				return "", "", err
+			}
+			return filepath.Join(cacheDir, commit, subPath), commit, nil
+		}
+	}
+
+	tmp, err := os.MkdirTemp(cacheDir, "fetch-")
+	if err != nil {
+		return "", "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	git, err := newGitCommand(tmp, credentials)
+	if err != nil {
+		return "", "", err
+	}
+	defer git.cleanup()
+
+	commit, err := git.fetch(ctx, repo, ref, pinnedCommit)
+	if err != nil {
+		return "", "", &types.DownloadError{Ref: redactURL(rawURL), Err: err}
+	}
+	if resolveKey != "" {
+		f.storeRef(resolveKey, commit)
+	}
+	if err := verifyCommit(rawURL, commit, pinnedCommit); err != nil {
+		return "", "", err
+	}
+
+	checkout := filepath.Join(cacheDir, commit)
+	if err := os.RemoveAll(filepath.Join(tmp, ".git")); err != nil {
+		return "", "", err
+	}
+	if err := os.Rename(tmp, checkout); err != nil && !os.IsExist(err) {
+		// a concurrent fetch of the same commit already populated the cache
+		if _, statErr := os.Stat(checkout); statErr != nil {
+			return "", "", err
+		}
+	}
+	return filepath.Join(checkout, subPath), commit, nil
+}
+
+// cachedRef returns the commit ref resolved to if it is checked out in cacheDir. The commit is resolved again
+// with git ls-remote once the ResolveInterval passed, an empty commit lets the caller fetch the ref.
+func (f *KustomizeRemoteFetcher) cachedRef(
+	ctx context.Context, cacheDir, key, repo, ref string, credentials *GitCredentials,
+) string {
+	f.mu.Lock()
+	entry, found := f.resolved[key]
+	f.mu.Unlock()
+	if !found {
+		return ""
+	}
+
+	commit := entry.commit
+	if f.ResolveInterval == nil || time.Since(entry.resolvedAt) >= f.ResolveInterval() {
+		git, err := newGitCommand(cacheDir, credentials)
+		if err != nil {
+			return ""
+		}
+		defer git.cleanup()
+		if commit, err = git.resolve(ctx, repo, ref); err != nil || commit == "" {
+			return ""
+		}
+		f.storeRef(key, commit)
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, commit)); err != nil {
+		return ""
+	}
+	return commit
+}
+
+func (f *KustomizeRemoteFetcher) storeRef(key, commit string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resolved == nil {
+		f.resolved = make(map[string]resolvedRef)
+	}
+	f.resolved[key] = resolvedRef{commit: commit, resolvedAt: time.Now()}
+}
+
+// verifyCommit checks that a fetched commit matches the pinned commit, if any.
+func verifyCommit(rawURL, commit, pinnedCommit string) error {
+	if pinnedCommit != "" && !strings.HasPrefix(commit, strings.ToLower(pinnedCommit)) {
+		return fmt.Errorf("%w: %s resolved to %s, expected %s",
+			ErrKustomizeCommitMismatch, redactURL(rawURL), commit, pinnedCommit)
+	}
+	return nil
+}
+
+func (f *KustomizeRemoteFetcher) mirrorOf(repo string) string {
+	if f.MirrorDir == "" {
+		return ""
+	}
+	host, path := repoHostAndPath(repo)
+	if host == "" {
+		return ""
+	}
+	mirror := filepath.Join(f.MirrorDir, host, filepath.FromSlash(strings.TrimSuffix(path, ".git")))
+	if _, err := os.Stat(mirror); err != nil {
+		return ""
+	}
+	return mirror
+}
+
+// ParseKustomizeURL splits a kustomize remote URL into the git repository, the path of the kustomization
+// inside the repository and the ref to check out. The repository and path are separated by "//";
+// for github.com the first two path segments are the repository if no separator is given.
+func ParseKustomizeURL(rawURL string) (string, string, string, error) {
+	repo, query, _ := strings.Cut(rawURL, "?")
+	var ref string
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", "", "", fmt.Errorf("%w: %s: %v", ErrInvalidKustomizeURL, redactURL(rawURL), err)
+		}
+		ref = values.Get("ref")
+		if ref == "" {
+			ref = values.Get("version")
+		}
+	}
+
+	scheme := ""
+	if i := strings.Index(repo, "://"); i >= 0 {
+		scheme, repo = repo[:i+3], repo[i+3:]
+	}
+
+	var subPath string
+	if i := strings.Index(repo, "//"); i >= 0 {
+		repo, subPath = repo[:i], repo[i+2:]
+	} else if strings.HasPrefix(repo, githubHost+"/") {
+		segments := strings.SplitN(repo, "/", 4) //nolint:gomnd
+		if len(segments) == 4 {                  //nolint:gomnd
+			repo, subPath = strings.Join(segments[:3], "/"), segments[3]
+		}
+	}
+	if repo == "" {
+		return "", "", "", fmt.Errorf("%w: %s", ErrInvalidKustomizeURL, redactURL(rawURL))
+	}
+
+	if scheme == "" && !strings.HasPrefix(repo, "git@") {
+		scheme = "https://"
+	}
+	return scheme + repo, filepath.FromSlash(subPath), ref, nil
+}
+
+// repoHostAndPath returns host and path of HTTPS, SSH and scp-like (git@host:path) repositories.
+func repoHostAndPath(repo string) (string, string) {
+	if strings.HasPrefix(repo, "git@") {
+		host, path, _ := strings.Cut(strings.TrimPrefix(repo, "git@"), ":")
+		return host, path
+	}
+	parsed, err := url.Parse(repo)
+	if err != nil || parsed.Scheme == "file" {
+		return "", ""
+	}
+	return parsed.Hostname(), strings.TrimPrefix(parsed.Path, "/")
+}
+
+func repoCacheKey(repo string) string {
+	sum := sha256.Sum256([]byte(redactURL(repo)))
+	return hex.EncodeToString(sum[:8]) //nolint:gomnd
+}
+
+func isFullCommit(commit string) bool {
+	const shaLength = 40
+	_, err := hex.DecodeString(commit)
+	return len(commit) == shaLength && err == nil
+}
+
+func redactURL(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil && parsed.User != nil {
+		parsed.User = nil
+		return parsed.String()
+	}
+	return rawURL
+}
+
+type gitCommand struct {
+	dir     string
+	env     []string
+	tempDir string
+}
+
+// newGitCommand prepares the environment of git invocations in dir. Credentials are passed through
+// the environment and temporary files instead of arguments, so that they are not visible in the process list.
+func newGitCommand(dir string, credentials *GitCredentials) (*gitCommand, error) {
+	git := &gitCommand{dir: dir, env: append(os.Environ(), "GIT_TERMINAL_PROMPT=0")}
+	if credentials == nil {
+		return git, nil
+	}
+
+	if credentials.Password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+		git.env = append(git.env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+		)
+	}
+
+	if len(credentials.SSHKey) > 0 {
+		tempDir, err := os.MkdirTemp("", "git-ssh-")
+		if err != nil {
+			return nil, err
+		}
+		git.tempDir = tempDir
+		keyFile := filepath.Join(tempDir, "identity")
+		if err := os.WriteFile(keyFile, credentials.SSHKey, 0o600); err != nil { //nolint:gomnd
+			git.cleanup()
+			return nil, err
+		}
+		sshCommand := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes -o BatchMode=yes", keyFile)
+		if len(credentials.KnownHosts) > 0 {
+			knownHostsFile := filepath.Join(tempDir, "known_hosts")
+			if err := os.WriteFile(knownHostsFile, credentials.KnownHosts, 0o600); err != nil { //nolint:gomnd
+				git.cleanup()
+				return nil, err
+			}
+			sshCommand += fmt.Sprintf(" -o UserKnownHostsFile=%s -o StrictHostKeyChecking=yes", knownHostsFile)
+		}
+		git.env = append(git.env, "GIT_SSH_COMMAND="+sshCommand)
+	}
+	return git, nil
+}
+
+func (g *gitCommand) cleanup() {
+	if g.tempDir != "" {
+		_ = os.RemoveAll(g.tempDir)
+	}
+}
+
+// fetch checks out ref, or commit if no ref is given, of repo and returns the resulting commit.
+// Commits are fetched shallow if the server allows it and completely otherwise.
+func (g *gitCommand) fetch(ctx context.Context, repo, ref, commit string) (string, error) {
+	if _, err := g.run(ctx, "init", "--quiet"); err != nil {
+		return "", err
+	}
+	if _, err := g.run(ctx, "remote", "add", "origin", repo); err != nil {
+		return "", err
+	}
+
+	target := ref
+	if target == "" {
+		target = commit
+	}
+	if target == "" {
+		target = "HEAD"
+	}
+	if _, err := g.run(ctx, "fetch", "--quiet", "--depth", "1", "origin", target); err != nil {
+		if ref != "" || commit == "" {
+			return "", err
+		}
+		if _, err := g.run(ctx, "fetch", "--quiet", "origin"); err != nil {
+			return "", err
+		}
+		target = commit
+	} else {
+		target = "FETCH_HEAD"
+	}
+	if _, err := g.run(ctx, "checkout", "--quiet", "--detach", target); err != nil {
+		return "", err
+	}
+	return g.run(ctx, "rev-parse", "HEAD")
+}
+
+// resolve returns the commit ref of repo points to without fetching it, the default branch if ref is empty.
+// Branches take precedence over tags and annotated tags resolve to the commit they point to. An empty commit is
+// returned for refs that cannot be listed, e.g. abbreviated commits.
+func (g *gitCommand) resolve(ctx context.Context, repo, ref string) (string, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := g.run(ctx, "ls-remote", repo, ref)
+	if err != nil {
+		return "", err
+	}
+	commits := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if commit, name, found := strings.Cut(line, "\t"); found {
+			commits[name] = commit
+		}
+	}
+	for _, name := range []string{
+		ref + "^{}", ref, "refs/heads/" + ref, "refs/tags/" + ref + "^{}", "refs/tags/" + ref,
+	} {
+		if commit, found := commits[name]; found {
+			return commit, nil
+		}
+	}
+	return "", nil
+}
+
+func (g *gitCommand) run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.dir
+	cmd.Env = g.env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,190 @@
+package internal_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseKustomizeURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		url, repo, path, ref string
+	}{
+		{"github.com/org/repo//overlays/prod?ref=v1.0.0", "https://github.com/org/repo", "overlays/prod", "v1.0.0"},
+		{"github.com/org/repo/overlays/prod?ref=main", "https://github.com/org/repo", "overlays/prod", "main"},
+		{"https://example.com/org/repo.git//base?version=v2", "https://example.com/org/repo.git", "base", "v2"},
+		{"git@github.com:org/repo.git//base", "git@github.com:org/repo.git", "base", ""},
+		{"ssh://git@example.com/org/repo", "ssh://git@example.com/org/repo", "", ""},
+	}
+	for _, test := range tests {
+		repo, path, ref, err := internal.ParseKustomizeURL(test.url)
+		assert.NoError(t, err, test.url)
+		assert.Equal(t, test.repo, repo, test.url)
+		assert.Equal(t, filepath.FromSlash(test.path), path, test.url)
+		assert.Equal(t, test.ref, ref, test.url)
+	}
+	_, _, _, err := internal.ParseKustomizeURL("//base")
+	assert.ErrorIs(t, err, internal.ErrInvalidKustomizeURL)
+}
+
+func gitRepoWithKustomization(t *testing.T, dir string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, "base"), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "base", "kustomization.yaml"),
+		[]byte("resources: []\n"), internal.DefaultFilePermission); err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{
+			"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false",
+		}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", args[0], err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "--quiet")
+	git("add", ".")
+	git("commit", "--quiet", "-m", "kustomization")
+	git("tag", "v1.0.0")
+	return git("rev-parse", "HEAD")
+}
+
+func Test_KustomizeRemoteFetcher(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	ctx := context.Background()
+	repo := t.TempDir()
+	commit := gitRepoWithKustomization(t, repo)
+	fetcher := &internal.KustomizeRemoteFetcher{CacheDir: t.TempDir()}
+
+	path, fetched, err := fetcher.Fetch(ctx, internal.KustomizeRemote{URL: "file://" + repo + "//base?ref=v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, commit, fetched)
+	assert.FileExists(t, filepath.Join(path, "kustomization.yaml"))
+
+	_, _, err = fetcher.Fetch(ctx, internal.KustomizeRemote{URL: "file://" + repo + "//base?ref=v1.0.0", Commit: commit[:12]})
+	assert.NoError(t, err, "abbreviated commits are verified")
+
+	_, _, err = fetcher.Fetch(ctx, internal.KustomizeRemote{
+		URL: "file://" + repo + "//base?ref=v1.0.0", Commit: strings.Repeat("0", len(commit)),
+	})
+	assert.ErrorIs(t, err, internal.ErrKustomizeCommitMismatch)
+
+	path, fetched, err = fetcher.Fetch(ctx, internal.KustomizeRemote{URL: "file://" + repo + "//base", Commit: commit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, commit, fetched)
+	assert.FileExists(t, filepath.Join(path, "kustomization.yaml"))
+
+	if err := os.RemoveAll(repo); err != nil {
+		t.Fatal(err)
+	}
+	cached, _, err := fetcher.Fetch(ctx, internal.KustomizeRemote{URL: "file://" + repo + "//base", Commit: commit})
+	assert.NoError(t, err, "pinned commits are served from the cache")
+	assert.Equal(t, path, cached)
+}
+
+func Test_KustomizeRemoteFetcherMirror(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	mirrorDir := t.TempDir()
+	commit := gitRepoWithKustomization(t, filepath.Join(mirrorDir, "example.invalid", "org", "repo"))
+	fetcher := &internal.KustomizeRemoteFetcher{CacheDir: t.TempDir(), MirrorDir: mirrorDir}
+
+	path, fetched, err := fetcher.Fetch(context.Background(),
+		internal.KustomizeRemote{URL: "example.invalid/org/repo.git//base?ref=v1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, commit, fetched)
+	assert.FileExists(t, filepath.Join(path, "kustomization.yaml"))
+}
+
+func Test_KustomizeRemoteFetcherFetchGit(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	repo := t.TempDir()
+	commit := gitRepoWithKustomization(t, repo)
+	fetcher := &internal.KustomizeRemoteFetcher{CacheDir: t.TempDir()}
+
+	path, fetched, err := fetcher.FetchGit(context.Background(),
+		internal.GitRemote{URL: "file://" + repo, Ref: "v1.0.0", Path: "base", Commit: commit})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, commit, fetched)
+	assert.FileExists(t, filepath.Join(path, "kustomization.yaml"))
+
+	_, _, err = fetcher.FetchGit(context.Background(), internal.GitRemote{URL: "file://" + repo, Path: "../base"})
+	assert.ErrorIs(t, err, internal.ErrInvalidGitPath)
+}
+
+func Test_KustomizeRemoteFetcherResolvesRefs(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	ctx := context.Background()
+	repo := t.TempDir()
+	first := gitRepoWithKustomization(t, repo)
+	interval := time.Hour
+	fetcher := &internal.KustomizeRemoteFetcher{
+		CacheDir: t.TempDir(), ResolveInterval: func() time.Duration { return interval },
+	}
+	remote := internal.GitRemote{URL: "file://" + repo, Path: "base"}
+
+	_, fetched, err := fetcher.FetchGit(ctx, remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, first, fetched)
+
+	cmd := exec.Command("git", "-c", "user.name=test", "-c", "user.email=test@example.com",
+		"-c", "commit.gpgsign=false", "commit", "--quiet", "--allow-empty", "-m", "update")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v: %s", err, out)
+	}
+
+	_, fetched, err = fetcher.FetchGit(ctx, remote)
+	assert.NoError(t, err)
+	assert.Equal(t, first, fetched, "refs are served from their resolved commit within the interval")
+
+	interval = 0
+	path, second, err := fetcher.FetchGit(ctx, remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, first, second, "refs are resolved again after the interval")
+	assert.FileExists(t, filepath.Join(path, "kustomization.yaml"))
+
+	interval = time.Hour
+	if err := os.RemoveAll(repo); err != nil {
+		t.Fatal(err)
+	}
+	_, fetched, err = fetcher.FetchGit(ctx, remote)
+	assert.NoError(t, err)
+	assert.Equal(t, second, fetched)
+}
@@ -0,0 +1,92 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	listener "github.com/kyma-project/runtime-watcher/listener/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	DefaultListenerShutdownTimeout = 30 * time.Second
+	listenerTimeout                = 60 * time.Second
+)
+
+// DefaultListenerPath is the path remote cluster watchers send their events to by contract.
+func DefaultListenerPath(componentName string) string {
+	return fmt.Sprintf("/v1/%s/event", componentName)
+}
+
+// DefaultListenerHeartbeatPath is the path remote cluster watchers send their heartbeats to.
+func DefaultListenerHeartbeatPath(componentName string) string {
+	return fmt.Sprintf("/v1/%s/heartbeat", componentName)
+}
+
+// EventListener serves the events of remote cluster watchers on Addr and Path and, if Heartbeat is set,
+// their heartbeats on HeartbeatPath.
+// It is added to the manager as a runnable, so the server is only started with the manager
+// and is shut down gracefully within ShutdownTimeout once the manager stops.
+type EventListener struct {
+	Addr            string
+	Path            string
+	HeartbeatPath   string
+	Heartbeat       http.Handler
+	ShutdownTimeout time.Duration
+
+	events *listener.SKREventListener
+}
+
+// NewEventListener returns the listener and the source of the received events.
+func NewEventListener(addr, path, componentName string) (*EventListener, *source.Channel) {
+	events, eventSource := listener.RegisterListenerComponent(addr, componentName)
+	if path == "" {
+		path = DefaultListenerPath(componentName)
+	}
+	return &EventListener{
+		Addr:            addr,
+		Path:            path,
+		HeartbeatPath:   DefaultListenerHeartbeatPath(componentName),
+		ShutdownTimeout: DefaultListenerShutdownTimeout,
+		events:          events,
+	}, eventSource
+}
+
+func (l *EventListener) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx, "Module", "Listener")
+	l.events.Logger = logger
+
+	router := http.NewServeMux()
+	router.HandleFunc(l.Path, l.events.HandleSKREvent())
+	if l.Heartbeat != nil {
+		router.Handle(l.HeartbeatPath, l.Heartbeat)
+	}
+	server := &http.Server{
+		Addr: l.Addr, Handler: router,
+		ReadHeaderTimeout: listenerTimeout, ReadTimeout: listenerTimeout, WriteTimeout: listenerTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Listener is starting up...", "Addr", l.Addr, "ApiPath", l.Path)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("listener on %s failed: %w", l.Addr, err)
+	case <-ctx.Done():
+	}
+
+	logger.Info("SKR events listener is shutting down: context got closed")
+	// the manager context is already done, so in-flight requests get their own deadline to finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), l.ShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
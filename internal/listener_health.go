@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultListenerHealthPath is the path of the ListenerHealth on the metrics server.
+	DefaultListenerHealthPath = "/listener"
+
+	MetricListenerLastEvent     = "module_manager_listener_last_event_timestamp_seconds"
+	MetricListenerLastHeartbeat = "module_manager_listener_last_heartbeat_timestamp_seconds"
+	MetricLabelCluster          = "cluster"
+
+	maxHeartbeatBytes = 4096
+)
+
+var (
+	// ListenerLastEvent is the time of the last event received from the watcher of every remote cluster.
+	ListenerLastEvent = prometheus.NewGaugeVec(prometheus.GaugeOpts{ //nolint:gochecknoglobals
+		Name: MetricListenerLastEvent,
+		Help: "Unix time of the last event received from the watcher of a remote cluster by cluster",
+	}, []string{MetricLabelCluster})
+	// ListenerLastHeartbeat is the time of the last heartbeat received from the watcher of every remote cluster.
+	ListenerLastHeartbeat = prometheus.NewGaugeVec(prometheus.GaugeOpts{ //nolint:gochecknoglobals
+		Name: MetricListenerLastHeartbeat,
+		Help: "Unix time of the last heartbeat received from the watcher of a remote cluster by cluster",
+	}, []string{MetricLabelCluster})
+)
+
+//nolint:gochecknoinits
+func init() {
+	ctrlmetrics.Registry.MustRegister(ListenerLastEvent, ListenerLastHeartbeat)
+}
+
+// ListenerClusterHealth describes when the last event and heartbeat of the watcher of a remote cluster were received.
+type ListenerClusterHealth struct {
+	LastEvent                 time.Time `json:"lastEvent"`
+	LastHeartbeat             time.Time `json:"lastHeartbeat"`
+	SecondsSinceLastHeartbeat float64   `json:"secondsSinceLastHeartbeat,omitempty"`
+	ExceedsStalenessTimeout   bool      `json:"exceedsStalenessTimeout,omitempty"`
+}
+
+// ListenerHeartbeat is the body watchers send to the heartbeat path of the listener.
+type ListenerHeartbeat struct {
+	// Cluster is the name of the Kyma of the remote cluster, as in the labels.KymaName label of its Manifests.
+	Cluster string `json:"cluster"`
+}
+
+// ListenerHealth records the events and heartbeats received from the watchers of remote clusters, so that watchers
+// that cannot reach the listener, e.g. because of a changed network policy, are detected. As clusters without
+// changes send no events, the staleness is determined by the heartbeats only, events are recorded for diagnosis.
+// Both are exported as metric and served over HTTP. Clusters that never sent a heartbeat are measured from the
+// creation of the ListenerHealth.
+type ListenerHealth struct {
+	// StalenessTimeout is the time without heartbeats after which a cluster is stale, 0 disables the staleness.
+	StalenessTimeout time.Duration
+
+	mu            sync.Mutex
+	started       time.Time
+	lastEvent     map[string]time.Time
+	lastHeartbeat map[string]time.Time
+}
+
+func NewListenerHealth(stalenessTimeout time.Duration) *ListenerHealth {
+	return &ListenerHealth{
+		StalenessTimeout: stalenessTimeout,
+		started:          time.Now(),
+		lastEvent:        make(map[string]time.Time),
+		lastHeartbeat:    make(map[string]time.Time),
+	}
+}
+
+// Observed records an event of the watcher of cluster received at the given time.
+func (h *ListenerHealth) Observed(cluster string, at time.Time) {
+	if cluster == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if at.After(h.lastEvent[cluster]) {
+		h.lastEvent[cluster] = at
+		ListenerLastEvent.WithLabelValues(cluster).Set(float64(at.Unix()))
+	}
+}
+
+// Heartbeat records a heartbeat of the watcher of cluster received at the given time.
+func (h *ListenerHealth) Heartbeat(cluster string, at time.Time) {
+	if cluster == "" {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if at.After(h.lastHeartbeat[cluster]) {
+		h.lastHeartbeat[cluster] = at
+		ListenerLastHeartbeat.WithLabelValues(cluster).Set(float64(at.Unix()))
+	}
+}
+
+// Forget drops the events, heartbeats and metric series of cluster, e.g. once all of its Manifests were deleted.
+func (h *ListenerHealth) Forget(cluster string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.lastEvent, cluster)
+	delete(h.lastHeartbeat, cluster)
+	ListenerLastEvent.DeleteLabelValues(cluster)
+	ListenerLastHeartbeat.DeleteLabelValues(cluster)
+}
+
+// Stale returns if no heartbeat of the watcher of cluster was received within the StalenessTimeout before now,
+// and the time of the last heartbeat, which is zero if no heartbeat was received yet.
+func (h *ListenerHealth) Stale(cluster string, now time.Time) (bool, time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	lastHeartbeat := h.lastHeartbeat[cluster]
+	if h.StalenessTimeout <= 0 {
+		return false, lastHeartbeat
+	}
+	since := lastHeartbeat
+	if since.IsZero() {
+		since = h.started
+	}
+	return now.Sub(since) > h.StalenessTimeout, lastHeartbeat
+}
+
+// State returns the health of all clusters that sent events or heartbeats.
+func (h *ListenerHealth) State() map[string]ListenerClusterHealth {
+	h.mu.Lock()
+	state := make(map[string]ListenerClusterHealth, len(h.lastHeartbeat))
+	for cluster, lastEvent := range h.lastEvent {
+		state[cluster] = ListenerClusterHealth{LastEvent: lastEvent}
+	}
+	for cluster := range h.lastHeartbeat {
+		state[cluster] = ListenerClusterHealth{LastEvent: h.lastEvent[cluster]}
+	}
+	h.mu.Unlock()
+
+	now := time.Now()
+	for cluster, health := range state {
+		stale, lastHeartbeat := h.Stale(cluster, now)
+		health.LastHeartbeat = lastHeartbeat
+		health.ExceedsStalenessTimeout = stale
+		if !lastHeartbeat.IsZero() {
+			health.SecondsSinceLastHeartbeat = now.Sub(lastHeartbeat).Seconds()
+		}
+		state[cluster] = health
+	}
+	return state
+}
+
+func (h *ListenerHealth) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.State()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// HeartbeatHandler records the ListenerHeartbeat POSTed by the watcher of a remote cluster.
+func (h *ListenerHealth) HeartbeatHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		heartbeat := ListenerHeartbeat{}
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxHeartbeatBytes)).Decode(&heartbeat); err != nil ||
+			heartbeat.Cluster == "" {
+			http.Error(w, "heartbeat has to name its cluster", http.StatusBadRequest)
+			return
+		}
+		h.Heartbeat(heartbeat.Cluster, time.Now())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
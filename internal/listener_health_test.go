@@ -0,0 +1,105 @@
+package internal_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerHealth(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	health := internal.NewListenerHealth(time.Minute)
+	now := time.Now()
+
+	stale, lastEvent := health.Stale("listener-health-kyma", now)
+	asserts.False(stale, "clusters are measured from the start of the listener")
+	asserts.True(lastEvent.IsZero())
+	stale, _ = health.Stale("listener-health-kyma", now.Add(2*time.Minute))
+	asserts.True(stale)
+
+	health.Heartbeat("listener-health-kyma", now)
+	health.Heartbeat("listener-health-kyma", now.Add(-time.Hour))
+	health.Heartbeat("", now)
+	stale, lastHeartbeat := health.Stale("listener-health-kyma", now.Add(30*time.Second))
+	asserts.False(stale)
+	asserts.Equal(now, lastHeartbeat, "older heartbeats do not replace the last heartbeat")
+
+	health.Observed("listener-health-kyma", now)
+	health.Observed("listener-health-kyma", now.Add(-time.Hour))
+	health.Observed("", now)
+	asserts.Equal(float64(now.Unix()),
+		testutil.ToFloat64(internal.ListenerLastEvent.WithLabelValues("listener-health-kyma")))
+
+	recorder := httptest.NewRecorder()
+	health.ServeHTTP(recorder, httptest.NewRequest("GET", internal.DefaultListenerHealthPath, nil))
+	state := map[string]internal.ListenerClusterHealth{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	asserts.Len(state, 1)
+	asserts.True(state["listener-health-kyma"].LastEvent.Equal(now), "older events do not replace the last event")
+	asserts.True(state["listener-health-kyma"].LastHeartbeat.Equal(now))
+
+	stale, _ = internal.NewListenerHealth(0).Stale("listener-health-kyma", now.Add(time.Hour))
+	asserts.False(stale, "staleness is disabled without timeout")
+}
+
+func TestListenerHealthHeartbeats(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	health := internal.NewListenerHealth(time.Minute)
+	now := time.Now()
+
+	health.Observed("listener-heartbeat-kyma", now)
+	stale, lastHeartbeat := health.Stale("listener-heartbeat-kyma", now.Add(2*time.Minute))
+	asserts.True(stale, "events do not replace heartbeats, as quiet clusters send no events")
+	asserts.True(lastHeartbeat.IsZero())
+
+	recorder := httptest.NewRecorder()
+	health.HeartbeatHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/module-manager/heartbeat",
+		strings.NewReader(`{"cluster":"listener-heartbeat-kyma"}`)))
+	asserts.Equal(http.StatusNoContent, recorder.Code)
+	stale, lastHeartbeat = health.Stale("listener-heartbeat-kyma", time.Now().Add(30*time.Second))
+	asserts.False(stale)
+	asserts.False(lastHeartbeat.IsZero())
+	asserts.True(hasClusterSeries(t, internal.ListenerLastHeartbeat, "listener-heartbeat-kyma"))
+
+	recorder = httptest.NewRecorder()
+	health.HeartbeatHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/module-manager/heartbeat",
+		strings.NewReader(`{}`)))
+	asserts.Equal(http.StatusBadRequest, recorder.Code)
+	recorder = httptest.NewRecorder()
+	health.HeartbeatHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v1/module-manager/heartbeat", nil))
+	asserts.Equal(http.StatusMethodNotAllowed, recorder.Code)
+
+	health.Forget("listener-heartbeat-kyma")
+	asserts.Empty(health.State())
+	asserts.False(hasClusterSeries(t, internal.ListenerLastHeartbeat, "listener-heartbeat-kyma"))
+	asserts.False(hasClusterSeries(t, internal.ListenerLastEvent, "listener-heartbeat-kyma"))
+}
+
+func hasClusterSeries(t *testing.T, gauge *prometheus.GaugeVec, cluster string) bool {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(gauge))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == internal.MetricLabelCluster && label.GetValue() == cluster {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ListenerQueueSuffix is appended to the controller name to name the ListenerQueue of the controller.
+const ListenerQueueSuffix = "-listener"
+
+// ListenerQueue passes the events of remote cluster watchers through a named workqueue before they are added
+// to the queue of the controller, so that the adds, depth and latency of listener events are observable
+// in the workqueue metrics separately from the events of the control plane.
+// Events for the same object that arrive before it is forwarded are deduplicated.
+// It is added to the manager as a runnable and forwards events until the manager stops.
+type ListenerQueue struct {
+	queue workqueue.Interface
+
+	mu     sync.Mutex
+	target workqueue.Interface
+}
+
+func NewListenerQueue(name string) *ListenerQueue {
+	return &ListenerQueue{queue: workqueue.NewNamed(name)}
+}
+
+// Add queues key to be forwarded to target, which is the queue of the controller handling the event.
+func (q *ListenerQueue) Add(key client.ObjectKey, target workqueue.Interface) {
+	q.mu.Lock()
+	q.target = target
+	q.mu.Unlock()
+	q.queue.Add(key)
+}
+
+func (q *ListenerQueue) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		q.queue.ShutDown()
+	}()
+	for {
+		item, shutdown := q.queue.Get()
+		if shutdown {
+			return nil
+		}
+		q.mu.Lock()
+		target := q.target
+		q.mu.Unlock()
+		target.Add(reconcile.Request{NamespacedName: item.(client.ObjectKey)})
+		q.queue.Done(item)
+	}
+}
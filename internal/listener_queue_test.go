@@ -0,0 +1,38 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestListenerQueue(t *testing.T) {
+	t.Parallel()
+	listenerQueue := internal.NewListenerQueue("test" + internal.ListenerQueueSuffix)
+	target := workqueue.New()
+	defer target.ShutDown()
+
+	key := client.ObjectKey{Name: "manifest", Namespace: "kcp-system"}
+	listenerQueue.Add(key, target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- listenerQueue.Start(ctx) }()
+
+	item, _ := target.Get()
+	assert.Equal(t, reconcile.Request{NamespacedName: key}, item)
+
+	cancel()
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener queue did not stop")
+	}
+}
@@ -0,0 +1,52 @@
+package internal_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventListener(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	freePort, err := net.Listen("tcp", "127.0.0.1:0")
+	asserts.NoError(err)
+	addr := freePort.Addr().String()
+	asserts.NoError(freePort.Close())
+
+	eventListener, eventSource := internal.NewEventListener(addr, "/custom/event", "module-manager")
+	asserts.NotNil(eventSource)
+	asserts.Equal("/custom/event", eventListener.Path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- eventListener.Start(ctx) }()
+
+	asserts.Eventually(func() bool {
+		request, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://"+addr+"/custom/event", nil)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return false
+		}
+		defer response.Body.Close()
+		return response.StatusCode == http.StatusMethodNotAllowed
+	}, 5*time.Second, 10*time.Millisecond, "only POST is served on the configured path")
+
+	cancel()
+	select {
+	case err := <-stopped:
+		asserts.NoError(err, "listener shuts down gracefully with the manager context")
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not shut down")
+	}
+
+	_, defaultSource := internal.NewEventListener(addr, "", "module-manager")
+	asserts.NotNil(defaultSource)
+	asserts.Equal("/v1/module-manager/event", internal.DefaultListenerPath("module-manager"))
+}
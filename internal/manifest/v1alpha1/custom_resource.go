@@ -6,32 +6,82 @@ import (
 
 	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
 	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-const CustomResourceManager = "resource.kyma-project.io/finalizer"
+const (
+	CustomResourceManager = "resource.kyma-project.io/finalizer"
+	installSummaryManager = "module-manager-install-summary"
+)
 
 var ErrWaitingForAsyncCustomResourceDeletion = errors.New(
 	"deletion of custom resource was triggered and is now waiting to be completed",
 )
 
 // PostRunCreateCR is a hook for creating the manifest default custom resource if not available in the cluster
-// It is used to provide the controller with default data in the Runtime.
+// It is used to provide the controller with default data in the Runtime and annotates it with the
+// InstallSummaryAnnotations.
 func PostRunCreateCR(
 	ctx context.Context, skr declarative.Client, kcp client.Client, obj declarative.Object,
+) error {
+	return createCR(ctx, skr, kcp, obj, declarative.MetadataPropagation{})
+}
+
+// PostRunCreateCRWithMetadata is PostRunCreateCR that also copies the labels and annotations of the Manifest
+// selected by propagation onto the default custom resource. Like the summary, they follow updates of the Manifest.
+func PostRunCreateCRWithMetadata(propagation declarative.MetadataPropagation) declarative.PostRun {
+	return func(ctx context.Context, skr declarative.Client, kcp client.Client, obj declarative.Object) error {
+		return createCR(ctx, skr, kcp, obj, propagation)
+	}
+}
+
+func createCR(
+	ctx context.Context, skr declarative.Client, kcp client.Client, obj declarative.Object,
+	propagation declarative.MetadataPropagation,
 ) error {
 	manifest := obj.(*manifestv1alpha1.Manifest)
 	if manifest.Spec.Resource == nil {
 		return nil
 	}
 	resource := manifest.Spec.Resource.DeepCopy()
+	propagatedLabels, summary := propagation.Propagated(manifest)
+	// the summary takes precedence over propagated annotations of the same keys
+	summary = k8slabels.Merge(summary, InstallSummaryAnnotations(manifest))
+	resource.SetAnnotations(k8slabels.Merge(resource.GetAnnotations(), summary))
+	if len(propagatedLabels) > 0 {
+		resource.SetLabels(k8slabels.Merge(resource.GetLabels(), propagatedLabels))
+	}
+
+	crdOwner, err := crdOwnerReference(ctx, skr, resource)
+	if err != nil {
+		return err
+	}
+	if crdOwner != nil {
+		resource.SetOwnerReferences(append(resource.GetOwnerReferences(), *crdOwner))
+	}
 
-	if err := skr.Create(
-		ctx, resource, client.FieldOwner(CustomResourceManager),
-	); err != nil && !k8serrors.IsAlreadyExists(err) {
+	err = skr.Create(ctx, resource, client.FieldOwner(CustomResourceManager))
+	if k8serrors.IsAlreadyExists(err) {
+		// the CR is only created once, but the summary and the owner follow upgrades of the Manifest.
+		resourceMeta := &v1.PartialObjectMetadata{}
+		resourceMeta.SetGroupVersionKind(resource.GroupVersionKind())
+		resourceMeta.SetName(resource.GetName())
+		resourceMeta.SetNamespace(resource.GetNamespace())
+		resourceMeta.SetAnnotations(summary)
+		resourceMeta.SetLabels(propagatedLabels)
+		if crdOwner != nil {
+			resourceMeta.SetOwnerReferences([]v1.OwnerReference{*crdOwner})
+		}
+		err = skr.Patch(ctx, resourceMeta, client.Apply, client.FieldOwner(installSummaryManager))
+	}
+	if err != nil {
 		return err
 	}
 
@@ -50,6 +100,52 @@ func PostRunCreateCR(
 	return nil
 }
 
+// crdOwnerReference returns a blocking owner reference to the CustomResourceDefinition of resource,
+// so that a foreground deletion of the definition outside of an uninstallation waits for the finalizers
+// of the default custom resource instead of removing it while its operator may already be gone.
+// It returns nil if the kind of resource is not defined by a CustomResourceDefinition.
+func crdOwnerReference(
+	ctx context.Context, skr declarative.Client, resource *unstructured.Unstructured,
+) (*v1.OwnerReference, error) {
+	gvk := resource.GroupVersionKind()
+	mapping, err := skr.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	crd := &v1.PartialObjectMetadata{}
+	crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+	if err := skr.Get(ctx, client.ObjectKey{Name: mapping.Resource.GroupResource().String()}, crd); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+	blockOwnerDeletion := true
+	return &v1.OwnerReference{
+		APIVersion:         apiextensionsv1.SchemeGroupVersion.String(),
+		Kind:               "CustomResourceDefinition",
+		Name:               crd.GetName(),
+		UID:                crd.GetUID(),
+		BlockOwnerDeletion: &blockOwnerDeletion,
+	}, nil
+}
+
+// InstallSummaryAnnotations traces the default custom resource in the Runtime back to the Manifest managing it.
+// They contain the namespaced name of the Manifest, the module version if the Manifest is labeled with it,
+// and the revision of the rendered manifest.
+func InstallSummaryAnnotations(manifest *manifestv1alpha1.Manifest) map[string]string {
+	summary := map[string]string{
+		labels.ManifestRef: client.ObjectKeyFromObject(manifest).String(),
+	}
+	if version := manifest.GetLabels()[labels.ModuleVersion]; version != "" {
+		summary[labels.ModuleVersion] = version
+	}
+	status := manifest.GetStatus()
+	if status.Journal != nil && status.Journal.Revision != "" {
+		summary[labels.Revision] = status.Journal.Revision
+	} else if len(status.Installs) > 0 && status.Installs[0].Revision != "" {
+		summary[labels.Revision] = status.Installs[0].Revision
+	}
+	return summary
+}
+
 // PreDeleteDeleteCR is a hook for deleting the manifest default custom resource if available in the cluster
 // It is used to clean up the controller default data.
 // It uses DeletePropagationBackground as it will return an error if the resource exists, even if deletion is triggered
@@ -85,12 +181,8 @@ func PreDeleteDeleteCR(
 	if err != nil {
 		return err
 	}
-	if removed := controllerutil.RemoveFinalizer(onCluster, CustomResourceManager); removed {
-		if err := kcp.Update(
-			ctx, onCluster, client.FieldOwner(CustomResourceManager),
-		); err != nil {
-			return err
-		}
-	}
-	return nil
+	_, err = declarative.RemoveFinalizer(
+		ctx, kcp, onCluster, CustomResourceManager, client.FieldOwner(CustomResourceManager),
+	)
+	return err
 }
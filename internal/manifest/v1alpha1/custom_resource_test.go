@@ -0,0 +1,36 @@
+package v1alpha1_test
+
+import (
+	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(
+	"test install summary of the default custom resource", func() {
+		It(
+			"should trace the custom resource back to the Manifest", func() {
+				manifest := &manifestv1alpha1.Manifest{}
+				manifest.SetName("keda")
+				manifest.SetNamespace("kcp-system")
+				Expect(v1alpha1.InstallSummaryAnnotations(manifest)).To(Equal(map[string]string{
+					labels.ManifestRef: "kcp-system/keda",
+				}))
+
+				manifest.SetLabels(map[string]string{labels.ModuleVersion: "2.8.1"})
+				manifest.SetStatus(declarative.Status{
+					Installs: []declarative.InstallStatus{{Name: "keda", Revision: "sha256:old"}},
+					Journal:  &declarative.OperationJournal{Revision: "sha256:new"},
+				})
+				Expect(v1alpha1.InstallSummaryAnnotations(manifest)).To(Equal(map[string]string{
+					labels.ManifestRef:   "kcp-system/keda",
+					labels.ModuleVersion: "2.8.1",
+					labels.Revision:      "sha256:new",
+				}))
+			},
+		)
+	},
+)
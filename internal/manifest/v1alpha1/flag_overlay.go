@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/pkg/custom"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	"helm.sh/helm/v3/pkg/strvals"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyFlagOverlays merges the flag overlays of the cluster profile of a remote Manifest on top of values.
+// The profile is the labels.ClusterProfile label of the kubeconfig secret of the target cluster, e.g. "trial".
+// Overlays are ConfigMaps in the namespace of the Manifest labeled with labels.FlagOverlay set to the profile.
+// Their data holds the overrides per install name in the format of the config layer, e.g. "replicaCount=1".
+// ConfigMaps are merged in the order of their names, so that later overlays take precedence.
+func ApplyFlagOverlays(
+	ctx context.Context, kcp client.Client, manifest *v1alpha1.Manifest, install string, values map[string]any,
+) (map[string]any, error) {
+	if kcp == nil || !manifest.Spec.Remote {
+		return values, nil
+	}
+	kymaName, found := manifest.GetLabels()[labels.KymaName]
+	if !found {
+		return values, nil
+	}
+	secret, err := (&custom.ClusterClient{DefaultClient: kcp}).GetKubeConfigSecret(
+		ctx, kymaName, manifest.GetNamespace(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve cluster profile from kubeconfig secret: %w", err)
+	}
+	profile := secret.GetLabels()[labels.ClusterProfile]
+	if profile == "" {
+		return values, nil
+	}
+
+	overlays := &v1.ConfigMapList{}
+	if err := kcp.List(ctx, overlays, client.InNamespace(manifest.GetNamespace()),
+		client.MatchingLabels{labels.FlagOverlay: profile}); err != nil {
+		return nil, fmt.Errorf("listing flag overlays of cluster profile %s: %w", profile, err)
+	}
+	sort.Slice(overlays.Items, func(i, j int) bool { return overlays.Items[i].Name < overlays.Items[j].Name })
+
+	if values == nil {
+		values = make(map[string]any)
+	}
+	for _, overlay := range overlays.Items {
+		overrides, found := overlay.Data[install]
+		if !found {
+			continue
+		}
+		if err := strvals.ParseInto(overrides, values); err != nil {
+			return nil, fmt.Errorf("parsing flag overlay %s for %s: %w", overlay.GetName(), install, err)
+		}
+	}
+	return values, nil
+}
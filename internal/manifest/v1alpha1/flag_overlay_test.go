@@ -0,0 +1,57 @@
+package v1alpha1_test
+
+import (
+	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe(
+	"test flag overlays of cluster profiles", func() {
+		It(
+			"should merge the overlays of the profile of the kubeconfig secret", func() {
+				const namespace = "kcp-system"
+				overlay := func(name, profile, overrides string) *corev1.ConfigMap {
+					return &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: name, Namespace: namespace, Labels: map[string]string{labels.FlagOverlay: profile},
+						},
+						Data: map[string]string{"keda": overrides},
+					}
+				}
+				kcp := fake.NewClientBuilder().WithObjects(
+					&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+						Name: "trial-kyma", Namespace: namespace,
+						Labels: map[string]string{labels.KymaName: "trial-kyma", labels.ClusterProfile: "trial"},
+					}},
+					overlay("a-trial", "trial", "replicaCount=1,resources.limits.cpu=100m"),
+					overlay("b-trial", "trial", "replicaCount=2"),
+					overlay("production", "production", "replicaCount=5"),
+				).Build()
+
+				manifest := &manifestv1alpha1.Manifest{Spec: manifestv1alpha1.ManifestSpec{Remote: true}}
+				manifest.SetNamespace(namespace)
+				manifest.SetLabels(map[string]string{labels.KymaName: "trial-kyma"})
+				values, err := v1alpha1.ApplyFlagOverlays(ctx, kcp, manifest, "keda", map[string]any{
+					"replicaCount": int64(3), "image": "keda:2.8.1",
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(values).To(Equal(map[string]any{
+					"replicaCount": int64(2),
+					"image":        "keda:2.8.1",
+					"resources":    map[string]any{"limits": map[string]any{"cpu": "100m"}},
+				}))
+
+				manifest.Spec.Remote = false
+				values, err = v1alpha1.ApplyFlagOverlays(ctx, kcp, manifest, "keda", nil)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(values).To(BeNil(), "Manifests installed in the control plane have no cluster profile")
+			},
+		)
+	},
+)
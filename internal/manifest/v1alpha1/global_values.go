@@ -0,0 +1,46 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// LoadGlobalValues reads the values file at path, e.g. mounted from a ConfigMap, whose values are set for
+// every install, such as global.imagePullSecrets or global.domainName.
+func LoadGlobalValues(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading global values file %s: %w", path, err)
+	}
+	values := map[string]any{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("decoding global values file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// ApplyGlobalValues merges values on top of a copy of the global values, so that the values of an install
+// take precedence. Maps are merged recursively, all other values of an install replace the global value.
+func ApplyGlobalValues(values, global map[string]any) map[string]any {
+	if len(global) == 0 {
+		return values
+	}
+	merged := runtime.DeepCopyJSON(global)
+	mergeValues(merged, values)
+	return merged
+}
+
+func mergeValues(dst, src map[string]any) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]any); ok {
+			if dstMap, ok := dst[key].(map[string]any); ok {
+				mergeValues(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
@@ -0,0 +1,45 @@
+package v1alpha1_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe(
+	"test global values", func() {
+		It(
+			"should merge the values of an install on top of the global values", func() {
+				path := filepath.Join(GinkgoT().TempDir(), "global-values.yaml")
+				Expect(os.WriteFile(path, []byte(`
+global:
+  domainName: kyma.example.com
+  imagePullSecrets:
+  - name: registry
+replicaCount: 1
+`), 0o600)).To(Succeed())
+				global, err := v1alpha1.LoadGlobalValues(path)
+				Expect(err).ToNot(HaveOccurred())
+
+				values := v1alpha1.ApplyGlobalValues(map[string]any{
+					"global":       map[string]any{"domainName": "custom.example.com"},
+					"replicaCount": int64(3),
+				}, global)
+				Expect(values).To(Equal(map[string]any{
+					"global": map[string]any{
+						"domainName":       "custom.example.com",
+						"imagePullSecrets": []any{map[string]any{"name": "registry"}},
+					},
+					"replicaCount": int64(3),
+				}))
+				Expect(global["global"]).To(HaveKeyWithValue("domainName", "kyma.example.com"),
+					"global values must not be modified by installs")
+
+				Expect(v1alpha1.ApplyGlobalValues(nil, nil)).To(BeNil())
+			},
+		)
+	},
+)
@@ -0,0 +1,75 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"helm.sh/helm/v3/pkg/registry"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newHelmRegistryClient creates a helm registry client for the OCI chart reference chart, e.g.
+// "oci://ghcr.io/org/charts/keda". Credentials of the pull secrets selected by credSecretSelector are logged in
+// into a credentials file of the client only, which is removed by the returned cleanup,
+// so that concurrent downloads of charts with different credentials do not share logins.
+func (m *ManifestSpecResolver) newHelmRegistryClient(
+	ctx context.Context, chart string, credSecretSelector *metav1.LabelSelector,
+) (*registry.Client, func(), error) {
+	credentialsDir, err := os.MkdirTemp("", "helm-registry-")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(credentialsDir) }
+
+	registryClient, err := registry.NewClient(
+		registry.ClientOptCredentialsFile(filepath.Join(credentialsDir, "config.json")),
+	)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	if credSecretSelector == nil {
+		return registryClient, cleanup, nil
+	}
+
+	if err := m.loginHelmRegistry(ctx, registryClient, chart, credSecretSelector); err != nil {
+		cleanup()
+		return nil, nil, &types.DownloadError{Ref: chart, Err: err}
+	}
+	return registryClient, cleanup, nil
+}
+
+// loginHelmRegistry logs in to the registry of chart with the credentials the pull secrets hold for it.
+func (m *ManifestSpecResolver) loginHelmRegistry(
+	ctx context.Context, registryClient *registry.Client, chart string, credSecretSelector *metav1.LabelSelector,
+) error {
+	keyChain, err := GetAuthnKeychain(ctx, types.ImageSpec{CredSecretSelector: credSecretSelector}, m.KCP)
+	if err != nil {
+		return err
+	}
+	host, _, _ := strings.Cut(strings.TrimPrefix(chart, fmt.Sprintf("%s://", registry.OCIScheme)), "/")
+	registryName, err := name.NewRegistry(host)
+	if err != nil {
+		return err
+	}
+	authenticator, err := keyChain.Resolve(registryName)
+	if err != nil {
+		return err
+	}
+	authConfig, err := authenticator.Authorization()
+	if err != nil {
+		return err
+	}
+	if authConfig.Username == "" && authConfig.Password == "" {
+		return nil
+	}
+	return registryClient.Login(host,
+		registry.LoginOptBasicAuth(authConfig.Username, authConfig.Password),
+		registry.LoginOptInsecure(m.Insecure),
+	)
+}
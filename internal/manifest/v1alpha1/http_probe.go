@@ -0,0 +1,143 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// ConditionTypeProbePrefix prefixes the types of the conditions reporting the result of every HTTPProbe,
+	// followed by its name, e.g. "Probe-gateway-health".
+	ConditionTypeProbePrefix = "Probe-"
+
+	ConditionReasonProbeSucceeded = "Succeeded"
+	ConditionReasonProbeFailed    = "Failed"
+
+	defaultProbeTimeout = 5 * time.Second
+	defaultProbePeriod  = time.Second
+)
+
+// probeClient requests probes through the service proxy of the API server of a target cluster.
+type probeClient struct {
+	httpClient *http.Client
+	server     string
+}
+
+// newProbeClient returns a probeClient for the target cluster of clnt that does not follow redirects,
+// so that probes cannot reach other endpoints than the Services of the target cluster.
+func newProbeClient(clnt declarative.Client) (*probeClient, error) {
+	config, err := clnt.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("configuring probe client: %w", err)
+	}
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("configuring probe client: %w", err)
+	}
+	server, _, err := rest.DefaultServerUrlFor(config)
+	if err != nil {
+		return nil, fmt.Errorf("configuring probe client: %w", err)
+	}
+	noRedirects := *httpClient
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &probeClient{httpClient: &noRedirects, server: strings.TrimSuffix(server.String(), "/")}, nil
+}
+
+func (c *probeClient) url(target manifestv1alpha1.ProbeTarget) string {
+	return fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s/proxy%s", c.server, target.Namespace,
+		utilnet.JoinSchemeNamePort(target.Scheme, target.Service, target.Port), target.Path)
+}
+
+// checkProbe requests the Service of probe up to its attempts and returns the condition reporting the result.
+// The limits of the probe are enforced even if the Manifest was not validated.
+func checkProbe(ctx context.Context, prober *probeClient, probe manifestv1alpha1.HTTPProbe) metav1.Condition {
+	condition := metav1.Condition{Type: ConditionTypeProbePrefix + probe.Name, Status: metav1.ConditionFalse}
+	target, err := probe.ServiceTarget()
+	if err != nil {
+		condition.Reason, condition.Message = ConditionReasonProbeFailed, err.Error()
+		return condition
+	}
+	expected := probe.ExpectedStatus
+	if expected == 0 {
+		expected = http.StatusOK
+	}
+	timeout := time.Duration(boundedProbeValue(probe.TimeoutSeconds, manifestv1alpha1.MaxProbeTimeoutSeconds)) *
+		time.Second
+	if timeout == 0 {
+		timeout = defaultProbeTimeout
+	}
+	period := time.Duration(boundedProbeValue(probe.PeriodSeconds, manifestv1alpha1.MaxProbePeriodSeconds)) *
+		time.Second
+	if period == 0 {
+		period = defaultProbePeriod
+	}
+	attempts := boundedProbeValue(probe.Attempts, manifestv1alpha1.MaxProbeAttempts)
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var failure string
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				condition.Reason, condition.Message = ConditionReasonProbeFailed, ctx.Err().Error()
+				return condition
+			case <-time.After(period):
+			}
+		}
+		status, err := prober.request(ctx, target, timeout)
+		switch {
+		case err != nil:
+			failure = err.Error()
+		case status != expected:
+			failure = fmt.Sprintf("responded with status %d but expected %d", status, expected)
+		default:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = ConditionReasonProbeSucceeded
+			condition.Message = fmt.Sprintf("responded with status %d", status)
+			return condition
+		}
+	}
+	condition.Reason = ConditionReasonProbeFailed
+	condition.Message = fmt.Sprintf("%s after %d attempts", failure, attempts)
+	return condition
+}
+
+func boundedProbeValue(value, limit int) int {
+	if value < 0 {
+		return 0
+	}
+	if value > limit {
+		return limit
+	}
+	return value
+}
+
+func (c *probeClient) request(
+	ctx context.Context, target manifestv1alpha1.ProbeTarget, timeout time.Duration,
+) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(target), nil)
+	if err != nil {
+		return 0, err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	return response.StatusCode, nil
+}
@@ -0,0 +1,109 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// DefaultInstallSourceTTL is the time decoded sources are kept after the last reconciliation of their Manifest.
+const DefaultInstallSourceTTL = time.Hour
+
+// installSource is the typed source of an install. Only the field matching Type is set.
+type installSource struct {
+	Type        types.RefTypeMetadata
+	HelmChart   types.HelmChartSpec
+	Image       types.ImageSpec
+	Kustomize   types.KustomizeSpec
+	Git         types.GitSpec
+	RawManifest types.RawManifestSpec
+}
+
+type installSourceKey struct {
+	uid        k8stypes.UID
+	generation int64
+	install    string
+}
+
+// installSourceCache holds the decoded sources of installs per generation of a Manifest. Decoding validates the
+// source against the JSON schema of its type, which is repeated on every reconciliation otherwise, even though
+// the source can only change with a new generation. Entries of older generations expire after the TTL.
+type installSourceCache struct {
+	*ttlcache.Cache[installSourceKey, *installSource]
+	ttl   time.Duration
+	stats *internal.CacheStats
+}
+
+func newInstallSourceCache(ttl time.Duration) *installSourceCache {
+	cache := ttlcache.New[installSourceKey, *installSource]()
+	go cache.Start()
+	sources := &installSourceCache{Cache: cache, ttl: ttl}
+	sources.stats = internal.RegisterCache("install-sources", sources.snapshot)
+	return sources
+}
+
+// snapshot derives the age of entries from their expiration, as their TTL is extended with every access.
+func (c *installSourceCache) snapshot() internal.CacheSnapshot {
+	snapshot := internal.CacheSnapshot{}
+	for _, item := range c.Items() {
+		snapshot.Entries++
+		if refreshed := item.ExpiresAt().Add(-c.ttl); snapshot.Oldest.IsZero() || refreshed.Before(snapshot.Oldest) {
+			snapshot.Oldest = refreshed
+		}
+	}
+	return snapshot
+}
+
+// decode returns the typed source of install, which must not be modified as it is shared between reconciliations.
+func (c *installSourceCache) decode(
+	codec *types.Codec, manifest *v1alpha1.Manifest, install v1alpha1.InstallInfo,
+) (*installSource, error) {
+	key := installSourceKey{uid: manifest.GetUID(), generation: manifest.GetGeneration(), install: install.Name}
+	if key.uid != "" {
+		if item := c.Get(key); item != nil {
+			c.stats.Hit()
+			return item.Value(), nil
+		}
+		c.stats.Miss()
+	}
+
+	source, err := decodeInstallSource(codec, install.Source.Raw)
+	if err != nil {
+		return nil, types.WithFieldPath(err, fmt.Sprintf("spec.installs[%s].source", install.Name))
+	}
+	if key.uid != "" {
+		c.Set(key, source, c.ttl)
+	}
+	return source, nil
+}
+
+func decodeInstallSource(codec *types.Codec, raw []byte) (*installSource, error) {
+	specType, err := types.GetSpecType(raw)
+	if err != nil {
+		return nil, err
+	}
+	source := &installSource{Type: specType}
+	switch specType {
+	case types.HelmChartType:
+		err = codec.Decode(raw, &source.HelmChart, specType)
+	case types.OciRefType:
+		err = codec.Decode(raw, &source.Image, specType)
+	case types.KustomizeType:
+		err = codec.Decode(raw, &source.Kustomize, specType)
+	case types.GitType:
+		err = codec.Decode(raw, &source.Git, specType)
+	case types.RawManifestType:
+		err = codec.Decode(raw, &source.RawManifest, specType)
+	default:
+		err = codec.Validate(raw, specType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
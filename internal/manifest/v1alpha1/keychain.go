@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	authnK8s "github.com/google/go-containerregistry/pkg/authn/kubernetes"
+	"github.com/kyma-project/module-manager/internal"
 	"github.com/kyma-project/module-manager/pkg/types"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,9 +21,36 @@ func GetAuthnKeychain(ctx context.Context, spec types.ImageSpec, clnt client.Cli
 	return authnK8s.NewFromPullSecrets(ctx, secretList.Items)
 }
 
+const (
+	gitUsernameKey   = "username"
+	gitPasswordKey   = "password"
+	gitIdentityKey   = "identity"
+	gitKnownHostsKey = "known_hosts"
+)
+
+// GetGitCredentials reads the credentials of private git remotes from the first secret matching
+// credSecretSelector in namespace, which is the namespace of the Manifest, so that Manifests cannot read
+// the secrets of other namespaces.
+func GetGitCredentials(
+	ctx context.Context, credSecretSelector *metav1.LabelSelector, namespace string, clnt client.Client,
+) (*internal.GitCredentials, error) {
+	secretList, err := getCredSecrets(ctx, credSecretSelector, clnt, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	data := secretList.Items[0].Data
+	return &internal.GitCredentials{
+		Username:   string(data[gitUsernameKey]),
+		Password:   string(data[gitPasswordKey]),
+		SSHKey:     data[gitIdentityKey],
+		KnownHosts: data[gitKnownHostsKey],
+	}, nil
+}
+
 func getCredSecrets(ctx context.Context,
 	credSecretSelector *metav1.LabelSelector,
 	clusterClient client.Client,
+	opts ...client.ListOption,
 ) (corev1.SecretList, error) {
 	secretList := corev1.SecretList{}
 	selector, err := metav1.LabelSelectorAsSelector(credSecretSelector)
@@ -30,9 +58,9 @@ func getCredSecrets(ctx context.Context,
 		return secretList, fmt.Errorf("error converting labelSelector: %w", err)
 	}
 	err = clusterClient.List(
-		ctx, &secretList, &client.ListOptions{
+		ctx, &secretList, append([]client.ListOption{&client.ListOptions{
 			LabelSelector: selector,
-		},
+		}}, opts...)...,
 	)
 	if err != nil {
 		return secretList, err
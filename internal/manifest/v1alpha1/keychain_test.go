@@ -36,6 +36,28 @@ var _ = Describe(
 				Expect(authConfig.Password).To(Equal("test_pass"))
 			},
 		)
+		It(
+			"should only read git credentials from the namespace of the manifest", func() {
+				selector := &metav1.LabelSelector{
+					MatchLabels: map[string]string{"operator.kyma-project.io/git-cred": "test-operator"},
+				}
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "git-cred", Namespace: metav1.NamespaceDefault, Labels: selector.MatchLabels,
+					},
+					Data: map[string][]byte{"username": []byte("git_user"), "password": []byte("git_token")},
+				}
+				Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+				credentials, err := v1alpha1.GetGitCredentials(ctx, selector, metav1.NamespaceDefault, k8sClient)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(credentials.Username).To(Equal("git_user"))
+				Expect(credentials.Password).To(Equal("git_token"))
+
+				_, err = v1alpha1.GetGitCredentials(ctx, selector, metav1.NamespaceSystem, k8sClient)
+				Expect(err).To(MatchError(v1alpha1.ErrNoAuthSecretFound))
+			},
+		)
 	},
 )
 
@@ -0,0 +1,68 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeListenerStale is true while no heartbeats were received from the watcher of the remote cluster
+	// of a Manifest within the staleness timeout, so that changes in the remote cluster are only noticed
+	// by the consistency checks.
+	ConditionTypeListenerStale = "ListenerStale"
+
+	ConditionReasonListenerHeartbeatsReceived = "HeartbeatsReceived"
+	ConditionReasonListenerHeartbeatsStale    = "HeartbeatsStale"
+)
+
+// PostRunListenerStaleness reports in the ListenerStale condition of remote Manifests if the watcher of their
+// cluster sends heartbeats. Manifests installed in the control plane have no watcher and are not reported.
+func PostRunListenerStaleness(health *internal.ListenerHealth) declarative.PostRun {
+	return func(_ context.Context, _ declarative.Client, _ client.Client, obj declarative.Object) error {
+		manifest := obj.(*manifestv1alpha1.Manifest)
+		status := manifest.GetStatus()
+		cluster := manifest.GetLabels()[labels.KymaName]
+		if !manifest.Spec.Remote || cluster == "" || health.StalenessTimeout <= 0 {
+			if meta.RemoveStatusCondition(&status.Conditions, ConditionTypeListenerStale) {
+				manifest.SetStatus(status)
+			}
+			return nil
+		}
+
+		stale, lastHeartbeat := health.Stale(cluster, time.Now())
+		condition := metav1.Condition{
+			Type:               ConditionTypeListenerStale,
+			Status:             metav1.ConditionFalse,
+			Reason:             ConditionReasonListenerHeartbeatsReceived,
+			Message:            fmt.Sprintf("watcher heartbeats are received from cluster %s", cluster),
+			ObservedGeneration: manifest.GetGeneration(),
+		}
+		switch {
+		case stale && lastHeartbeat.IsZero():
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = ConditionReasonListenerHeartbeatsStale
+			condition.Message = fmt.Sprintf("no watcher heartbeat received from cluster %s within %s",
+				cluster, health.StalenessTimeout)
+		case stale:
+			condition.Status = metav1.ConditionTrue
+			condition.Reason = ConditionReasonListenerHeartbeatsStale
+			condition.Message = fmt.Sprintf("no watcher heartbeat received from cluster %s since %s",
+				cluster, lastHeartbeat.Format(time.RFC3339))
+		case lastHeartbeat.IsZero():
+			// the watcher may not have sent a heartbeat since the controller started yet
+			return nil
+		}
+		meta.SetStatusCondition(&status.Conditions, condition)
+		manifest.SetStatus(status)
+		return nil
+	}
+}
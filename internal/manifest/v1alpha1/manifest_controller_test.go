@@ -30,12 +30,6 @@ const ManifestDir = "manifest"
 
 var ErrManifestStateMisMatch = errors.New("ManifestState mismatch")
 
-func setHelmEnv() {
-	os.Setenv(helmCacheHomeEnv, helmCacheHome)
-	os.Setenv(helmCacheRepoEnv, helmCacheRepo)
-	os.Setenv(helmRepoEnv, helmRepoFile)
-}
-
 var _ = Describe(
 	"Given manifest with kustomize specs", func() {
 		remoteKustomizeSpec := types.KustomizeSpec{
@@ -178,7 +172,6 @@ var _ = Describe(
 
 var _ = Describe(
 	"Given Manifest CR with Helm specs", func() {
-		setHelmEnv()
 		validHelmChartSpec := types.HelmChartSpec{
 			ChartName: "nginx-ingress",
 			URL:       "https://helm.nginx.com/stable",
@@ -0,0 +1,56 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const migrationPageSize = 100
+
+// MigrateLegacyManifests rewrites all stored Manifests that still use the former spec layout
+// (PreInstallCRDs, StateCR) to the current one. With dryRun, the Manifests are only reported.
+// It returns the keys of the converted Manifests.
+func MigrateLegacyManifests(ctx context.Context, clnt client.Client, dryRun bool) ([]client.ObjectKey, error) {
+	var migrated []client.ObjectKey
+	list := &v1alpha1.ManifestList{}
+	for {
+		if err := clnt.List(ctx, list, client.Limit(migrationPageSize), client.Continue(list.GetContinue())); err != nil {
+			return migrated, fmt.Errorf("listing manifests: %w", err)
+		}
+		for i := range list.Items {
+			manifest := &list.Items[i]
+			if !manifest.HasLegacySpec() {
+				continue
+			}
+			if !dryRun {
+				if err := migrateLegacyManifest(ctx, clnt, manifest); err != nil {
+					return migrated, err
+				}
+			}
+			migrated = append(migrated, client.ObjectKeyFromObject(manifest))
+		}
+		if list.GetContinue() == "" {
+			return migrated, nil
+		}
+	}
+}
+
+func migrateLegacyManifest(ctx context.Context, clnt client.Client, manifest *v1alpha1.Manifest) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(manifest), manifest); err != nil {
+			return err
+		}
+		if !manifest.ConvertLegacySpec() {
+			return nil
+		}
+		return clnt.Update(ctx, manifest)
+	})
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("migrating manifest %s: %w", client.ObjectKeyFromObject(manifest), err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package v1alpha1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/api/v1beta1"
+	"github.com/kyma-project/module-manager/internal"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	yamlUtil "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PrePullResult reports the pre-pull of the artifacts of a single Manifest.
+type PrePullResult struct {
+	Manifest client.ObjectKey
+	// Revision is the revision of the resolved install, it is empty if Err is set.
+	Revision string
+	Err      error
+}
+
+// PrePull resolves the spec of every Manifest, so that its chart, config and kustomization layers are pulled and
+// extracted into the cache before the Manifests are reconciled, e.g. ahead of a planned rollout window.
+// The Manifests are not modified and do not have to exist in the cluster yet. Failures of single Manifests are
+// reported in their PrePullResult and do not stop the pre-pull of the other Manifests.
+// Manifests that are listed more than once, e.g. under both served versions during the migration to v1beta1,
+// are pre-pulled once with their last entry.
+func (m *ManifestSpecResolver) PrePull(ctx context.Context, manifests []v1alpha1.Manifest) []PrePullResult {
+	last := make(map[client.ObjectKey]int, len(manifests))
+	for i := range manifests {
+		last[client.ObjectKeyFromObject(&manifests[i])] = i
+	}
+	results := make([]PrePullResult, 0, len(last))
+	for i := range manifests {
+		if last[client.ObjectKeyFromObject(&manifests[i])] != i {
+			continue
+		}
+		manifest := manifests[i].DeepCopy()
+		result := PrePullResult{Manifest: client.ObjectKeyFromObject(manifest)}
+		if spec, err := m.Spec(ctx, manifest); err != nil {
+			result.Err = err
+		} else {
+			result.Revision = spec.Revision
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// ReadPrePullManifests decodes the Manifests in the YAML or JSON documents of path, empty documents are skipped.
+// v1beta1 Manifests are converted to v1alpha1.
+func ReadPrePullManifests(path string) ([]v1alpha1.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeManifests(data)
+}
+
+func decodeManifests(data []byte) ([]v1alpha1.Manifest, error) {
+	var manifests []v1alpha1.Manifest
+	decoder := yamlUtil.NewYAMLOrJSONDecoder(bytes.NewReader(data), len(data))
+	for {
+		var document json.RawMessage
+		if err := decoder.Decode(&document); errors.Is(err, io.EOF) {
+			return manifests, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("decoding manifests to pre-pull: %w", err)
+		}
+		manifest, err := decodeHubManifest(document)
+		if err != nil {
+			return nil, fmt.Errorf("decoding manifests to pre-pull: %w", err)
+		}
+		if manifest.GetName() == "" && len(manifest.Spec.Installs) == 0 {
+			continue
+		}
+		manifests = append(manifests, *manifest)
+	}
+}
+
+// decodeHubManifest decodes document as v1alpha1 Manifest, v1beta1 Manifests are converted to v1alpha1.
+func decodeHubManifest(document json.RawMessage) (*v1alpha1.Manifest, error) {
+	manifest := &v1alpha1.Manifest{}
+	if len(document) == 0 || string(document) == "null" {
+		return manifest, nil
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(document, &typeMeta); err != nil {
+		return nil, err
+	}
+	if typeMeta.APIVersion != v1beta1.GroupVersion.String() {
+		return manifest, json.Unmarshal(document, manifest)
+	}
+	spoke := &v1beta1.Manifest{}
+	if err := json.Unmarshal(document, spoke); err != nil {
+		return nil, err
+	}
+	if err := spoke.ConvertTo(manifest); err != nil {
+		return nil, err
+	}
+	manifest.SetGroupVersionKind(v1alpha1.GroupVersionKind)
+	return manifest, nil
+}
+
+// PrePuller pre-pulls the Manifests read from Path with the Resolver on start and whenever the file changes,
+// e.g. a ConfigMap volume containing the Manifests of the next rollout. As the cache is local to every
+// instance, it implements manager.Runnable and runs independently of leader election.
+// Pre-pulling is disabled if Interval is not positive.
+type PrePuller struct {
+	Path     string
+	Interval time.Duration
+	Resolver *ManifestSpecResolver
+	Log      logr.Logger
+
+	lastRead []byte
+}
+
+func (p *PrePuller) Start(ctx context.Context) error {
+	if p.Interval <= 0 {
+		p.Log.Info("pre-pull is disabled as its interval is not positive", "interval", p.Interval)
+		return nil
+	}
+	ticker := time.NewTicker(p.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.prePull(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *PrePuller) NeedLeaderElection() bool {
+	return false
+}
+
+func (p *PrePuller) prePull(ctx context.Context) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		p.Log.Error(err, "reading manifests to pre-pull failed", "path", p.Path)
+		return
+	}
+	if bytes.Equal(data, p.lastRead) {
+		return
+	}
+	manifests, err := decodeManifests(data)
+	if err != nil {
+		p.Log.Error(err, "manifests to pre-pull are invalid and are ignored", "path", p.Path)
+		return
+	}
+
+	failed := 0
+	for _, result := range p.Resolver.PrePull(ctx, manifests) {
+		if result.Err != nil {
+			failed++
+			p.Log.Error(result.Err, "pre-pull failed", "manifest", result.Manifest)
+			continue
+		}
+		p.Log.V(internal.DebugLogLevel).Info("pre-pulled", "manifest", result.Manifest, "revision", result.Revision)
+	}
+	// failed pre-pulls are retried with the next interval
+	if failed == 0 {
+		p.lastRead = data
+	}
+	p.Log.Info("manifests pre-pulled", "path", p.Path, "manifests", len(manifests), "failed", failed)
+}
@@ -0,0 +1,109 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	"github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	"github.com/kyma-project/module-manager/pkg/types"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe(
+	"test pre-pull", func() {
+		It(
+			"should resolve every manifest of the file and report failures per manifest", func() {
+				kustomization := filepath.Join(GinkgoT().TempDir(), "kustomization")
+				path := filepath.Join(GinkgoT().TempDir(), "manifests.yaml")
+				Expect(os.WriteFile(path, []byte(fmt.Sprintf(`
+apiVersion: operator.kyma-project.io/v1alpha1
+kind: Manifest
+metadata:
+  name: keda
+  namespace: kcp-system
+spec:
+  installs:
+  - name: keda
+    source:
+      type: kustomize
+      path: %s
+      url: ""
+---
+---
+apiVersion: operator.kyma-project.io/v1alpha1
+kind: Manifest
+metadata:
+  name: broken
+  namespace: kcp-system
+spec:
+  installs:
+  - name: broken
+    source:
+      type: ""
+`, kustomization)), 0o600)).To(Succeed())
+
+				manifests, err := v1alpha1.ReadPrePullManifests(path)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(manifests).To(HaveLen(2))
+
+				codec, err := types.NewCodec()
+				Expect(err).ToNot(HaveOccurred())
+				results := v1alpha1.NewManifestSpecResolver(codec, true).PrePull(context.Background(), manifests)
+				Expect(results).To(HaveLen(2))
+				Expect(results[0].Err).ToNot(HaveOccurred())
+				Expect(results[0].Manifest).To(Equal(client.ObjectKey{Namespace: "kcp-system", Name: "keda"}))
+				Expect(results[0].Revision).To(Equal("kustomization"))
+				Expect(results[1].Err).To(HaveOccurred())
+				Expect(manifests[1].Status.State).To(BeEmpty(), "the given manifests must not be modified")
+			},
+		)
+		It(
+			"should convert v1beta1 manifests and pre-pull manifests listed under both versions once", func() {
+				path := filepath.Join(GinkgoT().TempDir(), "manifests.yaml")
+				Expect(os.WriteFile(path, []byte(`
+apiVersion: operator.kyma-project.io/v1alpha1
+kind: Manifest
+metadata:
+  name: keda
+  namespace: kcp-system
+spec:
+  installs: []
+---
+apiVersion: operator.kyma-project.io/v1beta1
+kind: Manifest
+metadata:
+  name: keda
+  namespace: kcp-system
+spec:
+  remote: true
+  installs: []
+`), 0o600)).To(Succeed())
+
+				manifests, err := v1alpha1.ReadPrePullManifests(path)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(manifests).To(HaveLen(2))
+				Expect(manifests[1].Spec.Remote).To(BeTrue())
+				Expect(manifests[1].APIVersion).To(Equal("operator.kyma-project.io/v1alpha1"))
+
+				codec, err := types.NewCodec()
+				Expect(err).ToNot(HaveOccurred())
+				results := v1alpha1.NewManifestSpecResolver(codec, true).PrePull(context.Background(), manifests)
+				Expect(results).To(HaveLen(1))
+				Expect(results[0].Manifest).To(Equal(client.ObjectKey{Namespace: "kcp-system", Name: "keda"}))
+			},
+		)
+		It(
+			"should not start pre-pulling without a positive interval", func() {
+				prePuller := &v1alpha1.PrePuller{
+					Path: filepath.Join(GinkgoT().TempDir(), "missing.yaml"), Log: logr.Discard(),
+				}
+				Expect(prePuller.Start(context.Background())).To(Succeed())
+			},
+		)
+	},
+)
@@ -0,0 +1,127 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConditionTypeReadinessRulePrefix prefixes the types of the conditions reporting the result of every
+	// ReadinessRule, followed by its kind and group, e.g. "ReadinessRule-Gateway.apps.example.com".
+	ConditionTypeReadinessRulePrefix = "ReadinessRule-"
+
+	maxReportedNotReady = 5
+)
+
+// LoadReadinessRules reads the file at path, e.g. mounted from a ConfigMap, with a list of the readiness rules
+// that apply to the resources of every Manifest, unless the Manifest defines a rule for the same kind.
+func LoadReadinessRules(path string) ([]manifestv1alpha1.ReadinessRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading readiness rules file %s: %w", path, err)
+	}
+	var rules []manifestv1alpha1.ReadinessRule
+	if err := yaml.UnmarshalStrict(data, &rules); err != nil {
+		return nil, fmt.Errorf("decoding readiness rules file %s: %w", path, err)
+	}
+	for _, rule := range rules {
+		if _, err := declarative.CompileReadinessExpression(rule.Expression); err != nil {
+			return nil, fmt.Errorf("readiness rule for %s %s: %w", rule.APIVersion, rule.Kind, err)
+		}
+	}
+	return rules, nil
+}
+
+// effectiveReadinessRules returns the readiness rules of the Manifest followed by the default rules
+// for all other kinds.
+func effectiveReadinessRules(
+	manifest *manifestv1alpha1.Manifest, defaults []manifestv1alpha1.ReadinessRule,
+) []manifestv1alpha1.ReadinessRule {
+	rules := make([]manifestv1alpha1.ReadinessRule, 0, len(manifest.Spec.ReadinessRules)+len(defaults))
+	defined := make(map[string]bool, len(manifest.Spec.ReadinessRules))
+	for _, rule := range manifest.Spec.ReadinessRules {
+		defined[rule.APIVersion+"/"+rule.Kind] = true
+		rules = append(rules, rule)
+	}
+	for _, rule := range defaults {
+		if !defined[rule.APIVersion+"/"+rule.Kind] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// checkReadinessRule evaluates rule for all resources of its kind and returns the condition reporting the result,
+// errors are only returned if a resource could not be read.
+func checkReadinessRule(
+	ctx context.Context, clnt declarative.Client, rule manifestv1alpha1.ReadinessRule, resources []*resource.Info,
+) (metav1.Condition, error) {
+	gvk := schema.FromAPIVersionAndKind(rule.APIVersion, rule.Kind)
+	conditionType := ConditionTypeReadinessRulePrefix + gvk.Kind
+	if gvk.Group != "" {
+		conditionType += "." + gvk.Group
+	}
+	condition := metav1.Condition{Type: conditionType, Status: metav1.ConditionFalse}
+
+	expression, err := declarative.CompileReadinessExpression(rule.Expression)
+	if err != nil {
+		condition.Reason = ConditionReasonCustomStateInvalid
+		condition.Message = err.Error()
+		return condition, nil
+	}
+
+	var checked int
+	var notReady []string
+	for _, info := range resources {
+		if info.Object == nil || info.Object.GetObjectKind().GroupVersionKind() != gvk {
+			continue
+		}
+		checked++
+		res := &unstructured.Unstructured{}
+		res.SetGroupVersionKind(gvk)
+		if err := clnt.Get(ctx, client.ObjectKey{Name: info.Name, Namespace: info.Namespace}, res); err != nil {
+			if !k8serrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+				return condition, err
+			}
+			notReady = append(notReady, fmt.Sprintf("%s does not exist", info.Name))
+			continue
+		}
+		ready, err := expression.Ready(res.Object)
+		switch {
+		case err != nil:
+			notReady = append(notReady, fmt.Sprintf("%s: %s", info.Name, err.Error()))
+		case !ready:
+			notReady = append(notReady, info.Name)
+		}
+	}
+
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		if len(notReady) > maxReportedNotReady {
+			notReady = append(notReady[:maxReportedNotReady], fmt.Sprintf("and %d more",
+				len(notReady)-maxReportedNotReady))
+		}
+		condition.Reason = ConditionReasonCustomStateNotReady
+		condition.Message = fmt.Sprintf("%s not ready according to %s: %s",
+			gvk.Kind, rule.Expression, strings.Join(notReady, ", "))
+		return condition, nil
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ConditionReasonCustomStateReady
+	condition.Message = fmt.Sprintf("%d %s ready according to %s", checked, gvk.Kind, rule.Expression)
+	return condition, nil
+}
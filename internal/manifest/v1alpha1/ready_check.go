@@ -2,57 +2,193 @@ package v1alpha1
 
 import (
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 	"strings"
 
 	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
 	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/resource"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const customResourceStatePath = "status.state"
+const (
+	customResourceStatePath = "status.state"
 
-var ErrCustomResourceStateNotFound = errors.New("custom resource state not found")
+	// ConditionTypeCustomStatePrefix prefixes the types of the conditions reporting the state of every tracked
+	// resource, see CustomStateConditionType, e.g. "CustomState-Sample.operator.kyma-project.io_kyma-system_sample".
+	// Entries that do not identify a resource are reported by their index, e.g. "CustomState-Invalid-2".
+	ConditionTypeCustomStatePrefix = "CustomState-"
+	// maxConditionTypeLength is the maximum length of condition types accepted by the API server.
+	maxConditionTypeLength = 316
 
-// NewManifestCustomResourceReadyCheck creates a readiness check that verifies that the Resource in the Manifest
-// returns the ready state, if not it returns not ready.
+	ConditionReasonCustomStateReady         = "Ready"
+	ConditionReasonCustomStateNotReady      = "NotReady"
+	ConditionReasonCustomResourceNotFound   = "ResourceNotFound"
+	ConditionReasonCustomStateNotFound      = "StateNotFound"
+	ConditionReasonCustomStateInvalid       = "Invalid"
+	ConditionReasonCustomStateNotObservable = "NotObservable"
+)
+
+// NewManifestCustomResourceReadyCheck creates a readiness check that verifies that the Resource and the
+// CustomStates in the Manifest return their ready state, that the installed resources fulfill its ReadinessRules
+// and that its Probes respond, if not it returns not ready.
 func NewManifestCustomResourceReadyCheck() *ManifestCustomResourceReadyCheck {
 	return &ManifestCustomResourceReadyCheck{}
 }
 
-type ManifestCustomResourceReadyCheck struct{}
+// ManifestCustomResourceReadyCheck reports the state of every tracked resource and the result of every probe
+// in a condition of the Manifest. Resources that do not exist yet or do not report a state are not ready,
+// entries that do not identify a resource are reported but do not block the readiness.
+// Probes are requested through the service proxy of the API server of the target cluster.
+type ManifestCustomResourceReadyCheck struct {
+	// ReadinessRules apply to the installed resources of all Manifests that define no rule for the same kind.
+	ReadinessRules []manifestv1alpha1.ReadinessRule
+}
 
 func (c *ManifestCustomResourceReadyCheck) Run(
-	ctx context.Context, clnt declarative.Client, obj declarative.Object, _ []*resource.Info,
+	ctx context.Context, clnt declarative.Client, obj declarative.Object, resources []*resource.Info,
 ) error {
 	manifest := obj.(*manifestv1alpha1.Manifest)
-	if manifest.Spec.Resource == nil {
-		return nil
+	customStates := trackedCustomStates(manifest)
+
+	status := manifest.GetStatus()
+	tracked := make(map[string]bool, len(customStates)+len(manifest.Spec.Probes)+len(c.ReadinessRules))
+	var notReady []string
+	report := func(condition metav1.Condition, subject string) {
+		tracked[condition.Type] = true
+		condition.ObservedGeneration = manifest.GetGeneration()
+		meta.SetStatusCondition(&status.Conditions, condition)
+		if condition.Status != metav1.ConditionTrue && condition.Reason != ConditionReasonCustomStateInvalid {
+			notReady = append(notReady, fmt.Sprintf("%s: %s", subject, condition.Message))
+		}
 	}
-	res := manifest.Spec.Resource.DeepCopy()
-	if err := clnt.Get(ctx, client.ObjectKeyFromObject(res), res); err != nil {
-		return err
+	for index, customState := range customStates {
+		condition, err := checkCustomState(ctx, clnt, index, customState)
+		if err != nil {
+			return err
+		}
+		report(condition, customState.Kind+" "+customState.Name)
 	}
-	state, stateExists, err := unstructured.NestedString(res.Object, strings.Split(customResourceStatePath, ".")...)
-	if err != nil {
-		return fmt.Errorf(
-			"could not get state from custom resource %s at path %s to determine readiness",
-			res.GetName(), customResourceStatePath,
-		)
+	for _, rule := range effectiveReadinessRules(manifest, c.ReadinessRules) {
+		condition, err := checkReadinessRule(ctx, clnt, rule, resources)
+		if err != nil {
+			return err
+		}
+		report(condition, "readiness rule of "+rule.Kind)
 	}
-	if !stateExists {
-		return ErrCustomResourceStateNotFound
+	if len(manifest.Spec.Probes) > 0 {
+		prober, err := newProbeClient(clnt)
+		if err != nil {
+			return err
+		}
+		for _, probe := range manifest.Spec.Probes {
+			report(checkProbe(ctx, prober, probe), "probe "+probe.Name)
+		}
 	}
-
-	if state := declarative.State(state); state != declarative.StateReady {
-		return fmt.Errorf(
-			"custom resource state is %s but expected %s: %w", state, declarative.StateReady,
-			declarative.ErrResourcesNotReady,
-		)
+	for _, condition := range append([]metav1.Condition{}, status.Conditions...) {
+		if (strings.HasPrefix(condition.Type, ConditionTypeCustomStatePrefix) ||
+			strings.HasPrefix(condition.Type, ConditionTypeReadinessRulePrefix) ||
+			strings.HasPrefix(condition.Type, ConditionTypeProbePrefix)) && !tracked[condition.Type] {
+			meta.RemoveStatusCondition(&status.Conditions, condition.Type)
+		}
 	}
+	manifest.SetStatus(status)
 
+	if len(notReady) > 0 {
+		sort.Strings(notReady)
+		return fmt.Errorf("readiness checks of manifest failed: %s: %w",
+			strings.Join(notReady, "; "), declarative.ErrResourcesNotReady)
+	}
 	return nil
 }
+
+// trackedCustomStates returns the Resource of the Manifest, if any, followed by its CustomStates.
+func trackedCustomStates(manifest *manifestv1alpha1.Manifest) []manifestv1alpha1.CustomState {
+	customStates := make([]manifestv1alpha1.CustomState, 0, len(manifest.Spec.CustomStates)+1)
+	if res := manifest.Spec.Resource; res != nil {
+		customStates = append(customStates, manifestv1alpha1.CustomState{
+			APIVersion: res.GetAPIVersion(), Kind: res.GetKind(), Name: res.GetName(), Namespace: res.GetNamespace(),
+		})
+	}
+	return append(customStates, manifest.Spec.CustomStates...)
+}
+
+// CustomStateConditionType returns the type of the condition reporting the state of customState. It contains the
+// kind, group, namespace and name of the resource separated by "_", which is not part of any of them, e.g.
+// "CustomState-ConfigMap_default_sample" or "CustomState-Sample.operator.kyma-project.io__sample" for a cluster
+// scoped resource. Types exceeding the limit of the API server contain a hash of the resource instead.
+func CustomStateConditionType(customState manifestv1alpha1.CustomState) string {
+	groupKind := customState.Kind
+	if group := schema.FromAPIVersionAndKind(customState.APIVersion, customState.Kind).Group; group != "" {
+		groupKind += "." + group
+	}
+	key := groupKind + "_" + customState.Namespace + "_" + customState.Name
+	conditionType := ConditionTypeCustomStatePrefix + key
+	if len(conditionType) > maxConditionTypeLength {
+		sum := sha256.Sum256([]byte(key))
+		conditionType = ConditionTypeCustomStatePrefix + customState.Kind + "_" + hex.EncodeToString(sum[:8])
+	}
+	return conditionType
+}
+
+// checkCustomState returns the condition reporting the state of customState at index of the tracked resources,
+// errors are only returned if the resource could not be read.
+func checkCustomState(
+	ctx context.Context, clnt declarative.Client, index int, customState manifestv1alpha1.CustomState,
+) (metav1.Condition, error) {
+	condition := metav1.Condition{Status: metav1.ConditionFalse}
+	if customState.APIVersion == "" || customState.Kind == "" || customState.Name == "" {
+		condition.Type = fmt.Sprintf("%sInvalid-%d", ConditionTypeCustomStatePrefix, index)
+		condition.Reason = ConditionReasonCustomStateInvalid
+		condition.Message = "apiVersion, kind and name are required to track the state of a resource"
+		return condition, nil
+	}
+	condition.Type = CustomStateConditionType(customState)
+
+	path := customState.Path
+	if path == "" {
+		path = customResourceStatePath
+	}
+	expected := customState.Value
+	if expected == "" {
+		expected = string(declarative.StateReady)
+	}
+
+	res := &unstructured.Unstructured{}
+	res.SetAPIVersion(customState.APIVersion)
+	res.SetKind(customState.Kind)
+	if err := clnt.Get(ctx, client.ObjectKey{Name: customState.Name, Namespace: customState.Namespace}, res); err != nil {
+		if !k8serrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return condition, err
+		}
+		condition.Reason = ConditionReasonCustomResourceNotFound
+		condition.Message = fmt.Sprintf("%s %s does not exist", customState.Kind, customState.Name)
+		return condition, nil
+	}
+
+	state, stateExists, err := unstructured.NestedFieldNoCopy(res.Object, strings.Split(path, ".")...)
+	switch {
+	case err != nil:
+		condition.Reason = ConditionReasonCustomStateNotObservable
+		condition.Message = fmt.Sprintf("state at path %s could not be read: %s", path, err.Error())
+	case !stateExists:
+		condition.Reason = ConditionReasonCustomStateNotFound
+		condition.Message = fmt.Sprintf("no state reported at path %s", path)
+	case fmt.Sprint(state) != expected:
+		condition.Reason = ConditionReasonCustomStateNotReady
+		condition.Message = fmt.Sprintf("state is %v but expected %s", state, expected)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ConditionReasonCustomStateReady
+		condition.Message = fmt.Sprintf("state is %s", expected)
+	}
+	return condition, nil
+}
@@ -0,0 +1,196 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+
+	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	"github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// readyCheckClient serves the reads of the ready check from a fake client and its probes from the API server
+// at config.
+type readyCheckClient struct {
+	declarative.Client
+	reader client.Client
+	config *rest.Config
+}
+
+func (c *readyCheckClient) ToRESTConfig() (*rest.Config, error) {
+	return c.config, nil
+}
+
+func (c *readyCheckClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.reader.Get(ctx, key, obj)
+}
+
+var _ = Describe(
+	"test ready check of custom states", func() {
+		It(
+			"should report every tracked resource in a condition and tolerate invalid entries", func() {
+				clnt := &readyCheckClient{reader: fake.NewClientBuilder().WithObjects(
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "ready", Namespace: metav1.NamespaceDefault},
+						Data:       map[string]string{"state": "Ready"},
+					},
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "stateless", Namespace: metav1.NamespaceDefault},
+					},
+				).Build()}
+				customState := func(name string) manifestv1alpha1.CustomState {
+					return manifestv1alpha1.CustomState{
+						APIVersion: "v1", Kind: "ConfigMap", Name: name, Namespace: metav1.NamespaceDefault,
+						Path: "data.state",
+					}
+				}
+
+				conditionType := func(name string) string {
+					return v1alpha1.CustomStateConditionType(customState(name))
+				}
+				otherNamespace := customState("ready")
+				otherNamespace.Namespace = metav1.NamespaceSystem
+
+				manifest := &manifestv1alpha1.Manifest{Spec: manifestv1alpha1.ManifestSpec{
+					CustomStates: []manifestv1alpha1.CustomState{
+						customState("ready"), customState("stateless"), customState("missing"), {Kind: "ConfigMap"},
+						otherNamespace, {Name: "unnamed-kind"},
+					},
+				}}
+				manifest.SetStatus(declarative.Status{Conditions: []metav1.Condition{{
+					Type: conditionType("removed"), Status: metav1.ConditionTrue,
+				}}})
+
+				check := v1alpha1.NewManifestCustomResourceReadyCheck()
+				err := check.Run(context.Background(), clnt, manifest, nil)
+				Expect(err).To(MatchError(declarative.ErrResourcesNotReady))
+				Expect(err.Error()).To(ContainSubstring("ConfigMap stateless"))
+				Expect(err.Error()).To(ContainSubstring("ConfigMap missing"))
+
+				conditions := manifest.Status.Conditions
+				Expect(conditions).To(HaveLen(6))
+				Expect(conditionType("ready")).To(Equal("CustomState-ConfigMap_default_ready"))
+				Expect(meta.IsStatusConditionTrue(conditions, conditionType("ready"))).To(BeTrue())
+				Expect(meta.FindStatusCondition(conditions, conditionType("stateless")).Reason).
+					To(Equal(v1alpha1.ConditionReasonCustomStateNotFound))
+				Expect(meta.FindStatusCondition(conditions, conditionType("missing")).Reason).
+					To(Equal(v1alpha1.ConditionReasonCustomResourceNotFound))
+				Expect(meta.FindStatusCondition(conditions, v1alpha1.CustomStateConditionType(otherNamespace)).
+					Reason).To(Equal(v1alpha1.ConditionReasonCustomResourceNotFound))
+				for _, invalid := range []string{"Invalid-3", "Invalid-5"} {
+					Expect(meta.FindStatusCondition(conditions, v1alpha1.ConditionTypeCustomStatePrefix+invalid).
+						Reason).To(Equal(v1alpha1.ConditionReasonCustomStateInvalid))
+				}
+				Expect(v1alpha1.CustomStateConditionType(manifestv1alpha1.CustomState{
+					APIVersion: "operator.kyma-project.io/v1alpha1", Kind: "Sample", Name: "sample",
+				})).To(Equal("CustomState-Sample.operator.kyma-project.io__sample"))
+
+				manifest.Spec.CustomStates = manifest.Spec.CustomStates[:1]
+				Expect(check.Run(context.Background(), clnt, manifest, nil)).To(Succeed())
+				Expect(manifest.Status.Conditions).To(HaveLen(1))
+			},
+		)
+		It(
+			"should retry probes and report their result in a condition", func() {
+				var requests atomic.Int32
+				// the server serves the service proxy of the API server of the target cluster
+				server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, req *http.Request) {
+					switch {
+					case req.URL.Path == "/api/v1/namespaces/kyma-system/services/http:moved:8080/proxy/":
+						http.Redirect(writer, req, "http://169.254.169.254/", http.StatusFound)
+					case req.URL.Path != "/api/v1/namespaces/istio-system/services/http:gateway:15021/proxy/healthz":
+						writer.WriteHeader(http.StatusNotFound)
+					case requests.Add(1) < 3:
+						writer.WriteHeader(http.StatusServiceUnavailable)
+					}
+				}))
+				defer server.Close()
+				clnt := &readyCheckClient{config: &rest.Config{Host: server.URL}}
+
+				manifest := &manifestv1alpha1.Manifest{Spec: manifestv1alpha1.ManifestSpec{
+					Probes: []manifestv1alpha1.HTTPProbe{
+						{Name: "gateway", URL: "http://gateway.istio-system.svc:15021/healthz"},
+					},
+				}}
+				check := v1alpha1.NewManifestCustomResourceReadyCheck()
+				err := check.Run(context.Background(), clnt, manifest, nil)
+				Expect(err).To(MatchError(declarative.ErrResourcesNotReady))
+				Expect(meta.FindStatusCondition(manifest.Status.Conditions,
+					v1alpha1.ConditionTypeProbePrefix+"gateway").Reason).To(Equal(v1alpha1.ConditionReasonProbeFailed))
+
+				manifest.Spec.Probes[0].Attempts = 2
+				Expect(check.Run(context.Background(), clnt, manifest, nil)).To(Succeed())
+				Expect(meta.IsStatusConditionTrue(manifest.Status.Conditions,
+					v1alpha1.ConditionTypeProbePrefix+"gateway")).To(BeTrue())
+
+				manifest.Spec.Probes = []manifestv1alpha1.HTTPProbe{
+					{Name: "moved", URL: "http://moved.kyma-system.svc:8080/"},
+					{Name: "external", URL: server.URL},
+				}
+				Expect(check.Run(context.Background(), clnt, manifest, nil)).
+					To(MatchError(declarative.ErrResourcesNotReady))
+				Expect(meta.FindStatusCondition(manifest.Status.Conditions,
+					v1alpha1.ConditionTypeProbePrefix+"moved").Message).To(ContainSubstring("status 302"),
+					"redirects are not followed")
+				Expect(meta.FindStatusCondition(manifest.Status.Conditions,
+					v1alpha1.ConditionTypeProbePrefix+"external").Message).
+					To(ContainSubstring(manifestv1alpha1.ErrProbeNotInCluster.Error()))
+			},
+		)
+		It(
+			"should evaluate the readiness rules of the Manifest and the controller", func() {
+				configMap := func(name, state string) *corev1.ConfigMap {
+					return &corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: metav1.NamespaceDefault},
+						Data:       map[string]string{"state": state},
+					}
+				}
+				clnt := &readyCheckClient{reader: fake.NewClientBuilder().WithObjects(
+					configMap("ready", "Ready"), configMap("processing", "Processing"),
+				).Build()}
+				var resources []*resource.Info
+				for _, name := range []string{"ready", "processing"} {
+					obj := &unstructured.Unstructured{}
+					obj.SetAPIVersion("v1")
+					obj.SetKind("ConfigMap")
+					obj.SetName(name)
+					obj.SetNamespace(metav1.NamespaceDefault)
+					resources = append(resources, &resource.Info{
+						Name: name, Namespace: metav1.NamespaceDefault, Object: obj,
+					})
+				}
+
+				check := v1alpha1.NewManifestCustomResourceReadyCheck()
+				check.ReadinessRules = []manifestv1alpha1.ReadinessRule{{
+					APIVersion: "v1", Kind: "ConfigMap", Expression: `data.state == "Ready"`,
+				}}
+				manifest := &manifestv1alpha1.Manifest{}
+				err := check.Run(context.Background(), clnt, manifest, resources)
+				Expect(err).To(MatchError(declarative.ErrResourcesNotReady))
+				condition := meta.FindStatusCondition(manifest.Status.Conditions,
+					v1alpha1.ConditionTypeReadinessRulePrefix+"ConfigMap")
+				Expect(condition.Reason).To(Equal(v1alpha1.ConditionReasonCustomStateNotReady))
+				Expect(condition.Message).To(ContainSubstring("processing"))
+
+				manifest.Spec.ReadinessRules = []manifestv1alpha1.ReadinessRule{{
+					APIVersion: "v1", Kind: "ConfigMap", Expression: `data.state in ["Ready", "Processing"]`,
+				}}
+				Expect(check.Run(context.Background(), clnt, manifest, resources)).To(Succeed())
+				Expect(meta.IsStatusConditionTrue(manifest.Status.Conditions,
+					v1alpha1.ConditionTypeReadinessRulePrefix+"ConfigMap")).To(BeTrue())
+			},
+		)
+	},
+)
@@ -2,6 +2,7 @@ package v1alpha1
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/kyma-project/module-manager/api/v1alpha1"
@@ -11,13 +12,22 @@ import (
 	"github.com/kyma-project/module-manager/pkg/labels"
 	"github.com/kyma-project/module-manager/pkg/types"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 type RESTConfigGetter func() (*rest.Config, error)
 
+var ErrRemoteDisabled = errors.New("remote installations are disabled, Manifests can only be installed " +
+	"in the control plane as spec.remote is not supported by this single-cluster installation")
+
 type RemoteClusterLookup struct {
 	KCP          *types.ClusterInfo
 	ConfigGetter RESTConfigGetter
+	// RemoteDisabled rejects all Manifests that should be installed on a remote cluster.
+	RemoteDisabled bool
+	// AllowInsecureTLS allows kubeconfig secrets to skip the TLS verification of their cluster,
+	// see ApplyTLSOverrides.
+	AllowInsecureTLS bool
 }
 
 func (r *RemoteClusterLookup) ConfigResolver(ctx context.Context, obj declarative.Object) (*types.ClusterInfo, error) {
@@ -27,6 +37,9 @@ func (r *RemoteClusterLookup) ConfigResolver(ctx context.Context, obj declarativ
 	if !manifest.Spec.Remote {
 		return r.KCP, nil
 	}
+	if r.RemoteDisabled {
+		return nil, ErrRemoteDisabled
+	}
 
 	kymaOwnerLabel, err := internal.GetResourceLabel(manifest, labels.KymaName)
 	if err != nil {
@@ -41,16 +54,28 @@ func (r *RemoteClusterLookup) ConfigResolver(ctx context.Context, obj declarativ
 	} else {
 		restConfigGetter = func() (*rest.Config, error) {
 			// evaluate remote rest config from secret
-			config, err := (&custom.ClusterClient{DefaultClient: r.KCP.Client}).GetRESTConfig(
+			secret, err := (&custom.ClusterClient{DefaultClient: r.KCP.Client}).GetKubeConfigSecret(
 				ctx, kymaOwnerLabel, manifest.GetNamespace(),
 			)
 			if err != nil {
 				return nil, fmt.Errorf("could not resolve remote cluster rest config: %w", err)
 			}
+			config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data["config"])
+			if err != nil {
+				return nil, fmt.Errorf("could not resolve remote cluster rest config: %w", err)
+			}
+			if err := ApplyTLSOverrides(ctx, secret, config, r.AllowInsecureTLS); err != nil {
+				return nil, fmt.Errorf("could not apply TLS overrides of remote cluster: %w", err)
+			}
 			return config, nil
 		}
 	}
 
+	version, err := r.ClusterVersion(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
 	config, err := restConfigGetter()
 	if err != nil {
 		return nil, err
@@ -59,5 +84,31 @@ func (r *RemoteClusterLookup) ConfigResolver(ctx context.Context, obj declarativ
 	config.QPS = r.KCP.Config.QPS
 	config.Burst = r.KCP.Config.Burst
 
-	return &types.ClusterInfo{Config: config}, nil
+	return &types.ClusterInfo{Config: config, Version: version}, nil
+}
+
+// ClusterVersion returns the resourceVersion of the kubeconfig secret of a remote Manifest,
+// so that clients are recreated after the kubeconfig was rotated.
+// Manifests installed in the control plane or resolved by a ConfigGetter have no version.
+func (r *RemoteClusterLookup) ClusterVersion(ctx context.Context, obj declarative.Object) (string, error) {
+	manifest := obj.(*v1alpha1.Manifest)
+	if !manifest.Spec.Remote || r.ConfigGetter != nil {
+		return "", nil
+	}
+	if r.RemoteDisabled {
+		return "", ErrRemoteDisabled
+	}
+
+	kymaOwnerLabel, err := internal.GetResourceLabel(manifest, labels.KymaName)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := (&custom.ClusterClient{DefaultClient: r.KCP.Client}).GetKubeConfigSecret(
+		ctx, kymaOwnerLabel, manifest.GetNamespace(),
+	)
+	if err != nil {
+		return "", fmt.Errorf("could not resolve remote cluster kubeconfig secret: %w", err)
+	}
+	return secret.GetResourceVersion(), nil
 }
@@ -2,27 +2,42 @@ package v1alpha1
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/kyma-project/module-manager/api/v1alpha1"
 	"github.com/kyma-project/module-manager/internal"
 	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
 	"github.com/kyma-project/module-manager/pkg/types"
-	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
-	"helm.sh/helm/v3/pkg/repo"
+	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/strvals"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-var ErrNoAuthSecretFound = errors.New("no auth secret found")
+const (
+	nameOverrideKey = "nameOverride"
+	// verifiedChartsFolder separates verified charts on disk from charts downloaded without verification.
+	verifiedChartsFolder = "verified-charts"
+)
+
+var (
+	ErrNoAuthSecretFound    = errors.New("no auth secret found")
+	ErrNoKeyring            = errors.New("no keyring configured to verify chart provenance")
+	ErrInvalidRawSource     = errors.New("raw manifest requires exactly one of manifest, url and image")
+	ErrOCIChartVerification = errors.New("provenance verification is not supported for charts in OCI registries")
+)
 
 type ManifestSpecResolver struct {
 	KCP client.Client
@@ -30,16 +45,37 @@ type ManifestSpecResolver struct {
 	*types.Codec
 	Insecure bool
 
-	ChartCache   string
+	ChartCache     string
+	RepoIndexCache *internal.HelmRepoIndexCache
+	// Keyring is the path to the public keyring used to verify the provenance of repository charts.
+	Keyring string
+	// KustomizeRemotes fetches remote kustomizations, so that credentials and pinned commits are supported.
+	KustomizeRemotes *internal.KustomizeRemoteFetcher
+	// RawManifests stores the manifests of raw-manifest installs, which are applied without rendering.
+	RawManifests *internal.RawManifestStore
+	// SecretResolver resolves the ValuesFrom of installs, installs with ValuesFrom fail if it is not configured.
+	SecretResolver *internal.SecretResolver
+	// RequireDigests rejects OCI images referenced by tag, otherwise tags are resolved to the digest of their layer.
+	RequireDigests bool
+	// GlobalValues are set for every install below the values of the install, e.g. landscape-wide settings.
+	GlobalValues map[string]any
+	// EventRecorder optionally reports chart extractions that wait for a free slot or disk budget.
+	EventRecorder record.EventRecorder
+
 	cachedCharts map[string]string
+	sources      *installSourceCache
 }
 
 func NewManifestSpecResolver(codec *types.Codec, insecure bool) *ManifestSpecResolver {
 	return &ManifestSpecResolver{
-		Codec:        codec,
-		Insecure:     insecure,
-		ChartCache:   os.TempDir(),
-		cachedCharts: make(map[string]string),
+		Codec:            codec,
+		Insecure:         insecure,
+		ChartCache:       os.TempDir(),
+		RepoIndexCache:   internal.NewHelmRepoIndexCache(internal.DefaultHelmRepoIndexTTL, os.TempDir()),
+		KustomizeRemotes: &internal.KustomizeRemoteFetcher{CacheDir: os.TempDir()},
+		RawManifests:     &internal.RawManifestStore{CacheDir: os.TempDir()},
+		cachedCharts:     make(map[string]string),
+		sources:          newInstallSourceCache(DefaultInstallSourceTTL),
 	}
 }
 
@@ -51,23 +87,34 @@ func (m *ManifestSpecResolver) Spec(ctx context.Context, obj declarative.Object)
 		)
 	}
 
+	// Manifests stored before the migration to the current spec layout are converted in memory,
+	// so that the custom resource checks running after the resolution see the current fields.
+	manifest.ConvertLegacySpec()
+
 	if len(manifest.Spec.Installs) != 1 {
 		return nil, fmt.Errorf("%v installs found in manifest, cannot install", len(manifest.Spec.Installs))
 	}
 
 	install := manifest.Spec.Installs[0]
+	if m.EventRecorder != nil {
+		ctx = internal.WithExtractionWaitHandler(ctx, func(reason string) {
+			m.EventRecorder.Event(manifest, "Normal", "ChartExtractionWaiting",
+				fmt.Sprintf("extraction of %s waits for a free %s", install.Name, reason))
+		})
+	}
 
-	specType, err := types.GetSpecType(install.Source.Raw)
+	source, err := m.sources.decode(m.Codec, manifest, install)
 	if err != nil {
 		return nil, err
 	}
+	specType := source.Type
 
 	keyChain, err := m.lookupKeyChain(ctx, manifest.Spec.Config)
 	if err != nil {
 		return nil, err
 	}
 
-	chartInfo, err := m.getChartInfoForInstall(ctx, install, specType, keyChain)
+	chartInfo, err := m.getChartInfoForInstall(ctx, manifest.GetNamespace(), install, source, keyChain)
 	if err != nil {
 		return nil, err
 	}
@@ -80,52 +127,120 @@ func (m *ManifestSpecResolver) Spec(ctx context.Context, obj declarative.Object)
 		mode = declarative.RenderModeHelm
 	case types.KustomizeType:
 		mode = declarative.RenderModeKustomize
+	case types.GitType:
+		mode = gitRenderMode(source.Git, chartInfo.ChartPath)
+	case types.RawManifestType:
+		mode = declarative.RenderModeRaw
 	case types.NilRefType:
 		return nil, fmt.Errorf("could not determine render mode for %s", client.ObjectKeyFromObject(manifest))
 	}
 
-	values, err := m.getValuesFromConfig(ctx, manifest.Spec.Config, install.Name, keyChain)
+	values, flags, err := m.getValuesFromConfig(ctx, manifest, install.Name, keyChain)
 	if err != nil {
 		return nil, err
 	}
+	values = ApplyGlobalValues(values, m.GlobalValues)
+	if values, err = ApplyFlagOverlays(ctx, m.KCP, manifest, install.Name, values); err != nil {
+		return nil, err
+	}
+	if values, err = m.resolveValuesFrom(ctx, values, install.ValuesFrom); err != nil {
+		return nil, err
+	}
+	if install.NameOverride && mode == declarative.RenderModeHelm {
+		values = withNameOverride(values, manifest.GetName()+"-"+install.Name)
+	}
 
 	path := chartInfo.ChartPath
 	if path == "" && chartInfo.URL != "" {
 		path = chartInfo.URL
 
 		if mode == declarative.RenderModeHelm {
-			path, err = m.downloadAndCacheHelmChart(chartInfo)
+			path, err = m.downloadAndCacheHelmChart(ctx, chartInfo)
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
 
+	revision := chartInfo.Revision
+	if revision == "" && chartInfo.Digest != "" {
+		revision = chartInfo.Digest
+	}
+	if revision == "" {
+		revision = filepath.Base(path)
+	}
+
 	return &declarative.Spec{
-		ManifestName: install.Name,
-		Path:         path,
-		Values:       values,
-		Mode:         mode,
+		ManifestName:    install.Name,
+		Path:            path,
+		Values:          values,
+		Mode:            mode,
+		Revision:        revision,
+		Provenance:      chartInfo.Provenance,
+		GeneratedValues: install.GeneratedValues,
+		Wait:            install.Wait,
+		InstallFlags:    flags,
 	}, nil
 }
 
-func (m *ManifestSpecResolver) downloadAndCacheHelmChart(chartInfo *types.ChartInfo) (string, error) {
-	filename := filepath.Join(m.ChartCache, chartInfo.ChartName)
+func (m *ManifestSpecResolver) downloadAndCacheHelmChart(
+	ctx context.Context, chartInfo *types.ChartInfo,
+) (string, error) {
+	destDir := m.ChartCache
+	verify := downloader.VerifyNever
+	if chartInfo.Verify {
+		if m.Keyring == "" {
+			return "", fmt.Errorf("%w: chart %s requires verification", ErrNoKeyring, chartInfo.ChartName)
+		}
+		if registry.IsOCI(chartInfo.URL) {
+			return "", fmt.Errorf("%w: chart %s", ErrOCIChartVerification, chartInfo.ChartName)
+		}
+		verify = downloader.VerifyAlways
+		// charts downloaded without verification must not satisfy a verified install, neither from memory
+		// nor from a chart of the same name and version that is already on disk
+		destDir = filepath.Join(m.ChartCache, verifiedChartsFolder)
+		if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+			return "", err
+		}
+	}
+	filename := filepath.Join(destDir, chartInfo.ChartName)
+	if chartInfo.Version != "" {
+		filename += "-" + chartInfo.Version
+	}
 
 	if cachedChart, ok := m.cachedCharts[filename]; !ok {
-		getters := getter.All(cli.New())
-		chart, err := repo.FindChartInRepoURL(
-			chartInfo.URL,
-			chartInfo.ChartName, "", "", "", "", getters,
-		)
-		if err != nil {
-			return "", err
+		settings := internal.NewHelmEnvSettings(m.ChartCache)
+		chartDownloader := &downloader.ChartDownloader{
+			Getters:          getter.All(settings),
+			Verify:           verify,
+			Keyring:          m.Keyring,
+			RepositoryConfig: settings.RepositoryConfig,
+			RepositoryCache:  settings.RepositoryCache,
 		}
-		cachedChart, _, err := (&downloader.ChartDownloader{Getters: getters}).DownloadTo(
-			chart, "", m.ChartCache,
-		)
+		var chart string
+		if registry.IsOCI(chartInfo.URL) {
+			chart = strings.TrimSuffix(chartInfo.URL, "/") + "/" + chartInfo.ChartName
+			registryClient, cleanup, err := m.newHelmRegistryClient(ctx, chart, chartInfo.CredSecretSelector)
+			if err != nil {
+				return "", err
+			}
+			defer cleanup()
+			chartDownloader.RegistryClient = registryClient
+			chartDownloader.Options = append(chartDownloader.Options, getter.WithRegistryClient(registryClient))
+		} else {
+			resolved, err := m.RepoIndexCache.FindChartInRepoURL(ctx, chartInfo.URL, chartInfo.ChartName,
+				chartInfo.Version)
+			if err != nil {
+				return "", &types.DownloadError{Ref: chartInfo.URL, Err: err}
+			}
+			chart = resolved
+		}
+		cachedChart, _, err := chartDownloader.DownloadTo(chart, chartInfo.Version, destDir)
 		if err != nil {
-			return "", err
+			if chartInfo.Verify {
+				return "", fmt.Errorf("verifying provenance of chart %s: %w", chart, err)
+			}
+			return "", &types.DownloadError{Ref: chart, Err: err}
 		}
 		m.cachedCharts[filename] = cachedChart
 		filename = cachedChart
@@ -133,95 +248,169 @@ func (m *ManifestSpecResolver) downloadAndCacheHelmChart(chartInfo *types.ChartI
 		filename = cachedChart
 	}
 
+	if chartInfo.Digest != "" {
+		if err := internal.VerifyChartDigest(filename, chartInfo.Digest); err != nil {
+			return "", err
+		}
+	}
+
 	return filename, nil
 }
 
+// withNameOverride sets the nameOverride value of a chart, a nameOverride from the install config takes precedence.
+func withNameOverride(values map[string]any, nameOverride string) map[string]any {
+	if _, found := values[nameOverrideKey]; found {
+		return values
+	}
+	if values == nil {
+		values = make(map[string]any)
+	}
+	values[nameOverrideKey] = nameOverride
+	return values
+}
+
+// getValuesFromConfig returns the values and the install flags of the install from the config layer of manifest.
+// Values of the install are merged with its strvals overrides, which take precedence.
 func (m *ManifestSpecResolver) getValuesFromConfig(
-	ctx context.Context, config types.ImageSpec, name string, keyChain authn.Keychain,
-) (map[string]any, error) {
-	var configs []any
-	if config.Type.NotEmpty() { //nolint:nestif
-		decodedConfig, err := internal.DecodeUncompressedYAMLLayer(ctx, config, m.Insecure, keyChain)
-		if err != nil {
-			// if EOF error, we should proceed without config
-			if !errors.Is(err, io.EOF) {
-				return nil, err
-			}
-		} else {
-			var err error
-			configs, err = parseInstallConfigs(decodedConfig)
-			if err != nil {
-				return nil, fmt.Errorf("value parsing for %s encountered an err: %w", name, err)
-			}
+	ctx context.Context, manifest *v1alpha1.Manifest, name string, keyChain authn.Keychain,
+) (map[string]any, types.Flags, error) {
+	values := map[string]any{}
+	config := manifest.Spec.Config
+	if !config.Type.NotEmpty() {
+		return values, nil, nil
+	}
+	config, err := internal.NormalizeImageSpec(
+		ctx, config, m.Insecure, keyChain, m.RequireDigests, internal.ConfigLayerTitle,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	decodedConfig, err := internal.DecodeUncompressedYAMLLayer(ctx, config, m.Insecure, keyChain)
+	if err != nil {
+		// if EOF error, we should proceed without config
+		if errors.Is(err, io.EOF) {
+			return values, nil, nil
 		}
+		return nil, nil, err
 	}
 
-	// filter config for install
-	chartValues, err := parseChartConfigAndValues(configs, name)
+	installConfig, err := m.parseInstallConfig(decodedConfig, name)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("value parsing for %s encountered an err: %w", name, err)
 	}
-	return chartValues, nil
+	if installConfig == nil {
+		return values, nil, nil
+	}
+	flags, ignored, err := installConfig.ConfigFlags()
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid config layer of "+v1alpha1.ManifestKind+": %w", err)
+	}
+	if len(ignored) > 0 && m.EventRecorder != nil {
+		m.EventRecorder.Event(manifest, "Warning", "DeprecatedClientConfig", fmt.Sprintf(
+			"the client config of install %s sets the unsupported flags %s, which are ignored, "+
+				"only the flags %s are applied", name, strings.Join(ignored, ", "), types.SupportedConfigFlagNames()))
+	}
+	if installConfig.Values != nil {
+		values = installConfig.Values
+	}
+	if err := strvals.ParseInto(installConfig.Overrides, values); err != nil {
+		return nil, nil, fmt.Errorf("manifest encountered an error while parsing chart config: %w", err)
+	}
+	return values, flags, nil
 }
 
-func parseInstallConfigs(decodedConfig interface{}) ([]interface{}, error) {
-	var configs []interface{}
-	installConfigObj, decodeOk := decodedConfig.(map[string]interface{})
-	if !decodeOk {
+// parseInstallConfig validates the decoded config layer and returns the configuration of the install,
+// or nil if the layer has none for it.
+func (m *ManifestSpecResolver) parseInstallConfig(
+	decodedConfig interface{}, name string,
+) (*types.InstallConfig, error) {
+	if _, ok := decodedConfig.(map[string]interface{}); !ok {
 		return nil, fmt.Errorf("reading install %s resulted in an error for "+v1alpha1.ManifestKind, ".spec.config")
 	}
-	if installConfigObj["configs"] != nil {
-		var configOk bool
-		configs, configOk = installConfigObj["configs"].([]interface{})
-		if !configOk {
-			return nil, fmt.Errorf(
-				"reading install %s resulted in an error for "+v1alpha1.ManifestKind,
-				"chart config object of .spec.config",
-			)
+	data, err := json.Marshal(decodedConfig)
+	if err != nil {
+		return nil, err
+	}
+	configs, err := m.DecodeInstallConfigs(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config layer of "+v1alpha1.ManifestKind+": %w", err)
+	}
+	return configs.ForInstall(name), nil
+}
+
+// resolveValuesFrom overrides values with the secrets referenced by valuesFrom.
+func (m *ManifestSpecResolver) resolveValuesFrom(
+	ctx context.Context, values map[string]any, valuesFrom []v1alpha1.ValuesReference,
+) (map[string]any, error) {
+	if len(valuesFrom) == 0 {
+		return values, nil
+	}
+	if m.SecretResolver == nil {
+		return nil, fmt.Errorf("%w: valuesFrom requires a secret provider", internal.ErrSecretProviderNotFound)
+	}
+	if values == nil {
+		values = make(map[string]any)
+	}
+	for _, ref := range valuesFrom {
+		value, err := m.SecretResolver.Resolve(ctx, ref.Provider, internal.SecretReference{Path: ref.Path, Key: ref.Key})
+		if err != nil {
+			return nil, err
+		}
+		if err := internal.SetValueAtPath(values, ref.TargetPath, value); err != nil {
+			return nil, err
 		}
 	}
-	return configs, nil
+	return values, nil
 }
 
 func (m *ManifestSpecResolver) getChartInfoForInstall(
 	ctx context.Context,
+	namespace string,
 	install v1alpha1.InstallInfo,
-	specType types.RefTypeMetadata,
+	source *installSource,
 	keyChain authn.Keychain,
 ) (*types.ChartInfo, error) {
-	var err error
-	switch specType {
+	switch source.Type {
 	case types.HelmChartType:
-		var helmChartSpec types.HelmChartSpec
-		if err = m.Codec.Decode(install.Source.Raw, &helmChartSpec, specType); err != nil {
-			return nil, err
-		}
-
+		helmChartSpec := source.HelmChart
 		return &types.ChartInfo{
-			ChartName: helmChartSpec.ChartName,
-			RepoName:  install.Name,
-			URL:       helmChartSpec.URL,
+			ChartName:          helmChartSpec.ChartName,
+			RepoName:           install.Name,
+			URL:                helmChartSpec.URL,
+			Digest:             helmChartSpec.Digest,
+			Verify:             helmChartSpec.Verify,
+			Version:            helmChartSpec.Version,
+			CredSecretSelector: helmChartSpec.CredSecretSelector,
 		}, nil
 	case types.OciRefType:
-		var imageSpec types.ImageSpec
-		if err = m.Codec.Decode(install.Source.Raw, &imageSpec, specType); err != nil {
+		imageSpec, err := internal.NormalizeImageSpec(
+			ctx, source.Image, m.Insecure, keyChain, m.RequireDigests, internal.ChartLayerTitle,
+		)
+		if err != nil {
 			return nil, err
 		}
-
 		// extract helm chart from layer digest
 		chartPath, err := internal.GetPathFromExtractedTarGz(ctx, imageSpec, m.Insecure, keyChain)
 		if err != nil {
 			return nil, err
 		}
 
+		provenance, err := internal.GetProvenance(ctx, imageSpec, m.Insecure, keyChain)
+		if err != nil {
+			log.FromContext(ctx).V(internal.DebugLogLevel).Info("no provenance available for chart",
+				"install", install.Name, "reason", err.Error())
+		}
+
 		return &types.ChartInfo{
-			ChartName: install.Name,
-			ChartPath: chartPath,
+			ChartName:  install.Name,
+			ChartPath:  chartPath,
+			Revision:   imageSpec.Ref,
+			Provenance: provenance,
 		}, nil
 	case types.KustomizeType:
-		var kustomizeSpec types.KustomizeSpec
-		if err = m.Codec.Decode(install.Source.Raw, &kustomizeSpec, specType); err != nil {
-			return nil, err
+		kustomizeSpec := source.Kustomize
+		if kustomizeSpec.Path == "" && kustomizeSpec.URL != "" {
+			return m.fetchKustomizeRemote(ctx, namespace, install.Name, kustomizeSpec)
 		}
 
 		return &types.ChartInfo{
@@ -229,54 +418,122 @@ func (m *ManifestSpecResolver) getChartInfoForInstall(
 			ChartPath: kustomizeSpec.Path,
 			URL:       kustomizeSpec.URL,
 		}, nil
+	case types.GitType:
+		return m.fetchGit(ctx, namespace, install.Name, source.Git)
+	case types.RawManifestType:
+		return m.storeRawManifest(ctx, install.Name, source.RawManifest)
 	case types.NilRefType:
 		return nil, fmt.Errorf("empty image type")
 	}
 
 	return nil, fmt.Errorf(
-		"unsupported type %s of install", specType,
+		"unsupported type %s of install", source.Type,
 	)
 }
 
-func parseChartConfigAndValues(
-	configs []interface{}, name string,
-) (map[string]interface{}, error) {
-	valuesString, err := getConfigAndValuesForInstall(configs, name)
+// fetchKustomizeRemote fetches the remote kustomization to disk instead of letting kustomize fetch it,
+// which supports neither credentials nor pinned commits. The revision is the fetched commit.
+func (m *ManifestSpecResolver) fetchKustomizeRemote(
+	ctx context.Context, namespace, name string, kustomizeSpec types.KustomizeSpec,
+) (*types.ChartInfo, error) {
+	remote := internal.KustomizeRemote{URL: kustomizeSpec.URL, Commit: kustomizeSpec.Commit}
+	if kustomizeSpec.CredSecretSelector != nil {
+		credentials, err := GetGitCredentials(ctx, kustomizeSpec.CredSecretSelector, namespace, m.KCP)
+		if err != nil {
+			return nil, err
+		}
+		remote.Credentials = credentials
+	}
+
+	path, commit, err := m.KustomizeRemotes.Fetch(ctx, remote)
 	if err != nil {
-		return nil, fmt.Errorf("manifest encountered an error while parsing chart config: %w", err)
+		return nil, err
 	}
 
-	values := map[string]interface{}{}
-	if err := strvals.ParseInto(valuesString, values); err != nil {
+	return &types.ChartInfo{
+		ChartName: name,
+		ChartPath: path,
+		URL:       kustomizeSpec.URL,
+		Revision:  commit,
+	}, nil
+}
+
+// fetchGit checks out the chart or kustomization of a git source, the revision is the fetched commit.
+func (m *ManifestSpecResolver) fetchGit(
+	ctx context.Context, namespace, name string, gitSpec types.GitSpec,
+) (*types.ChartInfo, error) {
+	remote := internal.GitRemote{URL: gitSpec.URL, Ref: gitSpec.Ref, Path: gitSpec.Path, Commit: gitSpec.Commit}
+	if gitSpec.CredSecretSelector != nil {
+		credentials, err := GetGitCredentials(ctx, gitSpec.CredSecretSelector, namespace, m.KCP)
+		if err != nil {
+			return nil, err
+		}
+		remote.Credentials = credentials
+	}
+
+	path, commit, err := m.KustomizeRemotes.FetchGit(ctx, remote)
+	if err != nil {
 		return nil, err
 	}
 
-	return values, nil
+	return &types.ChartInfo{
+		ChartName: name,
+		ChartPath: path,
+		URL:       gitSpec.URL,
+		Revision:  commit,
+	}, nil
 }
 
-func getConfigAndValuesForInstall(configs []interface{}, name string) (
-	string, error,
-) {
-	var defaultOverrides string
-
-	for _, config := range configs {
-		mappedConfig, configExists := config.(map[string]interface{})
-		if !configExists {
-			return "", fmt.Errorf(
-				"reading install %s resulted in an error for "+v1alpha1.ManifestKind, "config object",
-			)
+// storeRawManifest stores the manifest of a raw-manifest source on disk, the revision is its digest.
+func (m *ManifestSpecResolver) storeRawManifest(
+	ctx context.Context, name string, rawSpec types.RawManifestSpec,
+) (*types.ChartInfo, error) {
+	var path, digest string
+	var err error
+	switch {
+	case rawSpec.Manifest != "" && rawSpec.URL == "" && rawSpec.Image == nil:
+		path, digest, err = m.RawManifests.Store([]byte(rawSpec.Manifest), rawSpec.Digest)
+	case rawSpec.URL != "" && rawSpec.Manifest == "" && rawSpec.Image == nil:
+		path, digest, err = m.RawManifests.Download(ctx, rawSpec.URL, rawSpec.Digest)
+	case rawSpec.Image != nil && rawSpec.Manifest == "" && rawSpec.URL == "":
+		keyChain, keyChainErr := m.lookupKeyChain(ctx, *rawSpec.Image)
+		if keyChainErr != nil {
+			return nil, keyChainErr
 		}
-		if mappedConfig["name"] == name {
-			defaultOverrides, configExists = mappedConfig["overrides"].(string)
-			if !configExists {
-				return "", fmt.Errorf(
-					"reading install %s resulted in an error for "+v1alpha1.ManifestKind, "config object overrides",
-				)
-			}
-			break
+		imageSpec, normalizeErr := internal.NormalizeImageSpec(
+			ctx, *rawSpec.Image, m.Insecure, keyChain, m.RequireDigests, internal.RawManifestLayerTitle,
+		)
+		if normalizeErr != nil {
+			return nil, normalizeErr
 		}
+		path, digest, err = m.RawManifests.Pull(ctx, imageSpec, m.Insecure, keyChain, rawSpec.Digest)
+	default:
+		return nil, fmt.Errorf("%w: install %s", ErrInvalidRawSource, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ChartInfo{
+		ChartName: name,
+		ChartPath: path,
+		URL:       rawSpec.URL,
+		Revision:  digest,
+	}, nil
+}
+
+// gitRenderMode renders checked out paths with a Chart.yaml with helm, unless a renderer is set explicitly.
+func gitRenderMode(gitSpec types.GitSpec, path string) declarative.RenderMode {
+	switch gitSpec.Renderer {
+	case string(declarative.RenderModeHelm):
+		return declarative.RenderModeHelm
+	case string(declarative.RenderModeKustomize):
+		return declarative.RenderModeKustomize
+	}
+	if _, err := os.Stat(filepath.Join(path, chartutil.ChartfileName)); err == nil {
+		return declarative.RenderModeHelm
 	}
-	return defaultOverrides, nil
+	return declarative.RenderModeKustomize
 }
 
 func (m *ManifestSpecResolver) lookupKeyChain(ctx context.Context, imageSpec types.ImageSpec) (authn.Keychain, error) {
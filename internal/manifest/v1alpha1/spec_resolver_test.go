@@ -0,0 +1,24 @@
+// contains internal tests that should not be exposed, thus no v1alpha1_test
+//
+//nolint:testpackage
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithNameOverride(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, map[string]any{nameOverrideKey: "kyma-keda"}, withNameOverride(nil, "kyma-keda"),
+		"charts without values get the nameOverride")
+
+	values := map[string]any{"replicas": 2}
+	assert.Equal(t, map[string]any{"replicas": 2, nameOverrideKey: "kyma-keda"},
+		withNameOverride(values, "kyma-keda"))
+
+	configured := map[string]any{nameOverrideKey: "custom"}
+	assert.Equal(t, map[string]any{nameOverrideKey: "custom"}, withNameOverride(configured, "kyma-keda"),
+		"a nameOverride from the install config takes precedence")
+}
@@ -53,23 +53,18 @@ import (
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
 var (
-	k8sClient     client.Client                                       //nolint:gochecknoglobals
-	testEnv       *envtest.Environment                                //nolint:gochecknoglobals
-	k8sManager    ctrl.Manager                                        //nolint:gochecknoglobals
-	ctx           context.Context                                     //nolint:gochecknoglobals
-	cancel        context.CancelFunc                                  //nolint:gochecknoglobals
-	server        *httptest.Server                                    //nolint:gochecknoglobals
-	helmCacheRepo = filepath.Join(helmCacheHome, "repository")        //nolint:gochecknoglobals
-	helmRepoFile  = filepath.Join(helmCacheHome, "repositories.yaml") //nolint:gochecknoglobals
-	reconciler    *declarative.Reconciler                             //nolint:gochecknoglobals
-	cfg           *rest.Config                                        //nolint:gochecknoglobals
+	k8sClient  client.Client           //nolint:gochecknoglobals
+	testEnv    *envtest.Environment    //nolint:gochecknoglobals
+	k8sManager ctrl.Manager            //nolint:gochecknoglobals
+	ctx        context.Context         //nolint:gochecknoglobals
+	cancel     context.CancelFunc      //nolint:gochecknoglobals
+	server     *httptest.Server        //nolint:gochecknoglobals
+	reconciler *declarative.Reconciler //nolint:gochecknoglobals
+	cfg        *rest.Config            //nolint:gochecknoglobals
 )
 
 const (
-	helmCacheHomeEnv   = "HELM_CACHE_HOME"
 	helmCacheHome      = "/tmp/caches"
-	helmCacheRepoEnv   = "HELM_REPOSITORY_CACHE"
-	helmRepoEnv        = "HELM_REPOSITORY_CONFIG"
 	kustomizeLocalPath = "../../../pkg/test_samples/kustomize"
 	standardTimeout    = 30 * time.Second
 	standardInterval   = 100 * time.Millisecond
@@ -133,11 +128,12 @@ var _ = BeforeSuite(
 		)
 		Expect(err).NotTo(HaveOccurred())
 
+		// helm repositories and charts are cached in the test directory instead of the HELM_* environment
+		specResolver := internalv1alpha1.NewManifestSpecResolver(codec, true)
+		specResolver.ChartCache = helmCacheHome
 		reconciler = declarative.NewFromManager(
 			k8sManager, &v1alpha1.Manifest{},
-			declarative.WithSpecResolver(
-				internalv1alpha1.NewManifestSpecResolver(codec, true),
-			),
+			declarative.WithSpecResolver(specResolver),
 			declarative.WithPermanentConsistencyCheck(true),
 			declarative.WithRemoteTargetCluster(
 				func(_ context.Context, _ declarative.Object) (*types.ClusterInfo, error) {
@@ -156,7 +152,7 @@ var _ = BeforeSuite(
 			WithOptions(
 				controller.Options{
 					RateLimiter: internal.ManifestRateLimiter(
-						1*time.Second, 1000*time.Second,
+						internal.NewAnnotatedFailureRateLimiter(1*time.Second, 1000*time.Second),
 						30, 200,
 					),
 					MaxConcurrentReconciles: 1,
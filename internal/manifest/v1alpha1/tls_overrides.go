@@ -0,0 +1,69 @@
+package v1alpha1
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kyma-project/module-manager/pkg/labels"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var (
+	ErrInvalidCABundle     = errors.New("the CA bundle does not contain a PEM encoded certificate")
+	ErrInsecureTLSDisabled = errors.New("skipping the TLS verification of remote clusters is disabled")
+	ErrInvalidServerName   = errors.New("the TLS server name is not a valid DNS name")
+)
+
+// ApplyTLSOverrides overrides the TLS settings of config, which was created from the kubeconfig in secret,
+// with the annotations of secret, e.g. for clusters with IP based endpoints and custom CAs:
+// labels.TLSCABundle replaces the CA of the kubeconfig with a PEM encoded bundle,
+// labels.TLSServerName sets the name used for SNI and the verification of the server certificate, and
+// labels.TLSInsecureSkipVerify disables the verification, which is rejected unless allowInsecure is set.
+func ApplyTLSOverrides(ctx context.Context, secret *v1.Secret, config *rest.Config, allowInsecure bool) error {
+	annotations := secret.GetAnnotations()
+	logger := log.FromContext(ctx).WithValues("secret", secret.GetNamespace()+"/"+secret.GetName())
+
+	if caBundle, found := annotations[labels.TLSCABundle]; found {
+		if !x509.NewCertPool().AppendCertsFromPEM([]byte(caBundle)) {
+			return fmt.Errorf("%w: annotation %s", ErrInvalidCABundle, labels.TLSCABundle)
+		}
+		config.TLSClientConfig.CAData = []byte(caBundle)
+		config.TLSClientConfig.CAFile = ""
+		logger.Info("overriding CA of remote cluster", "annotation", labels.TLSCABundle)
+	}
+
+	if serverName, found := annotations[labels.TLSServerName]; found {
+		if errs := validation.IsDNS1123Subdomain(serverName); len(errs) > 0 {
+			return fmt.Errorf("%w: annotation %s: %s", ErrInvalidServerName, labels.TLSServerName,
+				strings.Join(errs, ", "))
+		}
+		config.TLSClientConfig.ServerName = serverName
+		logger.Info("overriding TLS server name of remote cluster", "serverName", serverName)
+	}
+
+	if value, found := annotations[labels.TLSInsecureSkipVerify]; found {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("parsing annotation %s: %w", labels.TLSInsecureSkipVerify, err)
+		}
+		if insecure {
+			if !allowInsecure {
+				return fmt.Errorf("%w: annotation %s", ErrInsecureTLSDisabled, labels.TLSInsecureSkipVerify)
+			}
+			// client-go rejects root certificates in combination with insecure connections
+			config.TLSClientConfig.Insecure = true
+			config.TLSClientConfig.CAData = nil
+			config.TLSClientConfig.CAFile = ""
+			logger.Info("skipping TLS verification of remote cluster",
+				"annotation", labels.TLSInsecureSkipVerify)
+		}
+	}
+	return nil
+}
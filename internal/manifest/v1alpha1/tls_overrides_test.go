@@ -0,0 +1,82 @@
+package v1alpha1_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+func selfSignedCA() string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).ToNot(HaveOccurred())
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "skr-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).ToNot(HaveOccurred())
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+var _ = Describe(
+	"test TLS overrides of kubeconfig secrets", func() {
+		secret := func(annotations map[string]string) *corev1.Secret {
+			return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Name: "skr-kyma", Namespace: "kcp-system", Annotations: annotations,
+			}}
+		}
+		It(
+			"should override the CA and server name of the kubeconfig", func() {
+				caBundle := selfSignedCA()
+				config := &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAFile: "/kubeconfig/ca.crt"}}
+				Expect(v1alpha1.ApplyTLSOverrides(ctx, secret(map[string]string{
+					labels.TLSCABundle:   caBundle,
+					labels.TLSServerName: "api.skr.example.com",
+				}), config, false)).To(Succeed())
+				Expect(config.TLSClientConfig).To(Equal(rest.TLSClientConfig{
+					CAData: []byte(caBundle), ServerName: "api.skr.example.com",
+				}))
+
+				Expect(v1alpha1.ApplyTLSOverrides(ctx, secret(map[string]string{
+					labels.TLSCABundle: "not a certificate",
+				}), config, false)).To(MatchError(v1alpha1.ErrInvalidCABundle))
+				Expect(v1alpha1.ApplyTLSOverrides(ctx, secret(map[string]string{
+					labels.TLSServerName: "10.0.0.1:443",
+				}), config, false)).To(MatchError(v1alpha1.ErrInvalidServerName))
+			},
+		)
+		It(
+			"should only skip the TLS verification if allowed", func() {
+				insecure := secret(map[string]string{labels.TLSInsecureSkipVerify: "true"})
+				config := &rest.Config{TLSClientConfig: rest.TLSClientConfig{CAData: []byte(selfSignedCA())}}
+				Expect(v1alpha1.ApplyTLSOverrides(ctx, insecure, config, false)).
+					To(MatchError(v1alpha1.ErrInsecureTLSDisabled))
+				Expect(config.TLSClientConfig.Insecure).To(BeFalse())
+
+				Expect(v1alpha1.ApplyTLSOverrides(ctx, insecure, config, true)).To(Succeed())
+				Expect(config.TLSClientConfig).To(Equal(rest.TLSClientConfig{Insecure: true}))
+
+				Expect(v1alpha1.ApplyTLSOverrides(ctx, secret(map[string]string{
+					labels.TLSInsecureSkipVerify: "maybe",
+				}), config, true)).ToNot(Succeed())
+			},
+		)
+	},
+)
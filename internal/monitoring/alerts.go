@@ -0,0 +1,113 @@
+package monitoring
+
+import (
+	"fmt"
+
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	PrometheusRuleFile = "prometheus/alerts.yaml"
+	ruleGroup          = "module-manager"
+	severityWarning    = "warning"
+	severityCritical   = "critical"
+	stuckWindow        = "30m"
+	errorWindow        = "15m"
+	corruptionWindow   = "10m"
+)
+
+type prometheusRule struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Metadata   ruleMetadata       `json:"metadata"`
+	Spec       prometheusRuleSpec `json:"spec"`
+}
+
+type ruleMetadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels"`
+}
+
+type prometheusRuleSpec struct {
+	Groups []ruleGroupSpec `json:"groups"`
+}
+
+type ruleGroupSpec struct {
+	Name  string `json:"name"`
+	Rules []rule `json:"rules"`
+}
+
+type rule struct {
+	Alert       string            `json:"alert"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// readyReconciles selects the modules with reconciliations that ended in the Ready state within window.
+func readyReconciles(window string) string {
+	return fmt.Sprintf("sum by (%s, %s) (increase(%s_count{%s=%q}[%s])) > 0",
+		declarative.MetricLabelModule, declarative.MetricLabelChannel, declarative.MetricReconcileDuration,
+		declarative.MetricLabelState, declarative.StateReady, window)
+}
+
+func rules() []rule {
+	return []rule{
+		{
+			Alert: "ModuleManagerManifestsInErrorState",
+			Expr: fmt.Sprintf("sum by (%s, %s) (increase(%s[%s])) > 0 unless %s",
+				declarative.MetricLabelModule, declarative.MetricLabelChannel, declarative.MetricReconcileErrors,
+				errorWindow, readyReconciles(errorWindow)),
+			For:    errorWindow,
+			Labels: map[string]string{"severity": severityCritical},
+			Annotations: map[string]string{
+				"summary": "Manifests of module {{ $labels.module }} ({{ $labels.channel }}) are in the Error state",
+				"description": "Reconciliations of module {{ $labels.module }} in channel {{ $labels.channel }} " +
+					"keep failing without becoming ready, check the lastOperation in the status of its Manifests.",
+			},
+		},
+		{
+			Alert: "ModuleManagerManifestsStuckProcessing",
+			Expr: fmt.Sprintf("sum by (%s, %s) (increase(%s_count{%s=%q}[%s])) > 0 unless %s",
+				declarative.MetricLabelModule, declarative.MetricLabelChannel, declarative.MetricReconcileDuration,
+				declarative.MetricLabelState, declarative.StateProcessing, stuckWindow, readyReconciles(stuckWindow)),
+			For:    stuckWindow,
+			Labels: map[string]string{"severity": severityWarning},
+			Annotations: map[string]string{
+				"summary": "Manifests of module {{ $labels.module }} ({{ $labels.channel }}) are stuck processing",
+				"description": "Manifests of module {{ $labels.module }} in channel {{ $labels.channel }} " +
+					"are processing for " + stuckWindow + " without becoming ready, " +
+					"e.g. because their resources never pass the ready check.",
+			},
+		},
+		{
+			Alert: "ModuleManagerManifestCacheCorrupted",
+			Expr: fmt.Sprintf("sum(increase(%s[%s])) > 0",
+				declarative.MetricManifestCacheCorruptions, corruptionWindow),
+			For:    "0m",
+			Labels: map[string]string{"severity": severityWarning},
+			Annotations: map[string]string{
+				"summary": "Cached manifests of the module manager are corrupted",
+				"description": "Cached manifests did not match their checksum and were rendered again, " +
+					"check the disk of the cache directory.",
+			},
+		},
+	}
+}
+
+// PrometheusRule returns the PrometheusRule with the alerts of the operator as YAML.
+func PrometheusRule() ([]byte, error) {
+	return yaml.Marshal(prometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata: ruleMetadata{
+			Name:      "controller-manager-alerts",
+			Namespace: "system",
+			Labels:    map[string]string{"app.kubernetes.io/component": "module-manager.kyma-project.io"},
+		},
+		Spec: prometheusRuleSpec{Groups: []ruleGroupSpec{{Name: ruleGroup, Rules: rules()}}},
+	})
+}
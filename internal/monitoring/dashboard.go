@@ -0,0 +1,206 @@
+// Package monitoring generates the Grafana dashboard and the PrometheusRule of the operator from the metric names
+// in code, so that both stay in lockstep with the metrics. The generated files are checked in below config and
+// are regenerated with "make monitoring".
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kyma-project/module-manager/internal"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+)
+
+const (
+	DashboardFile  = "grafana/module-manager-metrics.json"
+	dashboardUID   = "module-manager-metrics"
+	datasource     = "${DS_PROMETHEUS}"
+	schemaVersion  = 36
+	panelWidth     = 12
+	panelHeight    = 8
+	panelsPerRow   = 2
+	rateInterval   = "5m"
+	increaseWindow = "1h"
+)
+
+type dashboard struct {
+	Inputs        []dashboardInput `json:"__inputs"`
+	Title         string           `json:"title"`
+	UID           string           `json:"uid"`
+	Tags          []string         `json:"tags"`
+	Editable      bool             `json:"editable"`
+	SchemaVersion int              `json:"schemaVersion"`
+	Refresh       string           `json:"refresh"`
+	Time          dashboardTime    `json:"time"`
+	Panels        []panel          `json:"panels"`
+}
+
+type dashboardInput struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Type     string `json:"type"`
+	PluginID string `json:"pluginId"`
+}
+
+type dashboardTime struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type panel struct {
+	ID          int           `json:"id"`
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Type        string        `json:"type"`
+	Datasource  string        `json:"datasource"`
+	GridPos     gridPos       `json:"gridPos"`
+	FieldConfig fieldConfig   `json:"fieldConfig"`
+	Targets     []panelTarget `json:"targets"`
+}
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type fieldConfig struct {
+	Defaults fieldDefaults `json:"defaults"`
+}
+
+type fieldDefaults struct {
+	Unit string `json:"unit"`
+}
+
+type panelTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+// panelSpec describes a timeseries panel with a single query.
+type panelSpec struct {
+	title, description, expr, legend, unit string
+}
+
+func panelSpecs() []panelSpec {
+	return []panelSpec{
+		{
+			title:       "Reconciliations by state",
+			description: "Rate of finished reconciliations by the resulting state of the Manifest",
+			expr: fmt.Sprintf("sum by (%s) (rate(%s_count[%s]))",
+				declarative.MetricLabelState, declarative.MetricReconcileDuration, rateInterval),
+			legend: "{{" + declarative.MetricLabelState + "}}",
+			unit:   "ops",
+		},
+		{
+			title:       "Reconcile duration (p95) by module",
+			description: "95th percentile of the reconciliation duration by module",
+			expr: fmt.Sprintf("histogram_quantile(0.95, sum by (le, %s) (rate(%s_bucket[%s])))",
+				declarative.MetricLabelModule, declarative.MetricReconcileDuration, rateInterval),
+			legend: "{{" + declarative.MetricLabelModule + "}}",
+			unit:   "s",
+		},
+		{
+			title:       "Reconcile errors by module and channel",
+			description: "Rate of reconciliations that resulted in the Error state",
+			expr: fmt.Sprintf("sum by (%s, %s) (rate(%s[%s]))", declarative.MetricLabelModule,
+				declarative.MetricLabelChannel, declarative.MetricReconcileErrors, rateInterval),
+			legend: "{{" + declarative.MetricLabelModule + "}} ({{" + declarative.MetricLabelChannel + "}})",
+			unit:   "ops",
+		},
+		{
+			title:       "Metadata drifts by module",
+			description: "Consistency checks that found labels or annotations of rendered resources changed",
+			expr: fmt.Sprintf("sum by (%s) (increase(%s[%s]))",
+				declarative.MetricLabelModule, declarative.MetricMetadataDrifts, increaseWindow),
+			legend: "{{" + declarative.MetricLabelModule + "}}",
+			unit:   "short",
+		},
+		{
+			title:       "Recovered panics by source",
+			description: "Panics of renderers, transforms, hooks and checks that were converted into errors",
+			expr: fmt.Sprintf("sum by (%s) (increase(%s[%s]))",
+				declarative.MetricLabelSource, declarative.MetricRecoveredPanics, increaseWindow),
+			legend: "{{" + declarative.MetricLabelSource + "}}",
+			unit:   "short",
+		},
+		{
+			title:       "Abandoned responses by source",
+			description: "Worker responses that were no longer awaited because the operation ended",
+			expr: fmt.Sprintf("sum by (%s) (increase(%s[%s]))",
+				declarative.MetricLabelSource, declarative.MetricAbandonedResponses, increaseWindow),
+			legend: "{{" + declarative.MetricLabelSource + "}}",
+			unit:   "short",
+		},
+		{
+			title:       "Manifest cache corruptions",
+			description: "Cached manifests that did not match their checksum and were rendered again",
+			expr:        fmt.Sprintf("sum(increase(%s[%s]))", declarative.MetricManifestCacheCorruptions, increaseWindow),
+			legend:      "corruptions",
+			unit:        "short",
+		},
+		{
+			title:       "Cache hit ratio by cache",
+			description: "Share of lookups that were served from the cache",
+			expr: fmt.Sprintf("sum by (%[1]s) (rate(%[2]s[%[4]s])) / "+
+				"(sum by (%[1]s) (rate(%[2]s[%[4]s])) + sum by (%[1]s) (rate(%[3]s[%[4]s])))",
+				internal.MetricLabelCache, internal.MetricCacheHits, internal.MetricCacheMisses, rateInterval),
+			legend: "{{" + internal.MetricLabelCache + "}}",
+			unit:   "percentunit",
+		},
+		{
+			title:       "Cache size by cache",
+			description: "Size of the entries of caches that know the size of their entries",
+			expr:        fmt.Sprintf("sum by (%s) (%s)", internal.MetricLabelCache, internal.MetricCacheSize),
+			legend:      "{{" + internal.MetricLabelCache + "}}",
+			unit:        "bytes",
+		},
+		{
+			title:       "Waiting chart extractions",
+			description: "Chart extractions waiting for a free extraction slot or disk budget",
+			expr:        fmt.Sprintf("sum(%s)", internal.MetricExtractionsWaiting),
+			legend:      "waiting",
+			unit:        "short",
+		},
+	}
+}
+
+// Dashboard returns the Grafana dashboard of the operator metrics as indented JSON.
+func Dashboard() ([]byte, error) {
+	specs := panelSpecs()
+	panels := make([]panel, 0, len(specs))
+	for i, spec := range specs {
+		panels = append(panels, panel{
+			ID:          i + 1,
+			Title:       spec.title,
+			Description: spec.description,
+			Type:        "timeseries",
+			Datasource:  datasource,
+			GridPos: gridPos{
+				H: panelHeight, W: panelWidth,
+				X: (i % panelsPerRow) * panelWidth, Y: (i / panelsPerRow) * panelHeight,
+			},
+			FieldConfig: fieldConfig{Defaults: fieldDefaults{Unit: spec.unit}},
+			Targets:     []panelTarget{{Expr: spec.expr, LegendFormat: spec.legend, RefID: "A"}},
+		})
+	}
+	data, err := json.MarshalIndent(dashboard{
+		Inputs: []dashboardInput{{
+			Name: "DS_PROMETHEUS", Label: "Prometheus", Type: "datasource", PluginID: "prometheus",
+		}},
+		Title:         "Module Manager",
+		UID:           dashboardUID,
+		Tags:          []string{"module-manager"},
+		Editable:      true,
+		SchemaVersion: schemaVersion,
+		Refresh:       "30s",
+		Time:          dashboardTime{From: "now-6h", To: "now"},
+		Panels:        panels,
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
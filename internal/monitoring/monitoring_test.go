@@ -0,0 +1,31 @@
+package monitoring_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configDir = "../../config"
+
+// TestGeneratedFilesAreUpToDate fails if metrics changed without running "make monitoring".
+func TestGeneratedFilesAreUpToDate(t *testing.T) {
+	t.Parallel()
+	dashboard, err := monitoring.Dashboard()
+	require.NoError(t, err)
+	rule, err := monitoring.PrometheusRule()
+	require.NoError(t, err)
+
+	for file, generated := range map[string][]byte{
+		monitoring.DashboardFile:      dashboard,
+		monitoring.PrometheusRuleFile: rule,
+	} {
+		checkedIn, err := os.ReadFile(filepath.Join(configDir, file))
+		require.NoError(t, err)
+		assert.Equal(t, string(generated), string(checkedIn), "run make monitoring to regenerate %s", file)
+	}
+}
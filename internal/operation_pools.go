@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"context"
+	"sync/atomic"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Operation is the kind of work a reconciliation is expected to do.
+type Operation string
+
+const (
+	OperationInstall     Operation = "install"
+	OperationDelete      Operation = "delete"
+	OperationConsistency Operation = "consistency"
+)
+
+// OperationClassifier determines the Operation of the reconciliation of req before it runs.
+type OperationClassifier func(ctx context.Context, req ctrl.Request) Operation
+
+// OperationPoolReconciler splits the workers of a controller into a pool per Operation, so that a burst of
+// one operation cannot starve the others, e.g. deletions behind the installs of many new objects.
+// Each pool limits the number of active reconciliations of its Operation, operations without a limit
+// share all workers. Like the ConcurrencyLimitedReconciler, reconciliations above the limit of their pool
+// are requeued after the ConcurrencyLimitRequeueDelay, so that their workers are freed for other operations.
+type OperationPoolReconciler struct {
+	reconcile.Reconciler
+	classify OperationClassifier
+	pools    map[Operation]*operationPool
+}
+
+type operationPool struct {
+	limit  int
+	active atomic.Int64
+}
+
+// NewOperationPoolReconciler limits the active reconciliations of every Operation to its limit, a limit of 0
+// or an Operation without a limit are not limited. Limits should leave workers for the unlimited operations.
+func NewOperationPoolReconciler(
+	reconciler reconcile.Reconciler, classify OperationClassifier, limits map[Operation]int,
+) *OperationPoolReconciler {
+	pools := make(map[Operation]*operationPool, len(limits))
+	for operation, limit := range limits {
+		if limit > 0 {
+			pools[operation] = &operationPool{limit: limit}
+		}
+	}
+	return &OperationPoolReconciler{Reconciler: reconciler, classify: classify, pools: pools}
+}
+
+func (r *OperationPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	operation := r.classify(ctx, req)
+	pool, limited := r.pools[operation]
+	if !limited {
+		return r.Reconciler.Reconcile(ctx, req)
+	}
+	defer pool.active.Add(-1)
+	if active := pool.active.Add(1); active > int64(pool.limit) {
+		log.FromContext(ctx).V(DebugLogLevel).Info(
+			"operation pool exhausted, requeue reconciliation", "operation", operation, "limit", pool.limit,
+		)
+		return ctrl.Result{RequeueAfter: ConcurrencyLimitRequeueDelay}, nil
+	}
+	return r.Reconciler.Reconcile(ctx, req)
+}
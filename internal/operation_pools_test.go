@@ -0,0 +1,57 @@
+package internal_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_OperationPoolReconciler(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	blockingInstalls := reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if internal.Operation(req.Name) == internal.OperationInstall {
+			started <- struct{}{}
+			<-release
+		}
+		return ctrl.Result{}, nil
+	})
+	classify := func(ctx context.Context, req ctrl.Request) internal.Operation {
+		return internal.Operation(req.Name)
+	}
+	pools := internal.NewOperationPoolReconciler(blockingInstalls, classify, map[internal.Operation]int{
+		internal.OperationInstall:     1,
+		internal.OperationConsistency: 0,
+	})
+	request := func(operation internal.Operation) ctrl.Request {
+		return ctrl.Request{NamespacedName: types.NamespacedName{Name: string(operation)}}
+	}
+
+	done := make(chan ctrl.Result)
+	go func() {
+		result, _ := pools.Reconcile(context.Background(), request(internal.OperationInstall))
+		done <- result
+	}()
+	<-started
+
+	result, err := pools.Reconcile(context.Background(), request(internal.OperationInstall))
+	assertions.NoError(err)
+	assertions.Equal(internal.ConcurrencyLimitRequeueDelay, result.RequeueAfter, "installs are limited")
+
+	for _, operation := range []internal.Operation{internal.OperationDelete, internal.OperationConsistency} {
+		result, err = pools.Reconcile(context.Background(), request(operation))
+		assertions.NoError(err)
+		assertions.Equal(ctrl.Result{}, result, "%s is not limited by the exhausted install pool", operation)
+	}
+
+	close(release)
+	assertions.Equal(ctrl.Result{}, <-done)
+}
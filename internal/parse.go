@@ -8,14 +8,18 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"net/http"
 	"os"
 	"path"
+	"path/filepath"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 
 	"github.com/kyma-project/module-manager/pkg/types"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -28,7 +32,11 @@ func GetPathFromExtractedTarGz(
 	insecureRegistry bool,
 	keyChain authn.Keychain,
 ) (string, error) {
-	imageRef := fmt.Sprintf("%s/%s@%s", imageSpec.Repo, imageSpec.Name, imageSpec.Ref)
+	reference, err := ImageReference(imageSpec)
+	if err != nil {
+		return "", err
+	}
+	imageRef := reference.String()
 
 	// check existing dir
 	// if dir exists return existing dir
@@ -42,23 +50,72 @@ func GetPathFromExtractedTarGz(
 	}
 
 	// pull image layer
-	layer, err := pullLayer(ctx, insecureRegistry, imageRef, keyChain)
+	layer, err := pullLayer(ctx, insecureRegistry, reference, imageSpec.LayerSelector, ChartLayerTitle, keyChain)
 	if err != nil {
 		return "", err
 	}
 
+	// the compressed size is a lower bound of the disk space used by the extraction
+	size, err := layer.Size()
+	if err != nil {
+		size = 0
+	}
+	reservation, err := currentExtractionLimiter.Load().Acquire(ctx, size)
+	if err != nil {
+		return "", fmt.Errorf("extracting %s: %w", imageRef, err)
+	}
+	defer reservation.Release()
+
 	// uncompress chart to install path
 	blobReadCloser, err := layer.Compressed()
 	if err != nil {
-		return "", fmt.Errorf("fetching blob for compressed layer %s: %w", imageRef, err)
+		return "", &types.DownloadError{
+			Ref: imageRef, Err: fmt.Errorf("fetching blob for compressed layer: %w", err),
+		}
 	}
+	defer blobReadCloser.Close()
 
 	uncompressedStream, err := gzip.NewReader(blobReadCloser)
 	if err != nil {
-		return "", fmt.Errorf("failure in NewReader() while extracting TarGz %s: %w", imageRef, err)
+		return "", &types.DownloadError{
+			Ref: imageRef, Err: fmt.Errorf("failure in NewReader() while extracting TarGz: %w", err),
+		}
+	}
+	tarReader := tar.NewReader(reservation.Reader(uncompressedStream))
+	if err := extractTarGzContent(installPath, tarReader, imageRef); err != nil {
+		return "", err
+	}
+	reservation.Commit()
+	return installPath, nil
+}
+
+// extractTarGzContent extracts the chart into a temporary directory next to installPath and renames it
+// afterwards, so that an interrupted download never leaves a partially extracted chart in the cache.
+func extractTarGzContent(installPath string, tarReader *tar.Reader, layerReference string) error {
+	if err := os.MkdirAll(filepath.Dir(installPath), fs.ModePerm); err != nil {
+		return fmt.Errorf("failure in MkdirAll() while extracting TarGz %s: %w", layerReference, err)
+	}
+	tmpPath, err := os.MkdirTemp(filepath.Dir(installPath), filepath.Base(installPath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failure in MkdirTemp() while extracting TarGz %s: %w", layerReference, err)
+	}
+	// the temporary directory is only left behind if the extraction failed
+	defer os.RemoveAll(tmpPath)
+
+	if err := writeTarGzContent(tmpPath, tarReader, layerReference); err != nil {
+		return &types.DownloadError{Ref: layerReference, Err: err}
+	}
+	if err := os.Chmod(tmpPath, OthersReadExecuteFilePermission); err != nil {
+		return fmt.Errorf("failure in Chmod() while extracting TarGz %s: %w", layerReference, err)
 	}
-	tarReader := tar.NewReader(uncompressedStream)
-	return installPath, writeTarGzContent(installPath, tarReader, imageRef)
+	if err := os.Rename(tmpPath, installPath); err != nil {
+		if _, statErr := os.Stat(installPath); statErr == nil {
+			// the chart was extracted concurrently
+			return nil
+		}
+		return fmt.Errorf("failure in Rename() while extracting TarGz %s: %w", layerReference, err)
+	}
+	return nil
 }
 
 func writeTarGzContent(installPath string, tarReader *tar.Reader, layerReference string) error {
@@ -109,16 +166,10 @@ func handleExtractedHeaderFile(
 			return fmt.Errorf("failure in Mkdir() storage while extracting TarGz %s: %w", layerReference, err)
 		}
 	case tar.TypeReg:
-		filePath := path.Join(destinationPath, file)
-		//nolint:nosnakecase
-		outFile, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-		if err != nil {
-			return fmt.Errorf("file create failed while extracting TarGz %s: %w", layerReference, err)
-		}
-		if _, err := io.Copy(outFile, reader); err != nil {
-			return fmt.Errorf("file copy storage failed while extracting TarGz %s: %w", layerReference, err)
+		filePath := filepath.Join(destinationPath, file)
+		if err := WriteFileAtomically(filePath, reader, os.FileMode(header.Mode)); err != nil {
+			return fmt.Errorf("file write failed while extracting TarGz %s: %w", layerReference, err)
 		}
-		return outFile.Close()
 	default:
 		return fmt.Errorf(
 			"unknown type encountered while extracting TarGz %v in %s",
@@ -136,7 +187,12 @@ func DecodeUncompressedYAMLLayer(
 ) (interface{}, error) {
 	configFilePath := GetConfigFilePath(imageSpec)
 
-	imageRef := fmt.Sprintf("%s/%s@%s", imageSpec.Repo, imageSpec.Name, imageSpec.Ref)
+	reference, err := ImageReference(imageSpec)
+	if err != nil {
+		return nil, err
+	}
+	imageRef := reference.String()
+
 	// check existing file
 	decodedFile, err := GetYamlFileContent(configFilePath)
 	if err == nil {
@@ -147,23 +203,64 @@ func DecodeUncompressedYAMLLayer(
 
 	// proceed only if file was not found
 	// yaml is not compressed
-	layer, err := pullLayer(ctx, insecureRegistry, imageRef, keyChain)
+	layer, err := pullLayer(ctx, insecureRegistry, reference, imageSpec.LayerSelector, ConfigLayerTitle, keyChain)
 	if err != nil {
 		return nil, err
 	}
 	blob, err := layer.Uncompressed()
 	if err != nil {
-		return nil, fmt.Errorf("fetching blob for uncompressed layer %s: %w", imageRef, err)
+		return nil, &types.DownloadError{
+			Ref: imageRef, Err: fmt.Errorf("fetching blob for uncompressed layer: %w", err),
+		}
 	}
 
 	return writeYamlContent(blob, imageRef, configFilePath)
 }
 
-func pullLayer(ctx context.Context, insecureRegistry bool, imageRef string, keyChain authn.Keychain) (v1.Layer, error) {
+// pullLayer pulls the layer of reference, a tag references the layer of the tagged image that matches selector,
+// see SelectLayer.
+func pullLayer(
+	ctx context.Context, insecureRegistry bool, reference name.Reference, selector *types.LayerSelector,
+	defaultTitle string, keyChain authn.Keychain,
+) (v1.Layer, error) {
+	imageRef := reference.String()
+	if err := InjectFault(ctx, FaultPointRegistryPull); err != nil {
+		return nil, &types.DownloadError{Ref: imageRef, Err: err}
+	}
+	if _, isDigest := reference.(name.Digest); !isDigest {
+		digest, err := layerDigestOfTag(ctx, reference, selector, defaultTitle, insecureRegistry, keyChain)
+		if err != nil {
+			return nil, err
+		}
+		imageRef = reference.Context().Digest(digest).String()
+	}
+	var layer v1.Layer
+	var err error
 	if insecureRegistry {
-		return crane.PullLayer(imageRef, crane.Insecure, crane.WithAuthFromKeychain(keyChain))
+		layer, err = crane.PullLayer(imageRef, crane.Insecure, crane.WithAuthFromKeychain(keyChain),
+			crane.WithContext(ctx))
+	} else {
+		layer, err = crane.PullLayer(imageRef, crane.WithAuthFromKeychain(keyChain), crane.WithContext(ctx))
+	}
+	if err != nil {
+		return nil, &types.DownloadError{Ref: imageRef, Err: classifyRegistryError(err)}
+	}
+	return layer, nil
+}
+
+// classifyRegistryError marks registry responses that are not resolved by a retry with their failure class.
+func classifyRegistryError(err error) error {
+	var transportErr *transport.Error
+	if !errors.As(err, &transportErr) {
+		return err
+	}
+	switch transportErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return types.NewClassifiedError(types.ErrRegistryUnauthorized, err)
+	case http.StatusNotFound:
+		return types.NewClassifiedError(types.ErrChartNotFound, err)
 	}
-	return crane.PullLayer(imageRef, crane.WithAuthFromKeychain(keyChain), crane.WithContext(ctx))
+	return err
 }
 
 func writeYamlContent(blob io.ReadCloser, layerReference string, filePath string) (interface{}, error) {
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/kyma-project/module-manager/pkg/types"
+	yaml2 "sigs.k8s.io/yaml"
+)
+
+const (
+	provenanceFileSuffix = ".provenance.yaml"
+	// maxProvenanceTagLookups bounds the number of tags inspected to find the OCI manifest of a layer.
+	maxProvenanceTagLookups = 20
+
+	// BuildPipelineAnnotation can be set on OCI manifests to reference the pipeline run that built the chart.
+	BuildPipelineAnnotation = "operator.kyma-project.io/build-pipeline"
+
+	// ProvenanceFailureTTL is the time a failed provenance lookup is not repeated for the same chart,
+	// as every lookup can fetch up to maxProvenanceTagLookups OCI manifests.
+	ProvenanceFailureTTL = 10 * time.Minute
+)
+
+var ErrNoManifestForLayer = errors.New("no OCI manifest found")
+
+// provenanceFailures caches failed provenance lookups by the path of their chart until they expire.
+var provenanceFailures = struct { //nolint:gochecknoglobals
+	sync.Mutex
+	entries map[string]provenanceFailure
+}{entries: make(map[string]provenanceFailure)}
+
+type provenanceFailure struct {
+	err       error
+	expiresAt time.Time
+}
+
+// ProvenanceAnnotations are the OCI annotations that are propagated from the OCI manifest of a chart.
+var ProvenanceAnnotations = []string{ //nolint:gochecknoglobals
+	"org.opencontainers.image.source",
+	"org.opencontainers.image.revision",
+	"org.opencontainers.image.version",
+	"org.opencontainers.image.created",
+	"org.opencontainers.image.url",
+	BuildPipelineAnnotation,
+}
+
+// GetProvenance returns the ProvenanceAnnotations found on the OCI manifest referenced by imageSpec
+// and on the descriptor of the referenced layer inside of it, with layer annotations taking precedence.
+// As charts are usually referenced by their layer digest, which does not carry annotations on its own,
+// the most recent tags of the repository are inspected for an OCI manifest containing the layer.
+// The result is cached next to the extracted chart, failures are cached in memory for the ProvenanceFailureTTL.
+func GetProvenance(
+	ctx context.Context,
+	imageSpec types.ImageSpec,
+	insecureRegistry bool,
+	keyChain authn.Keychain,
+) (map[string]string, error) {
+	provenanceFilePath := GetFsChartPath(imageSpec) + provenanceFileSuffix
+	if cached, err := os.ReadFile(provenanceFilePath); err == nil {
+		provenance := map[string]string{}
+		if err := yaml2.Unmarshal(cached, &provenance); err != nil {
+			return nil, fmt.Errorf("reading cached provenance from %s: %w", provenanceFilePath, err)
+		}
+		return provenance, nil
+	}
+
+	provenanceFailures.Lock()
+	failure, failed := provenanceFailures.entries[provenanceFilePath]
+	provenanceFailures.Unlock()
+	if failed && time.Now().Before(failure.expiresAt) {
+		return nil, failure.err
+	}
+
+	provenance, err := lookupProvenance(ctx, imageSpec, insecureRegistry, keyChain)
+	provenanceFailures.Lock()
+	if err != nil {
+		provenanceFailures.entries[provenanceFilePath] = provenanceFailure{
+			err: err, expiresAt: time.Now().Add(ProvenanceFailureTTL),
+		}
+	} else {
+		delete(provenanceFailures.entries, provenanceFilePath)
+	}
+	provenanceFailures.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := yaml2.Marshal(provenance)
+	if err != nil {
+		return nil, err
+	}
+	return provenance, WriteToFile(provenanceFilePath, content)
+}
+
+func lookupProvenance(
+	ctx context.Context,
+	imageSpec types.ImageSpec,
+	insecureRegistry bool,
+	keyChain authn.Keychain,
+) (map[string]string, error) {
+	reference, err := ImageReference(imageSpec)
+	if err != nil {
+		return nil, err
+	}
+	imageRef := reference.String()
+	options := []crane.Option{crane.WithAuthFromKeychain(keyChain), crane.WithContext(ctx)}
+	if insecureRegistry {
+		options = append(options, crane.Insecure)
+	}
+	rawManifest, err := findManifestForRef(reference, imageSpec.Ref, options)
+	if err != nil {
+		return nil, fmt.Errorf("fetching OCI manifest for provenance of %s: %w", imageRef, err)
+	}
+
+	provenance, err := ParseProvenance(rawManifest, imageSpec.Ref)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OCI manifest for provenance of %s: %w", imageRef, err)
+	}
+	return provenance, nil
+}
+
+// findManifestForRef returns the OCI manifest referenced by reference or, if it references a layer,
+// the manifest of one of the most recent tags containing the layer.
+func findManifestForRef(reference name.Reference, layerDigest string, options []crane.Option) ([]byte, error) {
+	repository := reference.Context().String()
+	if rawManifest, err := crane.Manifest(reference.String(), options...); err == nil {
+		return rawManifest, nil
+	}
+
+	tags, err := crane.ListTags(repository, options...)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range RecentTags(tags, maxProvenanceTagLookups) {
+		rawManifest, err := crane.Manifest(reference.Context().Tag(tag).String(), options...)
+		if err != nil {
+			continue
+		}
+		manifest, err := v1.ParseManifest(bytes.NewReader(rawManifest))
+		if err != nil {
+			continue
+		}
+		for _, layer := range manifest.Layers {
+			if layer.Digest.String() == layerDigest {
+				return rawManifest, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: layer %s in %s", ErrNoManifestForLayer, layerDigest, repository)
+}
+
+// RecentTags returns up to limit of the most recent tags, semantic versions ordered from the highest version
+// followed by all other tags in reverse order of the registry, which lists tags lexically.
+func RecentTags(tags []string, limit int) []string {
+	type versionedTag struct {
+		tag     string
+		version *semver.Version
+	}
+	versioned := make([]versionedTag, 0, len(tags))
+	var others []string
+	for i := len(tags) - 1; i >= 0; i-- {
+		if version, err := semver.NewVersion(tags[i]); err == nil {
+			versioned = append(versioned, versionedTag{tag: tags[i], version: version})
+		} else {
+			others = append(others, tags[i])
+		}
+	}
+	sort.SliceStable(versioned, func(i, j int) bool {
+		return versioned[i].version.GreaterThan(versioned[j].version)
+	})
+
+	recent := make([]string, 0, limit)
+	for _, tag := range versioned {
+		recent = append(recent, tag.tag)
+	}
+	recent = append(recent, others...)
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
+// ParseProvenance extracts the ProvenanceAnnotations from a raw OCI manifest.
+// If layerDigest matches one of the layers, its annotations override the ones of the manifest.
+func ParseProvenance(rawManifest []byte, layerDigest string) (map[string]string, error) {
+	manifest, err := v1.ParseManifest(bytes.NewReader(rawManifest))
+	if err != nil {
+		return nil, err
+	}
+
+	annotations := make(map[string]string, len(manifest.Annotations))
+	for key, value := range manifest.Annotations {
+		annotations[key] = value
+	}
+	for _, layer := range manifest.Layers {
+		if layer.Digest.String() == layerDigest {
+			for key, value := range layer.Annotations {
+				annotations[key] = value
+			}
+		}
+	}
+
+	provenance := make(map[string]string)
+	for _, key := range ProvenanceAnnotations {
+		if value, found := annotations[key]; found && value != "" {
+			provenance[key] = value
+		}
+	}
+	return provenance, nil
+}
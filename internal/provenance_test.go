@@ -0,0 +1,84 @@
+package internal_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProvenance(t *testing.T) {
+	t.Parallel()
+	layerDigest := "sha256:6e9f7f2a0c6d3b0e44e4a4b2d3b1d4b5a8f1c2d3e4f5a6b7c8d9e0f1a2b3c4d5"
+	rawManifest := []byte(`{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.manifest.v1+json",
+  "config": {
+    "mediaType": "application/vnd.oci.image.config.v1+json",
+    "size": 2,
+    "digest": "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+  },
+  "layers": [{
+    "mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+    "size": 1024,
+    "digest": "` + layerDigest + `",
+    "annotations": {"org.opencontainers.image.revision": "layer-revision"}
+  }],
+  "annotations": {
+    "org.opencontainers.image.source": "https://github.com/kyma-project/module",
+    "org.opencontainers.image.revision": "manifest-revision",
+    "operator.kyma-project.io/build-pipeline": "release-42",
+    "unrelated": "value"
+  }
+}`)
+
+	provenance, err := internal.ParseProvenance(rawManifest, layerDigest)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"org.opencontainers.image.source":   "https://github.com/kyma-project/module",
+		"org.opencontainers.image.revision": "layer-revision",
+		internal.BuildPipelineAnnotation:    "release-42",
+	}, provenance)
+
+	provenance, err = internal.ParseProvenance(rawManifest, "sha256:other")
+	assert.NoError(t, err)
+	assert.Equal(t, "manifest-revision", provenance["org.opencontainers.image.revision"])
+}
+
+func TestRecentTags(t *testing.T) {
+	t.Parallel()
+	tags := []string{"1.10.0", "1.2.0", "1.9.1", "latest", "main", "v2.0.0-rc.1"}
+	assert.Equal(t, []string{"v2.0.0-rc.1", "1.10.0", "1.9.1", "1.2.0", "main", "latest"},
+		internal.RecentTags(tags, 10))
+	assert.Equal(t, []string{"v2.0.0-rc.1", "1.10.0"}, internal.RecentTags(tags, 2))
+}
+
+func TestGetProvenanceCachesFailures(t *testing.T) {
+	t.Parallel()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	imageSpec := types.ImageSpec{
+		Repo: strings.TrimPrefix(server.URL, "http://"), Name: "provenance-failure", Type: types.OciRefType,
+		Ref: "sha256:6e9f7f2a0c6d3b0e44e4a4b2d3b1d4b5a8f1c2d3e4f5a6b7c8d9e0f1a2b3c4d5",
+	}
+	_, err := internal.GetProvenance(context.Background(), imageSpec, true, authn.DefaultKeychain)
+	assert.Error(t, err)
+	failedRequests := requests.Load()
+	assert.Positive(t, failedRequests)
+
+	_, cachedErr := internal.GetProvenance(context.Background(), imageSpec, true, authn.DefaultKeychain)
+	assert.Equal(t, err, cachedErr)
+	assert.Equal(t, failedRequests, requests.Load(), "failures are not looked up again within the TTL")
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultQueueStatePath is the path of the QueueTracker on the metrics server.
+const DefaultQueueStatePath = "/queue"
+
+// QueueState is a snapshot of the reconciliation queue for capacity planning of replicas and workers.
+type QueueState struct {
+	// Waiting counts objects that are due for reconciliation but not yet picked up by a worker.
+	Waiting int `json:"waiting"`
+	// OldestWaitingSeconds is the time the longest waiting object has been due.
+	OldestWaitingSeconds float64 `json:"oldestWaitingSeconds"`
+	// Scheduled counts objects that are requeued for a later reconciliation, e.g. the next consistency check.
+	Scheduled int `json:"scheduled"`
+	// InFlight counts active reconciliations per target cluster.
+	InFlight map[string]int `json:"inFlight"`
+}
+
+// QueueTracker observes objects from the event that enqueues them until a worker reconciles them,
+// as well as the active reconciliations per target cluster. Events are observed with Predicate
+// and reconciliations with Reconciler, the State is served over HTTP.
+// Requeues are tracked from the result of the reconciliation, errors are treated as immediate requeue
+// although the rate limiter of the queue may delay them.
+type QueueTracker struct {
+	// ClusterOf determines the target cluster of an observed object.
+	ClusterOf func(client.Object) string
+
+	mu       sync.Mutex
+	due      map[client.ObjectKey]time.Time
+	clusters map[client.ObjectKey]string
+	inFlight map[string]int
+}
+
+func NewQueueTracker(clusterOf func(client.Object) string) *QueueTracker {
+	return &QueueTracker{
+		ClusterOf: clusterOf,
+		due:       make(map[client.ObjectKey]time.Time),
+		clusters:  make(map[client.ObjectKey]string),
+		inFlight:  make(map[string]int),
+	}
+}
+
+// Enqueued records that key is due for reconciliation at the given time. Earlier due times take precedence,
+// as the queue deduplicates objects.
+func (t *QueueTracker) Enqueued(key client.ObjectKey, due time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if current, found := t.due[key]; !found || due.Before(current) {
+		t.due[key] = due
+	}
+}
+
+func (t *QueueTracker) observe(obj client.Object) bool {
+	key := client.ObjectKeyFromObject(obj)
+	if t.ClusterOf != nil {
+		t.mu.Lock()
+		t.clusters[key] = t.ClusterOf(obj)
+		t.mu.Unlock()
+	}
+	t.Enqueued(key, time.Now())
+	return true
+}
+
+// Predicate observes all events of the watched object without filtering them.
+func (t *QueueTracker) Predicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return t.observe(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return t.observe(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return t.observe(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return t.observe(e.Object) },
+	}
+}
+
+// Reconciler tracks the reconciliations of next.
+func (t *QueueTracker) Reconciler(next reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+		cluster := t.started(req.NamespacedName)
+		result, err := next.Reconcile(ctx, req)
+		t.finished(req.NamespacedName, cluster, result, err)
+		return result, err
+	})
+}
+
+func (t *QueueTracker) started(key client.ObjectKey) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.due, key)
+	cluster := t.clusters[key]
+	t.inFlight[cluster]++
+	return cluster
+}
+
+func (t *QueueTracker) finished(key client.ObjectKey, cluster string, result ctrl.Result, err error) {
+	t.mu.Lock()
+	if t.inFlight[cluster]--; t.inFlight[cluster] <= 0 {
+		delete(t.inFlight, cluster)
+	}
+	t.mu.Unlock()
+
+	switch {
+	case err != nil || (result.Requeue && result.RequeueAfter == 0):
+		t.Enqueued(key, time.Now())
+	case result.RequeueAfter > 0:
+		t.Enqueued(key, time.Now().Add(result.RequeueAfter))
+	default:
+		t.mu.Lock()
+		delete(t.clusters, key)
+		t.mu.Unlock()
+	}
+}
+
+// State returns a snapshot of the tracked queue.
+func (t *QueueTracker) State() QueueState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	state := QueueState{InFlight: make(map[string]int, len(t.inFlight))}
+	for _, due := range t.due {
+		if due.After(now) {
+			state.Scheduled++
+			continue
+		}
+		state.Waiting++
+		if waiting := now.Sub(due).Seconds(); waiting > state.OldestWaitingSeconds {
+			state.OldestWaitingSeconds = waiting
+		}
+	}
+	for cluster, active := range t.inFlight {
+		state.InFlight[cluster] = active
+	}
+	return state
+}
+
+func (t *QueueTracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.State()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,69 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var errReconcile = errors.New("reconcile failed")
+
+func TestQueueTracker(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	tracker := internal.NewQueueTracker(func(obj client.Object) string { return obj.GetLabels()["cluster"] })
+
+	first := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "first", Namespace: "kcp-system", Labels: map[string]string{"cluster": "kyma"},
+	}}
+	second := first.DeepCopy()
+	second.SetName("second")
+	asserts.True(tracker.Predicate().Create(event.CreateEvent{Object: first}))
+	asserts.True(tracker.Predicate().Update(event.UpdateEvent{ObjectOld: second, ObjectNew: second}))
+	tracker.Enqueued(client.ObjectKey{Name: "scheduled", Namespace: "kcp-system"}, time.Now().Add(time.Hour))
+
+	state := tracker.State()
+	asserts.Equal(2, state.Waiting)
+	asserts.Equal(1, state.Scheduled)
+	asserts.Empty(state.InFlight)
+
+	var inFlight internal.QueueState
+	reconciler := tracker.Reconciler(reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		inFlight = tracker.State()
+		return ctrl.Result{RequeueAfter: time.Hour}, nil
+	}))
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(first)})
+	asserts.NoError(err)
+	asserts.Equal(map[string]int{"kyma": 1}, inFlight.InFlight)
+	asserts.Equal(1, inFlight.Waiting)
+
+	state = tracker.State()
+	asserts.Equal(1, state.Waiting)
+	asserts.Equal(2, state.Scheduled, "requeued after the consistency check interval")
+	asserts.Empty(state.InFlight)
+
+	failing := tracker.Reconciler(reconcile.Func(func(context.Context, ctrl.Request) (ctrl.Result, error) {
+		return ctrl.Result{}, errReconcile
+	}))
+	_, err = failing.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(second)})
+	asserts.ErrorIs(err, errReconcile)
+	asserts.Equal(1, tracker.State().Waiting, "failed reconciliations are waiting again")
+
+	recorder := httptest.NewRecorder()
+	tracker.ServeHTTP(recorder, httptest.NewRequest("GET", internal.DefaultQueueStatePath, nil))
+	var served internal.QueueState
+	asserts.NoError(json.NewDecoder(recorder.Body).Decode(&served))
+	asserts.Equal(1, served.Waiting)
+	asserts.Equal(2, served.Scheduled)
+}
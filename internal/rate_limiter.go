@@ -1,19 +1,141 @@
 package internal
 
 import (
+	"math"
+	"sync"
 	"time"
 
+	"github.com/kyma-project/module-manager/pkg/labels"
 	"golang.org/x/time/rate"
 	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 )
 
 func ManifestRateLimiter(
-	failureBaseDelay time.Duration, failureMaxDelay time.Duration,
+	failureRateLimiter ratelimiter.RateLimiter,
 	frequency int, burst int,
 ) ratelimiter.RateLimiter {
 	return workqueue.NewMaxOfRateLimiter(
-		workqueue.NewItemExponentialFailureRateLimiter(failureBaseDelay, failureMaxDelay),
+		failureRateLimiter,
 		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(frequency), burst)},
 	)
 }
+
+// MinAnnotatedFailureDelay is the lowest delay the failure delay annotations can set, so that an annotation
+// like "1ns" cannot requeue a failing object in a busy loop.
+const MinAnnotatedFailureDelay = time.Second
+
+type failureDelays struct {
+	base, max time.Duration
+}
+
+// AnnotatedFailureRateLimiter is an exponential failure rate limiter whose base and maximum delay can be
+// overridden per object with the labels.FailureBaseDelay and labels.FailureMaxDelay annotations,
+// e.g. for a module with a known flaky external dependency. As the queue only passes the request to the
+// rate limiter, the annotations are observed from the events of the objects with Predicate.
+// Annotations that are not valid durations are ignored, annotated delays are raised to MinAnnotatedFailureDelay
+// and the maximum delay to the base delay.
+type AnnotatedFailureRateLimiter struct {
+	defaults failureDelays
+
+	mu        sync.Mutex
+	failures  map[any]int
+	overrides map[client.ObjectKey]failureDelays
+}
+
+func NewAnnotatedFailureRateLimiter(baseDelay, maxDelay time.Duration) *AnnotatedFailureRateLimiter {
+	return &AnnotatedFailureRateLimiter{
+		defaults:  failureDelays{base: baseDelay, max: maxDelay},
+		failures:  make(map[any]int),
+		overrides: make(map[client.ObjectKey]failureDelays),
+	}
+}
+
+func (r *AnnotatedFailureRateLimiter) When(item any) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delays := r.delaysOf(item)
+	exp := r.failures[item]
+	r.failures[item]++
+
+	backoff := float64(delays.base.Nanoseconds()) * math.Pow(2, float64(exp)) //nolint:gomnd
+	if backoff > math.MaxInt64 || time.Duration(backoff) > delays.max {
+		return delays.max
+	}
+	return time.Duration(backoff)
+}
+
+func (r *AnnotatedFailureRateLimiter) Forget(item any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, item)
+}
+
+func (r *AnnotatedFailureRateLimiter) NumRequeues(item any) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[item]
+}
+
+func (r *AnnotatedFailureRateLimiter) delaysOf(item any) failureDelays {
+	req, ok := item.(ctrl.Request)
+	if !ok {
+		return r.defaults
+	}
+	if delays, found := r.overrides[req.NamespacedName]; found {
+		return delays
+	}
+	return r.defaults
+}
+
+func (r *AnnotatedFailureRateLimiter) observe(obj client.Object) bool {
+	delays, overridden := r.defaults, false
+	annotations := obj.GetAnnotations()
+	if baseDelay, err := time.ParseDuration(annotations[labels.FailureBaseDelay]); err == nil {
+		delays.base, overridden = atLeast(baseDelay, MinAnnotatedFailureDelay), true
+	}
+	if maxDelay, err := time.ParseDuration(annotations[labels.FailureMaxDelay]); err == nil {
+		delays.max, overridden = atLeast(maxDelay, MinAnnotatedFailureDelay), true
+	}
+	if overridden {
+		delays.max = atLeast(delays.max, delays.base)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if overridden {
+		r.overrides[client.ObjectKeyFromObject(obj)] = delays
+	} else {
+		delete(r.overrides, client.ObjectKeyFromObject(obj))
+	}
+	return true
+}
+
+func atLeast(delay, minimum time.Duration) time.Duration {
+	if delay < minimum {
+		return minimum
+	}
+	return delay
+}
+
+func (r *AnnotatedFailureRateLimiter) forgetObject(obj client.Object) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.overrides, client.ObjectKeyFromObject(obj))
+	return true
+}
+
+// Predicate observes the annotations of all events of the watched object without filtering them.
+func (r *AnnotatedFailureRateLimiter) Predicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return r.observe(e.Object) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return r.observe(e.ObjectNew) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return r.forgetObject(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return r.observe(e.Object) },
+	}
+}
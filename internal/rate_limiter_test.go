@@ -0,0 +1,73 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestAnnotatedFailureRateLimiter(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	limiter := internal.NewAnnotatedFailureRateLimiter(time.Second, 4*time.Second)
+
+	flaky := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "flaky", Namespace: "kcp-system", Annotations: map[string]string{
+			labels.FailureBaseDelay: "10s", labels.FailureMaxDelay: "1m",
+		},
+	}}
+	regular := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "regular", Namespace: "kcp-system", Annotations: map[string]string{
+			labels.FailureMaxDelay: "invalid",
+		},
+	}}
+	asserts.True(limiter.Predicate().Create(event.CreateEvent{Object: flaky}))
+	asserts.True(limiter.Predicate().Create(event.CreateEvent{Object: regular}))
+
+	flakyReq := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(flaky)}
+	regularReq := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(regular)}
+
+	asserts.Equal(10*time.Second, limiter.When(flakyReq))
+	asserts.Equal(20*time.Second, limiter.When(flakyReq))
+	asserts.Equal(40*time.Second, limiter.When(flakyReq))
+	asserts.Equal(time.Minute, limiter.When(flakyReq))
+	asserts.Equal(4, limiter.NumRequeues(flakyReq))
+
+	asserts.Equal(time.Second, limiter.When(regularReq), "invalid annotations keep the defaults")
+	asserts.Equal(2*time.Second, limiter.When(regularReq))
+	asserts.Equal(4*time.Second, limiter.When(regularReq))
+	asserts.Equal(4*time.Second, limiter.When(regularReq))
+
+	limiter.Forget(flakyReq)
+	asserts.Zero(limiter.NumRequeues(flakyReq))
+
+	busy := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "busy", Namespace: "kcp-system", Annotations: map[string]string{
+			labels.FailureBaseDelay: "1ns", labels.FailureMaxDelay: "-1s",
+		},
+	}}
+	asserts.True(limiter.Predicate().Create(event.CreateEvent{Object: busy}))
+	busyReq := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(busy)}
+	asserts.Equal(internal.MinAnnotatedFailureDelay, limiter.When(busyReq), "annotated delays have a minimum")
+	asserts.Equal(internal.MinAnnotatedFailureDelay, limiter.When(busyReq))
+
+	lowMax := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "low-max", Namespace: "kcp-system", Annotations: map[string]string{
+			labels.FailureBaseDelay: "10s", labels.FailureMaxDelay: "2s",
+		},
+	}}
+	asserts.True(limiter.Predicate().Create(event.CreateEvent{Object: lowMax}))
+	lowMaxReq := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(lowMax)}
+	asserts.Equal(10*time.Second, limiter.When(lowMaxReq), "the maximum delay is not below the base delay")
+
+	flaky.SetAnnotations(nil)
+	asserts.True(limiter.Predicate().Update(event.UpdateEvent{ObjectOld: flaky, ObjectNew: flaky}))
+	asserts.Equal(time.Second, limiter.When(flakyReq), "removed annotations restore the defaults")
+}
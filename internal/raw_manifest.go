@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/kyma-project/module-manager/pkg/types"
+)
+
+const (
+	rawManifestFolder = "raw-manifests"
+	rawManifestFile   = "manifest.yaml"
+	sha256Prefix      = "sha256:"
+	// RawManifestLayerTitle is the default title of the raw manifest layer of an image with several layers.
+	RawManifestLayerTitle = "raw-manifest"
+
+	// RawManifestDownloadTimeout bounds downloads of raw manifests if no HTTPClient is configured.
+	RawManifestDownloadTimeout = time.Minute
+	// MaxRawManifestBytes is the largest raw manifest that is downloaded.
+	MaxRawManifestBytes = 32 << 20
+)
+
+var (
+	ErrRawManifestDigestMismatch = errors.New("raw manifest digest mismatch")
+	ErrRawManifestTooLarge       = errors.New("raw manifest too large")
+)
+
+// RawManifestStore keeps plain multi-document YAML manifests content addressed in CacheDir, so that they are
+// applied from disk without rendering and their digest identifies the revision of the install.
+type RawManifestStore struct {
+	CacheDir string
+	// HTTPClient downloads manifests, a client with the RawManifestDownloadTimeout is used if nil.
+	HTTPClient *http.Client
+
+	mu sync.Mutex
+	// downloads are the ETag and digest of the last download per URL, so that unchanged manifests are not
+	// downloaded again.
+	downloads map[string]rawManifestDownload
+}
+
+type rawManifestDownload struct {
+	etag, digest string
+}
+
+// Store writes content to the store and returns its path and digest, e.g. "sha256:<hex>".
+// If digest is set, content has to match it.
+func (s *RawManifestStore) Store(content []byte, digest string) (string, string, error) {
+	sum := sha256.Sum256(content)
+	actual := sha256Prefix + hex.EncodeToString(sum[:])
+	if digest != "" && !strings.EqualFold(digest, actual) {
+		return "", "", fmt.Errorf("%w: expected %s, got %s", ErrRawManifestDigestMismatch, digest, actual)
+	}
+	path := s.path(actual)
+	if _, err := os.Stat(path); err == nil {
+		return path, actual, nil
+	}
+	if err := WriteToFile(path, content); err != nil {
+		return "", "", err
+	}
+	return path, actual, nil
+}
+
+// Download fetches the manifest at url. Manifests with a pinned digest are served from the store
+// without any request once they were downloaded, other manifests are requested with the ETag of their
+// last download and served from the store if they did not change.
+func (s *RawManifestStore) Download(ctx context.Context, url, digest string) (string, string, error) {
+	if digest != "" {
+		if path := s.path(strings.ToLower(digest)); fileExists(path) {
+			return path, strings.ToLower(digest), nil
+		}
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", err
+	}
+	s.mu.Lock()
+	previous, downloaded := s.downloads[url]
+	s.mu.Unlock()
+	if downloaded && previous.etag != "" && fileExists(s.path(previous.digest)) {
+		request.Header.Set("If-None-Match", previous.etag)
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: RawManifestDownloadTimeout}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", "", &types.DownloadError{Ref: redactURL(url), Err: err}
+	}
+	defer response.Body.Close()
+	switch {
+	case response.StatusCode == http.StatusNotModified && request.Header.Get("If-None-Match") != "":
+		if digest != "" && !strings.EqualFold(digest, previous.digest) {
+			return "", "", fmt.Errorf("%w: expected %s, got %s", ErrRawManifestDigestMismatch, digest, previous.digest)
+		}
+		return s.path(previous.digest), previous.digest, nil
+	case response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden:
+		return "", "", &types.DownloadError{Ref: redactURL(url),
+			Err: fmt.Errorf("%w: %s", types.ErrRegistryUnauthorized, response.Status)}
+	case response.StatusCode == http.StatusNotFound:
+		return "", "", &types.DownloadError{Ref: redactURL(url),
+			Err: fmt.Errorf("%w: %s", types.ErrChartNotFound, response.Status)}
+	case response.StatusCode != http.StatusOK:
+		return "", "", &types.DownloadError{Ref: redactURL(url),
+			Err: fmt.Errorf("unexpected status %s", response.Status)}
+	}
+
+	content, err := io.ReadAll(io.LimitReader(response.Body, MaxRawManifestBytes+1))
+	if err != nil {
+		return "", "", &types.DownloadError{Ref: redactURL(url), Err: err}
+	}
+	if len(content) > MaxRawManifestBytes {
+		return "", "", fmt.Errorf("%w: %s exceeds %d bytes", ErrRawManifestTooLarge, redactURL(url), MaxRawManifestBytes)
+	}
+	path, stored, err := s.Store(content, digest)
+	if err != nil {
+		return "", "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.downloads == nil {
+		s.downloads = make(map[string]rawManifestDownload)
+	}
+	s.downloads[url] = rawManifestDownload{etag: response.Header.Get("ETag"), digest: stored}
+	return path, stored, nil
+}
+
+// Pull pulls the uncompressed layer of imageSpec, whose Ref has to be the digest of the layer,
+// see NormalizeImageSpec. Pulled layers are served from the store.
+func (s *RawManifestStore) Pull(
+	ctx context.Context, imageSpec types.ImageSpec, insecureRegistry bool, keyChain authn.Keychain, digest string,
+) (string, string, error) {
+	reference, err := ImageReference(imageSpec)
+	if err != nil {
+		return "", "", err
+	}
+	layerPath := filepath.Join(s.CacheDir, rawManifestFolder, "layers",
+		EncodePathSegment(fmt.Sprintf("%s-%s", imageSpec.Name, imageSpec.Ref)))
+	if content, err := os.ReadFile(layerPath); err == nil {
+		return s.Store(content, digest)
+	}
+
+	layer, err := pullLayer(ctx, insecureRegistry, reference, imageSpec.LayerSelector, RawManifestLayerTitle, keyChain)
+	if err != nil {
+		return "", "", err
+	}
+	blob, err := layer.Uncompressed()
+	if err != nil {
+		return "", "", &types.DownloadError{
+			Ref: reference.String(), Err: fmt.Errorf("fetching blob for uncompressed layer: %w", err),
+		}
+	}
+	defer blob.Close()
+	content, err := io.ReadAll(blob)
+	if err != nil {
+		return "", "", &types.DownloadError{Ref: reference.String(), Err: err}
+	}
+	if err := WriteToFile(layerPath, content); err != nil {
+		return "", "", err
+	}
+	return s.Store(content, digest)
+}
+
+func (s *RawManifestStore) path(digest string) string {
+	return filepath.Join(s.CacheDir, rawManifestFolder, EncodePathSegment(digest), rawManifestFile)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
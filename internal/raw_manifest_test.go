@@ -0,0 +1,86 @@
+package internal_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRawManifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: keda
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: keda-config
+  namespace: keda
+`
+
+func TestRawManifestStore(t *testing.T) {
+	t.Parallel()
+	sum := sha256.Sum256([]byte(testRawManifest))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	var downloads, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(testRawManifest))
+	}))
+	defer server.Close()
+	store := &internal.RawManifestStore{CacheDir: t.TempDir()}
+
+	path, stored, err := store.Store([]byte(testRawManifest), "")
+	require.NoError(t, err)
+	assert.Equal(t, digest, stored)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	objects, err := internal.ParseManifestStringToObjects(string(content))
+	require.NoError(t, err)
+	assert.Len(t, objects.Items, 2)
+
+	downloaded, downloadedDigest, err := store.Download(context.Background(), server.URL, "")
+	require.NoError(t, err)
+	assert.Equal(t, path, downloaded, "manifests are stored by their content")
+	assert.Equal(t, digest, downloadedDigest)
+
+	_, _, err = store.Download(context.Background(), server.URL, digest)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), downloads.Load(), "pinned manifests are served from the store")
+
+	downloaded, downloadedDigest, err = store.Download(context.Background(), server.URL, "")
+	require.NoError(t, err)
+	assert.Equal(t, path, downloaded)
+	assert.Equal(t, digest, downloadedDigest)
+	assert.Equal(t, int32(1), downloads.Load())
+	assert.Equal(t, int32(1), notModified.Load(), "unchanged manifests are revalidated by their etag")
+
+	_, _, err = store.Store([]byte(testRawManifest), "sha256:"+hex.EncodeToString(make([]byte, sha256.Size)))
+	assert.ErrorIs(t, err, internal.ErrRawManifestDigestMismatch)
+}
+
+func TestRawManifestStoreLimitsDownloads(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(make([]byte, internal.MaxRawManifestBytes+1))
+	}))
+	defer server.Close()
+
+	_, _, err := (&internal.RawManifestStore{CacheDir: t.TempDir()}).Download(context.Background(), server.URL, "")
+	assert.ErrorIs(t, err, internal.ErrRawManifestTooLarge)
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactedValue replaces values of sensitive keys.
+const RedactedValue = "***"
+
+const defaultSensitiveKeys = `password|passwd|secret|token|credential|api[_-]?key|private[_-]?key|client[_-]?config|` +
+	`kubeconfig`
+
+// redaction determines sensitive keys by a deny-list of key fragments and an allow-list of keys
+// that are never masked, e.g. "tokenTTL" or "secretName".
+type redaction struct {
+	sensitiveKey *regexp.Regexp
+	// sensitiveAssignment matches key=value pairs as used in overrides and "key": value pairs as used in JSON.
+	// Unquoted keys followed by a colon are not matched, as error messages use them for context, e.g. "fetching
+	// kubeconfig: connection refused".
+	sensitiveAssignment *regexp.Regexp
+	allowed             map[string]bool
+}
+
+var currentRedaction atomic.Pointer[redaction] //nolint:gochecknoglobals
+
+//nolint:gochecknoinits
+func init() {
+	currentRedaction.Store(newRedaction(nil, nil))
+}
+
+func newRedaction(deniedKeys, allowedKeys []string) *redaction {
+	sensitiveKeys := defaultSensitiveKeys
+	for _, key := range deniedKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			sensitiveKeys += "|" + regexp.QuoteMeta(key)
+		}
+	}
+	allowed := make(map[string]bool, len(allowedKeys))
+	for _, key := range allowedKeys {
+		if key = strings.TrimSpace(key); key != "" {
+			allowed[strings.ToLower(key)] = true
+		}
+	}
+	return &redaction{
+		sensitiveKey: regexp.MustCompile(`(?i)(` + sensitiveKeys + `)`),
+		sensitiveAssignment: regexp.MustCompile(
+			`(?i)([\w.-]*(?:` + sensitiveKeys + `)[\w.-]*=|"[\w.-]*(?:` + sensitiveKeys + `)[\w.-]*"\s*:\s*)` +
+				`("[^"]*"|'[^']*'|[^,\s}\]]+)`,
+		),
+		allowed: allowed,
+	}
+}
+
+// ConfigureRedaction extends the sensitive keys by the key fragments of deniedKeys and excludes the keys
+// of allowedKeys from redaction. Allowed keys match the full key or the last segment of a dotted key,
+// case-insensitive. It is expected to be called once on startup.
+func ConfigureRedaction(deniedKeys, allowedKeys []string) {
+	currentRedaction.Store(newRedaction(deniedKeys, allowedKeys))
+}
+
+func (r *redaction) isAllowed(key string) bool {
+	key = strings.ToLower(strings.Trim(key, `"'`))
+	if r.allowed[key] {
+		return true
+	}
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return r.allowed[key[i+1:]]
+	}
+	return false
+}
+
+// IsSensitiveKey determines if the value of the key should never be exposed in logs or status.
+func IsSensitiveKey(key string) bool {
+	r := currentRedaction.Load()
+	return r.sensitiveKey.MatchString(key) && !r.isAllowed(key)
+}
+
+// RedactString masks the values of all key=value or "key": value pairs with a sensitive key.
+func RedactString(s string) string {
+	r := currentRedaction.Load()
+	if len(r.allowed) == 0 {
+		return r.sensitiveAssignment.ReplaceAllString(s, "${1}"+RedactedValue)
+	}
+	return r.sensitiveAssignment.ReplaceAllStringFunc(s, func(assignment string) string {
+		match := r.sensitiveAssignment.FindStringSubmatch(assignment)
+		if r.isAllowed(strings.TrimRight(match[1], "=: \t\r\n")) {
+			return assignment
+		}
+		return match[1] + RedactedValue
+	})
+}
+
+// RedactValues returns a deep copy of values in which all values of sensitive keys are masked.
+func RedactValues(values any) any {
+	switch typed := values.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(typed))
+		for key, value := range typed {
+			if IsSensitiveKey(key) {
+				redacted[key] = RedactedValue
+				continue
+			}
+			redacted[key] = RedactValues(value)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(typed))
+		for i := range typed {
+			redacted[i] = RedactValues(typed[i])
+		}
+		return redacted
+	case string:
+		return RedactString(typed)
+	default:
+		return values
+	}
+}
@@ -0,0 +1,67 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RedactString(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no sensitive keys", "replicas=1,image.tag=latest", "replicas=1,image.tag=latest"},
+		{"override password", "db.password=hunter2,replicas=1", "db.password=***,replicas=1"},
+		{"yaml token is not an assignment", "auth:\n  token: abc.def\n", "auth:\n  token: abc.def\n"},
+		{"quoted json secret", `{"clientSecret": "s3cr3t", "name": "x"}`, `{"clientSecret": ***, "name": "x"}`},
+		{"error message", "template: invalid apiKey=12345 in values", "template: invalid apiKey=*** in values"},
+		{
+			"error context", "fetching kubeconfig: connection refused; secret: not found",
+			"fetching kubeconfig: connection refused; secret: not found",
+		},
+		{
+			"quoted error context", `secrets "kubeconfig" not found: token expired`,
+			`secrets "kubeconfig" not found: token expired`,
+		},
+	}
+	for _, testCase := range tests {
+		tcase := testCase
+		t.Run(tcase.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tcase.expected, internal.RedactString(tcase.input))
+		})
+	}
+}
+
+func Test_RedactValues(t *testing.T) {
+	t.Parallel()
+	values := map[string]any{
+		"replicas": 1,
+		"db":       map[string]any{"password": "hunter2", "host": "localhost"},
+		"list":     []any{map[string]any{"token": "abc"}},
+	}
+	redacted := internal.RedactValues(values)
+	assert.Equal(t, map[string]any{
+		"replicas": 1,
+		"db":       map[string]any{"password": internal.RedactedValue, "host": "localhost"},
+		"list":     []any{map[string]any{"token": internal.RedactedValue}},
+	}, redacted)
+	assert.Equal(t, "hunter2", values["db"].(map[string]any)["password"], "input must not be modified")
+}
+
+//nolint:paralleltest // the redaction is configured globally
+func Test_ConfigureRedaction(t *testing.T) {
+	internal.ConfigureRedaction([]string{"connectionString"}, []string{"tokenTTL", "secretName"})
+	defer internal.ConfigureRedaction(nil, nil)
+
+	assert.Equal(t, "db.connectionString=***,auth.tokenTTL=5m,secretName: keda,token=***",
+		internal.RedactString("db.connectionString=host:5432,auth.tokenTTL=5m,secretName: keda,token=abc"))
+	assert.Equal(t, map[string]any{"connectionString": internal.RedactedValue, "secretName": "keda"},
+		internal.RedactValues(map[string]any{"connectionString": "host:5432", "secretName": "keda"}))
+	assert.False(t, internal.IsSensitiveKey("tokenTTL"))
+	assert.True(t, internal.IsSensitiveKey("token"))
+}
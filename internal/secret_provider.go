@@ -0,0 +1,285 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SecretProviderVault = "vault"
+	SecretProviderExec  = "exec"
+	// DefaultSecretTTL is the time secrets without a lease are cached for.
+	DefaultSecretTTL = 5 * time.Minute
+)
+
+var (
+	ErrSecretProviderNotFound = errors.New("secret provider not configured")
+	ErrSecretKeyNotFound      = errors.New("secret key not found")
+	ErrSecretPathForbidden    = errors.New("secret path not allowed")
+)
+
+// SecretReference identifies a value in a secret of a SecretProvider.
+type SecretReference struct {
+	Path string
+	Key  string
+}
+
+// Secret is a resolved value. Secrets with a LeaseDuration are cached for the duration of the lease,
+// secrets without one for the TTL of the SecretResolver.
+type Secret struct {
+	Value         string
+	LeaseID       string
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// SecretProvider resolves secret overrides of values at render time, so that they are neither stored in
+// the Manifest nor in config layers.
+type SecretProvider interface {
+	Resolve(ctx context.Context, ref SecretReference) (*Secret, error)
+}
+
+// RenewableSecretProvider renews the leases of Secrets instead of resolving them again.
+type RenewableSecretProvider interface {
+	SecretProvider
+	Renew(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error)
+}
+
+// SecretResolver caches the Secrets of multiple SecretProviders by their name. Secrets are refreshed after
+// half of their lease, but at the latest after the TTL. Renewable leases are renewed and all other Secrets are
+// resolved again.
+type SecretResolver struct {
+	Providers map[string]SecretProvider
+	// TTL bounds the time Secrets are cached for, also if their lease is longer, e.g. of KV secrets.
+	TTL time.Duration
+
+	mu      sync.Mutex
+	entries map[secretCacheKey]*cachedSecret
+}
+
+type secretCacheKey struct {
+	provider string
+	ref      SecretReference
+}
+
+type cachedSecret struct {
+	secret    *Secret
+	refreshAt time.Time
+	expiresAt time.Time
+}
+
+func NewSecretResolver(providers map[string]SecretProvider) *SecretResolver {
+	return &SecretResolver{
+		Providers: providers,
+		TTL:       DefaultSecretTTL,
+		entries:   make(map[secretCacheKey]*cachedSecret),
+	}
+}
+
+// Resolve returns the value referenced by ref in the secrets of provider.
+func (r *SecretResolver) Resolve(ctx context.Context, provider string, ref SecretReference) (string, error) {
+	secretProvider, found := r.Providers[provider]
+	if !found {
+		return "", fmt.Errorf("%w: %q", ErrSecretProviderNotFound, provider)
+	}
+	key := secretCacheKey{provider: provider, ref: ref}
+
+	r.mu.Lock()
+	entry, found := r.entries[key]
+	r.mu.Unlock()
+	now := time.Now()
+	if found && now.Before(entry.refreshAt) {
+		return entry.secret.Value, nil
+	}
+
+	if found && entry.secret.Renewable && now.Before(entry.expiresAt) {
+		if renewer, ok := secretProvider.(RenewableSecretProvider); ok {
+			leaseDuration, err := renewer.Renew(ctx, entry.secret.LeaseID, entry.secret.LeaseDuration)
+			if err == nil {
+				renewed := *entry.secret
+				renewed.LeaseDuration = leaseDuration
+				r.store(key, &renewed, now)
+				return renewed.Value, nil
+			}
+		}
+	}
+
+	secret, err := secretProvider.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s of %s from %s secret provider: %w", ref.Key, ref.Path, provider, err)
+	}
+	r.store(key, secret, now)
+	return secret.Value, nil
+}
+
+func (r *SecretResolver) store(key secretCacheKey, secret *Secret, now time.Time) {
+	ttl := secret.LeaseDuration
+	if ttl <= 0 {
+		ttl = r.TTL
+	}
+	refresh := ttl / 2 //nolint:gomnd
+	if r.TTL > 0 && refresh > r.TTL {
+		refresh = r.TTL
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.entries == nil {
+		r.entries = make(map[secretCacheKey]*cachedSecret)
+	}
+	r.entries[key] = &cachedSecret{secret: secret, refreshAt: now.Add(refresh), expiresAt: now.Add(ttl)}
+}
+
+// VaultSecretProvider reads secrets from the KV (version 1 and 2) and dynamic secret engines of HashiCorp Vault.
+// TokenFile is read for every request, so that tokens rotated by e.g. the vault agent are picked up.
+// Only paths below PathPrefix can be read, so that Manifests cannot read arbitrary secrets of the token.
+// PathPrefix is required and compared by its "/" separated segments.
+type VaultSecretProvider struct {
+	Address    string
+	TokenFile  string
+	PathPrefix string
+	HTTPClient *http.Client
+}
+
+type vaultResponse struct {
+	LeaseID       string         `json:"lease_id"`
+	LeaseDuration int            `json:"lease_duration"`
+	Renewable     bool           `json:"renewable"`
+	Data          map[string]any `json:"data"`
+	Errors        []string       `json:"errors"`
+}
+
+func (p *VaultSecretProvider) Resolve(ctx context.Context, ref SecretReference) (*Secret, error) {
+	path := strings.TrimPrefix(ref.Path, "/")
+	if !isBelowPathPrefix(path, p.PathPrefix) {
+		return nil, fmt.Errorf("%w: %s is not below %q", ErrSecretPathForbidden, path, p.PathPrefix)
+	}
+
+	response, err := p.do(ctx, http.MethodGet, "/v1/"+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	data := response.Data
+	// KV version 2 nests the secret below data and adds metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+
+	value, found := data[ref.Key]
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrSecretKeyNotFound, ref.Key)
+	}
+	secret := &Secret{
+		LeaseID:       response.LeaseID,
+		LeaseDuration: time.Duration(response.LeaseDuration) * time.Second,
+		Renewable:     response.Renewable,
+	}
+	if secret.Value, found = value.(string); !found {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		secret.Value = string(encoded)
+	}
+	return secret, nil
+}
+
+// isBelowPathPrefix compares path with a non-empty prefix by their "/" separated segments, so that e.g.
+// "secret/modules-other" is not below "secret/modules". Paths with empty, "." or ".." segments are rejected.
+func isBelowPathPrefix(path, prefix string) bool {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return false
+	}
+	pathSegments, prefixSegments := strings.Split(path, "/"), strings.Split(prefix, "/")
+	if len(pathSegments) < len(prefixSegments) {
+		return false
+	}
+	for _, segment := range pathSegments {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	for i := range prefixSegments {
+		if pathSegments[i] != prefixSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *VaultSecretProvider) Renew(
+	ctx context.Context, leaseID string, increment time.Duration,
+) (time.Duration, error) {
+	body, err := json.Marshal(map[string]any{"lease_id": leaseID, "increment": int(increment.Seconds())})
+	if err != nil {
+		return 0, err
+	}
+	response, err := p.do(ctx, http.MethodPut, "/v1/sys/leases/renew", body)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(response.LeaseDuration) * time.Second, nil
+}
+
+func (p *VaultSecretProvider) do(ctx context.Context, method, path string, body []byte) (*vaultResponse, error) {
+	token, err := os.ReadFile(p.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault token: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(p.Address, "/")+path,
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+
+	httpClient := p.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("vault request %s: %w", path, err)
+	}
+	defer response.Body.Close()
+
+	vaultResp := &vaultResponse{}
+	if err := json.NewDecoder(response.Body).Decode(vaultResp); err != nil && response.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decoding vault response of %s: %w", path, err)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("vault request %s: unexpected status %s: %s",
+			path, response.Status, strings.Join(vaultResp.Errors, ", "))
+	}
+	return vaultResp, nil
+}
+
+// ExecSecretProvider resolves secrets with a command configured by the operator, e.g. a wrapper of the CLI
+// of a secret manager. The reference is passed in the SECRET_PATH and SECRET_KEY environment variables
+// and the value is read from the standard output, without a trailing newline.
+type ExecSecretProvider struct {
+	Command string
+	Args    []string
+}
+
+func (p *ExecSecretProvider) Resolve(ctx context.Context, ref SecretReference) (*Secret, error) {
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Env = append(os.Environ(), "SECRET_PATH="+ref.Path, "SECRET_KEY="+ref.Key)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", p.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	return &Secret{Value: strings.TrimSuffix(stdout.String(), "\n")}, nil
+}
@@ -0,0 +1,135 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultSecretProvider(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+	ctx := context.Background()
+
+	var reads, renewals atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			_ = json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/modules/keda":
+			reads.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+				"data": map[string]any{"password": "kv2", "port": 5432}, "metadata": map[string]any{},
+			}})
+		case "/v1/database/creds/keda":
+			reads.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"lease_id": "database/creds/keda/1", "lease_duration": 1, "renewable": true,
+				"data": map[string]any{"password": "dynamic"},
+			})
+		case "/v1/sys/leases/renew":
+			renewals.Add(1)
+			_ = json.NewEncoder(w).Encode(map[string]any{"lease_duration": 3600})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	asserts.NoError(os.WriteFile(tokenFile, []byte("token\n"), 0o600))
+	vault := &internal.VaultSecretProvider{Address: server.URL, TokenFile: tokenFile, PathPrefix: "secret/data/modules/"}
+	ref := internal.SecretReference{Path: "secret/data/modules/keda", Key: "password"}
+
+	secret, err := vault.Resolve(ctx, internal.SecretReference{Path: "secret/data/modules/keda", Key: "password"})
+	asserts.NoError(err)
+	asserts.Equal("kv2", secret.Value)
+	secret, err = vault.Resolve(ctx, internal.SecretReference{Path: "secret/data/modules/keda", Key: "port"})
+	asserts.NoError(err)
+	asserts.Equal("5432", secret.Value)
+	_, err = vault.Resolve(ctx, internal.SecretReference{Path: "secret/data/modules/keda", Key: "missing"})
+	asserts.ErrorIs(err, internal.ErrSecretKeyNotFound)
+	_, err = vault.Resolve(ctx, internal.SecretReference{Path: "database/creds/keda", Key: "password"})
+	asserts.ErrorIs(err, internal.ErrSecretPathForbidden)
+	_, err = vault.Resolve(ctx, internal.SecretReference{Path: "secret/data/modules/../../sys", Key: "password"})
+	asserts.ErrorIs(err, internal.ErrSecretPathForbidden)
+	_, err = vault.Resolve(ctx, internal.SecretReference{Path: "secret/data/modules-other/keda", Key: "password"})
+	asserts.ErrorIs(err, internal.ErrSecretPathForbidden, "prefixes are compared by segments")
+	_, err = (&internal.VaultSecretProvider{Address: server.URL, TokenFile: tokenFile}).Resolve(ctx, ref)
+	asserts.ErrorIs(err, internal.ErrSecretPathForbidden, "a path prefix is required")
+
+	vault.PathPrefix = "/"
+	_, err = vault.Resolve(ctx, ref)
+	asserts.ErrorIs(err, internal.ErrSecretPathForbidden, "a path prefix is required")
+
+	vault.PathPrefix = "secret/data/modules"
+	resolver := internal.NewSecretResolver(map[string]internal.SecretProvider{internal.SecretProviderVault: vault})
+	for i := 0; i < 2; i++ {
+		value, err := resolver.Resolve(ctx, internal.SecretProviderVault, ref)
+		asserts.NoError(err)
+		asserts.Equal("kv2", value)
+	}
+	asserts.Equal(int32(4), reads.Load(), "secrets are cached by the resolver")
+
+	// leases are renewed after half of their duration instead of reading the secret again
+	reads.Store(0)
+	vault.PathPrefix = "database/creds"
+	leased := internal.SecretReference{Path: "database/creds/keda", Key: "password"}
+	value, err := resolver.Resolve(ctx, internal.SecretProviderVault, leased)
+	asserts.NoError(err)
+	asserts.Equal("dynamic", value)
+	time.Sleep(600 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		value, err = resolver.Resolve(ctx, internal.SecretProviderVault, leased)
+		asserts.NoError(err)
+		asserts.Equal("dynamic", value)
+	}
+	asserts.Equal(int32(1), reads.Load())
+	asserts.Equal(int32(1), renewals.Load())
+
+	// the TTL bounds the refresh of leases longer than it
+	resolver = internal.NewSecretResolver(map[string]internal.SecretProvider{internal.SecretProviderVault: vault})
+	resolver.TTL = 100 * time.Millisecond
+	_, err = resolver.Resolve(ctx, internal.SecretProviderVault, leased)
+	asserts.NoError(err)
+	time.Sleep(150 * time.Millisecond)
+	_, err = resolver.Resolve(ctx, internal.SecretProviderVault, leased)
+	asserts.NoError(err)
+	asserts.Equal(int32(2), reads.Load())
+	asserts.Equal(int32(2), renewals.Load())
+
+	_, err = resolver.Resolve(ctx, "unknown", ref)
+	asserts.ErrorIs(err, internal.ErrSecretProviderNotFound)
+
+	asserts.NoError(os.WriteFile(tokenFile, []byte("rotated"), 0o600))
+	_, err = vault.Resolve(ctx, leased)
+	asserts.ErrorContains(err, "permission denied")
+}
+
+func TestExecSecretProvider(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	provider := &internal.ExecSecretProvider{
+		Command: "sh", Args: []string{"-c", `echo "$SECRET_PATH/$SECRET_KEY"`},
+	}
+	secret, err := provider.Resolve(context.Background(), internal.SecretReference{Path: "modules/keda", Key: "token"})
+	asserts.NoError(err)
+	asserts.Equal("modules/keda/token", secret.Value)
+
+	provider = &internal.ExecSecretProvider{Command: "sh", Args: []string{"-c", "echo denied >&2; exit 1"}}
+	_, err = provider.Resolve(context.Background(), internal.SecretReference{})
+	asserts.ErrorContains(err, "denied")
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	yamlUtil "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// SortObjects orders objects by group, version, kind, namespace and name, so that the order of rendered
+// resources does not depend on map iteration in renderers.
+func SortObjects(objects []*unstructured.Unstructured) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objectSortKey(objects[i]) < objectSortKey(objects[j])
+	})
+}
+
+func objectSortKey(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s",
+		gvk.Group, gvk.Version, gvk.Kind, obj.GetNamespace(), obj.GetName())
+}
+
+// SortManifestDocuments orders the YAML documents of manifest like SortObjects.
+// Documents that are not objects keep their relative order and follow all objects. Empty documents are dropped
+// when reordering, a manifest that is already in order is returned as is.
+func SortManifestDocuments(manifest []byte) ([]byte, error) {
+	type document struct {
+		key   string
+		raw   []byte
+		index int
+	}
+	index := 0
+	var objects, blobs []document
+	reader := yamlUtil.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifest)))
+	for {
+		rawBytes, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid YAML doc: %w", err)
+		}
+		rawBytes = bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(rawBytes), []byte("---")))
+		if len(rawBytes) == 0 || bytes.Equal(rawBytes, []byte("null")) {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal(rawBytes, obj); err != nil || len(obj.Object) == 0 {
+			blobs = append(blobs, document{raw: rawBytes, index: index})
+		} else {
+			objects = append(objects, document{key: objectSortKey(obj), raw: rawBytes, index: index})
+		}
+		index++
+	}
+	sort.SliceStable(objects, func(i, j int) bool { return objects[i].key < objects[j].key })
+
+	documents := append(objects, blobs...)
+	inOrder := true
+	for i, doc := range documents {
+		inOrder = inOrder && doc.index == i
+	}
+	if inOrder {
+		return manifest, nil
+	}
+
+	var sorted bytes.Buffer
+	for _, doc := range documents {
+		sorted.WriteString("---\n")
+		sorted.Write(doc.raw)
+		sorted.WriteByte('\n')
+	}
+	return sorted.Bytes(), nil
+}
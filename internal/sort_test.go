@@ -0,0 +1,55 @@
+package internal_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const unsortedManifest = `# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: b
+  namespace: kyma-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: a
+  namespace: kyma-system
+---
+
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: a
+  namespace: kyma-system
+`
+
+func Test_SortManifestDocuments(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	sorted, err := internal.SortManifestDocuments([]byte(unsortedManifest))
+	asserts.NoError(err)
+
+	objects, err := internal.ParseManifestStringToObjects(string(sorted))
+	asserts.NoError(err)
+	names := make([]string, 0, len(objects.Items))
+	for _, obj := range objects.Items {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	asserts.Equal([]string{"Service/a", "Service/b", "Deployment/a"}, names, "core group sorts before apps")
+
+	again, err := internal.SortManifestDocuments(sorted)
+	asserts.NoError(err)
+	asserts.Equal(string(sorted), string(again), "sorting is idempotent")
+
+	shuffled := []*unstructured.Unstructured{objects.Items[2], objects.Items[1], objects.Items[0]}
+	internal.SortObjects(shuffled)
+	asserts.Equal(objects.Items, shuffled)
+}
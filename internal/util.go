@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	opLabels "github.com/kyma-project/module-manager/pkg/labels"
@@ -28,23 +29,26 @@ import (
 const (
 	YamlDecodeBufferSize            = 2048
 	OthersReadExecuteFilePermission = 0o755
+	DefaultFilePermission           = 0o644
 	DebugLogLevel                   = 2
 	TraceLogLevel                   = 3
 	configFileName                  = "installConfig.yaml"
 	configsFolder                   = "configs"
 )
 
+// CleanFilePathJoin joins root with the slash separated destDir of an archive entry and returns a path with the
+// separators of the current platform. Entries that would escape root are rejected.
 func CleanFilePathJoin(root, destDir string) (string, error) {
-	// On Windows, this is a drive separator. On UNIX-like, this is the path list separator.
-	// In neither case do we want to trust a TAR that contains these.
-	if strings.Contains(destDir, ":") {
-		return "", errors.New("path contains ':', which is illegal")
-	}
-
 	// The Go tar library does not convert separators for us.
 	// We assume here, as we do elsewhere, that `\\` means a Windows path.
 	destDir = strings.ReplaceAll(destDir, "\\", "/")
 
+	// A drive letter would make the path absolute on Windows, and on Windows ':' addresses alternate data streams.
+	// On other platforms ':' is a valid character of file names, e.g. of digests.
+	if hasDriveLetter(destDir) || (runtime.GOOS == "windows" && strings.Contains(destDir, ":")) {
+		return "", errors.New("path contains ':', which is illegal")
+	}
+
 	// We want to alert the user that something bad was attempted. Cleaning it
 	// is not a good practice.
 	for _, part := range strings.Split(destDir, "/") {
@@ -58,9 +62,12 @@ func CleanFilePathJoin(root, destDir string) (string, error) {
 		return "", errors.New("path is absolute, which is illegal")
 	}
 
-	newPath := filepath.Join(root, filepath.Clean(destDir))
+	return filepath.Join(root, filepath.FromSlash(path.Clean(destDir))), nil
+}
 
-	return filepath.ToSlash(newPath), nil
+func hasDriveLetter(p string) bool {
+	return len(p) >= 2 && p[1] == ':' &&
+		(('a' <= p[0] && p[0] <= 'z') || ('A' <= p[0] && p[0] <= 'Z'))
 }
 
 func ParseManifestStringToObjects(manifest string) (*types.ManifestResources, error) {
@@ -89,12 +96,36 @@ func ParseManifestStringToObjects(manifest string) (*types.ManifestResources, er
 	}
 }
 
+// GetFsChartPath is the directory the chart layer of imageSpec is extracted to.
+// Name and reference are encoded into a single segment with EncodePathSegment.
 func GetFsChartPath(imageSpec types.ImageSpec) string {
-	return filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s", imageSpec.Name, imageSpec.Ref))
+	return filepath.Join(os.TempDir(), EncodePathSegment(fmt.Sprintf("%s-%s", imageSpec.Name, imageSpec.Ref)))
 }
 
+// GetConfigFilePath is the file the config layer of config is stored in.
 func GetConfigFilePath(config types.ImageSpec) string {
-	return filepath.Join(os.TempDir(), configsFolder, config.Ref, configFileName)
+	return filepath.Join(os.TempDir(), configsFolder, EncodePathSegment(config.Ref), configFileName)
+}
+
+// EncodePathSegment encodes s into a single path segment that is valid on Linux and Windows.
+// All bytes except ASCII letters, digits, '.', '_' and '-' are percent-encoded, e.g. the digest "sha256:1a2b"
+// becomes "sha256%3A1a2b" and "kyma/keda" becomes "kyma%2Fkeda". As the encoding is reversible,
+// different references never share a path.
+func EncodePathSegment(s string) string {
+	if s == "." || s == ".." {
+		return strings.ReplaceAll(s, ".", "%2E")
+	}
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '.' || c == '_' || c == '-' {
+			encoded.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&encoded, "%%%02X", c)
+	}
+	return encoded.String()
 }
 
 func GetYamlFileContent(filePath string) (interface{}, error) {
@@ -113,23 +144,46 @@ func GetYamlFileContent(filePath string) (interface{}, error) {
 	return fileContent, err
 }
 
-func WriteToFile(filePath string, bytes []byte) error {
+// WriteToFile writes bytes to filePath atomically, creating all parent directories.
+// See WriteFileAtomically for the guarantees on crashes during the write.
+func WriteToFile(filePath string, content []byte) error {
 	// create directory
 	if err := os.MkdirAll(filepath.Dir(filePath), fs.ModePerm); err != nil {
 		return err
 	}
+	return WriteFileAtomically(filePath, bytes.NewReader(content), DefaultFilePermission)
+}
 
-	// create file
-	file, err := os.Create(filePath)
+// WriteFileAtomically writes the content of reader into a temporary file next to filePath,
+// syncs it to disk and renames it to filePath afterwards. Readers of filePath therefore
+// either see the previous or the complete new content, but never a partially written file.
+func WriteFileAtomically(filePath string, reader io.Reader, perm fs.FileMode) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
 	if err != nil {
 		return fmt.Errorf("file creation at path %s caused an error: %w", filePath, err)
 	}
+	tmpPath := tmpFile.Name()
+	// the temporary file is only left behind if writing failed
+	defer os.Remove(tmpPath)
 
-	// write to file
-	if _, err = file.Write(bytes); err != nil {
+	if _, err = io.Copy(tmpFile, reader); err != nil {
+		_ = tmpFile.Close()
 		return fmt.Errorf("writing file to path %s caused an error: %w", filePath, err)
 	}
-	return file.Close()
+	if err = tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return fmt.Errorf("syncing file to path %s caused an error: %w", filePath, err)
+	}
+	if err = tmpFile.Close(); err != nil {
+		return fmt.Errorf("closing file at path %s caused an error: %w", filePath, err)
+	}
+	if err = os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("setting permissions of file at path %s caused an error: %w", filePath, err)
+	}
+	if err = os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("renaming file to path %s caused an error: %w", filePath, err)
+	}
+	return nil
 }
 
 func GetResourceLabel(resource client.Object, labelName string) (string, error) {
@@ -165,6 +219,39 @@ func CalculateHash(interfaceToBeHashed any) (uint32, error) {
 	return h.Sum32(), nil
 }
 
+// CalculateDirHash returns a hash over the relative paths and contents of all files in dir, except for .git.
+func CalculateDirHash(dir string) (uint32, error) {
+	h := fnv.New32a()
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		h.Write([]byte(filepath.ToSlash(rel)))
+		h.Write([]byte{0})
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(h, file)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
 func GetCacheFunc() cache.NewCacheFunc {
 	return cache.BuilderWithOptions(
 		cache.Options{
@@ -1,9 +1,14 @@
 package internal_test
 
 import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -165,3 +170,86 @@ key32: "value3"`
 	twoDocsExpectedOutput   = yamlWithoutMarkers1 + marker + yamlWithoutMarkers2 + "\n"
 	threeDocsExpectedOutput = yamlWithoutMarkers1 + marker + yamlWithoutMarkers2 + marker + yamlWithoutMarkers3 + "\n"
 )
+
+func Test_WriteToFile(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	filePath := filepath.Join(t.TempDir(), "nested", "manifest.yaml")
+
+	assertions.NoError(internal.WriteToFile(filePath, []byte("first")))
+	assertions.NoError(internal.WriteToFile(filePath, []byte("second")))
+
+	content, err := os.ReadFile(filePath)
+	assertions.NoError(err)
+	assertions.Equal("second", string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	assertions.NoError(err)
+	assertions.Len(entries, 1, "no temporary files should be left behind")
+}
+
+func Test_CalculateDirHash(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	dir := t.TempDir()
+	assertions.NoError(internal.WriteToFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources: []")))
+
+	hash, err := internal.CalculateDirHash(dir)
+	assertions.NoError(err)
+
+	assertions.NoError(internal.WriteToFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref: refs/heads/main")))
+	unchanged, err := internal.CalculateDirHash(dir)
+	assertions.NoError(err)
+	assertions.Equal(hash, unchanged, ".git should be ignored")
+
+	assertions.NoError(internal.WriteToFile(filepath.Join(dir, "base", "kustomization.yaml"), []byte("resources: []")))
+	changed, err := internal.CalculateDirHash(dir)
+	assertions.NoError(err)
+	assertions.NotEqual(hash, changed)
+
+	_, err = internal.CalculateDirHash(filepath.Join(dir, "missing"))
+	assertions.Error(err)
+}
+
+func Test_CleanFilePathJoin(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	root := t.TempDir()
+
+	joined, err := internal.CleanFilePathJoin(root, "templates/crds/")
+	assertions.NoError(err)
+	assertions.Equal(filepath.Join(root, "templates", "crds"), joined, "separators of the platform are used")
+
+	joined, err = internal.CleanFilePathJoin(root, `templates\crds`)
+	assertions.NoError(err)
+	assertions.Equal(filepath.Join(root, "templates", "crds"), joined)
+
+	for _, illegal := range []string{"../escape", "templates/../../escape", "/absolute", `C:\Windows`, "c:relative"} {
+		_, err = internal.CleanFilePathJoin(root, illegal)
+		assertions.Error(err, illegal)
+	}
+
+	joined, err = internal.CleanFilePathJoin(root, "blobs/sha256:1a2b")
+	if runtime.GOOS == "windows" {
+		assertions.Error(err, "':' addresses alternate data streams on windows")
+	} else {
+		assertions.NoError(err)
+		assertions.Equal(filepath.Join(root, "blobs", "sha256:1a2b"), joined)
+	}
+}
+
+func Test_EncodePathSegment(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	assertions.Equal("sha256%3A1a2b", internal.EncodePathSegment("sha256:1a2b"))
+	assertions.Equal("kyma%2Fkeda-v1.0_0", internal.EncodePathSegment("kyma/keda-v1.0_0"))
+	assertions.Equal(`%5C%3C%3E%22%7C%3F%2A%25`, internal.EncodePathSegment(`\<>"|?*%`))
+	assertions.Equal("%2E%2E", internal.EncodePathSegment(".."))
+	assertions.NotEqual(internal.EncodePathSegment("a:b"), internal.EncodePathSegment("a%3Ab"),
+		"the encoding is reversible")
+
+	chartPath := internal.GetFsChartPath(types.ImageSpec{Name: "kyma/keda", Ref: "sha256:1a2b"})
+	assertions.Equal(filepath.Join(os.TempDir(), "kyma%2Fkeda-sha256%3A1a2b"), chartPath)
+	assertions.NotContains(strings.TrimPrefix(chartPath, filepath.VolumeName(chartPath)), ":")
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidValuesPath = errors.New("invalid values path")
+
+// SetValueAtPath sets value at the dot separated path in values, creating missing maps along the path.
+func SetValueAtPath(values map[string]any, path string, value any) error {
+	keys := strings.Split(path, ".")
+	for i, key := range keys[:len(keys)-1] {
+		if key == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidValuesPath, path)
+		}
+		next, found := values[key]
+		if !found {
+			next = make(map[string]any)
+			values[key] = next
+		}
+		nested, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%w: %s is not a map in %q", ErrInvalidValuesPath, strings.Join(keys[:i+1], "."), path)
+		}
+		values = nested
+	}
+	if keys[len(keys)-1] == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidValuesPath, path)
+	}
+	values[keys[len(keys)-1]] = value
+	return nil
+}
@@ -0,0 +1,30 @@
+package internal
+
+import "runtime/debug"
+
+// Version of the module manager, it is set at build time with
+// -ldflags "-X github.com/kyma-project/module-manager/internal.Version=<version>".
+//
+//nolint:gochecknoglobals
+var Version = ""
+
+// BuildVersion returns the Version set at build time, falling back to the version of the main module or the
+// VCS revision the binary was built from. It is empty if neither is known, e.g. in tests.
+func BuildVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
@@ -25,15 +25,19 @@ import (
 	"time"
 
 	manifestv1alpha1 "github.com/kyma-project/module-manager/api/v1alpha1"
+	manifestv1beta1 "github.com/kyma-project/module-manager/api/v1beta1"
 	"github.com/kyma-project/module-manager/controllers"
 	"github.com/kyma-project/module-manager/internal"
+	controllerConfig "github.com/kyma-project/module-manager/internal/config"
+	internalv1alpha1 "github.com/kyma-project/module-manager/internal/manifest/v1alpha1"
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
 	"github.com/kyma-project/module-manager/pkg/labels"
 	"github.com/kyma-project/module-manager/pkg/types"
-	listener "github.com/kyma-project/runtime-watcher/listener/pkg/event"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	apiExtensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
@@ -41,6 +45,7 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -66,6 +71,15 @@ const (
 	clientBurstDefault            = 150
 	defaultPprofServerTimeout     = 90 * time.Second
 	defaultCacheSyncTimeout       = 2 * time.Minute
+	logSamplingThereafterDefault  = 100
+	operationTimeoutDefault       = 5 * time.Minute
+	defaultMaxRenderedBytes       = 20 << 20
+	defaultSharedRenderCacheBytes = 128 << 20
+	defaultMaxRenderedObjects     = 3000
+	extractionsDefault            = 4
+	versionResyncIntervalDefault  = 2 * time.Second
+	prePullIntervalDefault        = time.Minute
+	retryBudgetWindowDefault      = time.Hour
 )
 
 //nolint:gochecknoinits
@@ -75,30 +89,96 @@ func init() {
 	utilruntime.Must(apiExtensionsv1.AddToScheme(scheme))
 
 	utilruntime.Must(manifestv1alpha1.AddToScheme(scheme))
+	// v1beta1 is served next to v1alpha1 and converted by the conversion webhook of the hub v1alpha1
+	utilruntime.Must(manifestv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 type FlagVar struct {
-	metricsAddr, listenerAddr                            string
-	enableLeaderElection, enablePProf, enableWebhooks    bool
-	checkReadyStates, customStateCheck, insecureRegistry bool
-	probeAddr                                            string
-	requeueSuccessInterval                               time.Duration
-	failureBaseDelay, failureMaxDelay                    time.Duration
-	concurrentReconciles, workersConcurrentManifests     int
-	rateLimiterBurst, rateLimiterFrequency               int
-	clientQPS                                            float64
-	clientBurst                                          int
-	pprofAddr                                            string
-	pprofServerTimeout                                   time.Duration
-	cacheSyncTimeout                                     time.Duration
-	logLevel                                             int
+	metricsAddr, listenerAddr, listenerPath           string
+	enableLeaderElection, enablePProf, enableWebhooks bool
+	checkReadyStates, insecureRegistry                bool
+	customStateCheck                                  bool
+	disableRemote, enableListener                     bool
+	allowInsecureRemoteTLS                            bool
+	requireImageDigests                               bool
+	enableMetadataInformers, waitForWebhooks          bool
+	clusterReadiness, strictFieldValidation           bool
+	stableNames, lastAppliedConfiguration             bool
+	renderOnly                                        bool
+	renderShard, renderShards                         int
+	probeAddr                                         string
+	requeueSuccessInterval                            time.Duration
+	failureBaseDelay, failureMaxDelay                 time.Duration
+	concurrentReconciles, workersConcurrentManifests  int
+	rateLimiterBurst, rateLimiterFrequency            int
+	installWorkers, consistencyWorkers                int
+	clientQPS                                         float64
+	clientBurst                                       int
+	pprofAddr                                         string
+	pprofServerTimeout                                time.Duration
+	cacheSyncTimeout                                  time.Duration
+	operationTimeout                                  time.Duration
+	installOperationTimeout, installRequeueInterval   time.Duration
+	dependencyRequeueInterval, versionResyncInterval  time.Duration
+	prePullInterval                                   time.Duration
+	retryBudgetWindow                                 time.Duration
+	retryBudgetFailures                               int
+	maxRenderedBytes, maxRenderedObjects              int
+	sharedRenderCacheBytes                            int
+	maxConcurrentExtractions, extractionDiskBudget    int
+	logLevel                                          int
+	logSamplingInitial, logSamplingThereafter         int
+	configFile, cacheDir                              string
+	helmKeyring, releaseNameTemplate                  string
+	globalValuesFile, auditLog, prePullFile           string
+	readinessRulesFile                                string
+	secretLabelSelector                               string
+	listenerStalenessTimeout                          time.Duration
+	secretCacheTTL                                    time.Duration
+	kustomizeMirror, kustomizeHelmCommand             string
+	vaultAddress, vaultTokenFile, vaultPathPrefix     string
+	secretExecCommand                                 string
+	redactKeys, redactAllowedKeys                     string
+	propagateLabels, propagateAnnotations             string
+	kustomizeEnableHelm, kustomizeEnableFunctions     bool
+	kustomizeFunctionNetwork, kustomizeEnableExec     bool
 }
 
 func main() {
 	flagVar := defineFlagVar()
 	flag.Parse()
-	ctrl.SetLogger(log.ConfigLogger(int8(flagVar.logLevel)))
+	if flagVar.configFile != "" {
+		if err := applyComponentConfig(flagVar.configFile); err != nil {
+			setupLog.Error(err, "unable to load controller configuration")
+			os.Exit(1)
+		}
+	}
+	internal.ConfigureRedaction(splitList(flagVar.redactKeys), splitList(flagVar.redactAllowedKeys))
+	// charts are extracted into the temporary directory, see internal.GetFsChartPath
+	internal.ConfigureExtraction(flagVar.maxConcurrentExtractions, int64(flagVar.extractionDiskBudget), os.TempDir())
+	logLevel := log.NewAtomicLevel(int8(flagVar.logLevel))
+	ctrl.SetLogger(log.ConfigLoggerWithLevel(logLevel, log.Options{
+		SamplingInitial:    flagVar.logSamplingInitial,
+		SamplingThereafter: flagVar.logSamplingThereafter,
+	}))
+	if err := flagVar.Validate(); err != nil {
+		setupLog.Error(err, "invalid flags")
+		os.Exit(1)
+	}
+
+	faultPoints, err := internal.LoadFaultsFromEnv()
+	if err != nil {
+		setupLog.Error(err, "unable to load fault injection settings")
+		os.Exit(1)
+	}
+	if len(faultPoints) > 0 {
+		setupLog.Info("fault injection is active, do not use this setup in production", "points", faultPoints)
+	}
+	if flagVar.customStateCheck {
+		setupLog.Info("custom-state-check is deprecated and has no effect, " +
+			"use readiness rules to check the state of custom resources")
+	}
 
 	config := ctrl.GetConfigOrDie()
 	config.QPS = float32(flagVar.clientQPS)
@@ -106,7 +186,16 @@ func main() {
 	if flagVar.enablePProf {
 		go pprofStartServer(flagVar.pprofAddr, flagVar.pprofServerTimeout)
 	}
-	setupWithManager(flagVar, internal.GetCacheFunc(), scheme, config)
+	settings := controllerConfig.NewSettings(flagVar.requeueSuccessInterval, flagVar.concurrentReconciles, logLevel)
+	setupWithManager(flagVar, settings, internal.GetCacheFunc(), scheme, config)
+}
+
+// splitList splits a comma separated flag value, an empty value is an empty list.
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
 }
 
 func pprofStartServer(addr string, timeout time.Duration) {
@@ -116,6 +205,7 @@ func pprofStartServer(addr string, timeout time.Duration) {
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/caches", internal.CacheDumpHandler())
 
 	server := &http.Server{
 		Addr:              addr,
@@ -130,14 +220,17 @@ func pprofStartServer(addr string, timeout time.Duration) {
 	}
 }
 
-func setupWithManager(flagVar *FlagVar, newCacheFunc cache.NewCacheFunc, scheme *runtime.Scheme, config *rest.Config) {
+func setupWithManager(
+	flagVar *FlagVar, settings *controllerConfig.Settings,
+	newCacheFunc cache.NewCacheFunc, scheme *runtime.Scheme, config *rest.Config,
+) {
 	mgr, err := ctrl.NewManager(
 		config, ctrl.Options{
 			Scheme:                 scheme,
 			MetricsBindAddress:     flagVar.metricsAddr,
 			Port:                   port,
 			HealthProbeBindAddress: flagVar.probeAddr,
-			LeaderElection:         flagVar.enableLeaderElection,
+			LeaderElection:         flagVar.enableLeaderElection && !flagVar.renderOnly,
 			LeaderElectionID:       "7f5e28d0.kyma-project.io",
 			NewCache:               newCacheFunc,
 		},
@@ -153,33 +246,157 @@ func setupWithManager(flagVar *FlagVar, newCacheFunc cache.NewCacheFunc, scheme
 		os.Exit(1)
 	}
 
-	runnableListener, eventChannel := listener.RegisterListenerComponent(
-		flagVar.listenerAddr, strings.ToLower(labels.OperatorName),
-	)
+	// events from remote clusters are only expected if Manifests can be installed remotely
+	var eventChannel source.Source
+	var listenerHealth *internal.ListenerHealth
+	if flagVar.enableListener && !flagVar.disableRemote && !flagVar.renderOnly {
+		var runnableListener *internal.EventListener
+		runnableListener, eventChannel = internal.NewEventListener(
+			flagVar.listenerAddr, flagVar.listenerPath, strings.ToLower(labels.OperatorName),
+		)
+		listenerHealth = internal.NewListenerHealth(flagVar.listenerStalenessTimeout)
+		runnableListener.Heartbeat = listenerHealth.HeartbeatHandler()
+
+		// start listener as a manager runnable
+		if err := mgr.Add(runnableListener); err != nil {
+			setupLog.Error(err, "unable to initialize listener")
+			os.Exit(1)
+		}
+	}
+
+	if flagVar.configFile != "" {
+		if err := mgr.Add(&controllerConfig.Reloader{
+			Path:     flagVar.configFile,
+			Interval: controllerConfig.DefaultReloadInterval,
+			Settings: settings,
+			Log:      ctrl.Log.WithName("config"),
+		}); err != nil {
+			setupLog.Error(err, "unable to initialize configuration reload")
+			os.Exit(1)
+		}
+	}
 
-	// start listener as a manager runnable
-	if err := mgr.Add(runnableListener); err != nil {
-		setupLog.Error(err, "unable to initialize listener")
+	releaseNameTemplate, err := declarative.ParseReleaseNameTemplate(flagVar.releaseNameTemplate)
+	if err != nil {
+		setupLog.Error(err, "unable to parse release name template")
+		os.Exit(1)
+	}
+	kustomizePlugins := declarative.KustomizePlugins{
+		HelmCharts:      flagVar.kustomizeEnableHelm,
+		HelmCommand:     flagVar.kustomizeHelmCommand,
+		Functions:       flagVar.kustomizeEnableFunctions,
+		FunctionNetwork: flagVar.kustomizeFunctionNetwork,
+		Exec:            flagVar.kustomizeEnableExec,
+	}
+	secretProviders := make(map[string]internal.SecretProvider)
+	if flagVar.vaultAddress != "" {
+		secretProviders[internal.SecretProviderVault] = &internal.VaultSecretProvider{
+			Address: flagVar.vaultAddress, TokenFile: flagVar.vaultTokenFile, PathPrefix: flagVar.vaultPathPrefix,
+		}
+	}
+	if flagVar.secretExecCommand != "" {
+		secretProviders[internal.SecretProviderExec] = &internal.ExecSecretProvider{Command: flagVar.secretExecCommand}
+	}
+	var globalValues map[string]any
+	if flagVar.globalValuesFile != "" {
+		if globalValues, err = internalv1alpha1.LoadGlobalValues(flagVar.globalValuesFile); err != nil {
+			setupLog.Error(err, "unable to load global values")
+			os.Exit(1)
+		}
+	}
+	var readinessRules []manifestv1alpha1.ReadinessRule
+	if flagVar.readinessRulesFile != "" {
+		if readinessRules, err = internalv1alpha1.LoadReadinessRules(flagVar.readinessRulesFile); err != nil {
+			setupLog.Error(err, "unable to load readiness rules")
+			os.Exit(1)
+		}
+	}
+	var secretSelector *metav1.LabelSelector
+	if flagVar.secretLabelSelector != "" {
+		if secretSelector, err = metav1.ParseToLabelSelector(flagVar.secretLabelSelector); err != nil {
+			setupLog.Error(err, "unable to parse secret label selector")
+			os.Exit(1)
+		}
+	}
+	metadataPropagation := declarative.MetadataPropagation{
+		Labels:      splitList(flagVar.propagateLabels),
+		Annotations: splitList(flagVar.propagateAnnotations),
+	}
+	if err := metadataPropagation.Validate(); err != nil {
+		setupLog.Error(err, "unable to parse metadata propagation")
 		os.Exit(1)
 	}
+	auditLog, err := controllers.NewAuditLog(mgr, flagVar.auditLog)
+	if err != nil {
+		setupLog.Error(err, "unable to create audit log")
+		os.Exit(1)
+	}
+	failureRateLimiter := internal.NewAnnotatedFailureRateLimiter(flagVar.failureBaseDelay, flagVar.failureMaxDelay)
 
 	if err := controllers.SetupWithManager(
 		mgr, eventChannel, codec, controller.Options{
 			RateLimiter: internal.ManifestRateLimiter(
-				flagVar.failureBaseDelay, flagVar.failureMaxDelay,
+				failureRateLimiter,
 				flagVar.rateLimiterFrequency,
 				flagVar.rateLimiterBurst,
 			),
 			MaxConcurrentReconciles: flagVar.concurrentReconciles,
 			CacheSyncTimeout:        flagVar.cacheSyncTimeout,
-		}, flagVar.insecureRegistry, flagVar.requeueSuccessInterval,
+		}, controllers.ReconcilerSettings{
+			Insecure:                 flagVar.insecureRegistry,
+			CacheDir:                 flagVar.cacheDir,
+			CheckInterval:            settings.RequeueSuccessInterval,
+			ActiveReconciles:         settings.ActiveReconciles,
+			InstallWorkers:           flagVar.installWorkers,
+			ConsistencyWorkers:       flagVar.consistencyWorkers,
+			OperationTimeout:         flagVar.operationTimeout,
+			InstallTimeout:           flagVar.installOperationTimeout,
+			InstallInterval:          flagVar.installRequeueInterval,
+			DependencyInterval:       flagVar.dependencyRequeueInterval,
+			RetryBudgetFailures:      flagVar.retryBudgetFailures,
+			RetryBudgetWindow:        flagVar.retryBudgetWindow,
+			MaxRenderedBytes:         flagVar.maxRenderedBytes,
+			SharedRenderCacheBytes:   flagVar.sharedRenderCacheBytes,
+			MaxRenderedObjects:       flagVar.maxRenderedObjects,
+			FailureRateLimiter:       failureRateLimiter,
+			SecretProviders:          secretProviders,
+			SecretCacheTTL:           flagVar.secretCacheTTL,
+			GlobalValues:             globalValues,
+			ReadinessRules:           readinessRules,
+			RequireImageDigests:      flagVar.requireImageDigests,
+			HelmKeyring:              flagVar.helmKeyring,
+			KustomizeMirror:          flagVar.kustomizeMirror,
+			KustomizePlugins:         kustomizePlugins,
+			RemoteDisabled:           flagVar.disableRemote,
+			AllowInsecureRemoteTLS:   flagVar.allowInsecureRemoteTLS,
+			ListenerHealth:           listenerHealth,
+			SecretSelector:           secretSelector,
+			MetadataInformers:        flagVar.enableMetadataInformers,
+			WaitForWebhooks:          flagVar.waitForWebhooks,
+			ClusterReadiness:         flagVar.clusterReadiness,
+			StrictFieldValidation:    flagVar.strictFieldValidation,
+			StableNames:              flagVar.stableNames,
+			LastAppliedConfiguration: flagVar.lastAppliedConfiguration,
+			RenderOnly:               flagVar.renderOnly,
+			RenderShard:              flagVar.renderShard,
+			RenderShards:             flagVar.renderShards,
+			MetadataPropagation:      metadataPropagation,
+			ReleaseNameTemplate:      releaseNameTemplate,
+			AuditLog:                 auditLog,
+			Version:                  internal.BuildVersion(),
+			VersionResyncInterval:    flagVar.versionResyncInterval,
+			PrePullFile:              flagVar.prePullFile,
+			PrePullInterval:          flagVar.prePullInterval,
+		},
 	); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Manifest")
 		os.Exit(1)
 	}
 
-	if flagVar.enableWebhooks {
-		if err = (&manifestv1alpha1.Manifest{}).SetupWebhookWithManager(mgr); err != nil {
+	if flagVar.enableWebhooks && !flagVar.renderOnly {
+		if err = (&manifestv1alpha1.Manifest{}).SetupWebhookWithValidator(mgr, &manifestv1alpha1.ManifestValidator{
+			RemoteDisabled: flagVar.disableRemote,
+		}); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Manifest")
 			os.Exit(1)
 		}
@@ -211,9 +428,25 @@ func defineFlagVar() *FlagVar {
 		&flagVar.probeAddr, "health-probe-bind-address", ":8081",
 		"The address the probe endpoint binds to.",
 	)
+	flag.BoolVar(
+		&flagVar.enableListener, "enable-listener", false,
+		"indicates if the listener for events of remote cluster watchers should be started",
+	)
 	flag.StringVar(
 		&flagVar.listenerAddr, "listener-address", ":8082",
-		"The address the probe endpoint binds to.",
+		"The address the listener for events of remote cluster watchers binds to.",
+	)
+	flag.DurationVar(
+		&flagVar.listenerStalenessTimeout, "listener-staleness-timeout", 0,
+		"Reports remote Manifests in the ListenerStale condition if no heartbeat was received from the watcher "+
+			"of their cluster within the timeout, e.g. because of a changed network policy. Watchers send heartbeats "+
+			"to /v1/<operator>/heartbeat of the listener address. 0 disables the condition, the time of the last "+
+			"event and heartbeat per cluster is exported as metric either way.",
+	)
+	flag.StringVar(
+		&flagVar.listenerPath, "listener-path", "",
+		"The path the listener receives events of remote cluster watchers on, "+
+			"defaults to the path of the watcher contract.",
 	)
 	flag.StringVar(
 		&flagVar.pprofAddr, "pprof-bind-address", ":8083",
@@ -233,6 +466,16 @@ func defineFlagVar() *FlagVar {
 		&flagVar.concurrentReconciles, "max-concurrent-reconciles", 1,
 		"Determines the number of concurrent reconciliations by the operator.",
 	)
+	flag.IntVar(
+		&flagVar.installWorkers, "install-workers", 0,
+		"Limits the concurrent reconciliations that install or upgrade Manifests below max-concurrent-reconciles, "+
+			"so that deletions keep flowing during bursts of installs, 0 disables the limit.",
+	)
+	flag.IntVar(
+		&flagVar.consistencyWorkers, "consistency-workers", 0,
+		"Limits the concurrent consistency checks of ready Manifests below max-concurrent-reconciles, "+
+			"0 disables the limit.",
+	)
 	flag.IntVar(
 		&flagVar.workersConcurrentManifests, "workers-concurrent-manifest", workersCountDefault,
 		"Determines the number of concurrent manifest operations for a single resource by the operator.",
@@ -244,7 +487,7 @@ func defineFlagVar() *FlagVar {
 	)
 	flag.BoolVar(
 		&flagVar.customStateCheck, "custom-state-check", false,
-		"Indicates if desired state should be checked on custom resource(s)",
+		"Deprecated: has no effect, the state of custom resources is checked with readiness rules.",
 	)
 	flag.IntVar(
 		&flagVar.rateLimiterBurst, "rate-limiter-burst", rateLimiterBurstDefault,
@@ -268,6 +511,73 @@ func defineFlagVar() *FlagVar {
 		&flagVar.insecureRegistry, "insecure-registry", false,
 		"indicates if insecure (http) response is expected from image registry",
 	)
+	flag.BoolVar(
+		&flagVar.requireImageDigests, "require-image-digests", false,
+		"Rejects OCI images of Manifests that are referenced by tag instead of digest, e.g. in production. "+
+			"Otherwise tags are resolved to the digest of the single layer of the tagged image.",
+	)
+	flag.BoolVar(
+		&flagVar.enableMetadataInformers, "enable-metadata-informers", false,
+		"Enables informers for the metadata of managed resources per target cluster, which serve the consistency "+
+			"checks instead of a request per resource at the cost of memory and one watch per kind and cluster.",
+	)
+	flag.BoolVar(
+		&flagVar.waitForWebhooks, "wait-for-webhooks", false,
+		"Manifests only become ready once the caBundle of their webhooks is set "+
+			"and the webhook services have ready endpoints.",
+	)
+	flag.BoolVar(
+		&flagVar.strictFieldValidation, "strict-field-validation", false,
+		"Applies rendered resources with strict server-side field validation, "+
+			"so that unknown fields, e.g. typos in chart templates, fail the install instead of being ignored.",
+	)
+	flag.BoolVar(
+		&flagVar.clusterReadiness, "cluster-readiness-check", false,
+		"Holds the first install of Manifests until the target cluster has a ready node and "+
+			"the default service account, so that installs do not race the cluster bootstrap. "+
+			"Webhooks of the cluster that are not serving yet are reported in a warning condition.",
+	)
+	flag.BoolVar(
+		&flagVar.renderOnly, "render-only", false,
+		"Runs the instance without leader election as a render worker, which only pulls and renders the Manifests "+
+			"waiting for their install into the cache-dir, which has to be a volume shared with the leader and "+
+			"mounted at the same path.",
+	)
+	flag.IntVar(
+		&flagVar.renderShards, "render-shards", 1,
+		"Number of render-only instances that split the Manifests to render among them.",
+	)
+	flag.IntVar(
+		&flagVar.renderShard, "render-shard", 0,
+		"Index of the render-only instance from 0 to render-shards - 1, e.g. the ordinal of a StatefulSet pod.",
+	)
+	flag.BoolVar(
+		&flagVar.stableNames, "stable-names", false,
+		"Removes versions, e.g. \"-2.8.1\", from the names of rendered resources and the references to them, "+
+			"so that charts deriving names from their version do not replace their resources on upgrades.",
+	)
+	flag.BoolVar(
+		&flagVar.lastAppliedConfiguration, "last-applied-configuration", false,
+		"Records the resources applied for every Manifest in a Secret next to it, which is referenced by the "+
+			"declarative.kyma-project.io/last-applied-configuration annotation, for external diff tools.",
+	)
+	flag.BoolVar(
+		&flagVar.disableRemote, "disable-remote", false,
+		"indicates a single-cluster installation, Manifests with spec.remote are rejected "+
+			"and no listener for remote cluster events is started",
+	)
+	flag.BoolVar(
+		&flagVar.allowInsecureRemoteTLS, "allow-insecure-remote-tls", false,
+		"Allows kubeconfig secrets to skip the TLS verification of their remote cluster with the "+
+			"operator.kyma-project.io/tls-insecure-skip-verify annotation.",
+	)
+	flag.StringVar(
+		&flagVar.secretLabelSelector, "secret-label-selector", labels.KymaName,
+		"The label selector of the watched Secrets whose changes invalidate the clients of remote clusters, "+
+			"e.g. kubeconfig secrets labeled with the Kyma name. Secrets named like the Kyma of a Manifest are "+
+			"watched regardless of the selector, as they are looked up by name if no Secret is labeled. "+
+			"An empty selector watches all Secrets.",
+	)
 	flag.BoolVar(
 		&flagVar.enableWebhooks, "enable-webhooks", false,
 		"indicates if webhooks should be enabled",
@@ -284,9 +594,188 @@ func defineFlagVar() *FlagVar {
 		&flagVar.cacheSyncTimeout, "cache-sync-timeout", defaultCacheSyncTimeout,
 		"Indicates the cache sync timeout in seconds",
 	)
+	flag.DurationVar(
+		&flagVar.operationTimeout, "operation-timeout", operationTimeoutDefault,
+		"Maximum duration of resolving, rendering, applying and deleting the resources of a Manifest "+
+			"in a single reconciliation, 0 disables the timeout.",
+	)
+	flag.DurationVar(
+		&flagVar.installOperationTimeout, "install-operation-timeout", 0,
+		"Replaces the operation-timeout for Manifests that were never ready, e.g. to allow for image pulls "+
+			"and volume provisioning during the initial install, 0 keeps the operation-timeout.",
+	)
+	flag.DurationVar(
+		&flagVar.installRequeueInterval, "install-requeue-interval", 0,
+		"Interval in which Manifests that were never ready are checked while their resources become ready, "+
+			"0 uses the backoff of the rate limiter.",
+	)
+	flag.DurationVar(
+		&flagVar.dependencyRequeueInterval, "dependency-requeue-interval",
+		declarative.DefaultDependencyRequeueInterval,
+		"Interval in which Manifests are checked while they wait for CRDs or namespaces of other modules, "+
+			"0 uses the backoff of the rate limiter.",
+	)
+	flag.IntVar(
+		&flagVar.retryBudgetFailures, "retry-budget-failures", 0,
+		"Number of consecutive failures within the retry-budget-window after which Manifests are no longer retried "+
+			"until they are updated or their "+declarative.ResyncAnnotation+" annotation changes, 0 retries forever.",
+	)
+	flag.DurationVar(
+		&flagVar.retryBudgetWindow, "retry-budget-window", retryBudgetWindowDefault,
+		"Window after the first of the consecutive failures of a Manifest in which further failures count towards "+
+			"the retry-budget-failures, later failures start a new count, 0 counts all consecutive failures.",
+	)
+	flag.DurationVar(
+		&flagVar.versionResyncInterval, "version-resync-interval", versionResyncIntervalDefault,
+		"Interval in which ready Manifests applied by a previous version of the module manager are resynced "+
+			"after an upgrade, 0 resyncs all of them immediately.",
+	)
+	flag.IntVar(
+		&flagVar.maxConcurrentExtractions, "max-concurrent-extractions", extractionsDefault,
+		"The number of chart layers that are extracted concurrently, 0 disables the limit.",
+	)
+	flag.IntVar(
+		&flagVar.extractionDiskBudget, "extraction-disk-budget", 0,
+		"The bytes of disk space that the extracted charts in the cache and the chart extractions in progress "+
+			"may use together, extractions wait for the budget before they start, 0 disables the budget.",
+	)
+	flag.IntVar(
+		&flagVar.maxRenderedBytes, "max-rendered-bytes", defaultMaxRenderedBytes,
+		"Rejects rendered manifests of Manifests that are larger than the given bytes, 0 disables the limit.",
+	)
+	flag.IntVar(
+		&flagVar.maxRenderedObjects, "max-rendered-objects", defaultMaxRenderedObjects,
+		"Rejects rendered manifests of Manifests with more objects than the given number, 0 disables the limit.",
+	)
+	flag.IntVar(
+		&flagVar.sharedRenderCacheBytes, "shared-render-cache-bytes", defaultSharedRenderCacheBytes,
+		"Size of the in-memory cache of rendered manifests that is shared by Manifests rendering the same chart "+
+			"with the same values for the same kind of cluster, 0 disables the cache.",
+	)
 	flag.IntVar(
 		&flagVar.logLevel, "log-level", 0,
 		"indicates the current log-level, enter negative values to increase verbosity (e.g. 9)",
 	)
+	flag.IntVar(
+		&flagVar.logSamplingInitial, "log-sampling-initial", 0,
+		"The number of identical log entries per second that are logged before sampling starts, "+
+			"0 disables sampling.",
+	)
+	flag.IntVar(
+		&flagVar.logSamplingThereafter, "log-sampling-thereafter", logSamplingThereafterDefault,
+		"Once sampling started, only every n-th identical log entry within the same second is logged.",
+	)
+	flag.StringVar(
+		&flagVar.redactKeys, "redact-keys", "",
+		"Comma separated key fragments whose values are masked in logs, events and status in addition to "+
+			"passwords, secrets, tokens and credentials, e.g. \"connectionString,dsn\".",
+	)
+	flag.StringVar(
+		&flagVar.redactAllowedKeys, "redact-allowed-keys", "",
+		"Comma separated keys whose values are never masked although they match a sensitive key fragment, "+
+			"e.g. \"tokenTTL,secretName\".",
+	)
+	flag.StringVar(
+		&flagVar.propagateLabels, "propagate-labels", "",
+		"Comma separated patterns of Manifest labels that are copied onto the default CR and the workloads of "+
+			"the module, e.g. \"billing.example.com/*\" for cost attribution.",
+	)
+	flag.StringVar(
+		&flagVar.propagateAnnotations, "propagate-annotations", "",
+		"Comma separated patterns of Manifest annotations that are copied onto the default CR and the workloads "+
+			"of the module.",
+	)
+	flag.StringVar(
+		&flagVar.configFile, "config", "",
+		"The path to a ModuleManagerConfiguration file. Values from the file are used for all flags "+
+			"that are not set explicitly, safe-to-change values are reloaded at runtime.",
+	)
+	flag.StringVar(
+		&flagVar.cacheDir, "cache-dir", os.TempDir(),
+		"The directory in which charts and rendered manifests are cached.",
+	)
+	flag.StringVar(
+		&flagVar.releaseNameTemplate, "release-name-template", declarative.DefaultReleaseNameTemplate,
+		"The text/template for helm release names of installs, e.g. \"{{ .ManifestName }}-{{ .Hash }}\" "+
+			"for release names unique per Manifest. Available fields are ManifestName, Name, Namespace and Hash.",
+	)
+	flag.StringVar(
+		&flagVar.globalValuesFile, "global-values-file", "",
+		"The path to a values file, e.g. mounted from a ConfigMap, whose values are set for every install "+
+			"below the values of the install, e.g. global.imagePullSecrets.",
+	)
+	flag.StringVar(
+		&flagVar.readinessRulesFile, "readiness-rules-file", "",
+		"The path to a file, e.g. mounted from a ConfigMap, with a list of readiness rules (apiVersion, kind and "+
+			"a CEL expression such as status.phase == \"Running\") that decide the readiness of installed resources "+
+			"of their kind, unless a Manifest defines spec.readinessRules for the same kind.",
+	)
+	flag.StringVar(
+		&flagVar.auditLog, "audit-log", "",
+		"Records the installs, upgrades and deletions of Manifests with their actor, revision, outcome and duration. "+
+			"\"events\" emits annotated events for a long-retention event exporter, "+
+			"\"configmap\" appends them to a ConfigMap <manifest>-audit next to every Manifest.",
+	)
+	flag.StringVar(
+		&flagVar.prePullFile, "pre-pull-file", "",
+		"The path to a file, e.g. mounted from a ConfigMap, with Manifests whose charts and layers are pulled "+
+			"into the cache whenever the file changes, e.g. ahead of a planned rollout window.",
+	)
+	flag.DurationVar(
+		&flagVar.prePullInterval, "pre-pull-interval", prePullIntervalDefault,
+		"Interval in which the pre-pull-file is checked for changes and failed pre-pulls are retried, "+
+			"0 disables pre-pulling.",
+	)
+	flag.StringVar(
+		&flagVar.helmKeyring, "helm-keyring", "",
+		"The path to the public keyring used to verify the provenance (.prov) of charts with verify enabled.",
+	)
+	flag.StringVar(
+		&flagVar.kustomizeMirror, "kustomize-mirror", "",
+		"The directory with git mirrors of kustomize remotes at <host>/<path>, e.g. github.com/org/repo, "+
+			"which are used instead of the remotes.",
+	)
+	flag.BoolVar(
+		&flagVar.kustomizeEnableHelm, "kustomize-enable-helm", false,
+		"Enables the helmCharts field of kustomizations, which runs the binary of --kustomize-helm-command.",
+	)
+	flag.StringVar(
+		&flagVar.kustomizeHelmCommand, "kustomize-helm-command", declarative.DefaultKustomizeHelmCommand,
+		"The helm binary used to inflate the helmCharts of kustomizations.",
+	)
+	flag.BoolVar(
+		&flagVar.kustomizeEnableFunctions, "kustomize-enable-functions", false,
+		"Enables containerized KRM function plugins in kustomizations, which requires a container runtime.",
+	)
+	flag.BoolVar(
+		&flagVar.kustomizeFunctionNetwork, "kustomize-function-network", false,
+		"Grants containerized KRM function plugins of kustomizations access to the network.",
+	)
+	flag.BoolVar(
+		&flagVar.kustomizeEnableExec, "kustomize-enable-exec", false,
+		"Enables exec KRM function plugins in kustomizations, which run arbitrary binaries of the controller.",
+	)
+	flag.StringVar(
+		&flagVar.vaultAddress, "vault-address", "",
+		"The address of the vault secret provider for the valuesFrom of installs, empty disables the provider.",
+	)
+	flag.StringVar(
+		&flagVar.vaultTokenFile, "vault-token-file", "/var/run/secrets/vault/token",
+		"The file containing the vault token, it is read for every request to pick up rotated tokens.",
+	)
+	flag.StringVar(
+		&flagVar.vaultPathPrefix, "vault-path-prefix", "",
+		"Restricts the vault paths referenced by valuesFrom of installs to the prefix, e.g. \"secret/data/modules/\". "+
+			"Required if the vault secret provider is enabled.",
+	)
+	flag.DurationVar(
+		&flagVar.secretCacheTTL, "secret-cache-ttl", internal.DefaultSecretTTL,
+		"The maximum time secrets of the secret providers are cached for before they are renewed or resolved again.",
+	)
+	flag.StringVar(
+		&flagVar.secretExecCommand, "secret-exec-command", "",
+		"The command of the exec secret provider for the valuesFrom of installs, it receives the reference "+
+			"in SECRET_PATH and SECRET_KEY and prints the value. Empty disables the provider.",
+	)
 	return flagVar
 }
@@ -21,6 +21,23 @@ type ClusterClient struct {
 func (cc *ClusterClient) GetRESTConfig(
 	ctx context.Context, kymaOwner string, namespace string,
 ) (*rest.Config, error) {
+	kubeConfigSecret, err := cc.GetKubeConfigSecret(ctx, kymaOwner, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeConfigSecret.Data["config"])
+	if err != nil {
+		return nil, err
+	}
+	return restConfig, err
+}
+
+// GetKubeConfigSecret returns the secret containing the kubeconfig of the cluster of kymaOwner,
+// either selected by the labels.KymaName label or by its name.
+func (cc *ClusterClient) GetKubeConfigSecret(
+	ctx context.Context, kymaOwner string, namespace string,
+) (*v1.Secret, error) {
 	kubeConfigSecretList := &v1.SecretList{}
 	groupResource := v1.SchemeGroupVersion.WithResource(string(v1.ResourceSecrets)).GroupResource()
 	labelSelector := k8slabels.SelectorFromSet(k8slabels.Set{labels.KymaName: kymaOwner})
@@ -43,10 +60,5 @@ func (cc *ClusterClient) GetRESTConfig(
 	if len(kubeConfigSecretList.Items) > 1 {
 		return nil, errors.NewConflict(groupResource, kymaOwner, fmt.Errorf("more than one instance found"))
 	}
-
-	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeConfigSecret.Data["config"])
-	if err != nil {
-		return nil, err
-	}
-	return restConfig, err
+	return kubeConfigSecret, nil
 }
@@ -0,0 +1,281 @@
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type AuditOperation string
+
+const (
+	// AuditOperationInstall applies a revision to an object that was never ready.
+	AuditOperationInstall AuditOperation = "Install"
+	// AuditOperationUpgrade applies a revision that differs from the last ready revision.
+	AuditOperationUpgrade AuditOperation = "Upgrade"
+	// AuditOperationSync applies the last ready revision again, e.g. after a change of the values or a drift.
+	AuditOperationSync AuditOperation = "Sync"
+	// AuditOperationDelete uninstalls the resources of a deleted object.
+	AuditOperationDelete AuditOperation = "Delete"
+)
+
+type AuditOutcome string
+
+const (
+	AuditOutcomeSucceeded AuditOutcome = "Succeeded"
+	AuditOutcomeFailed    AuditOutcome = "Failed"
+)
+
+// DefaultAuditLogMaxEntries is the number of entries kept per object by the ConfigMapAuditLog.
+const DefaultAuditLogMaxEntries = 100
+
+const (
+	auditEventReason          = "Audit"
+	auditAnnotationPrefix     = "audit.declarative.kyma-project.io/"
+	auditConfigMapSuffix      = "-audit"
+	auditEntryKeyFormat       = "%020d"
+	auditConfigMapObjectLabel = "declarative.kyma-project.io/audited-object"
+)
+
+// AuditEntry describes a finished or failed operation of an object for the AuditLog.
+type AuditEntry struct {
+	// Object is the reconciled object.
+	Object client.ObjectKey `json:"object"`
+	// Generation of the object that was reconciled.
+	Generation int64 `json:"generation"`
+	// Actor is the field manager that changed the spec of the object last, e.g. the operator that created it.
+	Actor string `json:"actor,omitempty"`
+	// Operation that was executed.
+	Operation AuditOperation `json:"operation"`
+	// Revision of the install that was applied.
+	Revision string `json:"revision,omitempty"`
+	// Outcome of the operation, failed operations are recorded once per error.
+	Outcome AuditOutcome `json:"outcome"`
+	// Error of a failed operation.
+	Error string `json:"error,omitempty"`
+	// StartedAt and FinishedAt bound the operation, from the start of the journal or the deletion timestamp.
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// Duration of the operation.
+func (e AuditEntry) Duration() time.Duration {
+	return e.FinishedAt.Sub(e.StartedAt)
+}
+
+// AuditLog persists an append-only trail of the operations of reconciled objects, so that the change history
+// of a cluster can be reconstructed. Errors of the AuditLog are logged but do not fail the reconciliation.
+type AuditLog interface {
+	Record(ctx context.Context, obj Object, entry AuditEntry) error
+}
+
+// WithAuditLog records every finished operation and every failure with a new error in the AuditLog.
+func WithAuditLog(auditLog AuditLog) WithAuditLogOption {
+	return WithAuditLogOption{AuditLog: auditLog}
+}
+
+type WithAuditLogOption struct {
+	AuditLog
+}
+
+func (o WithAuditLogOption) Apply(options *Options) {
+	options.AuditLog = o.AuditLog
+}
+
+// EventAuditLog records entries as Events of the object whose annotations carry the fields of the entry,
+// so that they can be shipped to a long-retention store by an event exporter.
+type EventAuditLog struct {
+	record.EventRecorder
+}
+
+func (l *EventAuditLog) Record(_ context.Context, obj Object, entry AuditEntry) error {
+	eventType := corev1.EventTypeNormal
+	if entry.Outcome == AuditOutcomeFailed {
+		eventType = corev1.EventTypeWarning
+	}
+	annotations := map[string]string{
+		auditAnnotationPrefix + "generation":  strconv.FormatInt(entry.Generation, 10),
+		auditAnnotationPrefix + "actor":       entry.Actor,
+		auditAnnotationPrefix + "operation":   string(entry.Operation),
+		auditAnnotationPrefix + "revision":    entry.Revision,
+		auditAnnotationPrefix + "outcome":     string(entry.Outcome),
+		auditAnnotationPrefix + "started-at":  entry.StartedAt.UTC().Format(time.RFC3339),
+		auditAnnotationPrefix + "finished-at": entry.FinishedAt.UTC().Format(time.RFC3339),
+		auditAnnotationPrefix + "duration":    entry.Duration().String(),
+	}
+	msg := fmt.Sprintf("%s of revision %q %s after %s", entry.Operation, entry.Revision, entry.Outcome,
+		entry.Duration().Round(time.Second))
+	if entry.Error != "" {
+		msg = fmt.Sprintf("%s: %s", msg, entry.Error)
+	}
+	l.AnnotatedEventf(obj, annotations, eventType, auditEventReason, "%s", msg)
+	return nil
+}
+
+// ConfigMapAuditLog appends entries to a ConfigMap named <object>-audit next to the object, with one key per entry
+// ordered by the time the operation finished. Only the latest MaxEntries are kept to stay below the size limit
+// of ConfigMaps. The ConfigMap is not owned by the object, so that the history outlives its deletion.
+type ConfigMapAuditLog struct {
+	client.Client
+	MaxEntries int
+}
+
+func (l *ConfigMapAuditLog) Record(ctx context.Context, obj Object, entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf(auditEntryKeyFormat, entry.FinishedAt.UnixNano())
+
+	configMap := &corev1.ConfigMap{}
+	configMap.SetName(obj.GetName() + auditConfigMapSuffix)
+	configMap.SetNamespace(obj.GetNamespace())
+	err = l.Get(ctx, client.ObjectKeyFromObject(configMap), configMap)
+	if apierrors.IsNotFound(err) {
+		configMap.SetLabels(map[string]string{auditConfigMapObjectLabel: obj.GetName()})
+		configMap.Data = map[string]string{key: string(data)}
+		return l.Create(ctx, configMap)
+	} else if err != nil {
+		return err
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = map[string]string{}
+	}
+	configMap.Data[key] = string(data)
+	l.truncate(configMap)
+	// the update is rejected on conflicts, so that concurrent entries are never overwritten
+	return l.Update(ctx, configMap)
+}
+
+func (l *ConfigMapAuditLog) truncate(configMap *corev1.ConfigMap) {
+	maxEntries := l.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = DefaultAuditLogMaxEntries
+	}
+	if len(configMap.Data) <= maxEntries {
+		return
+	}
+	keys := make([]string, 0, len(configMap.Data))
+	for key := range configMap.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys[:len(keys)-maxEntries] {
+		delete(configMap.Data, key)
+	}
+}
+
+// auditActor returns the field manager that updated the object last, ignoring updates of the status
+// and the ones of the reconciler itself, e.g. for finalizers.
+func auditActor(obj Object, fieldOwner client.FieldOwner) string {
+	var actor string
+	var latest *metav1.Time
+	for _, entry := range obj.GetManagedFields() {
+		if entry.Subresource != "" || entry.Manager == string(fieldOwner) || entry.Time == nil {
+			continue
+		}
+		if latest == nil || latest.Before(entry.Time) {
+			actor, latest = entry.Manager, entry.Time
+		}
+	}
+	return actor
+}
+
+// auditOperation derives the operation of revision from the last ready revision of the install.
+func auditOperation(status Status, installName, revision string) AuditOperation {
+	for _, install := range status.Installs {
+		if install.Name != installName || install.Revision == "" {
+			continue
+		}
+		if install.Revision == revision {
+			return AuditOperationSync
+		}
+		return AuditOperationUpgrade
+	}
+	return AuditOperationInstall
+}
+
+// recordAudit completes entry with the identity of obj and records it in the AuditLog if one is configured.
+func (r *Reconciler) recordAudit(ctx context.Context, obj Object, entry AuditEntry) {
+	if r.AuditLog == nil {
+		return
+	}
+	entry.Object = client.ObjectKeyFromObject(obj)
+	entry.Generation = obj.GetGeneration()
+	entry.Actor = auditActor(obj, r.FieldOwner)
+	if err := r.AuditLog.Record(ctx, obj, entry); err != nil {
+		log.FromContext(ctx).Error(err, "operation could not be recorded in the audit log",
+			"operation", entry.Operation, "outcome", entry.Outcome)
+	}
+}
+
+// auditSync records the outcome of syncResources, given the status before the sync. An operation succeeded
+// once its journal is finished.
+func (r *Reconciler) auditSync(ctx context.Context, obj Object, spec *Spec, before Status, err error) {
+	after := obj.GetStatus()
+	now := time.Now()
+	entry := AuditEntry{
+		Operation:  auditOperation(before, spec.ManifestName, spec.Revision),
+		Revision:   spec.Revision,
+		StartedAt:  now,
+		FinishedAt: now,
+	}
+	if before.Journal.InFlight() {
+		entry.StartedAt = before.Journal.StartedAt.Time
+	}
+	switch {
+	case before.Journal.InFlight() && !after.Journal.InFlight():
+		entry.Outcome = AuditOutcomeSucceeded
+		entry.FinishedAt = after.Journal.FinishedAt.Time
+	case err != nil && isNewFailure(before, after, spec.ManifestName):
+		entry.Outcome = AuditOutcomeFailed
+		entry.Error = after.LastOperation.Operation
+	default:
+		return
+	}
+	r.recordAudit(ctx, obj, entry)
+}
+
+// auditDeletion records the outcome of the uninstallation of obj, given the status before it.
+func (r *Reconciler) auditDeletion(ctx context.Context, obj Object, spec *Spec, before Status, err error) {
+	entry := AuditEntry{
+		Operation:  AuditOperationDelete,
+		Revision:   spec.Revision,
+		Outcome:    AuditOutcomeSucceeded,
+		StartedAt:  obj.GetDeletionTimestamp().Time,
+		FinishedAt: time.Now(),
+	}
+	if err != nil {
+		if !isNewFailure(before, obj.GetStatus(), spec.ManifestName) {
+			return
+		}
+		entry.Outcome = AuditOutcomeFailed
+		entry.Error = obj.GetStatus().LastOperation.Operation
+	}
+	r.recordAudit(ctx, obj, entry)
+}
+
+// isNewFailure is true if after is in StateError with an error that differs from the last error of the install,
+// so that retries failing with the same error do not flood the AuditLog.
+func isNewFailure(before, after Status, installName string) bool {
+	if after.State != StateError {
+		return false
+	}
+	for _, install := range before.Installs {
+		if install.Name == installName && install.LastError == after.LastOperation.Operation {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type recordingAuditLog struct {
+	entries []AuditEntry
+}
+
+func (l *recordingAuditLog) Record(_ context.Context, _ Object, entry AuditEntry) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func auditedObj() *statusObj {
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("audited")
+	obj.SetNamespace(metav1.NamespaceDefault)
+	obj.SetGeneration(3)
+	earlier, later := metav1.NewTime(time.Now().Add(-time.Hour)), metav1.Now()
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "lifecycle-manager", Operation: metav1.ManagedFieldsOperationApply, Time: &earlier},
+		{Manager: "kubectl-edit", Operation: metav1.ManagedFieldsOperationUpdate, Time: &later},
+		{Manager: "status-writer", Operation: metav1.ManagedFieldsOperationUpdate, Time: &later, Subresource: "status"},
+		{Manager: FieldOwnerDefault, Operation: metav1.ManagedFieldsOperationApply, Time: &later},
+	})
+	return obj
+}
+
+func TestAuditSync(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	auditLog := &recordingAuditLog{}
+	r := &Reconciler{Options: DefaultOptions().Apply(WithAuditLog(auditLog))}
+	spec := &Spec{ManifestName: "install", Revision: "sha256:new"}
+	obj := auditedObj()
+
+	before, _ := Status{
+		State:    StateReady,
+		Installs: []InstallStatus{{Name: "install", Revision: "sha256:old", Ready: true}},
+	}.WithJournalStart(spec.Revision, nil)
+	obj.SetStatus(before.WithState(StateError).WithErr(errors.New("apply failed")))
+	r.auditSync(ctx, obj, spec, before, errors.New("apply failed"))
+
+	failedBefore := obj.GetStatus().WithInstall(spec.ManifestName, spec.Revision)
+	r.auditSync(ctx, obj, spec, failedBefore, errors.New("apply failed"))
+
+	obj.SetStatus(failedBefore.WithJournalFinish())
+	r.auditSync(ctx, obj, spec, failedBefore, nil)
+	r.auditSync(ctx, obj, spec, obj.GetStatus(), nil)
+
+	require.Len(t, auditLog.entries, 2, "repeated errors and syncs without a journal are not recorded")
+	failed, succeeded := auditLog.entries[0], auditLog.entries[1]
+	assert.Equal(t, AuditOutcomeFailed, failed.Outcome)
+	assert.Equal(t, "apply failed", failed.Error)
+	assert.Equal(t, AuditOutcomeSucceeded, succeeded.Outcome)
+	assert.Equal(t, AuditOperationUpgrade, succeeded.Operation)
+	assert.Equal(t, "sha256:new", succeeded.Revision)
+	assert.Equal(t, "kubectl-edit", succeeded.Actor)
+	assert.Equal(t, int64(3), succeeded.Generation)
+	assert.Equal(t, client.ObjectKey{Name: "audited", Namespace: metav1.NamespaceDefault}, succeeded.Object)
+	assert.GreaterOrEqual(t, succeeded.Duration(), time.Duration(0))
+}
+
+func TestAuditOperation(t *testing.T) {
+	t.Parallel()
+	status := Status{Installs: []InstallStatus{{Name: "install", Revision: "sha256:old"}}}
+	assert.Equal(t, AuditOperationSync, auditOperation(status, "install", "sha256:old"))
+	assert.Equal(t, AuditOperationUpgrade, auditOperation(status, "install", "sha256:new"))
+	assert.Equal(t, AuditOperationInstall, auditOperation(status, "other", "sha256:new"))
+	assert.Equal(t, AuditOperationInstall, auditOperation(Status{}, "install", "sha256:new"))
+}
+
+func TestEventAuditLog(t *testing.T) {
+	t.Parallel()
+	recorder := record.NewFakeRecorder(1)
+	auditLog := &EventAuditLog{EventRecorder: recorder}
+	finished := time.Now()
+	entry := AuditEntry{
+		Operation: AuditOperationInstall, Revision: "sha256:new", Outcome: AuditOutcomeFailed, Error: "apply failed",
+		StartedAt: finished.Add(-time.Minute), FinishedAt: finished,
+	}
+
+	require.NoError(t, auditLog.Record(context.Background(), auditedObj(), entry))
+	assert.Equal(t, `Warning Audit Install of revision "sha256:new" Failed after 1m0s: apply failed`, <-recorder.Events)
+}
+
+func TestConfigMapAuditLog(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	clnt := fake.NewClientBuilder().Build()
+	auditLog := &ConfigMapAuditLog{Client: clnt, MaxEntries: 2}
+	obj := auditedObj()
+
+	finished := time.Now()
+	for i := 0; i < 3; i++ {
+		entry := AuditEntry{Revision: string(rune('a' + i)), FinishedAt: finished.Add(time.Duration(i) * time.Second)}
+		require.NoError(t, auditLog.Record(ctx, obj, entry))
+	}
+
+	configMap := &v1.ConfigMap{}
+	require.NoError(t, clnt.Get(ctx, client.ObjectKey{Name: "audited-audit", Namespace: obj.GetNamespace()}, configMap))
+	assert.Equal(t, "audited", configMap.GetLabels()[auditConfigMapObjectLabel])
+	require.Len(t, configMap.Data, 2, "the oldest entry is dropped")
+	var revisions []string
+	for _, data := range configMap.Data {
+		entry := AuditEntry{}
+		require.NoError(t, json.Unmarshal([]byte(data), &entry))
+		revisions = append(revisions, entry.Revision)
+	}
+	assert.ElementsMatch(t, []string{"b", "c"}, revisions)
+}
@@ -34,10 +34,14 @@ func (c *ConcurrentCleanup) Run(ctx context.Context, infos []*resource.Info) err
 		go c.cleanupResource(ctx, infos[i], results)
 	}
 
+	collected, err := collectResults(ctx, "cleanup", results, len(infos))
+	if err != nil {
+		return err
+	}
+
 	var errs []error
 	present := len(infos)
-	for i := 0; i < len(infos); i++ {
-		err := <-results
+	for _, err := range collected {
 		if apierrors.IsNotFound(err) {
 			present--
 			continue
@@ -58,5 +62,7 @@ func (c *ConcurrentCleanup) Run(ctx context.Context, infos []*resource.Info) err
 }
 
 func (c *ConcurrentCleanup) cleanupResource(ctx context.Context, info *resource.Info, results chan error) {
-	results <- c.clnt.Delete(ctx, info.Object.(client.Object), c.policy)
+	results <- withPanicRecovery("cleanup", func() error {
+		return c.clnt.Delete(ctx, info.Object.(client.Object), c.policy)
+	})
 }
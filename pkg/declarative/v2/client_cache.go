@@ -2,6 +2,9 @@ package v2
 
 import (
 	"sync"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
 )
 
 type ClientCache interface {
@@ -9,25 +12,59 @@ type ClientCache interface {
 	SetClientInCache(key any, client Client)
 }
 
+// InvalidatingClientCache is a ClientCache that can remove clients, e.g. after the credentials of their cluster
+// rotated. Clients of a ClientCache without it are replaced by the next reconciliation instead.
+type InvalidatingClientCache interface {
+	ClientCache
+	// DeleteClientFromCache invalidates the client of key.
+	DeleteClientFromCache(key any)
+}
+
 type MemoryClientCache struct {
 	cache sync.Map // Cluster specific
+	stats *internal.CacheStats
+}
+
+type cachedClientEntry struct {
+	client  Client
+	created time.Time
 }
 
-// NewMemorySingletonClientCache returns a new instance of MemoryClientCache.
+// NewMemorySingletonClientCache returns a new instance of MemoryClientCache, registered as the "clients" cache.
 func NewMemorySingletonClientCache() *MemoryClientCache {
-	return &MemoryClientCache{
+	clientCache := &MemoryClientCache{
 		cache: sync.Map{},
 	}
+	clientCache.stats = internal.RegisterCache("clients", clientCache.snapshot)
+	return clientCache
 }
 
 func (r *MemoryClientCache) GetClientFromCache(key any) Client {
 	value, ok := r.cache.Load(key)
 	if !ok {
+		r.stats.Miss()
 		return nil
 	}
-	return value.(Client)
+	r.stats.Hit()
+	return value.(cachedClientEntry).client
 }
 
 func (r *MemoryClientCache) SetClientInCache(key any, client Client) {
-	r.cache.Store(key, client)
+	r.cache.Store(key, cachedClientEntry{client: client, created: time.Now()})
+}
+
+func (r *MemoryClientCache) DeleteClientFromCache(key any) {
+	r.cache.Delete(key)
+}
+
+func (r *MemoryClientCache) snapshot() internal.CacheSnapshot {
+	var snapshot internal.CacheSnapshot
+	r.cache.Range(func(_, value any) bool {
+		snapshot.Entries++
+		if created := value.(cachedClientEntry).created; snapshot.Oldest.IsZero() || created.Before(snapshot.Oldest) {
+			snapshot.Oldest = created
+		}
+		return true
+	})
+	return snapshot
 }
@@ -0,0 +1,63 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type cachedClient struct {
+	Client
+}
+
+func TestReconcilerInvalidateClient(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	reconciler := &Reconciler{Options: &Options{ClientCache: NewMemorySingletonClientCache()}}
+	key := client.ObjectKey{Name: "kyma", Namespace: "kcp-system"}
+	other := client.ObjectKey{Name: "other-kyma", Namespace: "kcp-system"}
+	reconciler.SetClientInCache(key, &cachedClient{})
+	reconciler.clientVersions.Store(key, "1")
+	reconciler.SetClientInCache(other, &cachedClient{})
+
+	reconciler.InvalidateClient(key)
+
+	asserts.Nil(reconciler.GetClientFromCache(key))
+	_, found := reconciler.clientVersions.Load(key)
+	asserts.False(found)
+	asserts.NotNil(reconciler.GetClientFromCache(other), "only the client of the invalidated key is removed")
+}
+
+// mapClientCache is a ClientCache that cannot remove clients.
+type mapClientCache map[any]Client
+
+func (c mapClientCache) GetClientFromCache(key any) Client {
+	return c[key]
+}
+
+func (c mapClientCache) SetClientInCache(key any, client Client) {
+	c[key] = client
+}
+
+func TestReconcilerInvalidateClientWithoutDeletion(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	cache := mapClientCache{}
+	reconciler := &Reconciler{Options: &Options{}}
+	WithSingletonClientCache(cache).Apply(reconciler.Options)
+	key := client.ObjectKey{Name: "kyma", Namespace: "kcp-system"}
+	reconciler.SetClientInCache(key, &cachedClient{})
+	reconciler.clientVersions.Store(key, "1")
+
+	reconciler.InvalidateClient(key)
+
+	version, _ := reconciler.clientVersions.Load(key)
+	asserts.Equal(invalidatedClient{}, version, "the client is replaced by the next reconciliation")
+	asserts.NotNil(cache[key])
+}
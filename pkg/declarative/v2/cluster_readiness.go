@@ -0,0 +1,199 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionReasonWaitingForCluster is the reason of the installation condition while the first install
+	// waits for the bootstrap of the target cluster, see WithClusterReadinessCheck.
+	ConditionReasonWaitingForCluster ConditionReason = "WaitingForCluster"
+
+	// ConditionTypeClusterWarning reports checks of the target cluster that failed without holding the install,
+	// see ErrClusterDegraded.
+	ConditionTypeClusterWarning    ConditionType   = "ClusterWarning"
+	ConditionReasonClusterDegraded ConditionReason = "ClusterDegraded"
+
+	webhookFailurePolicyFail = "Fail"
+)
+
+var (
+	ErrClusterNotReady = errors.New("target cluster is not ready")
+	// ErrClusterDegraded is wrapped by errors of checks that may fail the install but do not hold it.
+	ErrClusterDegraded = errors.New("target cluster is degraded")
+)
+
+// ClusterReadinessCheck verifies that the target cluster can run an install. It returns an error wrapping
+// ErrClusterNotReady while the cluster is not ready, or ErrClusterDegraded if the install can proceed but may fail.
+// All other errors are reported as failures of the check.
+type ClusterReadinessCheck interface {
+	Run(ctx context.Context, clnt Client) error
+}
+
+// WithClusterReadinessCheck holds the first install of every object until the target cluster passes the check,
+// so that installs do not race the bootstrap of new clusters and fail repeatedly. Waiting objects are Processing
+// with the ConditionReasonWaitingForCluster and are requeued after the DependencyRequeueInterval.
+// Objects that were installed once are never held, as their clusters are expected to recover on their own.
+func WithClusterReadinessCheck(check ClusterReadinessCheck) WithClusterReadinessCheckOption {
+	return WithClusterReadinessCheckOption{check: check}
+}
+
+type WithClusterReadinessCheckOption struct {
+	check ClusterReadinessCheck
+}
+
+func (o WithClusterReadinessCheckOption) Apply(options *Options) {
+	options.ClusterReadinessCheck = o.check
+}
+
+// BootstrapReadinessCheck is ready once the cluster has a schedulable Node that is Ready and the default
+// ServiceAccount exists. Afterwards it reports the cluster as degraded while webhooks that reject requests
+// on failure are not serving.
+type BootstrapReadinessCheck struct{}
+
+func NewBootstrapReadinessCheck() *BootstrapReadinessCheck {
+	return &BootstrapReadinessCheck{}
+}
+
+func (c *BootstrapReadinessCheck) Run(ctx context.Context, clnt Client) error {
+	for _, check := range []func(context.Context, client.Reader) error{
+		checkSchedulableNode, checkDefaultServiceAccount, checkClusterWebhooksServing,
+	} {
+		if err := check(ctx, clnt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkSchedulableNode(ctx context.Context, clnt client.Reader) error {
+	nodes := &corev1.NodeList{}
+	if err := clnt.List(ctx, nodes); err != nil {
+		return fmt.Errorf("listing nodes: %w", err)
+	}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: none of %d nodes is ready and schedulable", ErrClusterNotReady, len(nodes.Items))
+}
+
+// checkDefaultServiceAccount verifies that the controllers of the cluster are running, as pods cannot be
+// created in namespaces before their default ServiceAccount is created.
+func checkDefaultServiceAccount(ctx context.Context, clnt client.Reader) error {
+	key := client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "default"}
+	if err := clnt.Get(ctx, key, &corev1.ServiceAccount{}); apierrors.IsNotFound(err) {
+		return fmt.Errorf("%w: service account %s does not exist yet", ErrClusterNotReady, key)
+	} else if err != nil {
+		return fmt.Errorf("reading service account %s: %w", key, err)
+	}
+	return nil
+}
+
+// checkClusterWebhooksServing verifies that the webhooks installed by the bootstrap of the cluster are serving,
+// as their failures reject every matching request of the install. Webhooks that ignore failures are not checked,
+// neither are webhooks applied by a Reconciler, including those of the installed object and of its siblings,
+// as they may only be served once their install finished. Failures wrap ErrClusterDegraded.
+func checkClusterWebhooksServing(ctx context.Context, clnt client.Reader) error {
+	for _, kind := range []string{"ValidatingWebhookConfigurationList", "MutatingWebhookConfigurationList"} {
+		configurations := &unstructured.UnstructuredList{}
+		configurations.SetAPIVersion("admissionregistration.k8s.io/v1")
+		configurations.SetKind(kind)
+		if err := clnt.List(ctx, configurations); err != nil {
+			return fmt.Errorf("listing webhook configurations: %w", err)
+		}
+		for i := range configurations.Items {
+			configuration := &configurations.Items[i]
+			if configuration.GetLabels()[ManagedByLabel] == managedByLabelValue {
+				continue
+			}
+			webhooks, _, _ := unstructured.NestedSlice(configuration.Object, "webhooks")
+			for _, webhook := range webhooks {
+				webhook, ok := webhook.(map[string]any)
+				if !ok {
+					continue
+				}
+				// the failurePolicy defaults to Fail
+				if policy, found, _ := unstructured.NestedString(webhook, "failurePolicy"); found &&
+					policy != webhookFailurePolicyFail {
+					continue
+				}
+				clientConfig, _, _ := unstructured.NestedMap(webhook, "clientConfig")
+				err := checkWebhookClientConfig(ctx, clnt, configuration.GetName(), clientConfig)
+				if errors.Is(err, ErrResourcesNotReady) {
+					return fmt.Errorf("%w: %v", ErrClusterDegraded, err)
+				} else if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkClusterReadiness runs the ClusterReadinessCheck before the first install of obj. While the cluster is not
+// ready, obj is reported as Processing and the returned error wraps ErrClusterNotReady. A degraded cluster is
+// reported in the ConditionTypeClusterWarning without holding the install.
+func (r *Reconciler) checkClusterReadiness(ctx context.Context, clnt Client, obj Object) error {
+	status := obj.GetStatus()
+	if r.ClusterReadinessCheck == nil || status.InstalledOnce || !obj.GetDeletionTimestamp().IsZero() {
+		r.reportClusterWarning(obj, nil)
+		return nil
+	}
+	err := r.ClusterReadinessCheck.Run(ctx, clnt)
+	if err == nil || errors.Is(err, ErrClusterDegraded) {
+		r.reportClusterWarning(obj, err)
+		return nil
+	}
+	if !errors.Is(err, ErrClusterNotReady) {
+		err = fmt.Errorf("checking target cluster readiness: %w", err)
+		r.Event(obj, "Warning", "ClusterReadiness", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return err
+	}
+	msg := err.Error()
+	r.Event(obj, "Normal", string(ConditionReasonWaitingForCluster), msg)
+	installationCondition := newInstallationCondition(obj)
+	installationCondition.Reason = string(ConditionReasonWaitingForCluster)
+	installationCondition.Message = msg
+	meta.SetStatusCondition(&status.Conditions, installationCondition)
+	obj.SetStatus(status.WithState(StateProcessing).WithOperation(msg))
+	return err
+}
+
+// reportClusterWarning reports err of a degraded cluster in the ConditionTypeClusterWarning, which is removed
+// once the cluster is no longer degraded.
+func (r *Reconciler) reportClusterWarning(obj Object, err error) {
+	status := obj.GetStatus()
+	if err == nil {
+		if meta.FindStatusCondition(status.Conditions, string(ConditionTypeClusterWarning)) != nil {
+			meta.RemoveStatusCondition(&status.Conditions, string(ConditionTypeClusterWarning))
+			obj.SetStatus(status)
+		}
+		return
+	}
+	r.Event(obj, "Warning", string(ConditionReasonClusterDegraded), err.Error())
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               string(ConditionTypeClusterWarning),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(ConditionReasonClusterDegraded),
+		Message:            err.Error(),
+		ObservedGeneration: obj.GetGeneration(),
+	})
+	obj.SetStatus(status)
+}
@@ -0,0 +1,96 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBootstrapReadinessChecks(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	readyNode := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+		}},
+	}
+	cordonedNode := readyNode.DeepCopy()
+	cordonedNode.Spec.Unschedulable = true
+
+	assert.ErrorIs(t, checkSchedulableNode(ctx, fake.NewClientBuilder().WithObjects(cordonedNode).Build()),
+		ErrClusterNotReady)
+	assert.NoError(t, checkSchedulableNode(ctx, fake.NewClientBuilder().WithObjects(readyNode).Build()))
+
+	assert.ErrorIs(t, checkDefaultServiceAccount(ctx, fake.NewClientBuilder().Build()), ErrClusterNotReady)
+	serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"}}
+	assert.NoError(t, checkDefaultServiceAccount(ctx, fake.NewClientBuilder().WithObjects(serviceAccount).Build()))
+
+	ignored := webhookConfiguration("")
+	ignored.SetName("ignored")
+	webhooks, _, _ := unstructured.NestedSlice(ignored.Object, "webhooks")
+	webhooks[0].(map[string]any)["failurePolicy"] = "Ignore"
+	require.NoError(t, unstructured.SetNestedSlice(ignored.Object, webhooks, "webhooks"))
+	assert.NoError(t, checkClusterWebhooksServing(ctx, fake.NewClientBuilder().WithRuntimeObjects(ignored).Build()))
+	assert.ErrorIs(t, checkClusterWebhooksServing(ctx,
+		fake.NewClientBuilder().WithRuntimeObjects(webhookConfiguration("Y2E=")).Build()), ErrClusterDegraded)
+	managed := webhookConfiguration("Y2E=")
+	managed.SetLabels(map[string]string{ManagedByLabel: managedByLabelValue})
+	assert.NoError(t, checkClusterWebhooksServing(ctx, fake.NewClientBuilder().WithRuntimeObjects(managed).Build()),
+		"webhooks of installed objects are not checked")
+}
+
+type clusterReadinessFunc func() error
+
+func (f clusterReadinessFunc) Run(context.Context, Client) error { return f() }
+
+func TestCheckClusterReadiness(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	notReady := clusterReadinessFunc(func() error { return ErrClusterNotReady })
+	r := &Reconciler{Options: &Options{ClusterReadinessCheck: notReady, EventRecorder: record.NewFakeRecorder(10)}}
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	require.ErrorIs(t, r.checkClusterReadiness(ctx, nil, obj), ErrClusterNotReady)
+	assert.Equal(t, StateProcessing, obj.GetStatus().State)
+	condition := meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeInstallation))
+	require.NotNil(t, condition)
+	assert.Equal(t, string(ConditionReasonWaitingForCluster), condition.Reason)
+
+	installed := &statusObj{Unstructured: &unstructured.Unstructured{}, status: Status{InstalledOnce: true}}
+	assert.NoError(t, r.checkClusterReadiness(ctx, nil, installed), "installed objects are never held")
+
+	degraded := &Reconciler{Options: &Options{
+		ClusterReadinessCheck: clusterReadinessFunc(func() error { return ErrClusterDegraded }),
+		EventRecorder:         record.NewFakeRecorder(10),
+	}}
+	warned := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	assert.NoError(t, degraded.checkClusterReadiness(ctx, nil, warned), "degraded clusters do not hold the install")
+	assert.True(t, meta.IsStatusConditionTrue(warned.GetStatus().Conditions, string(ConditionTypeClusterWarning)))
+	status := warned.GetStatus()
+	status.InstalledOnce = true
+	warned.SetStatus(status)
+	assert.NoError(t, degraded.checkClusterReadiness(ctx, nil, warned))
+	assert.Nil(t, meta.FindStatusCondition(warned.GetStatus().Conditions, string(ConditionTypeClusterWarning)))
+
+	failing := &Reconciler{Options: &Options{
+		ClusterReadinessCheck: clusterReadinessFunc(func() error { return errors.New("forbidden") }),
+		EventRecorder:         record.NewFakeRecorder(10),
+	}}
+	err := failing.checkClusterReadiness(ctx, nil, obj)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrClusterNotReady)
+	assert.Equal(t, StateError, obj.GetStatus().State)
+}
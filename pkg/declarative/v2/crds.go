@@ -1,64 +1,49 @@
 package v2
 
 import (
-	"sync"
-
 	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/resource"
 	"github.com/kyma-project/module-manager/pkg/types"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/kube"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
-func getCRDs(clnt Client, crdFiles []chart.CRD) (kube.ResourceList, error) {
+// getCRDs parses the crds of a chart with resource.ParseCRDs, so that they are installed with resource.InstallCRDs,
+// and returns them together with their resource infos for the readiness checks and the removal.
+func getCRDs(
+	clnt Client, crdFiles []chart.CRD,
+) ([]*apiextensionsv1.CustomResourceDefinition, kube.ResourceList, error) {
 	var crdDocs [][]byte
 	for _, crdFile := range crdFiles {
 		if crdFile.File != nil {
 			crdDocs = append(crdDocs, crdFile.File.Data)
 		}
 	}
-	crdManifest := internal.JoinYAMLDocuments(crdDocs)
-	crdsObjects, err := internal.ParseManifestStringToObjects(crdManifest)
+	crds, err := resource.ParseCRDs([]byte(internal.JoinYAMLDocuments(crdDocs)))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	var crds kube.ResourceList
-	errs := make([]error, 0, len(crdsObjects.Items))
-	for _, crd := range crdsObjects.Items {
-		crdInfo, err := clnt.ResourceInfo(crd, false)
+	var infos kube.ResourceList
+	errs := make([]error, 0, len(crds))
+	for _, crd := range crds {
+		crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
 		if err != nil {
 			errs = append(errs, err)
 			continue
 		}
-		crds = append(crds, crdInfo)
-	}
-	if len(errs) > 0 {
-		return nil, types.NewMultiError(errs)
-	}
-	return crds, nil
-}
-
-func installCRDs(clnt Client, crds kube.ResourceList) error {
-	crdInstallWaitGroup := sync.WaitGroup{}
-	errChan := make(chan error, len(crds))
-	createCRD := func(i int) {
-		defer crdInstallWaitGroup.Done()
-		_, err := clnt.KubeClient().Create(kube.ResourceList{crds[i]})
-		errChan <- err
-	}
-
-	for i := range crds {
-		crdInstallWaitGroup.Add(1)
-		go createCRD(i)
-	}
-	crdInstallWaitGroup.Wait()
-	close(errChan)
-
-	for err := range errChan {
-		if err == nil || apierrors.IsAlreadyExists(err) {
+		crdInfo, err := clnt.ResourceInfo(&unstructured.Unstructured{Object: obj}, false)
+		if err != nil {
+			errs = append(errs, err)
 			continue
 		}
-		return err
+		infos = append(infos, crdInfo)
+	}
+	if len(errs) > 0 {
+		return nil, nil, types.NewMultiError(errs)
 	}
-	return nil
+	return crds, infos, nil
 }
@@ -0,0 +1,87 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kyma-project/module-manager/pkg/labels"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ForceDeletionAnnotation set to "true" removes the finalizer without verifying that all owned resources
+// are gone from the target cluster, e.g. if owned resources are stuck on finalizers that are never removed.
+const ForceDeletionAnnotation = "declarative.kyma-project.io/force-deletion"
+
+func isForcedDeletion(obj Object) bool {
+	return obj.GetAnnotations()[ForceDeletionAnnotation] == "true"
+}
+
+const (
+	// DeletionProtectionAnnotation set to "true" keeps the resources of an object on deletion
+	// until the deletion is confirmed with the ConfirmDeletionAnnotation.
+	DeletionProtectionAnnotation = "declarative.kyma-project.io/deletion-protection"
+	// ConfirmDeletionAnnotation set to "true" confirms the deletion of an object with DeletionProtectionAnnotation.
+	ConfirmDeletionAnnotation = "declarative.kyma-project.io/confirm-deletion"
+)
+
+// IsDeletionBlocked determines if obj is protected by the DeletionProtectionAnnotation
+// and its deletion was not confirmed with the ConfirmDeletionAnnotation.
+func IsDeletionBlocked(obj metav1.Object) bool {
+	annotations := obj.GetAnnotations()
+	return annotations[DeletionProtectionAnnotation] == "true" && annotations[ConfirmDeletionAnnotation] != "true"
+}
+
+// remainingOwnedResources lists the resources in the cluster that are labeled with the labels.OwnedByLabel
+// of obj, for every kind that was synced. Resources that are already terminating are considered remaining,
+// adopted namespaces and resources kept by their ResourcePolicyAnnotation are not, as they are never deleted.
+func remainingOwnedResources(ctx context.Context, clnt client.Reader, obj Object) ([]string, error) {
+	selector := client.MatchingLabels{
+		labels.OwnedByLabel: fmt.Sprintf(labels.OwnedByFormat, obj.GetNamespace(), obj.GetName()),
+	}
+
+	var remaining []string
+	for _, gvk := range syncedKinds(obj.GetStatus().Synced) {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := clnt.List(ctx, list, selector); meta.IsNoMatchError(err) {
+			// the kind was removed from the cluster, e.g. together with its CRD
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("owned %s could not be listed: %w", gvk.Kind, err)
+		}
+		for _, item := range list.Items {
+			if isAdoptedNamespace(gvk, &item) || isKeptResource(item.GetAnnotations()) {
+				continue
+			}
+			remaining = append(remaining, strings.TrimPrefix(item.GetNamespace()+"/"+item.GetName(), "/")+
+				" ("+gvk.Kind+")")
+		}
+	}
+	return remaining, nil
+}
+
+func syncedKinds(synced []Resource) []schema.GroupVersionKind {
+	seen := make(map[schema.GroupVersionKind]bool, len(synced))
+	kinds := make([]schema.GroupVersionKind, 0, len(synced))
+	for _, resource := range synced {
+		gvk := schema.GroupVersionKind(resource.GroupVersionKind)
+		if !seen[gvk] {
+			seen[gvk] = true
+			kinds = append(kinds, gvk)
+		}
+	}
+	return kinds
+}
+
+func isAdoptedNamespace(gvk schema.GroupVersionKind, item *metav1.PartialObjectMetadata) bool {
+	if gvk.GroupKind() != v1.SchemeGroupVersion.WithKind("Namespace").GroupKind() {
+		return false
+	}
+	_, created := item.GetLabels()[NamespaceCreatedByLabel]
+	return !created || isProtectedNamespace(item.GetName())
+}
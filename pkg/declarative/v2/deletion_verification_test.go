@@ -0,0 +1,57 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/labels"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRemainingOwnedResources(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("manifest")
+	obj.SetNamespace("kcp-system")
+	obj.SetStatus(Status{Synced: []Resource{
+		{Name: "deleted", Namespace: metav1.NamespaceDefault, GroupVersionKind: metav1.GroupVersionKind{
+			Version: "v1", Kind: "ConfigMap",
+		}},
+		{Name: "adopted", GroupVersionKind: metav1.GroupVersionKind{Version: "v1", Kind: "Namespace"}},
+	}})
+	owned := map[string]string{labels.OwnedByLabel: fmt.Sprintf(labels.OwnedByFormat, "kcp-system", "manifest")}
+
+	clnt := fake.NewClientBuilder().WithObjects(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "adopted", Labels: owned}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "unrelated", Namespace: metav1.NamespaceDefault,
+		}},
+	).Build()
+	remaining, err := remainingOwnedResources(ctx, clnt, obj)
+	assertions.NoError(err)
+	assertions.Empty(remaining, "adopted namespaces and unlabeled resources are not remaining")
+
+	clnt = fake.NewClientBuilder().WithObjects(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "orphaned", Namespace: metav1.NamespaceDefault, Labels: owned,
+		}},
+	).Build()
+	remaining, err = remainingOwnedResources(ctx, clnt, obj)
+	assertions.NoError(err)
+	assertions.Equal([]string{"default/orphaned (ConfigMap)"}, remaining)
+
+	assertions.False(isForcedDeletion(obj))
+	obj.SetAnnotations(map[string]string{ForceDeletionAnnotation: "true"})
+	assertions.True(isForcedDeletion(obj))
+}
@@ -0,0 +1,72 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ConditionReasonWaitingForDependency is the reason of the installation condition while resources
+	// cannot be applied or checked because their kind or namespace is not installed yet, e.g. by another module.
+	ConditionReasonWaitingForDependency ConditionReason = "WaitingForDependency"
+
+	DefaultDependencyRequeueInterval = 30 * time.Second
+)
+
+var ErrWaitingForDependency = errors.New("waiting for dependency")
+
+// WithDependencyRequeueInterval requeues objects that wait for a dependency after the interval
+// instead of the backoff of the queue, as their resources cannot succeed before the dependency is installed.
+type WithDependencyRequeueInterval time.Duration
+
+func (o WithDependencyRequeueInterval) Apply(options *Options) {
+	options.DependencyRequeueInterval = time.Duration(o)
+}
+
+// isMissingDependency determines if err is only caused by kinds without a matching CRD or by resources
+// and namespaces that are not found. Collected errors are only missing dependencies if all of them are.
+func isMissingDependency(err error) bool {
+	if err == nil {
+		return false
+	}
+	var multiErr *types.MultiError
+	if errors.As(err, &multiErr) {
+		for _, err := range multiErr.Errs {
+			if !isMissingDependency(err) {
+				return false
+			}
+		}
+		return len(multiErr.Errs) > 0
+	}
+	return meta.IsNoMatchError(err) || apierrors.IsNotFound(err)
+}
+
+// waitForDependency reports an object as Processing instead of Error while it waits for a dependency
+// and returns ErrWaitingForDependency.
+func (r *Reconciler) waitForDependency(obj Object, status Status, err error) error {
+	msg := fmt.Sprintf("%s: %s", ErrWaitingForDependency, err)
+	r.Event(obj, "Normal", string(ConditionReasonWaitingForDependency), msg)
+	installationCondition := newInstallationCondition(obj)
+	installationCondition.Reason = string(ConditionReasonWaitingForDependency)
+	installationCondition.Message = msg
+	meta.SetStatusCondition(&status.Conditions, installationCondition)
+	obj.SetStatus(status.WithState(StateProcessing).WithOperation(msg))
+	return fmt.Errorf("%w: %v", ErrWaitingForDependency, err)
+}
+
+// awaitDependency updates the status of an object that waits for a dependency and requeues it after
+// the DependencyRequeueInterval if configured.
+func (r *Reconciler) awaitDependency(ctx context.Context, obj Object, spec *Spec) (ctrl.Result, error) {
+	result, err := r.ssaInstallStatus(ctx, obj, spec)
+	if err != nil || r.DependencyRequeueInterval <= 0 {
+		return result, err
+	}
+	return ctrl.Result{RequeueAfter: r.DependencyRequeueInterval}, nil
+}
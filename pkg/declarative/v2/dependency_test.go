@@ -0,0 +1,47 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestWaitForDependency(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	noMatch := &meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "keda.sh", Kind: "ScaledObject"}}
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "keda")
+	assertions.True(isMissingDependency(fmt.Errorf("patch failed: %w", noMatch)))
+	assertions.True(isMissingDependency(notFound))
+	assertions.True(isMissingDependency(
+		fmt.Errorf("ServerSideApply failed: %w", types.NewMultiError([]error{noMatch, notFound})),
+	))
+	assertions.False(isMissingDependency(
+		fmt.Errorf("ServerSideApply failed: %w", types.NewMultiError([]error{noMatch, errors.New("invalid")})),
+	), "genuine failures are not hidden by missing dependencies")
+	assertions.False(isMissingDependency(errors.New("invalid")))
+	assertions.False(isMissingDependency(nil))
+
+	recorder := record.NewFakeRecorder(1)
+	reconciler := &Reconciler{Options: &Options{EventRecorder: recorder}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	err := reconciler.waitForDependency(obj, obj.GetStatus(), noMatch)
+	assertions.ErrorIs(err, ErrWaitingForDependency)
+	assertions.Equal(StateProcessing, obj.GetStatus().State)
+	condition := meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeInstallation))
+	assertions.NotNil(condition)
+	assertions.Equal(string(ConditionReasonWaitingForDependency), condition.Reason)
+	assertions.Contains(<-recorder.Events, "ScaledObject")
+}
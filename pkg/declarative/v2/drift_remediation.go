@@ -0,0 +1,238 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/diff"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RemediationMode determines how consistency checks of an object handle resources that drifted in the cluster.
+type RemediationMode string
+
+const (
+	// RemediationEnforce reports drifted resources and applies them again.
+	RemediationEnforce RemediationMode = "Enforce"
+	// RemediationDetect only reports drifted resources, consistency checks do not apply resources.
+	RemediationDetect RemediationMode = "Detect"
+	// RemediationOff neither detects drift nor applies resources in consistency checks.
+	RemediationOff RemediationMode = "Off"
+)
+
+const (
+	ConditionTypeDrift             ConditionType   = "Drift"
+	ConditionReasonNoDrift         ConditionReason = "NoDrift"
+	ConditionReasonDriftDetected   ConditionReason = "DriftDetected"
+	ConditionReasonDriftRemediated ConditionReason = "DriftRemediated"
+)
+
+// maxReportedDrift bounds the drifted resources listed in the Drift condition and maxReportedDriftFields the
+// fields listed for every drifted resource.
+const (
+	maxReportedDrift       = 10
+	maxReportedDriftFields = 3
+)
+
+// WithRemediation determines the RemediationMode of every object. Objects without a mode are applied in
+// every consistency check without detecting drift.
+type WithRemediation func(obj Object) RemediationMode
+
+func (o WithRemediation) Apply(options *Options) {
+	options.Remediation = o
+}
+
+func (r *Reconciler) remediationMode(obj Object) RemediationMode {
+	if r.Remediation == nil {
+		return ""
+	}
+	return r.Remediation(obj)
+}
+
+// isConsistencyCheck is true if obj is ready and spec was already applied for the current generation of obj,
+// so that applying the resources again only corrects drift.
+func isConsistencyCheck(obj Object, spec *Spec) bool {
+	status := obj.GetStatus()
+	return obj.GetDeletionTimestamp().IsZero() && status.State == StateReady && !status.Journal.InFlight() &&
+		!resyncRequested(obj) && status.ObservedGeneration == obj.GetGeneration() &&
+		(spec.Hash == "" || status.SpecHash == spec.Hash)
+}
+
+// remediateDrift detects drift of target in consistency checks and reports it in the Drift condition.
+// It returns false if target must not be applied in this reconciliation.
+func (r *Reconciler) remediateDrift(
+	ctx context.Context, clnt client.Client, obj Object, spec *Spec, target []*resource.Info,
+) bool {
+	mode := r.remediationMode(obj)
+	if mode == "" || !isConsistencyCheck(obj, spec) {
+		return true
+	}
+	if mode == RemediationOff {
+		return false
+	}
+
+	drifted, err := detectDrift(ctx, clnt, r.FieldOwner, obj, target)
+	if err != nil {
+		// the drift is reported by the next consistency check, the apply itself is not affected
+		log.FromContext(ctx).V(internal.DebugLogLevel).Info("drift detection failed", "error", err.Error())
+		return mode == RemediationEnforce
+	}
+	withDriftCondition(obj, mode, drifted)
+	if len(drifted) > 0 {
+		r.Event(obj, "Warning", "Drift", fmt.Sprintf("%d resources drifted: %s", len(drifted), driftSummary(drifted)))
+	}
+	return mode == RemediationEnforce
+}
+
+// detectDrift compares the result of a server-side dry-run apply of every target resource with the live resource
+// and returns the names of the resources that would be changed by an apply, including missing ones, together with
+// the differing fields. The live resources are listed once per kind by the labels.OwnedByLabel of obj, only resources
+// missing from the lists, e.g. because their labels were changed, are read one by one.
+func detectDrift(
+	ctx context.Context, clnt client.Client, fieldOwner client.FieldOwner, obj Object, target []*resource.Info,
+) ([]string, error) {
+	live, err := listOwnedResources(ctx, clnt, obj, target)
+	if err != nil {
+		return nil, err
+	}
+	var drifted []string
+	for _, info := range target {
+		desired, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		current, listed := live[ownedResourceKey(desired)]
+		if !listed {
+			if current, err = getLiveResource(ctx, clnt, desired); err != nil {
+				return nil, fmt.Errorf("%s could not be fetched: %w", info.ObjectName(), err)
+			}
+			if current == nil {
+				drifted = append(drifted, info.ObjectName()+" (missing)")
+				continue
+			}
+		}
+		applied := desired.DeepCopy()
+		applied.SetResourceVersion("")
+		applied.SetManagedFields(nil)
+		if err := clnt.Patch(ctx, applied, client.Apply, client.DryRunAll, client.ForceOwnership,
+			fieldOwner); err != nil {
+			return nil, fmt.Errorf("dry-run apply of %s failed: %w", info.ObjectName(), err)
+		}
+		if fields := diff.Fields(current, applied); len(fields) > 0 {
+			drifted = append(drifted, fmt.Sprintf("%s (%s)", info.ObjectName(), driftedFieldsSummary(fields)))
+		}
+	}
+	return drifted, nil
+}
+
+// listOwnedResources lists the resources labeled with the labels.OwnedByLabel of obj once for every kind of target.
+func listOwnedResources(
+	ctx context.Context, clnt client.Reader, obj Object, target []*resource.Info,
+) (map[string]*unstructured.Unstructured, error) {
+	selector := client.MatchingLabels{
+		labels.OwnedByLabel: fmt.Sprintf(labels.OwnedByFormat, obj.GetNamespace(), obj.GetName()),
+	}
+	live := make(map[string]*unstructured.Unstructured, len(target))
+	listed := make(map[schema.GroupVersionKind]bool)
+	for _, info := range target {
+		desired, ok := info.Object.(*unstructured.Unstructured)
+		if !ok || listed[desired.GroupVersionKind()] {
+			continue
+		}
+		gvk := desired.GroupVersionKind()
+		listed[gvk] = true
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := clnt.List(ctx, list, selector); meta.IsNoMatchError(err) {
+			// the kind is not served yet, e.g. because its CRD is installed by the apply
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("owned %s could not be listed: %w", gvk.Kind, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			item.SetGroupVersionKind(gvk)
+			live[ownedResourceKey(item)] = item
+		}
+	}
+	return live, nil
+}
+
+// getLiveResource reads a resource that is not labeled as owned, its existence is checked with a metadata read
+// first, so that missing resources are served by the MetadataInformerCache if configured.
+// It returns nil if the resource does not exist.
+func getLiveResource(
+	ctx context.Context, clnt client.Reader, desired *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	metadata := &metav1.PartialObjectMetadata{}
+	metadata.SetGroupVersionKind(desired.GroupVersionKind())
+	if err := clnt.Get(ctx, client.ObjectKeyFromObject(desired), metadata); err != nil {
+		if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(desired.GroupVersionKind())
+	if err := clnt.Get(ctx, client.ObjectKeyFromObject(desired), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+func ownedResourceKey(obj *unstructured.Unstructured) string {
+	return obj.GroupVersionKind().GroupKind().String() + "/" + obj.GetNamespace() + "/" + obj.GetName()
+}
+
+// driftedFieldsSummary lists at most maxReportedDriftFields of the fields that differ in a drifted resource.
+func driftedFieldsSummary(fields []string) string {
+	if len(fields) <= maxReportedDriftFields {
+		return strings.Join(fields, ", ")
+	}
+	return fmt.Sprintf("%s and %d more",
+		strings.Join(fields[:maxReportedDriftFields], ", "), len(fields)-maxReportedDriftFields)
+}
+
+// withDriftCondition reports drifted in the Drift condition of obj.
+func withDriftCondition(obj Object, mode RemediationMode, drifted []string) {
+	status := obj.GetStatus()
+	condition := metav1.Condition{
+		Type:               string(ConditionTypeDrift),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(ConditionReasonNoDrift),
+		Message:            "resources match the rendered resources",
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	if len(drifted) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = string(ConditionReasonDriftDetected)
+		condition.Message = "drifted resources: " + driftSummary(drifted)
+		if mode == RemediationEnforce {
+			condition.Reason = string(ConditionReasonDriftRemediated)
+			condition.Message = "drifted resources are applied again: " + driftSummary(drifted)
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	obj.SetStatus(status)
+}
+
+func driftSummary(drifted []string) string {
+	if len(drifted) <= maxReportedDrift {
+		return strings.Join(drifted, ", ")
+	}
+	return fmt.Sprintf("%s and %d more",
+		strings.Join(drifted[:maxReportedDrift], ", "), len(drifted)-maxReportedDrift)
+}
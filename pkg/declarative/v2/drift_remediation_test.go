@@ -0,0 +1,76 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRemediateDrift(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	var mode RemediationMode
+	recorder := record.NewFakeRecorder(2)
+	reconciler := &Reconciler{Options: &Options{
+		EventRecorder: recorder,
+		FieldOwner:    FieldOwnerDefault,
+		Remediation:   func(Object) RemediationMode { return mode },
+	}}
+	clnt := fake.NewClientBuilder().Build()
+	target := []*resource.Info{configMapInfo("missing")}
+	spec := &Spec{}
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetGeneration(1)
+	obj.SetStatus(Status{State: StateProcessing})
+	mode = RemediationOff
+	assertions.True(reconciler.remediateDrift(ctx, clnt, obj, spec, target),
+		"resources are applied until the object is ready")
+
+	obj.SetStatus(Status{State: StateReady, ObservedGeneration: 1})
+	assertions.False(reconciler.remediateDrift(ctx, clnt, obj, spec, target))
+	assertions.Nil(meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeDrift)))
+
+	mode = RemediationDetect
+	assertions.False(reconciler.remediateDrift(ctx, clnt, obj, spec, target))
+	condition := meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeDrift))
+	assertions.NotNil(condition)
+	assertions.Equal(metav1.ConditionTrue, condition.Status)
+	assertions.Equal(string(ConditionReasonDriftDetected), condition.Reason)
+	assertions.Contains(condition.Message, "missing")
+	assertions.Contains(<-recorder.Events, "1 resources drifted")
+
+	mode = RemediationEnforce
+	assertions.True(reconciler.remediateDrift(ctx, clnt, obj, spec, target))
+	condition = meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeDrift))
+	assertions.Equal(string(ConditionReasonDriftRemediated), condition.Reason)
+
+	obj.SetGeneration(2)
+	mode = RemediationDetect
+	assertions.True(reconciler.remediateDrift(ctx, clnt, obj, spec, target),
+		"changes of the object are applied regardless of the mode")
+}
+
+func TestDriftSummary(t *testing.T) {
+	t.Parallel()
+	drifted := make([]string, maxReportedDrift+2)
+	for i := range drifted {
+		drifted[i] = "ConfigMap/cm"
+	}
+	assert.Contains(t, driftSummary(drifted), "and 2 more")
+	assert.Equal(t, "ConfigMap/a, ConfigMap/b", driftSummary([]string{"ConfigMap/a", "ConfigMap/b"}))
+	assert.Equal(t, "data.a, data.b, data.c and 1 more",
+		driftedFieldsSummary([]string{"data.a", "data.b", "data.c", "data.d"}))
+}
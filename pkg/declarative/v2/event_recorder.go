@@ -0,0 +1,32 @@
+package v2
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/module-manager/internal"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// redactingEventRecorder masks the values of sensitive keys in event messages, as they usually contain
+// errors of rendering or applying the values of an object, like the status does.
+type redactingEventRecorder struct {
+	record.EventRecorder
+}
+
+func (r *redactingEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.EventRecorder.Event(object, eventtype, reason, internal.RedactString(message))
+}
+
+func (r *redactingEventRecorder) Eventf(
+	object runtime.Object, eventtype, reason, messageFmt string, args ...interface{},
+) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *redactingEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string,
+	eventtype, reason, messageFmt string, args ...interface{},
+) {
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s",
+		internal.RedactString(fmt.Sprintf(messageFmt, args...)))
+}
@@ -0,0 +1,26 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRedactingEventRecorder(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	fakeRecorder := record.NewFakeRecorder(2)
+	recorder := &redactingEventRecorder{EventRecorder: fakeRecorder}
+	obj := &unstructured.Unstructured{}
+
+	recorder.Event(obj, "Warning", "Render", "invalid value db.password=hunter2")
+	recorder.Eventf(obj, "Warning", "Render", "invalid value %s=%s", "apiKey", "12345")
+	assertions.Equal("Warning Render invalid value db.password=***", <-fakeRecorder.Events)
+	assertions.Equal("Warning Render invalid value apiKey=***", <-fakeRecorder.Events)
+}
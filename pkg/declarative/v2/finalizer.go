@@ -0,0 +1,37 @@
+package v2
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// RemoveFinalizer removes finalizer from obj with a merge patch that is guarded by the resourceVersion of obj,
+// as finalizers cannot be removed with server-side apply. On conflicts with concurrent writers, obj is read again
+// and the removal is retried, so that finalizers added in the meantime are kept.
+// It returns false if obj does not carry the finalizer.
+func RemoveFinalizer(
+	ctx context.Context, clnt client.Client, obj client.Object, finalizer string, opts ...client.PatchOption,
+) (bool, error) {
+	removed := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		original, ok := obj.DeepCopyObject().(client.Object)
+		if !ok {
+			return nil
+		}
+		if removed = controllerutil.RemoveFinalizer(obj, finalizer); !removed {
+			return nil
+		}
+		err := clnt.Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}), opts...)
+		if apierrors.IsConflict(err) {
+			if err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+		}
+		return err
+	})
+	return removed, client.IgnoreNotFound(err)
+}
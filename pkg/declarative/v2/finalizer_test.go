@@ -0,0 +1,43 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRemoveFinalizerRetriesOnConflict(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	clnt := fake.NewClientBuilder().WithObjects(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "finalized", Namespace: metav1.NamespaceDefault, Finalizers: []string{"declarative"},
+	}}).Build()
+	stale := &v1.ConfigMap{}
+	assertions.NoError(clnt.Get(ctx, client.ObjectKey{Name: "finalized", Namespace: metav1.NamespaceDefault}, stale))
+
+	concurrent := stale.DeepCopy()
+	concurrent.Finalizers = append(concurrent.Finalizers, "concurrent")
+	assertions.NoError(clnt.Update(ctx, concurrent))
+
+	removed, err := RemoveFinalizer(ctx, clnt, stale, "declarative")
+	assertions.NoError(err)
+	assertions.True(removed)
+
+	live := &v1.ConfigMap{}
+	assertions.NoError(clnt.Get(ctx, client.ObjectKeyFromObject(stale), live))
+	assertions.Equal([]string{"concurrent"}, live.Finalizers, "concurrently added finalizers are kept")
+
+	removed, err = RemoveFinalizer(ctx, clnt, live, "declarative")
+	assertions.NoError(err)
+	assertions.False(removed)
+}
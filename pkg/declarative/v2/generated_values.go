@@ -0,0 +1,65 @@
+package v2
+
+import (
+	"context"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GeneratedValuesAnnotation set to "keep" on a rendered Secret or ConfigMap keeps the values of its data that
+// already exist in the cluster instead of applying the rendered ones. Charts render in dry-run mode without
+// access to helm's lookup function, so values generated during rendering, e.g. with randAlphaNum,
+// change with every render and would otherwise be rotated whenever the manifest is rendered again.
+const GeneratedValuesAnnotation = "declarative.kyma-project.io/generated-values"
+
+const keepGeneratedValues = "keep"
+
+// keepGeneratedValuesFromCluster replaces the data of annotated Secrets and ConfigMaps in target with the
+// values of the same keys in the cluster. Keys that do not exist in the cluster yet are applied as rendered.
+func keepGeneratedValuesFromCluster(ctx context.Context, clnt client.Client, target []*resource.Info) error {
+	for _, info := range target {
+		rendered, ok := info.Object.(*unstructured.Unstructured)
+		if !ok || rendered.GetAnnotations()[GeneratedValuesAnnotation] != keepGeneratedValues {
+			continue
+		}
+		if kind := rendered.GetKind(); rendered.GroupVersionKind().Group != "" ||
+			(kind != "Secret" && kind != "ConfigMap") {
+			continue
+		}
+
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(rendered.GroupVersionKind())
+		err := clnt.Get(ctx, client.ObjectKeyFromObject(rendered), existing)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		for _, field := range []string{"data", "binaryData"} {
+			existingData, _, _ := unstructured.NestedMap(existing.Object, field)
+			for key, value := range existingData {
+				if keepExistingValue(rendered, field, key) {
+					_ = unstructured.SetNestedField(rendered.Object, value, field, key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// keepExistingValue returns true if the rendered object contains key in field.
+// Values rendered into the stringData of Secrets are removed, as they would take precedence over data.
+func keepExistingValue(rendered *unstructured.Unstructured, field, key string) bool {
+	_, found, _ := unstructured.NestedFieldNoCopy(rendered.Object, field, key)
+	if field == "data" && rendered.GetKind() == "Secret" {
+		if _, inStringData, _ := unstructured.NestedFieldNoCopy(rendered.Object, "stringData", key); inStringData {
+			unstructured.RemoveNestedField(rendered.Object, "stringData", key)
+			found = true
+		}
+	}
+	return found
+}
@@ -0,0 +1,57 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func renderedSecret(name string, annotations map[string]string, stringData map[string]any) *resource.Info {
+	secret := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "v1", "kind": "Secret",
+		"metadata":   map[string]any{"name": name, "namespace": metav1.NamespaceDefault},
+		"stringData": stringData,
+	}}
+	secret.SetAnnotations(annotations)
+	return &resource.Info{Name: name, Namespace: metav1.NamespaceDefault, Object: secret}
+}
+
+func TestKeepGeneratedValuesFromCluster(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	clnt := fake.NewClientBuilder().WithObjects(
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "generated", Namespace: metav1.NamespaceDefault},
+			Data:       map[string][]byte{"password": []byte("existing")},
+		},
+		&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "rotated", Namespace: metav1.NamespaceDefault},
+			Data:       map[string][]byte{"password": []byte("existing")},
+		},
+	).Build()
+
+	keep := map[string]string{GeneratedValuesAnnotation: keepGeneratedValues}
+	generated := renderedSecret("generated", keep, map[string]any{"password": "random", "user": "admin"})
+	rotated := renderedSecret("rotated", nil, map[string]any{"password": "random"})
+	missing := renderedSecret("missing", keep, map[string]any{"password": "random"})
+
+	assertions.NoError(keepGeneratedValuesFromCluster(ctx, clnt, []*resource.Info{generated, rotated, missing}))
+
+	secret := generated.Object.(*unstructured.Unstructured)
+	assertions.Equal(map[string]any{"password": "ZXhpc3Rpbmc="}, secret.Object["data"], "existing values are kept")
+	assertions.Equal(map[string]any{"user": "admin"}, secret.Object["stringData"], "new values are applied")
+	for _, info := range []*resource.Info{rotated, missing} {
+		assertions.Equal(map[string]any{"password": "random"}, info.Object.(*unstructured.Unstructured).Object["stringData"])
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"reflect"
 
+	"github.com/kyma-project/module-manager/pkg/resource"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/kube"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -24,11 +29,13 @@ func NewHelmRenderer(
 	options *Options,
 ) Renderer {
 	return &Helm{
-		recorder:   options.EventRecorder,
-		chartPath:  spec.Path,
-		values:     spec.Values,
-		clnt:       clnt,
-		crdChecker: NewHelmReadyCheck(clnt),
+		recorder:    options.EventRecorder,
+		chartPath:   spec.Path,
+		values:      spec.Values,
+		flags:       spec.InstallFlags,
+		releaseName: spec.ReleaseName,
+		clnt:        clnt,
+		crdChecker:  NewHelmReadyCheck(clnt),
 	}
 }
 
@@ -36,8 +43,10 @@ type Helm struct {
 	recorder record.EventRecorder
 	clnt     Client
 
-	chartPath string
-	values    any
+	chartPath   string
+	values      any
+	flags       types.Flags
+	releaseName string
 
 	crds kube.ResourceList
 
@@ -76,7 +85,7 @@ func (h *Helm) EnsurePrerequisites(ctx context.Context, obj Object) error {
 		return nil
 	}
 
-	chrt, err := loader.Load(h.chartPath)
+	chrt, err := loadChart(h.chartPath)
 	if err != nil {
 		h.recorder.Event(obj, "Warning", "ChartLoading", err.Error())
 		meta.SetStatusCondition(&status.Conditions, h.prerequisiteCondition(obj))
@@ -84,16 +93,16 @@ func (h *Helm) EnsurePrerequisites(ctx context.Context, obj Object) error {
 		return err
 	}
 
-	crds, err := getCRDs(h.clnt, chrt.CRDObjects())
+	crds, crdInfos, err := getCRDs(h.clnt, chrt.CRDObjects())
 	if err != nil {
 		h.recorder.Event(obj, "Warning", "CRDParsing", err.Error())
 		meta.SetStatusCondition(&status.Conditions, h.prerequisiteCondition(obj))
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
 		return err
 	}
-	h.crds = crds
+	h.crds = crdInfos
 
-	if err := installCRDs(h.clnt, h.crds); err != nil {
+	if err := resource.InstallCRDs(ctx, h.clnt, crds); err != nil {
 		h.recorder.Event(obj, "Warning", "CRDInstallation", err.Error())
 		meta.SetStatusCondition(&status.Conditions, h.prerequisiteCondition(obj))
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
@@ -157,7 +166,7 @@ func (h *Helm) Render(ctx context.Context, obj Object) ([]byte, error) {
 		valuesAsMap = map[string]any{}
 	}
 
-	chrt, err := loader.Load(h.chartPath)
+	chrt, err := loadChart(h.chartPath)
 	if err != nil {
 		h.recorder.Event(obj, "Warning", "ChartLoading", err.Error())
 		meta.SetStatusCondition(&status.Conditions, h.prerequisiteCondition(obj))
@@ -165,7 +174,14 @@ func (h *Helm) Render(ctx context.Context, obj Object) ([]byte, error) {
 		return nil, err
 	}
 
-	release, err := h.clnt.Install().RunWithContext(ctx, chrt, valuesAsMap)
+	install, err := withInstallFlags(h.clnt.Install(), h.flags)
+	if err != nil {
+		h.recorder.Event(obj, "Warning", "HelmInstallFlags", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return nil, err
+	}
+	install.ReleaseName = h.releaseName
+	release, err := install.RunWithContext(ctx, chrt, valuesAsMap)
 	if err != nil {
 		h.recorder.Event(obj, "Warning", "HelmRenderRun", err.Error())
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
@@ -173,3 +189,41 @@ func (h *Helm) Render(ctx context.Context, obj Object) ([]byte, error) {
 	}
 	return []byte(release.Manifest), nil
 }
+
+// withInstallFlags returns a copy of install with the types.SupportedConfigFlags of flags set,
+// so that the flags and the release name of one render do not leak into other renders sharing the install
+// action of a client.
+func withInstallFlags(install *action.Install, flags types.Flags) (*action.Install, error) {
+	if err := flags.ValidateConfigFlags(); err != nil {
+		return nil, err
+	}
+	configured := *install
+	for name := range flags {
+		var err error
+		switch name {
+		case "Description":
+			configured.Description, err = flags.GetString(name)
+		case "Devel":
+			configured.Devel, err = flags.GetBool(name)
+		case "SkipCRDs":
+			configured.SkipCRDs, err = flags.GetBool(name)
+		case "SubNotes":
+			configured.SubNotes, err = flags.GetBool(name)
+		case "Version":
+			configured.Version, err = flags.GetString(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &configured, nil
+}
+
+// loadChart loads the chart from chartPath and classifies a missing chart as types.ErrChartNotFound.
+func loadChart(chartPath string) (*chart.Chart, error) {
+	chrt, err := loader.Load(chartPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, types.NewClassifiedError(types.ErrChartNotFound, err)
+	}
+	return chrt, err
+}
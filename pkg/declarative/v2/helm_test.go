@@ -0,0 +1,28 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+)
+
+func TestWithInstallFlags(t *testing.T) {
+	t.Parallel()
+	install := &action.Install{DryRun: true, ReleaseName: "keda"}
+	configured, err := withInstallFlags(install, types.Flags{"Devel": "true", "Version": "2.8.x"})
+	require.NoError(t, err)
+	assert.True(t, configured.Devel)
+	assert.Equal(t, "2.8.x", configured.Version)
+	assert.True(t, configured.DryRun)
+	assert.Equal(t, "keda", configured.ReleaseName)
+	assert.False(t, install.Devel, "the shared install action is not changed")
+
+	_, err = withInstallFlags(install, types.Flags{"DryRun": false})
+	assert.ErrorIs(t, err, types.ErrUnsupportedFlag)
+}
@@ -31,9 +31,7 @@ func (c *InMemoryManifestCache) Parse(
 	ctx context.Context, renderer Renderer, obj Object, spec *Spec,
 ) (*types.ManifestResources, error) {
 	file := filepath.Join(manifest, spec.Path, spec.ManifestName)
-	hashedValues, _ := internal.CalculateHash(spec.Values)
-	hash := fmt.Sprintf("%v", hashedValues)
-	key := fmt.Sprintf("%s-%s-%s", file, spec.Mode, hash)
+	key := fmt.Sprintf("%s-%s-%s", file, spec.Mode, renderInputHash(spec))
 
 	item := c.Cache.Get(key)
 	if item != nil {
@@ -50,6 +48,10 @@ func (c *InMemoryManifestCache) Parse(
 		return copied, nil
 	}
 
+	if err := internal.InjectFault(ctx, internal.FaultPointRender); err != nil {
+		return nil, err
+	}
+
 	rendered, err := renderer.Render(ctx, obj)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,42 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestOperationContextOfInitialInstall(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	reconciler := &Reconciler{Options: DefaultOptions().Apply(
+		WithOperationTimeout(time.Minute),
+		WithInstallOperationTimeout(time.Hour),
+	)}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+
+	deadlineIn := func() time.Duration {
+		ctx, cancel := reconciler.operationContext(context.Background(), obj)
+		defer cancel()
+		deadline, ok := ctx.Deadline()
+		assertions.True(ok)
+		return time.Until(deadline)
+	}
+
+	assertions.Greater(deadlineIn(), time.Minute, "objects that were never ready use the install timeout")
+
+	obj.SetStatus(obj.GetStatus().WithState(StateReady))
+	obj.status.InstalledOnce = true
+	assertions.LessOrEqual(deadlineIn(), time.Minute, "installed objects use the operation timeout")
+
+	reconciler.Options = DefaultOptions().Apply(WithOperationTimeout(time.Minute))
+	obj.status.InstalledOnce = false
+	assertions.LessOrEqual(deadlineIn(), time.Minute, "without install timeout the operation timeout applies")
+}
@@ -0,0 +1,110 @@
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type JournalPhase string
+
+const (
+	// JournalPhaseStarted marks an operation whose resources may have been applied partially.
+	JournalPhaseStarted JournalPhase = "Started"
+	// JournalPhaseFinished marks an operation whose resources were applied and recorded as Synced.
+	JournalPhaseFinished JournalPhase = "Finished"
+)
+
+// OperationJournal records the last operation that applied resources to the target cluster.
+// It is persisted in the status before resources that are not yet Synced are applied,
+// so that an operation interrupted by a restart is resumed and its partially applied resources
+// are still considered for orphan removal instead of being lost.
+// +k8s:deepcopy-gen=true
+type OperationJournal struct {
+	// Revision of the install that is applied by the operation.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// Phase of the operation.
+	// +kubebuilder:validation:Enum=Started;Finished
+	Phase JournalPhase `json:"phase"`
+	// StartedAt is the time the operation was started.
+	StartedAt metav1.Time `json:"startedAt"`
+	// FinishedAt is the time the operation finished, it is unset while the operation is in flight.
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+	// Resources that are applied by the operation but were not yet Synced when it started.
+	// +listType=atomic
+	// +optional
+	Resources []Resource `json:"resources,omitempty"`
+}
+
+// InFlight is true if the operation was started but not finished.
+func (j *OperationJournal) InFlight() bool {
+	return j != nil && j.Phase == JournalPhaseStarted
+}
+
+// WithJournalStart records the start of an operation applying target for revision.
+// It returns false if the journal already covers the operation, so that no status update is needed.
+// Resources of an operation that is still in flight are kept, as they may have been applied already.
+func (s Status) WithJournalStart(revision string, target []Resource) (Status, bool) {
+	pending := withoutResources(target, s.Synced)
+	if s.Journal.InFlight() {
+		if s.Journal.Revision == revision && len(withoutResources(pending, s.Journal.Resources)) == 0 {
+			return s, false
+		}
+		pending = mergeResources(s.Journal.Resources, pending)
+	} else if s.Journal != nil && s.Journal.Revision == revision && len(pending) == 0 {
+		return s, false
+	}
+
+	s.Journal = &OperationJournal{
+		Revision:  revision,
+		Phase:     JournalPhaseStarted,
+		StartedAt: metav1.Now(),
+		Resources: pending,
+	}
+	return s, true
+}
+
+// WithJournalFinish records the end of the operation that is in flight.
+func (s Status) WithJournalFinish() Status {
+	if !s.Journal.InFlight() {
+		return s
+	}
+	finishedAt := metav1.Now()
+	s.Journal = &OperationJournal{
+		Revision:   s.Journal.Revision,
+		Phase:      JournalPhaseFinished,
+		StartedAt:  s.Journal.StartedAt,
+		FinishedAt: &finishedAt,
+	}
+	return s
+}
+
+// journaledResources returns the Synced resources together with the resources of an operation in flight,
+// which may have been applied before the operation was interrupted.
+func journaledResources(status Status) []Resource {
+	if !status.Journal.InFlight() {
+		return status.Synced
+	}
+	return mergeResources(status.Synced, status.Journal.Resources)
+}
+
+// withoutResources returns all resources of resourcesA that are not contained in resourcesB.
+func withoutResources(resourcesA, resourcesB []Resource) []Resource {
+	excluded := make(map[string]struct{}, len(resourcesB))
+	for _, x := range resourcesB {
+		excluded[x.ID()] = struct{}{}
+	}
+	var remaining []Resource
+	for _, x := range resourcesA {
+		if _, found := excluded[x.ID()]; !found {
+			remaining = append(remaining, x)
+		}
+	}
+	return remaining
+}
+
+func mergeResources(resourcesA, resourcesB []Resource) []Resource {
+	merged := make([]Resource, 0, len(resourcesA)+len(resourcesB))
+	merged = append(merged, resourcesA...)
+	return append(merged, withoutResources(resourcesB, resourcesA)...)
+}
@@ -0,0 +1,47 @@
+package v2_test
+
+import (
+	"testing"
+
+	. "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatusWithJournal(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	configMap := func(name string) Resource {
+		return Resource{Name: name, Namespace: "default", GroupVersionKind: metav1.GroupVersionKind{
+			Version: "v1", Kind: "ConfigMap",
+		}}
+	}
+
+	status, started := Status{}.WithJournalStart("rev-1", []Resource{configMap("a")})
+	assertions.True(started)
+	assertions.True(status.Journal.InFlight())
+	assertions.Equal([]Resource{configMap("a")}, status.Journal.Resources)
+
+	_, started = status.WithJournalStart("rev-1", []Resource{configMap("a")})
+	assertions.False(started, "operation in flight already covers the target")
+
+	status, started = status.WithJournalStart("rev-2", []Resource{configMap("b")})
+	assertions.True(started)
+	assertions.Equal("rev-2", status.Journal.Revision)
+	assertions.Equal([]Resource{configMap("a"), configMap("b")}, status.Journal.Resources,
+		"resources of the interrupted operation are kept for orphan removal")
+
+	status.Synced = []Resource{configMap("b")}
+	status = status.WithJournalFinish()
+	assertions.False(status.Journal.InFlight())
+	assertions.NotNil(status.Journal.FinishedAt)
+	assertions.Empty(status.Journal.Resources)
+
+	_, started = status.WithJournalStart("rev-2", []Resource{configMap("b")})
+	assertions.False(started, "finished operation covers the target")
+
+	status, started = status.WithJournalStart("rev-2", []Resource{configMap("b"), configMap("c")})
+	assertions.True(started)
+	assertions.Equal([]Resource{configMap("c")}, status.Journal.Resources, "synced resources are not journaled")
+}
@@ -6,6 +6,7 @@ import (
 
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
 	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
@@ -16,7 +17,8 @@ func NewKustomizeRenderer(
 	var krustyOpts *krusty.Options
 
 	if optionsFromValues, areKrustyOpts := spec.Values.(*krusty.Options); areKrustyOpts {
-		krustyOpts = optionsFromValues
+		opts := *optionsFromValues
+		krustyOpts = &opts
 	} else {
 		krustyOpts = krusty.MakeDefaultOptions()
 		jsonValues, err := json.Marshal(spec.Values)
@@ -27,6 +29,9 @@ func NewKustomizeRenderer(
 		}
 	}
 
+	// plugins run code on behalf of the kustomization and are never configured from the values of the spec
+	krustyOpts.PluginConfig = options.KustomizePlugins.pluginConfig()
+
 	return &Kustomize{
 		recorder: options.EventRecorder,
 		path:     spec.Path,
@@ -34,6 +39,41 @@ func NewKustomizeRenderer(
 	}
 }
 
+// DefaultKustomizeHelmCommand is the helm binary used for the helmCharts field of kustomizations.
+const DefaultKustomizeHelmCommand = "helm"
+
+// KustomizePlugins determine which plugins beyond the statically linked builtins may run during kustomize rendering.
+// Plugins execute binaries or containers on behalf of the rendered kustomization, thus all of them are disabled
+// by default.
+type KustomizePlugins struct {
+	// HelmCharts enables the helmCharts field and the HelmChartInflationGenerator, which run HelmCommand.
+	HelmCharts  bool
+	HelmCommand string
+	// Functions enables containerized KRM function plugins, which require a container runtime.
+	Functions bool
+	// FunctionNetwork grants containerized KRM functions access to the network.
+	FunctionNetwork bool
+	// Exec enables exec KRM function plugins, which run arbitrary binaries available to the controller.
+	Exec bool
+}
+
+func (p KustomizePlugins) pluginConfig() *kustomizetypes.PluginConfig {
+	pluginConfig := kustomizetypes.DisabledPluginConfig()
+	if p.HelmCharts {
+		pluginConfig.HelmConfig.Enabled = true
+		pluginConfig.HelmConfig.Command = p.HelmCommand
+		if pluginConfig.HelmConfig.Command == "" {
+			pluginConfig.HelmConfig.Command = DefaultKustomizeHelmCommand
+		}
+	}
+	if p.Functions || p.Exec {
+		pluginConfig.PluginRestrictions = kustomizetypes.PluginRestrictionsNone
+		pluginConfig.FnpLoadingOptions.Network = p.Functions && p.FunctionNetwork
+		pluginConfig.FnpLoadingOptions.EnableExec = p.Exec
+	}
+	return pluginConfig
+}
+
 type Kustomize struct {
 	recorder record.EventRecorder
 	path     string
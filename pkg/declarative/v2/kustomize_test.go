@@ -0,0 +1,49 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
+)
+
+func TestKustomizePlugins(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	disabled := KustomizePlugins{}.pluginConfig()
+	asserts.Equal(kustomizetypes.PluginRestrictionsBuiltinsOnly, disabled.PluginRestrictions)
+	asserts.False(disabled.HelmConfig.Enabled)
+	asserts.False(disabled.FnpLoadingOptions.EnableExec)
+
+	helm := KustomizePlugins{HelmCharts: true}.pluginConfig()
+	asserts.True(helm.HelmConfig.Enabled)
+	asserts.Equal(DefaultKustomizeHelmCommand, helm.HelmConfig.Command)
+	asserts.Equal(kustomizetypes.PluginRestrictionsBuiltinsOnly, helm.PluginRestrictions)
+
+	functions := KustomizePlugins{Functions: true, FunctionNetwork: true}.pluginConfig()
+	asserts.Equal(kustomizetypes.PluginRestrictionsNone, functions.PluginRestrictions)
+	asserts.True(functions.FnpLoadingOptions.Network)
+	asserts.False(functions.FnpLoadingOptions.EnableExec)
+
+	exec := KustomizePlugins{Exec: true, FunctionNetwork: true}.pluginConfig()
+	asserts.True(exec.FnpLoadingOptions.EnableExec)
+	asserts.False(exec.FnpLoadingOptions.Network, "network is only granted to containerized functions")
+}
+
+func TestKustomizePluginsIgnoreValues(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	values := krusty.MakeDefaultOptions()
+	values.PluginConfig = kustomizetypes.EnabledPluginConfig(kustomizetypes.BploUseStaticallyLinked)
+	renderer, ok := NewKustomizeRenderer(&Spec{Values: values}, &Options{}).(*Kustomize)
+	asserts.True(ok)
+	asserts.False(renderer.opts.PluginConfig.HelmConfig.Enabled)
+	asserts.Equal(kustomizetypes.PluginRestrictionsBuiltinsOnly, renderer.opts.PluginConfig.PluginRestrictions)
+	asserts.True(values.PluginConfig.HelmConfig.Enabled, "the values of the spec are not modified")
+}
@@ -0,0 +1,176 @@
+package v2
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kyma-project/module-manager/internal"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// LastAppliedConfigurationAnnotation is set on objects to the name of the Secret in their namespace that
+	// stores the resources applied last, see LastAppliedConfiguration.
+	LastAppliedConfigurationAnnotation = "declarative.kyma-project.io/last-applied-configuration"
+	// LastAppliedConfigurationChecksumAnnotation is the sha256 checksum of the resources applied last.
+	LastAppliedConfigurationChecksumAnnotation = "declarative.kyma-project.io/last-applied-configuration-sha256"
+	// LastAppliedConfigurationKey is the key of the gzip compressed multi-document YAML in the Secret.
+	LastAppliedConfigurationKey = "resources.yaml.gz"
+
+	lastAppliedConfigurationSuffix = "-last-applied"
+	// maxLastAppliedConfigurationSize leaves room for the metadata of the Secret within the limit of 1MiB.
+	maxLastAppliedConfigurationSize = 1000 * 1024
+)
+
+var (
+	ErrNoLastAppliedConfiguration               = errors.New("no last applied configuration recorded")
+	ErrLastAppliedConfigurationChecksumMismatch = errors.New("last applied configuration does not match its checksum")
+)
+
+// WithLastAppliedConfiguration stores the resources applied last for every object in a Secret next to the object,
+// which is referenced by the LastAppliedConfigurationAnnotation. External tools can use LastAppliedConfiguration
+// to compute their own drift views without rendering again. The Secret is owned by the object and deleted with it.
+type WithLastAppliedConfiguration bool
+
+func (o WithLastAppliedConfiguration) Apply(options *Options) {
+	options.LastAppliedConfiguration = bool(o)
+}
+
+// LastAppliedConfiguration returns the resources that were applied last for obj exactly as they were applied,
+// i.e. after all PostRenderTransforms. It fails with ErrNoLastAppliedConfiguration if none is recorded.
+func LastAppliedConfiguration(
+	ctx context.Context, clnt client.Reader, obj client.Object,
+) ([]*unstructured.Unstructured, error) {
+	name, found := obj.GetAnnotations()[LastAppliedConfigurationAnnotation]
+	if !found {
+		return nil, fmt.Errorf("%w for %s", ErrNoLastAppliedConfiguration, client.ObjectKeyFromObject(obj))
+	}
+	secret := &v1.Secret{}
+	if err := clnt.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("reading last applied configuration of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(secret.Data[LastAppliedConfigurationKey]))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing last applied configuration of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	manifest, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing last applied configuration of %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	if checksum := obj.GetAnnotations()[LastAppliedConfigurationChecksumAnnotation]; checksum != "" &&
+		checksum != lastAppliedChecksum(manifest) {
+		return nil, fmt.Errorf("%w: %s", ErrLastAppliedConfigurationChecksumMismatch, client.ObjectKeyFromObject(obj))
+	}
+	resources, err := internal.ParseManifestStringToObjects(string(manifest))
+	if err != nil {
+		return nil, err
+	}
+	return resources.Items, nil
+}
+
+func lastAppliedChecksum(manifest []byte) string {
+	sum := sha256.Sum256(manifest)
+	return hex.EncodeToString(sum[:])
+}
+
+func lastAppliedManifest(target []*resource.Info) ([]byte, error) {
+	var manifest bytes.Buffer
+	for _, info := range target {
+		document, err := yaml.Marshal(info.Object)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s/%s: %w", info.Namespace, info.Name, err)
+		}
+		manifest.WriteString("---\n")
+		manifest.Write(document)
+	}
+	return manifest.Bytes(), nil
+}
+
+func compressLastAppliedManifest(manifest []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(manifest); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// recordLastAppliedConfiguration stores target in the Secret of obj if it changed since it was recorded last.
+// The record is informational, so failures are reported as events without failing the reconciliation.
+func (r *Reconciler) recordLastAppliedConfiguration(ctx context.Context, obj Object, target []*resource.Info) {
+	if err := r.storeLastAppliedConfiguration(ctx, obj, target); err != nil {
+		log.FromContext(ctx).V(internal.DebugLogLevel).Info("last applied configuration not recorded",
+			"error", err.Error())
+		r.Event(obj, "Warning", "LastAppliedConfiguration", err.Error())
+	}
+}
+
+func (r *Reconciler) storeLastAppliedConfiguration(ctx context.Context, obj Object, target []*resource.Info) error {
+	manifest, err := lastAppliedManifest(target)
+	if err != nil {
+		return err
+	}
+	checksum := lastAppliedChecksum(manifest)
+	name := obj.GetName() + lastAppliedConfigurationSuffix
+	annotations := obj.GetAnnotations()
+	if annotations[LastAppliedConfigurationAnnotation] == name &&
+		annotations[LastAppliedConfigurationChecksumAnnotation] == checksum {
+		return nil
+	}
+
+	compressed, err := compressLastAppliedManifest(manifest)
+	if err != nil {
+		return err
+	}
+	if len(compressed) > maxLastAppliedConfigurationSize {
+		return fmt.Errorf("last applied configuration of %d bytes exceeds the secret size limit", len(compressed))
+	}
+
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: obj.GetNamespace(),
+			Labels:    map[string]string{ManagedByLabel: managedByLabelValue},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind, Name: obj.GetName(), UID: obj.GetUID(),
+			}},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{LastAppliedConfigurationKey: compressed},
+	}
+	if err := r.Patch(ctx, secret, client.Apply, client.ForceOwnership, r.FieldOwner); err != nil {
+		return fmt.Errorf("storing last applied configuration in secret %s: %w", name, err)
+	}
+
+	// the annotations are applied by another field owner, so that the finalizer updates do not remove them
+	objMeta := &metav1.PartialObjectMetadata{}
+	objMeta.SetGroupVersionKind(gvk)
+	objMeta.SetName(obj.GetName())
+	objMeta.SetNamespace(obj.GetNamespace())
+	objMeta.SetAnnotations(map[string]string{
+		LastAppliedConfigurationAnnotation:         name,
+		LastAppliedConfigurationChecksumAnnotation: checksum,
+	})
+	fieldOwner := client.FieldOwner(string(r.FieldOwner) + lastAppliedConfigurationSuffix)
+	if err := r.Patch(ctx, objMeta, client.Apply, client.ForceOwnership, fieldOwner); err != nil {
+		return fmt.Errorf("referencing last applied configuration: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestLastAppliedConfiguration(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	configMap := renderedObject("ConfigMap", "keda-config", nil)
+	configMap.Object["data"] = map[string]any{"level": "debug"}
+	target := []*resource.Info{
+		{Name: "keda-config", Namespace: "keda", Object: configMap},
+		{Name: "keda", Namespace: "keda", Object: renderedObject("Pod", "keda", map[string]any{"image": "keda:2.8.1"})},
+	}
+	manifest, err := lastAppliedManifest(target)
+	require.NoError(t, err)
+	compressed, err := compressLastAppliedManifest(manifest)
+	require.NoError(t, err)
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda" + lastAppliedConfigurationSuffix, Namespace: "kcp-system"},
+		Data:       map[string][]byte{LastAppliedConfigurationKey: compressed},
+	}
+	clnt := fake.NewClientBuilder().WithObjects(secret).Build()
+
+	obj := &unstructured.Unstructured{}
+	obj.SetName("keda")
+	obj.SetNamespace("kcp-system")
+	_, err = LastAppliedConfiguration(ctx, clnt, obj)
+	require.ErrorIs(t, err, ErrNoLastAppliedConfiguration)
+
+	obj.SetAnnotations(map[string]string{
+		LastAppliedConfigurationAnnotation:         secret.GetName(),
+		LastAppliedConfigurationChecksumAnnotation: lastAppliedChecksum(manifest),
+	})
+	resources, err := LastAppliedConfiguration(ctx, clnt, obj)
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Equal(t, configMap.Object, resources[0].Object)
+	assert.Equal(t, "keda", resources[1].GetName())
+
+	annotations := obj.GetAnnotations()
+	annotations[LastAppliedConfigurationChecksumAnnotation] = lastAppliedChecksum([]byte("changed"))
+	obj.SetAnnotations(annotations)
+	_, err = LastAppliedConfiguration(ctx, clnt, obj)
+	assert.ErrorIs(t, err, ErrLastAppliedConfigurationChecksumMismatch)
+}
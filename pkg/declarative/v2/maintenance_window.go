@@ -0,0 +1,276 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// MaintenanceWindowAnnotation restricts upgrades and drift corrections of an object that was installed once to
+// recurring maintenance windows. The value consists of the five fields of a cron expression in UTC for the start
+// of the window, followed by its duration, e.g. "0 2 * * 6 4h" for Saturdays from 02:00 to 06:00.
+// Outside the window only the readiness of the synced resources is checked and pending changes are reported
+// in the PendingUpdate condition. Initial installs, operations in flight and deletions are never deferred.
+const MaintenanceWindowAnnotation = "declarative.kyma-project.io/maintenance-window"
+
+const (
+	ConditionTypePendingUpdate              ConditionType   = "PendingUpdate"
+	ConditionReasonOutsideMaintenanceWindow ConditionReason = "OutsideMaintenanceWindow"
+	ConditionReasonUpToDate                 ConditionReason = "UpToDate"
+)
+
+var ErrInvalidMaintenanceWindow = errors.New("invalid maintenance window")
+
+const (
+	cronFields = 5
+	// maxWindowSearch bounds the search for the next start of a window, so that expressions that never match,
+	// e.g. on February 30th, do not loop forever.
+	maxWindowSearch = 5 * 366 * 24 * time.Hour
+)
+
+// cronField is the set of values a field of a cron expression matches.
+type cronField map[int]bool
+
+type cronFieldBounds struct {
+	name     string
+	min, max int
+}
+
+//nolint:gochecknoglobals,gomnd
+var cronFieldsBounds = [cronFields]cronFieldBounds{
+	{"minute", 0, 59}, {"hour", 0, 23}, {"day of month", 1, 31}, {"month", 1, 12}, {"day of week", 0, 7},
+}
+
+// MaintenanceWindow is a recurring window starting at every match of a cron expression.
+type MaintenanceWindow struct {
+	minute, hour, dayOfMonth, month, dayOfWeek cronField
+	// restrictedDays is true if both day fields are restricted, so that a day matching either of them matches.
+	restrictedDays bool
+	Duration       time.Duration
+}
+
+// ParseMaintenanceWindow parses the value of the MaintenanceWindowAnnotation.
+func ParseMaintenanceWindow(value string) (*MaintenanceWindow, error) {
+	fields := strings.Fields(value)
+	if len(fields) != cronFields+1 {
+		return nil, fmt.Errorf("%w %q: expected %d cron fields and a duration",
+			ErrInvalidMaintenanceWindow, value, cronFields)
+	}
+	duration, err := time.ParseDuration(fields[cronFields])
+	if err != nil || duration <= 0 {
+		return nil, fmt.Errorf("%w %q: duration must be positive, e.g. 4h", ErrInvalidMaintenanceWindow, value)
+	}
+
+	var parsed [cronFields]cronField
+	for i, bounds := range cronFieldsBounds {
+		if parsed[i], err = parseCronField(fields[i], bounds); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidMaintenanceWindow, value, err)
+		}
+	}
+	// 7 is an alias for sunday
+	if parsed[4][7] {
+		parsed[4][0] = true
+	}
+	return &MaintenanceWindow{
+		minute: parsed[0], hour: parsed[1], dayOfMonth: parsed[2], month: parsed[3], dayOfWeek: parsed[4],
+		restrictedDays: fields[2] != "*" && fields[4] != "*",
+		Duration:       duration,
+	}, nil
+}
+
+func parseCronField(field string, bounds cronFieldBounds) (cronField, error) {
+	values := cronField{}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if before, after, found := strings.Cut(part, "/"); found {
+			var err error
+			if step, err = strconv.Atoi(after); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q of %s", after, bounds.name)
+			}
+			rangePart = before
+		}
+		low, high := bounds.min, bounds.max
+		if rangePart != "*" {
+			var err error
+			from, to, isRange := strings.Cut(rangePart, "-")
+			if low, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value %q of %s", from, bounds.name)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid value %q of %s", to, bounds.name)
+				}
+			}
+		}
+		if low < bounds.min || high > bounds.max || low > high {
+			return nil, fmt.Errorf("%s %q is outside of %d-%d", bounds.name, part, bounds.min, bounds.max)
+		}
+		for value := low; value <= high; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+func (w *MaintenanceWindow) matchesDay(t time.Time) bool {
+	if !w.month[int(t.Month())] {
+		return false
+	}
+	if w.restrictedDays {
+		return w.dayOfMonth[t.Day()] || w.dayOfWeek[int(t.Weekday())]
+	}
+	return w.dayOfMonth[t.Day()] && w.dayOfWeek[int(t.Weekday())]
+}
+
+// nextStart returns the first start of the window at or after t, truncated to the minute.
+func (w *MaintenanceWindow) nextStart(t time.Time) (time.Time, bool) {
+	t = t.UTC().Truncate(time.Minute)
+	limit := t.Add(maxWindowSearch)
+	for t.Before(limit) {
+		if !w.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !w.hour[t.Hour()] {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !w.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Contains is true if t is within a window that started at most Duration before t.
+func (w *MaintenanceWindow) Contains(t time.Time) bool {
+	start, found := w.nextStart(t.Add(-w.Duration).Add(time.Minute))
+	return found && !start.After(t)
+}
+
+// UntilNextStart returns the time from t until the next window starts, it is 0 if no window starts anymore.
+func (w *MaintenanceWindow) UntilNextStart(t time.Time) time.Duration {
+	start, found := w.nextStart(t.Add(time.Minute))
+	if !found {
+		return 0
+	}
+	return start.Sub(t)
+}
+
+// maintenanceWindowOf returns the MaintenanceWindow of obj, it is nil if obj has no MaintenanceWindowAnnotation.
+func maintenanceWindowOf(obj Object) (*MaintenanceWindow, error) {
+	value, found := obj.GetAnnotations()[MaintenanceWindowAnnotation]
+	if !found {
+		return nil, nil //nolint:nilnil
+	}
+	return ParseMaintenanceWindow(value)
+}
+
+// pendingUpdate describes the changes of target and spec that are not applied yet, it is empty if there are none.
+func pendingUpdate(obj Object, spec *Spec, target []*resource.Info) string {
+	status := obj.GetStatus()
+	var pending []string
+	if revision := installedRevision(status, spec.ManifestName); revision != spec.Revision {
+		pending = append(pending, fmt.Sprintf("revision %s (installed %s)", spec.Revision, revision))
+	}
+	targetResources := NewInfoToResourceConverter().InfosToResources(target)
+	added := withoutResources(targetResources, status.Synced)
+	removed := withoutResources(status.Synced, syncedResources(obj, status.Synced, target))
+	if len(added) > 0 || len(removed) > 0 {
+		pending = append(pending, fmt.Sprintf("%d added and %d removed resources", len(added), len(removed)))
+	}
+	return strings.Join(pending, ", ")
+}
+
+func installedRevision(status Status, installName string) string {
+	for _, install := range status.Installs {
+		if install.Name == installName {
+			return install.Revision
+		}
+	}
+	return ""
+}
+
+// withPendingUpdateCondition sets the PendingUpdate condition to pending and returns true if it changed.
+// Objects without the condition only get it once an update is pending.
+func withPendingUpdateCondition(obj Object, pending string) bool {
+	status := obj.GetStatus()
+	condition := metav1.Condition{
+		Type:               string(ConditionTypePendingUpdate),
+		Status:             metav1.ConditionFalse,
+		Reason:             string(ConditionReasonUpToDate),
+		Message:            "all changes are applied",
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	if pending != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = string(ConditionReasonOutsideMaintenanceWindow)
+		condition.Message = "pending until the next maintenance window: " + pending
+	}
+	existing := meta.FindStatusCondition(status.Conditions, condition.Type)
+	if existing == nil && pending == "" {
+		return false
+	}
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return false
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	obj.SetStatus(status)
+	return true
+}
+
+// deferToMaintenanceWindow checks the readiness of the current resources instead of applying target if obj is
+// outside of its maintenance window and requeues obj until the window starts. It returns false if the operation
+// is not deferred.
+func (r *Reconciler) deferToMaintenanceWindow(
+	ctx context.Context, clnt Client, obj Object, spec *Spec, current, target []*resource.Info,
+) (ctrl.Result, bool, error) {
+	status := obj.GetStatus()
+	if !status.InstalledOnce || status.Journal.InFlight() {
+		return ctrl.Result{}, false, nil
+	}
+	window, err := maintenanceWindowOf(obj)
+	if err != nil {
+		r.Event(obj, "Warning", "MaintenanceWindow", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		result, err := r.ssaStatus(ctx, obj)
+		return result, true, err
+	}
+	now := time.Now()
+	if window == nil || window.Contains(now) {
+		return ctrl.Result{}, false, nil
+	}
+
+	changed := withPendingUpdateCondition(obj, pendingUpdate(obj, spec, target))
+	readyCheckCtx := WithReadyCheckContext(ctx,
+		newReadyCheckContext(spec, installedRevision(status, spec.ManifestName), current))
+	if err := r.checkTargetReadiness(readyCheckCtx, r.verificationClient(ctx, obj, clnt), obj, current); err != nil {
+		changed = true
+	}
+	if changed {
+		// the install keeps the installed revision, as the revision of spec is not applied yet
+		obj.SetStatus(obj.GetStatus().WithInstall(spec.ManifestName, installedRevision(status, spec.ManifestName)))
+		result, err := r.ssaStatus(ctx, obj)
+		return result, true, err
+	}
+
+	result := r.CtrlOnSuccess
+	if r.CtrlOnSuccessFn != nil {
+		result = r.CtrlOnSuccessFn()
+	}
+	if until := window.UntilNextStart(now); until > 0 && (result.RequeueAfter == 0 || until < result.RequeueAfter) {
+		result.RequeueAfter = until
+	}
+	return result, true, nil
+}
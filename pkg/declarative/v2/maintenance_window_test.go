@@ -0,0 +1,95 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func TestParseMaintenanceWindow(t *testing.T) {
+	t.Parallel()
+	for _, value := range []string{
+		"0 2 * * 6",
+		"0 2 * * 6 0h",
+		"0 2 * * 6 four-hours",
+		"60 2 * * 6 4h",
+		"0 2 * * 8 4h",
+		"0 2-1 * * * 4h",
+		"*/0 2 * * * 4h",
+		"a 2 * * * 4h",
+	} {
+		_, err := ParseMaintenanceWindow(value)
+		assert.ErrorIs(t, err, ErrInvalidMaintenanceWindow, value)
+	}
+
+	window, err := ParseMaintenanceWindow("0,30 22-23 * 1-12/2 0-5/5 90m")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, window.Duration)
+	assert.Equal(t, cronField{0: true, 30: true}, window.minute)
+	assert.Equal(t, cronField{1: true, 3: true, 5: true, 7: true, 9: true, 11: true}, window.month)
+	assert.Equal(t, cronField{0: true, 5: true}, window.dayOfWeek)
+}
+
+func TestMaintenanceWindowContains(t *testing.T) {
+	t.Parallel()
+	// Saturdays from 02:00 to 06:00
+	window, err := ParseMaintenanceWindow("0 2 * * 6 4h")
+	require.NoError(t, err)
+	saturday := time.Date(2023, time.February, 11, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, window.Contains(saturday.Add(time.Hour+59*time.Minute)))
+	assert.True(t, window.Contains(saturday.Add(2*time.Hour)))
+	assert.True(t, window.Contains(saturday.Add(5*time.Hour+59*time.Minute+59*time.Second)))
+	assert.False(t, window.Contains(saturday.Add(6*time.Hour)))
+	assert.False(t, window.Contains(saturday.Add(24*time.Hour+3*time.Hour)))
+
+	assert.Equal(t, time.Hour, window.UntilNextStart(saturday.Add(time.Hour)))
+	assert.Equal(t, 7*24*time.Hour-time.Hour, window.UntilNextStart(saturday.Add(3*time.Hour)))
+
+	// day of month or day of week, as both are restricted
+	window, err = ParseMaintenanceWindow("30 23 1 * 1 1h")
+	require.NoError(t, err)
+	assert.True(t, window.Contains(time.Date(2023, time.February, 1, 23, 45, 0, 0, time.UTC)), "wednesday 1st")
+	assert.True(t, window.Contains(time.Date(2023, time.February, 7, 0, 15, 0, 0, time.UTC)), "after monday night")
+	assert.False(t, window.Contains(time.Date(2023, time.February, 7, 23, 45, 0, 0, time.UTC)), "tuesday")
+
+	window, err = ParseMaintenanceWindow("0 0 30 2 * 1h")
+	require.NoError(t, err)
+	assert.False(t, window.Contains(time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Zero(t, window.UntilNextStart(time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestPendingUpdate(t *testing.T) {
+	t.Parallel()
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	spec := &Spec{ManifestName: "install", Revision: "sha256:new"}
+	synced := configMapInfo("synced")
+	status := Status{
+		Installs: []InstallStatus{{Name: "install", Revision: "sha256:old", Ready: true}},
+		Synced:   NewInfoToResourceConverter().InfosToResources([]*resource.Info{synced}),
+	}
+	obj.SetStatus(status)
+
+	assert.False(t, withPendingUpdateCondition(obj, ""), "the condition is only added for pending updates")
+	pending := pendingUpdate(obj, spec, []*resource.Info{configMapInfo("added")})
+	assert.Equal(t, "revision sha256:new (installed sha256:old), 1 added and 1 removed resources", pending)
+	assert.True(t, withPendingUpdateCondition(obj, pending))
+	assert.False(t, withPendingUpdateCondition(obj, pending))
+	assert.True(t, meta.IsStatusConditionTrue(obj.GetStatus().Conditions, string(ConditionTypePendingUpdate)))
+
+	spec.Revision = "sha256:old"
+	assert.Empty(t, pendingUpdate(obj, spec, []*resource.Info{synced}))
+	assert.True(t, withPendingUpdateCondition(obj, ""))
+	condition := meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypePendingUpdate))
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+}
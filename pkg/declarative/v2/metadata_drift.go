@@ -0,0 +1,53 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// detectMetadataDrift compares the labels and annotations of the target resources, including the ones
+// added by the PostRenderTransforms such as ManagedByLabel and DisclaimerAnnotation, with the live resources.
+// It returns a description of every label or annotation that is missing or differs in the cluster.
+// Resources that do not exist yet are not considered drifted, as they are created by the next apply anyway.
+func detectMetadataDrift(ctx context.Context, clnt client.Client, target []*resource.Info) ([]string, error) {
+	var drifted []string
+	for _, info := range target {
+		obj, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		live := &metav1.PartialObjectMetadata{}
+		live.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				return nil, fmt.Errorf("metadata of %s could not be fetched: %w", info.ObjectName(), err)
+			}
+			continue
+		}
+		for _, key := range driftedKeys(obj.GetLabels(), live.GetLabels()) {
+			drifted = append(drifted, fmt.Sprintf("%s: label %s", info.ObjectName(), key))
+		}
+		for _, key := range driftedKeys(obj.GetAnnotations(), live.GetAnnotations()) {
+			drifted = append(drifted, fmt.Sprintf("%s: annotation %s", info.ObjectName(), key))
+		}
+	}
+	return drifted, nil
+}
+
+// driftedKeys returns the sorted keys of target that are missing in live or have a different value.
+func driftedKeys(target, live map[string]string) []string {
+	var keys []string
+	for key, value := range target {
+		if liveValue, found := live[key]; !found || liveValue != value {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func configMapInfo(name string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetName(name)
+	obj.SetNamespace(metav1.NamespaceDefault)
+	return &resource.Info{Name: name, Namespace: metav1.NamespaceDefault, Object: obj}
+}
+
+func TestDetectMetadataDrift(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	clnt := fake.NewClientBuilder().WithObjects(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "consistent", Namespace: metav1.NamespaceDefault,
+			Labels:      map[string]string{ManagedByLabel: managedByLabelValue},
+			Annotations: map[string]string{DisclaimerAnnotation: disclaimerAnnotationValue},
+		}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "stripped", Namespace: metav1.NamespaceDefault,
+			Labels: map[string]string{ManagedByLabel: "someone-else", "unrelated": "label"},
+		}},
+	).Build()
+
+	infos := []*resource.Info{configMapInfo("consistent"), configMapInfo("stripped"), configMapInfo("missing")}
+	var resources []*unstructured.Unstructured
+	for _, info := range infos {
+		resources = append(resources, info.Object.(*unstructured.Unstructured))
+	}
+	assertions.NoError(managedByDeclarativeV2(ctx, nil, resources))
+	assertions.NoError(disclaimerTransform(ctx, nil, resources))
+
+	drifted, err := detectMetadataDrift(ctx, clnt, infos)
+	assertions.NoError(err)
+	assertions.Len(drifted, 2)
+	assertions.Contains(drifted[0], "label "+ManagedByLabel)
+	assertions.Contains(drifted[1], "annotation "+DisclaimerAnnotation)
+}
@@ -0,0 +1,195 @@
+package v2
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	DefaultMetadataInformerClusters = 50
+	DefaultMetadataInformerKinds    = 30
+	// DefaultMetadataInformerSyncTimeout bounds the initial sync of an informer, e.g. if it is not allowed to watch.
+	DefaultMetadataInformerSyncTimeout = 10 * time.Second
+)
+
+// MetadataInformerCache serves metadata-only reads of the consistency checks, such as ExistsReadyCheck and
+// WithMetadataDriftCheck, from informers per target cluster instead of a GET per resource and reconciliation.
+// Memory is bounded by only caching the metadata of resources matching Selector without their managed fields,
+// by at most MaxKinds informers per cluster and by evicting the least recently used of more than MaxClusters clusters.
+// Reads that are not served by an informer, including resources missing from the cache, fall back to the cluster.
+type MetadataInformerCache struct {
+	Selector    labels.Selector
+	MaxClusters int
+	MaxKinds    int
+
+	mu       sync.Mutex
+	clusters map[any]*clusterInformers
+}
+
+type clusterInformers struct {
+	cache    cache.Cache
+	cancel   context.CancelFunc
+	kinds    map[schema.GroupVersionKind]bool
+	lastUsed time.Time
+}
+
+// NewMetadataInformerCache caches the metadata of resources labeled by the managedByDeclarativeV2 transform.
+func NewMetadataInformerCache() *MetadataInformerCache {
+	return &MetadataInformerCache{
+		Selector:    labels.SelectorFromSet(labels.Set{ManagedByLabel: managedByLabelValue}),
+		MaxClusters: DefaultMetadataInformerClusters,
+		MaxKinds:    DefaultMetadataInformerKinds,
+	}
+}
+
+// Client wraps clnt, so that reads of metav1.PartialObjectMetadata are served by the informers of key.
+func (c *MetadataInformerCache) Client(key any, clnt Client) Client {
+	return &metadataInformerClient{Client: clnt, informers: c, key: key}
+}
+
+// Invalidate stops the informers of key, e.g. because the client of the cluster was recreated.
+func (c *MetadataInformerCache) Invalidate(key any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if informers, found := c.clusters[key]; found {
+		informers.cancel()
+		delete(c.clusters, key)
+	}
+}
+
+// reader returns the informer cache of key if it can serve gvk, otherwise nil.
+func (c *MetadataInformerCache) reader(ctx context.Context, key any, clnt Client,
+	gvk schema.GroupVersionKind,
+) client.Reader {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	informers, found := c.clusters[key]
+	if !found {
+		var err error
+		if informers, err = c.start(ctx, clnt); err != nil {
+			log.FromContext(ctx).V(internal.DebugLogLevel).Info("metadata informers could not be started",
+				"error", err.Error())
+			return nil
+		}
+		c.evictLeastRecentlyUsed()
+		if c.clusters == nil {
+			c.clusters = make(map[any]*clusterInformers)
+		}
+		c.clusters[key] = informers
+	}
+	informers.lastUsed = time.Now()
+
+	usable, found := informers.kinds[gvk]
+	if !found {
+		if len(informers.kinds) >= c.MaxKinds {
+			return nil
+		}
+		informers.kinds[gvk], usable = true, true
+	}
+	if !usable {
+		return nil
+	}
+	return informers.cache
+}
+
+// disable falls back to the cluster for all further reads of gvk, e.g. because it cannot be watched.
+func (c *MetadataInformerCache) disable(key any, gvk schema.GroupVersionKind) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if informers, found := c.clusters[key]; found {
+		informers.kinds[gvk] = false
+	}
+}
+
+func (c *MetadataInformerCache) start(ctx context.Context, clnt Client) (*clusterInformers, error) {
+	config, err := clnt.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	informerCache, err := cache.New(config, cache.Options{
+		Scheme:          clnt.Scheme(),
+		Mapper:          clnt.RESTMapper(),
+		DefaultSelector: cache.ObjectSelector{Label: c.Selector},
+		DefaultTransform: func(obj interface{}) (interface{}, error) {
+			if accessor, ok := obj.(metav1.Object); ok {
+				accessor.SetManagedFields(nil)
+			}
+			return obj, nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the informers outlive the reconciliation that started them
+	informerCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := informerCache.Start(informerCtx); err != nil {
+			log.FromContext(ctx).Error(err, "metadata informers stopped")
+		}
+	}()
+	if !informerCache.WaitForCacheSync(ctx) {
+		cancel()
+		return nil, ctx.Err()
+	}
+	return &clusterInformers{
+		cache: informerCache, cancel: cancel, kinds: make(map[schema.GroupVersionKind]bool),
+	}, nil
+}
+
+func (c *MetadataInformerCache) evictLeastRecentlyUsed() {
+	for len(c.clusters) >= c.MaxClusters && len(c.clusters) > 0 {
+		var oldestKey any
+		var oldest *clusterInformers
+		for key, informers := range c.clusters {
+			if oldest == nil || informers.lastUsed.Before(oldest.lastUsed) {
+				oldestKey, oldest = key, informers
+			}
+		}
+		oldest.cancel()
+		delete(c.clusters, oldestKey)
+	}
+}
+
+type metadataInformerClient struct {
+	Client
+	informers *MetadataInformerCache
+	key       any
+}
+
+func (c *metadataInformerClient) Get(
+	ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption,
+) error {
+	metadata, isMetadata := obj.(*metav1.PartialObjectMetadata)
+	if !isMetadata {
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+	reader := c.informers.reader(ctx, c.key, c.Client, metadata.GroupVersionKind())
+	if reader == nil {
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+	syncCtx, cancel := context.WithTimeout(ctx, DefaultMetadataInformerSyncTimeout)
+	defer cancel()
+	err := reader.Get(syncCtx, key, obj, opts...)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) && ctx.Err() == nil {
+		log.FromContext(ctx).V(internal.DebugLogLevel).Info("metadata informer read failed, reading from cluster",
+			"kind", metadata.GroupVersionKind(), "error", err.Error())
+		c.informers.disable(c.key, metadata.GroupVersionKind())
+	}
+	// resources without the selected labels or not yet observed by the informer are read from the cluster
+	return c.Client.Get(ctx, key, obj, opts...)
+}
@@ -0,0 +1,78 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type countingClient struct {
+	Client
+	gets int
+}
+
+func (c *countingClient) Get(context.Context, client.ObjectKey, client.Object, ...client.GetOption) error {
+	c.gets++
+	return nil
+}
+
+func TestMetadataInformerCacheEvictsLeastRecentlyUsedClusters(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	informers := &MetadataInformerCache{MaxClusters: 2, MaxKinds: 1}
+	cancelled := map[string]bool{}
+	informers.clusters = map[any]*clusterInformers{}
+	for i, key := range []string{"old", "recent"} {
+		key := key
+		informers.clusters[key] = &clusterInformers{
+			cancel:   func() { cancelled[key] = true },
+			kinds:    map[schema.GroupVersionKind]bool{},
+			lastUsed: time.Now().Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	informers.evictLeastRecentlyUsed()
+	asserts.True(cancelled["old"])
+	asserts.False(cancelled["recent"])
+	asserts.Len(informers.clusters, 1)
+
+	informers.Invalidate("recent")
+	asserts.True(cancelled["recent"])
+	asserts.Empty(informers.clusters)
+}
+
+func TestMetadataInformerCacheFallsBackToCluster(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	informers := &MetadataInformerCache{MaxClusters: 1, MaxKinds: 1}
+	informers.clusters = map[any]*clusterInformers{"kyma": {
+		cancel: func() {},
+		kinds: map[schema.GroupVersionKind]bool{
+			{Version: "v1", Kind: "ConfigMap"}: true,
+			{Version: "v1", Kind: "Secret"}:    false,
+		},
+	}}
+	clnt := &countingClient{}
+	cached := informers.Client("kyma", clnt)
+
+	secret := &metav1.PartialObjectMetadata{}
+	secret.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Secret"})
+	asserts.NoError(cached.Get(context.Background(), client.ObjectKey{Name: "secret"}, secret))
+	asserts.Equal(1, clnt.gets, "disabled kinds are read from the cluster")
+
+	service := &metav1.PartialObjectMetadata{}
+	service.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "Service"})
+	asserts.NoError(cached.Get(context.Background(), client.ObjectKey{Name: "service"}, service))
+	asserts.Equal(2, clnt.gets, "kinds beyond MaxKinds are read from the cluster")
+	asserts.Len(informers.clusters["kyma"].kinds, 2)
+}
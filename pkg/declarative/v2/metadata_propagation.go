@@ -0,0 +1,89 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// MetadataPropagation selects the labels and annotations of an object that are copied onto its resources,
+// e.g. for cost attribution. Keys are selected by allowlist patterns in the syntax of path.Match,
+// e.g. "billing.example.com/*". Propagated values replace rendered values of the same keys.
+type MetadataPropagation struct {
+	Labels      []string
+	Annotations []string
+	// Kinds are the kinds of the rendered resources that receive the metadata,
+	// DefaultMetadataPropagationKinds if empty.
+	Kinds []schema.GroupKind
+}
+
+// DefaultMetadataPropagationKinds are the workloads of modules, their pod templates are not changed,
+// so that changes of propagated metadata do not restart pods.
+func DefaultMetadataPropagationKinds() []schema.GroupKind {
+	return []schema.GroupKind{
+		{Group: "apps", Kind: "Deployment"}, {Group: "apps", Kind: "StatefulSet"}, {Group: "apps", Kind: "DaemonSet"},
+	}
+}
+
+// Validate fails for malformed patterns, which would never match.
+func (p MetadataPropagation) Validate() error {
+	for _, pattern := range append(append([]string{}, p.Labels...), p.Annotations...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid metadata propagation pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Propagated returns the labels and annotations of obj that match the patterns, nil if none match.
+func (p MetadataPropagation) Propagated(obj metav1.Object) (map[string]string, map[string]string) {
+	return matchingKeys(obj.GetLabels(), p.Labels), matchingKeys(obj.GetAnnotations(), p.Annotations)
+}
+
+func matchingKeys(values map[string]string, patterns []string) map[string]string {
+	var matching map[string]string
+	for key, value := range values {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, key); matched {
+				if matching == nil {
+					matching = make(map[string]string)
+				}
+				matching[key] = value
+				break
+			}
+		}
+	}
+	return matching
+}
+
+// WithMetadataPropagation copies the selected labels and annotations of every object onto its rendered
+// resources of the MetadataPropagation Kinds.
+func WithMetadataPropagation(propagation MetadataPropagation) PostRenderTransformOption {
+	kinds := propagation.Kinds
+	if len(kinds) == 0 {
+		kinds = DefaultMetadataPropagationKinds()
+	}
+	return WithPostRenderTransform(func(_ context.Context, obj Object, resources []*unstructured.Unstructured) error {
+		lbls, annotations := propagation.Propagated(obj)
+		if len(lbls) == 0 && len(annotations) == 0 {
+			return nil
+		}
+		for _, resource := range resources {
+			if !containsGroupKind(kinds, resource.GroupVersionKind().GroupKind()) {
+				continue
+			}
+			if len(lbls) > 0 {
+				resource.SetLabels(labels.Merge(resource.GetLabels(), lbls))
+			}
+			if len(annotations) > 0 {
+				resource.SetAnnotations(labels.Merge(resource.GetAnnotations(), annotations))
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,45 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestMetadataPropagation(t *testing.T) {
+	t.Parallel()
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetLabels(map[string]string{"billing.example.com/cost-center": "42", "operator.kyma-project.io/kyma": "kyma"})
+	obj.SetAnnotations(map[string]string{"billing.example.com/owner": "team-a", "note": "internal"})
+
+	deployment := renderedObject("Deployment", "keda-operator", nil)
+	deployment.SetAPIVersion("apps/v1")
+	deployment.SetLabels(map[string]string{"billing.example.com/cost-center": "rendered", "app": "keda"})
+	configMap := renderedObject("ConfigMap", "keda-config", nil)
+
+	options := &Options{}
+	WithMetadataPropagation(MetadataPropagation{
+		Labels: []string{"billing.example.com/*"}, Annotations: []string{"billing.example.com/*"},
+	}).Apply(options)
+	require.Len(t, options.PostRenderTransforms, 1)
+	require.NoError(t, options.PostRenderTransforms[0](
+		context.Background(), obj, []*unstructured.Unstructured{deployment, configMap},
+	))
+
+	assert.Equal(t, map[string]string{"billing.example.com/cost-center": "42", "app": "keda"}, deployment.GetLabels())
+	assert.Equal(t, map[string]string{"billing.example.com/owner": "team-a"}, deployment.GetAnnotations())
+	assert.Empty(t, configMap.GetLabels(), "only workloads receive the metadata by default")
+	assert.Empty(t, configMap.GetAnnotations())
+}
+
+func TestMetadataPropagationValidate(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, MetadataPropagation{Labels: []string{"billing.example.com/*", "team"}}.Validate())
+	assert.Error(t, MetadataPropagation{Annotations: []string{"billing.example.com/["}}.Validate())
+}
@@ -0,0 +1,89 @@
+package v2
+
+import (
+	"time"
+
+	"github.com/kyma-project/module-manager/pkg/labels"
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Names and labels of the metrics, they are referenced by the generated dashboards and alerts.
+const (
+	MetricManifestCacheCorruptions = "declarative_manifest_cache_corruptions_total"
+	MetricReconcileDuration        = "declarative_reconcile_duration_seconds"
+	MetricReconcileErrors          = "declarative_reconcile_errors_total"
+	MetricRecoveredPanics          = "declarative_recovered_panics_total"
+	MetricAbandonedResponses       = "declarative_abandoned_responses_total"
+	MetricMetadataDrifts           = "declarative_metadata_drifts_total"
+
+	MetricLabelModule  = "module"
+	MetricLabelChannel = "channel"
+	MetricLabelState   = "state"
+	MetricLabelSource  = "source"
+)
+
+var (
+	// ManifestCacheCorruptions counts cached manifests that failed checksum verification and had to be rendered again.
+	ManifestCacheCorruptions = prometheus.NewCounter(prometheus.CounterOpts{ //nolint:gochecknoglobals
+		Name: MetricManifestCacheCorruptions,
+		Help: "Number of cached manifests that did not match their checksum and were rendered again",
+	})
+	// ReconcileDuration observes the duration of reconciliations per module and channel,
+	// labeled with the resulting state of the object.
+	ReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{ //nolint:gochecknoglobals
+		Name:    MetricReconcileDuration,
+		Help:    "Duration of reconciliations by module, channel and resulting state",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12), //nolint:gomnd
+	}, []string{MetricLabelModule, MetricLabelChannel, MetricLabelState})
+	// ReconcileErrors counts reconciliations per module and channel that ended in StateError.
+	ReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals
+		Name: MetricReconcileErrors,
+		Help: "Number of reconciliations by module and channel that resulted in an error state",
+	}, []string{MetricLabelModule, MetricLabelChannel})
+	// RecoveredPanics counts panics that were recovered and converted into errors.
+	RecoveredPanics = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals
+		Name: MetricRecoveredPanics,
+		Help: "Number of panics that were recovered and converted into errors by source",
+	}, []string{MetricLabelSource})
+	// AbandonedResponses counts worker responses that were no longer awaited because the operation ended.
+	AbandonedResponses = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals
+		Name: MetricAbandonedResponses,
+		Help: "Number of worker responses that were abandoned because the operation context was done by source",
+	}, []string{MetricLabelSource})
+	// MetadataDrifts counts consistency checks per module and channel that found labels or annotations
+	// of the rendered resources missing or changed in the cluster.
+	MetadataDrifts = prometheus.NewCounterVec(prometheus.CounterOpts{ //nolint:gochecknoglobals
+		Name: MetricMetadataDrifts,
+		Help: "Number of consistency checks by module and channel that detected drifted labels or annotations",
+	}, []string{MetricLabelModule, MetricLabelChannel})
+)
+
+//nolint:gochecknoinits
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		ManifestCacheCorruptions,
+		ReconcileDuration,
+		ReconcileErrors,
+		RecoveredPanics,
+		AbandonedResponses,
+		MetadataDrifts,
+	)
+}
+
+// recordReconcile observes a finished reconciliation of obj. Module and channel are taken from the
+// labels.ModuleName and labels.Channel labels instead of the object name to keep the cardinality low.
+func recordMetadataDrift(obj Object) {
+	lbls := obj.GetLabels()
+	MetadataDrifts.WithLabelValues(lbls[labels.ModuleName], lbls[labels.Channel]).Inc()
+}
+
+func recordReconcile(obj Object, start time.Time) {
+	lbls := obj.GetLabels()
+	module, channel := lbls[labels.ModuleName], lbls[labels.Channel]
+	state := obj.GetStatus().State
+	ReconcileDuration.WithLabelValues(module, channel, string(state)).Observe(time.Since(start).Seconds())
+	if state == StateError {
+		ReconcileErrors.WithLabelValues(module, channel).Inc()
+	}
+}
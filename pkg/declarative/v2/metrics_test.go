@@ -0,0 +1,48 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kyma-project/module-manager/pkg/labels"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type statusObj struct {
+	*unstructured.Unstructured
+	status Status
+}
+
+func (s *statusObj) ComponentName() string   { return "test-object" }
+func (s *statusObj) GetStatus() Status       { return s.status }
+func (s *statusObj) SetStatus(status Status) { s.status = status }
+func (s *statusObj) DeepCopyObject() runtime.Object {
+	return &statusObj{Unstructured: s.Unstructured.DeepCopy(), status: *s.status.DeepCopy()}
+}
+
+func TestRecordReconcile(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetLabels(map[string]string{labels.ModuleName: "metrics-test-module", labels.Channel: "fast"})
+
+	obj.SetStatus(Status{State: StateError})
+	recordReconcile(obj, time.Now())
+	obj.SetStatus(Status{State: StateReady})
+	recordReconcile(obj, time.Now())
+
+	assertions.Equal(float64(1), testutil.ToFloat64(ReconcileErrors.WithLabelValues("metrics-test-module", "fast")))
+	observer := ReconcileDuration.WithLabelValues("metrics-test-module", "fast", string(StateReady))
+	histogram := &dto.Metric{}
+	assertions.NoError(observer.(prometheus.Histogram).Write(histogram))
+	assertions.Equal(uint64(1), histogram.GetHistogram().GetSampleCount())
+}
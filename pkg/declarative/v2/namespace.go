@@ -0,0 +1,124 @@
+package v2
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NamespaceCreatedByLabel marks namespaces that were created by the reconciler.
+// Only namespaces carrying it are deleted, adopted namespaces that existed before are always kept.
+const NamespaceCreatedByLabel = "declarative.kyma-project.io/namespace-created-by"
+
+// ProtectedNamespaces are never deleted, regardless of the ownership of the namespace.
+var ProtectedNamespaces = []string{ //nolint:gochecknoglobals
+	metav1.NamespaceSystem,
+	metav1.NamespaceDefault,
+	metav1.NamespacePublic,
+	v1.NamespaceNodeLease,
+}
+
+func isProtectedNamespace(name string) bool {
+	for _, protected := range ProtectedNamespaces {
+		if name == protected {
+			return true
+		}
+	}
+	return false
+}
+
+func isNamespace(info *resource.Info) bool {
+	gvk := info.Object.GetObjectKind().GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
+
+// ensureNamespace creates the namespace with the NamespaceCreatedByLabel if it does not exist yet.
+// Existing namespaces are adopted as they are.
+func ensureNamespace(ctx context.Context, clnt client.Client, name string) error {
+	namespace := &v1.Namespace{}
+	err := clnt.Get(ctx, client.ObjectKey{Name: name}, namespace)
+	if err == nil || !apierrors.IsNotFound(err) {
+		return err
+	}
+	namespace.SetName(name)
+	namespace.SetLabels(map[string]string{NamespaceCreatedByLabel: managedByLabelValue})
+	if err := clnt.Create(ctx, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// markCreatedNamespaces adds the NamespaceCreatedByLabel to all rendered namespaces
+// that do not exist yet or were created by the reconciler before.
+func markCreatedNamespaces(ctx context.Context, clnt client.Client, infos []*resource.Info) error {
+	for _, info := range infos {
+		if !isNamespace(info) || isProtectedNamespace(info.Name) {
+			continue
+		}
+		created, err := isCreatedNamespace(ctx, clnt, info.Name)
+		if apierrors.IsNotFound(err) {
+			created = true
+		} else if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+		if obj, ok := info.Object.(*unstructured.Unstructured); ok {
+			lbls := obj.GetLabels()
+			if lbls == nil {
+				lbls = make(map[string]string)
+			}
+			lbls[NamespaceCreatedByLabel] = managedByLabelValue
+			obj.SetLabels(lbls)
+		}
+	}
+	return nil
+}
+
+// withoutAdoptedNamespaces removes all protected namespaces and namespaces that were not created
+// by the reconciler from infos, so that they are never deleted.
+func withoutAdoptedNamespaces(
+	ctx context.Context, clnt client.Client, infos []*resource.Info,
+) ([]*resource.Info, error) {
+	filtered := make([]*resource.Info, 0, len(infos))
+	for _, info := range infos {
+		if !isNamespace(info) {
+			filtered = append(filtered, info)
+			continue
+		}
+		if isProtectedNamespace(info.Name) {
+			log.FromContext(ctx).Info("keeping protected namespace", "namespace", info.Name)
+			continue
+		}
+		created, err := isCreatedNamespace(ctx, clnt, info.Name)
+		if apierrors.IsNotFound(err) {
+			filtered = append(filtered, info)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if !created {
+			log.FromContext(ctx).Info("keeping adopted namespace", "namespace", info.Name)
+			continue
+		}
+		filtered = append(filtered, info)
+	}
+	return filtered, nil
+}
+
+func isCreatedNamespace(ctx context.Context, clnt client.Client, name string) (bool, error) {
+	namespace := &metav1.PartialObjectMetadata{}
+	namespace.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Namespace"))
+	if err := clnt.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+		return false, err
+	}
+	_, created := namespace.GetLabels()[NamespaceCreatedByLabel]
+	return created, nil
+}
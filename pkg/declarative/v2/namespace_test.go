@@ -0,0 +1,66 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func namespaceInfo(name string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("Namespace")
+	obj.SetName(name)
+	return &resource.Info{Name: name, Object: obj}
+}
+
+func TestNamespaceOwnership(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	clnt := fake.NewClientBuilder().WithObjects(
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "adopted"}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "created", Labels: map[string]string{NamespaceCreatedByLabel: managedByLabelValue},
+		}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: metav1.NamespaceSystem, Labels: map[string]string{NamespaceCreatedByLabel: managedByLabelValue},
+		}},
+	).Build()
+
+	infos := []*resource.Info{
+		namespaceInfo("adopted"), namespaceInfo("created"), namespaceInfo("new"), namespaceInfo(metav1.NamespaceSystem),
+	}
+	assertions.NoError(markCreatedNamespaces(ctx, clnt, infos))
+	for _, info := range infos {
+		_, marked := info.Object.(*unstructured.Unstructured).GetLabels()[NamespaceCreatedByLabel]
+		assertions.Equal(info.Name == "created" || info.Name == "new", marked, info.Name)
+	}
+
+	deletable, err := withoutAdoptedNamespaces(ctx, clnt, infos)
+	assertions.NoError(err)
+	var names []string
+	for _, info := range deletable {
+		names = append(names, info.Name)
+	}
+	assertions.ElementsMatch([]string{"created", "new"}, names)
+
+	assertions.NoError(ensureNamespace(ctx, clnt, "ensured"))
+	assertions.NoError(ensureNamespace(ctx, clnt, "adopted"))
+	created, err := isCreatedNamespace(ctx, clnt, "ensured")
+	assertions.NoError(err)
+	assertions.True(created)
+	created, err = isCreatedNamespace(ctx, clnt, "adopted")
+	assertions.NoError(err)
+	assertions.False(created)
+}
@@ -4,6 +4,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kyma-project/module-manager/internal"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -41,6 +43,77 @@ type Status struct {
 	// +listType=atomic
 	Synced        []Resource `json:"synced,omitempty"`
 	LastOperation `json:"lastOperation,omitempty"`
+
+	// Installs contains the observed state of every install rendered for the CustomObject.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Installs []InstallStatus `json:"installs,omitempty"`
+
+	// Provenance contains metadata about the origin of the rendered artifact, e.g. the source repository
+	// and revision from the annotations of the OCI manifest a chart was pulled from.
+	// +optional
+	Provenance map[string]string `json:"provenance,omitempty"`
+
+	// Journal records the last operation that applied resources, see OperationJournal.
+	// +optional
+	Journal *OperationJournal `json:"journal,omitempty"`
+
+	// InstalledOnce marks that the CustomObject reached the Ready state at least once, so that
+	// following reconciliations are treated as upgrades or consistency checks instead of the initial install.
+	// +optional
+	InstalledOnce bool `json:"installedOnce,omitempty"`
+
+	// ReconcilerVersion is the version of the reconciler that applied the resources last, so that objects
+	// applied by previous versions can be reprocessed once the rendering behavior changed.
+	// +optional
+	ReconcilerVersion string `json:"reconcilerVersion,omitempty"`
+
+	// ReleaseName is the helm release name claimed by the CustomObject, so that other CustomObjects using the same
+	// release name in the same namespace of the target cluster are detected, see ErrReleaseNameCollision.
+	// +optional
+	ReleaseName string `json:"releaseName,omitempty"`
+
+	// ObservedGeneration is the generation of the CustomObject whose resources were applied last.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// SpecHash identifies the resolved specification whose resources were applied last, so that renders can be
+	// skipped as long as neither the CustomObject nor its resolved specification changed.
+	// +optional
+	SpecHash string `json:"specHash,omitempty"`
+
+	// Resync is the value of the ResyncAnnotation whose resources were applied last, so that every change of the
+	// annotation renders and applies the resources once.
+	// +optional
+	Resync string `json:"resync,omitempty"`
+
+	// Failures counts the consecutive reconciliations that failed, see FailureStreak.
+	// +optional
+	Failures *FailureStreak `json:"failures,omitempty"`
+
+	// RolledBack identifies the resources that were rolled back after their apply failed, they are not applied
+	// again until the CustomObject, its resolved specification or its ResyncAnnotation changes.
+	// +optional
+	RolledBack *RolledBackSpec `json:"rolledBack,omitempty"`
+}
+
+// InstallStatus defines the observed state of a single install.
+type InstallStatus struct {
+	// Name of the install as referenced in the spec.
+	Name string `json:"name"`
+	// State of the install, see Status.State.
+	// +kubebuilder:validation:Enum=Processing;Deleting;Ready;Error
+	State State `json:"state"`
+	// Ready is true once all resources of the install are applied and passed the ready check.
+	Ready bool `json:"ready"`
+	// Revision is the last revision of the install that was applied and became ready,
+	// e.g. the layer digest of an OCI chart.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+	// LastError is the last error that occurred for the install, it is cleared once the install is ready.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
 }
 
 type State string
@@ -101,6 +174,28 @@ func (r Resource) ID() string {
 	return strings.Join([]string{r.Namespace, r.Name, r.Group, r.Version, r.Kind}, "/")
 }
 
+// WithInstall sets the status of the install based on the current State and LastOperation.
+// Installs with other names are dropped, as only one install is rendered per CustomObject.
+func (s Status) WithInstall(name, revision string) Status {
+	install := InstallStatus{Name: name, State: s.State}
+	for i := range s.Installs {
+		if s.Installs[i].Name == name {
+			install.Revision = s.Installs[i].Revision
+			install.LastError = s.Installs[i].LastError
+		}
+	}
+	switch s.State { //nolint:exhaustive
+	case StateReady:
+		install.Ready = true
+		install.Revision = revision
+		install.LastError = ""
+	case StateError:
+		install.LastError = s.LastOperation.Operation
+	}
+	s.Installs = []InstallStatus{install}
+	return s
+}
+
 // LastOperation defines the last operation from the control-loop.
 // +k8s:deepcopy-gen=true
 type LastOperation struct {
@@ -108,12 +203,18 @@ type LastOperation struct {
 	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
+// WithErr sets the error as last operation, values of sensitive keys in the error message are redacted.
 func (s Status) WithErr(err error) Status {
-	s.LastOperation = LastOperation{Operation: err.Error(), LastUpdateTime: metav1.NewTime(time.Now())}
+	s.LastOperation = LastOperation{
+		Operation: internal.RedactString(err.Error()), LastUpdateTime: metav1.NewTime(time.Now()),
+	}
 	return s
 }
 
+// WithOperation sets the last operation, values of sensitive keys in the operation are redacted.
 func (s Status) WithOperation(operation string) Status {
-	s.LastOperation = LastOperation{Operation: operation, LastUpdateTime: metav1.NewTime(time.Now())}
+	s.LastOperation = LastOperation{
+		Operation: internal.RedactString(operation), LastUpdateTime: metav1.NewTime(time.Now()),
+	}
 	return s
 }
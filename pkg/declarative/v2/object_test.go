@@ -0,0 +1,42 @@
+package v2_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusWithInstall(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	status := Status{}.WithState(StateError).WithErr(errors.New("render failed")).WithInstall("install", "rev-1")
+	assertions.Equal([]InstallStatus{{Name: "install", State: StateError, LastError: "render failed"}}, status.Installs)
+
+	status = status.WithState(StateProcessing).WithInstall("install", "rev-1")
+	assertions.Equal([]InstallStatus{{Name: "install", State: StateProcessing, LastError: "render failed"}},
+		status.Installs, "last error and revision are kept until the install is ready")
+
+	status = status.WithState(StateReady).WithInstall("install", "rev-1")
+	assertions.Equal([]InstallStatus{{Name: "install", State: StateReady, Ready: true, Revision: "rev-1"}},
+		status.Installs)
+
+	status = status.WithState(StateProcessing).WithInstall("install", "rev-2")
+	assertions.Equal([]InstallStatus{{Name: "install", State: StateProcessing, Revision: "rev-1"}}, status.Installs)
+
+	status = status.WithInstall("renamed", "rev-2")
+	assertions.Equal([]InstallStatus{{Name: "renamed", State: StateProcessing}}, status.Installs)
+}
+
+func TestStatusWithErrRedactsOnlyAssignments(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	status := Status{}.WithErr(errors.New("fetching kubeconfig: connection refused"))
+	assertions.Equal("fetching kubeconfig: connection refused", status.LastOperation.Operation)
+
+	status = status.WithErr(errors.New("invalid override db.password=hunter2"))
+	assertions.Equal("invalid override db.password=***", status.LastOperation.Operation)
+}
@@ -4,15 +4,18 @@ import (
 	"context"
 	"errors"
 	"os"
+	"text/template"
 	"time"
 
 	"github.com/kyma-project/module-manager/internal"
 	"github.com/kyma-project/module-manager/pkg/types"
+	"golang.org/x/time/rate"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -26,6 +29,9 @@ const (
 	DefaultSkipReconcileLabel = "declarative.kyma-project.io/skip-reconciliation"
 	DefaultCacheKey           = "declarative.kyma-project.io/cache-key"
 	DefaultInMemoryParseTTL   = 24 * time.Hour
+
+	DefaultDownloadRetryBaseDelay = 200 * time.Millisecond
+	DefaultDownloadRetryMaxDelay  = 10 * time.Second
 )
 
 func DefaultOptions() *Options {
@@ -34,6 +40,7 @@ func DefaultOptions() *Options {
 		WithNamespace(metav1.NamespaceDefault, false),
 		WithFinalizer(FinalizerDefault),
 		WithFieldOwner(FieldOwnerDefault),
+		WithForceConflicts(true),
 		WithPostRenderTransform(
 			managedByDeclarativeV2,
 			watchedByOwnedBy,
@@ -46,6 +53,9 @@ func DefaultOptions() *Options {
 		WithManifestCache(os.TempDir()),
 		WithSkipReconcileOn(SkipReconcileOnDefaultLabelPresentAndTrue),
 		WithManifestParser(NewInMemoryCachedManifestParser(DefaultInMemoryParseTTL)),
+		WithDownloadRetryBackoff(DefaultDownloadRetryBaseDelay, DefaultDownloadRetryMaxDelay),
+		WithReleaseNameTemplate(template.Must(ParseReleaseNameTemplate(DefaultReleaseNameTemplate))),
+		WithDependencyRequeueInterval(DefaultDependencyRequeueInterval),
 	)
 }
 
@@ -53,24 +63,38 @@ type Options struct {
 	record.EventRecorder
 	Config *rest.Config
 	client.Client
-	TargetCluster ClusterFn
+	TargetCluster  ClusterFn
+	ClusterVersion ClusterVersionFn
 
 	SpecResolver
 	ClientCache
 	ClientCacheKeyFn
 	ManifestParser
 	ManifestCache
-	CustomReadyCheck ReadyCheck
+	SharedRenderCache *SharedRenderCache
+	CustomReadyCheck  ReadyCheck
+	WaitForWebhooks   bool
+
+	ClusterReadinessCheck ClusterReadinessCheck
 
 	Namespace       string
 	CreateNamespace bool
 
 	Finalizer string
 
-	ServerSideApply bool
-	FieldOwner      client.FieldOwner
+	ServerSideApply       bool
+	FieldOwner            client.FieldOwner
+	ForceConflicts        bool
+	PreviousFieldOwners   []client.FieldOwner
+	StrictFieldValidation bool
 
 	PostRenderTransforms []ObjectTransform
+	MetadataDriftCheck   bool
+	SkipUnchangedSpec    bool
+	Remediation          WithRemediation
+
+	LastAppliedConfiguration bool
+	RollbackOnFailure        WithRollbackOnFailure
 
 	PostRuns   []PostRun
 	PreDeletes []PreDelete
@@ -79,7 +103,34 @@ type Options struct {
 
 	ShouldSkip SkipReconcile
 
-	CtrlOnSuccess ctrl.Result
+	CtrlOnSuccess   ctrl.Result
+	CtrlOnSuccessFn func() ctrl.Result
+
+	DownloadRetryRateLimiter workqueue.RateLimiter
+
+	OperationTimeout time.Duration
+
+	InstallOperationTimeout time.Duration
+	InstallRequeueInterval  time.Duration
+
+	DependencyRequeueInterval time.Duration
+
+	RetryBudgetFailures int
+	RetryBudgetWindow   time.Duration
+
+	MaxRenderedBytes   int
+	MaxRenderedObjects int
+
+	ReleaseNameTemplate *template.Template
+
+	KustomizePlugins KustomizePlugins
+
+	MetadataInformers *MetadataInformerCache
+
+	AuditLog AuditLog
+
+	ReconcilerVersion    string
+	VersionResyncLimiter *rate.Limiter
 }
 
 type Option interface {
@@ -110,12 +161,49 @@ func (o WithNamespaceOption) Apply(options *Options) {
 	options.CreateNamespace = o.createIfMissing
 }
 
+// WithFieldOwner sets the field manager used for server-side apply of all resources and the object itself.
+// Operators that co-manage the same resources must use distinct field owners, otherwise they overwrite
+// and prune each other's fields. See WithForceConflicts for the handling of fields owned by both.
 type WithFieldOwner client.FieldOwner
 
 func (o WithFieldOwner) Apply(options *Options) {
 	options.FieldOwner = client.FieldOwner(o)
 }
 
+// WithForceConflicts determines if fields that are also owned by other field managers are taken over (default)
+// or if the conflict is reported as ErrFieldOwnershipConflict, leaving the resource unchanged.
+// Fields owned by only one of several co-managing operators never conflict and are merged by the API server.
+type WithForceConflicts bool
+
+func (o WithForceConflicts) Apply(options *Options) {
+	options.ForceConflicts = bool(o)
+}
+
+// WithStrictFieldValidation applies resources with the strict server-side field validation, so that unknown or
+// duplicate fields, e.g. `replica:` instead of `replicas:` in a chart template, fail the install with
+// ErrStrictFieldValidation instead of being ignored.
+type WithStrictFieldValidation bool
+
+func (o WithStrictFieldValidation) Apply(options *Options) {
+	options.StrictFieldValidation = bool(o)
+}
+
+// WithFieldOwnerMigration merges the fields of previous field managers, such as the legacy reconciler
+// that used client-side apply, into the ownership of the field owner before applying resources.
+// Without the migration, fields that are no longer rendered stay owned by the previous manager and are never removed.
+// Every apply requires an additional request while the option is configured.
+func WithFieldOwnerMigration(previousOwners ...client.FieldOwner) WithFieldOwnerMigrationOption {
+	return WithFieldOwnerMigrationOption{previousOwners: previousOwners}
+}
+
+type WithFieldOwnerMigrationOption struct {
+	previousOwners []client.FieldOwner
+}
+
+func (o WithFieldOwnerMigrationOption) Apply(options *Options) {
+	options.PreviousFieldOwners = append(options.PreviousFieldOwners, o.previousOwners...)
+}
+
 type WithFinalizer string
 
 func (o WithFinalizer) Apply(options *Options) {
@@ -131,7 +219,7 @@ func WithManager(mgr manager.Manager) WithManagerOption {
 }
 
 func (o WithManagerOption) Apply(options *Options) {
-	options.EventRecorder = o.GetEventRecorderFor(EventRecorderDefault)
+	options.EventRecorder = &redactingEventRecorder{EventRecorder: o.GetEventRecorderFor(EventRecorderDefault)}
 	options.Config = o.GetConfig()
 	options.Client = o.GetClient()
 }
@@ -181,6 +269,16 @@ func (o PostRenderTransformOption) Apply(options *Options) {
 	options.PostRenderTransforms = append(options.PostRenderTransforms, o.ObjectTransforms...)
 }
 
+// WithMetadataDriftCheck compares the labels and annotations of the rendered resources, including the ones added
+// by PostRenderTransforms, with the live resources on every consistency check of ready objects.
+// Drift is reported as an event and in MetadataDrifts before the resources are applied again.
+// Every check requires an additional metadata request per resource.
+type WithMetadataDriftCheck bool
+
+func (o WithMetadataDriftCheck) Apply(options *Options) {
+	options.MetadataDriftCheck = bool(o)
+}
+
 // Hook defines a Hook into the declarative reconciliation
 // skr is the runtime cluster
 // kcp is the control-plane cluster
@@ -223,6 +321,95 @@ func (o WithPermanentConsistencyCheck) Apply(options *Options) {
 	options.CtrlOnSuccess = ctrl.Result{Requeue: bool(o)}
 }
 
+// WithDynamicConsistencyCheck evaluates the consistency check interval after every successful reconciliation,
+// so that it can be changed at runtime. It takes precedence over WithPeriodicConsistencyCheck.
+type WithDynamicConsistencyCheck func() time.Duration
+
+func (o WithDynamicConsistencyCheck) Apply(options *Options) {
+	options.CtrlOnSuccessFn = func() ctrl.Result {
+		return ctrl.Result{RequeueAfter: o()}
+	}
+}
+
+// WithDownloadRetryBackoff configures the exponential backoff used to requeue objects whose spec could not be
+// resolved because of a types.DownloadError. It is independent of the rate limiter of the controller,
+// so that transient registry failures are retried faster than other errors.
+func WithDownloadRetryBackoff(baseDelay, maxDelay time.Duration) WithDownloadRetryBackoffOption {
+	return WithDownloadRetryBackoffOption{
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+	}
+}
+
+type WithDownloadRetryBackoffOption struct {
+	workqueue.RateLimiter
+}
+
+func (o WithDownloadRetryBackoffOption) Apply(options *Options) {
+	options.DownloadRetryRateLimiter = o.RateLimiter
+}
+
+// WithOperationTimeout bounds the resolution, rendering, apply and deletion of a single reconciliation.
+// Exceeding it fails the reconciliation with context.DeadlineExceeded, which is retried like any other error.
+// A timeout of 0 disables the deadline.
+type WithOperationTimeout time.Duration
+
+func (o WithOperationTimeout) Apply(options *Options) {
+	options.OperationTimeout = time.Duration(o)
+}
+
+// WithInstallOperationTimeout replaces the WithOperationTimeout of objects that were never ready,
+// as initial installs usually take longer than upgrades, e.g. because of image pulls or volume provisioning.
+// A timeout of 0 keeps the WithOperationTimeout.
+type WithInstallOperationTimeout time.Duration
+
+func (o WithInstallOperationTimeout) Apply(options *Options) {
+	options.InstallOperationTimeout = time.Duration(o)
+}
+
+// WithInstallRequeueInterval requeues objects that were never ready and wait for their resources to become ready
+// after the interval instead of the backoff of the queue. An interval of 0 keeps the backoff.
+type WithInstallRequeueInterval time.Duration
+
+func (o WithInstallRequeueInterval) Apply(options *Options) {
+	options.InstallRequeueInterval = time.Duration(o)
+}
+
+// WithReleaseNameTemplate determines the helm release name of an object, see ReleaseNameData for the available fields.
+// Objects that resolve to the same release name in the same namespace of a target cluster are reported
+// with ErrReleaseNameCollision, e.g. use "{{ .ManifestName }}-{{ .Hash }}" to derive unique release names.
+func WithReleaseNameTemplate(tmpl *template.Template) WithReleaseNameTemplateOption {
+	return WithReleaseNameTemplateOption{Template: tmpl}
+}
+
+type WithReleaseNameTemplateOption struct {
+	*template.Template
+}
+
+func (o WithReleaseNameTemplateOption) Apply(options *Options) {
+	options.ReleaseNameTemplate = o.Template
+}
+
+// WithKustomizePlugins enables plugins of the kustomize renderer, see KustomizePlugins.
+type WithKustomizePlugins KustomizePlugins
+
+func (o WithKustomizePlugins) Apply(options *Options) {
+	options.KustomizePlugins = KustomizePlugins(o)
+}
+
+// WithMetadataInformerCache serves the existence and metadata reads of consistency checks from informers
+// of the target clusters instead of a GET per resource, see MetadataInformerCache for its memory bounds.
+func WithMetadataInformerCache(informers *MetadataInformerCache) WithMetadataInformerCacheOption {
+	return WithMetadataInformerCacheOption{MetadataInformerCache: informers}
+}
+
+type WithMetadataInformerCacheOption struct {
+	*MetadataInformerCache
+}
+
+func (o WithMetadataInformerCacheOption) Apply(options *Options) {
+	options.MetadataInformers = o.MetadataInformerCache
+}
+
 type WithSingletonClientCacheOption struct {
 	ClientCache
 }
@@ -232,7 +419,7 @@ func WithSingletonClientCache(cache ClientCache) WithSingletonClientCacheOption
 }
 
 func (o WithSingletonClientCacheOption) Apply(options *Options) {
-	options.ClientCache = o
+	options.ClientCache = o.ClientCache
 }
 
 type WithDeleteCRDs bool
@@ -275,6 +462,14 @@ func (o WithCustomReadyCheckOption) Apply(options *Options) {
 	options.CustomReadyCheck = o
 }
 
+// WithWaitForWebhooks extends the ready check with a WebhookReadyCheck, so that objects only become ready
+// once the webhooks of their resources are serving.
+type WithWaitForWebhooks bool
+
+func (o WithWaitForWebhooks) Apply(options *Options) {
+	options.WaitForWebhooks = bool(o)
+}
+
 type ClusterFn func(context.Context, Object) (*types.ClusterInfo, error)
 
 func WithRemoteTargetCluster(configFn ClusterFn) WithRemoteTargetClusterOption {
@@ -289,17 +484,64 @@ func (o WithRemoteTargetClusterOption) Apply(options *Options) {
 	options.TargetCluster = o.ClusterFn
 }
 
-func WithSkipReconcileOn(skipReconcile SkipReconcile) WithSkipReconcileOnOption {
-	return WithSkipReconcileOnOption{skipReconcile: skipReconcile}
+// ClusterVersionFn returns the current types.ClusterInfo Version of the target cluster of an object.
+type ClusterVersionFn func(context.Context, Object) (string, error)
+
+// WithClusterVersion verifies cached clients against the current version of their target cluster,
+// e.g. the resourceVersion of the kubeconfig secret, so that rotated credentials produce a new client.
+func WithClusterVersion(versionFn ClusterVersionFn) WithClusterVersionOption {
+	return WithClusterVersionOption{ClusterVersionFn: versionFn}
+}
+
+type WithClusterVersionOption struct {
+	ClusterVersionFn
+}
+
+func (o WithClusterVersionOption) Apply(options *Options) {
+	options.ClusterVersion = o.ClusterVersionFn
+}
+
+// WithSkipReconcileOn replaces the predicates that determine if an object is skipped during reconciliation.
+// The object is skipped if any of the predicates returns true.
+func WithSkipReconcileOn(skipReconcile ...SkipReconcile) WithSkipReconcileOnOption {
+	return WithSkipReconcileOnOption{skipReconcile: SkipReconcileOnAny(skipReconcile...)}
+}
+
+// WithAdditionalSkipReconcileOn adds predicates to the already configured ones, e.g. to keep
+// SkipReconcileOnDefaultLabelPresentAndTrue while skipping objects outside a maintenance window.
+func WithAdditionalSkipReconcileOn(skipReconcile ...SkipReconcile) WithAdditionalSkipReconcileOnOption {
+	return WithAdditionalSkipReconcileOnOption{skipReconcile: skipReconcile}
 }
 
 type SkipReconcile func(context.Context, Object) (skip bool)
 
+// SkipReconcileOnAny combines the predicates so that the object is skipped if any of them returns true.
+func SkipReconcileOnAny(skipReconcile ...SkipReconcile) SkipReconcile {
+	return func(ctx context.Context, object Object) bool {
+		for _, skip := range skipReconcile {
+			if skip != nil && skip(ctx, object) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // SkipReconcileOnDefaultLabelPresentAndTrue determines SkipReconcile by checking if DefaultSkipReconcileLabel is true.
 func SkipReconcileOnDefaultLabelPresentAndTrue(ctx context.Context, object Object) bool {
-	log.FromContext(ctx, "skip-label", DefaultSkipReconcileLabel).
-		V(internal.DebugLogLevel).Info("resource gets skipped because of label")
-	return object.GetLabels() != nil && object.GetLabels()[DefaultSkipReconcileLabel] == "true"
+	return SkipReconcileOnLabel(DefaultSkipReconcileLabel, "true")(ctx, object)
+}
+
+// SkipReconcileOnLabel skips objects that carry the label with the given value.
+func SkipReconcileOnLabel(label, value string) SkipReconcile {
+	return func(ctx context.Context, object Object) bool {
+		if object.GetLabels() == nil || object.GetLabels()[label] != value {
+			return false
+		}
+		log.FromContext(ctx, "skip-label", label).
+			V(internal.DebugLogLevel).Info("resource gets skipped because of label")
+		return true
+	}
 }
 
 type WithSkipReconcileOnOption struct {
@@ -310,6 +552,14 @@ func (o WithSkipReconcileOnOption) Apply(options *Options) {
 	options.ShouldSkip = o.skipReconcile
 }
 
+type WithAdditionalSkipReconcileOnOption struct {
+	skipReconcile []SkipReconcile
+}
+
+func (o WithAdditionalSkipReconcileOnOption) Apply(options *Options) {
+	options.ShouldSkip = SkipReconcileOnAny(append([]SkipReconcile{options.ShouldSkip}, o.skipReconcile...)...)
+}
+
 type ClientCacheKeyFn func(ctx context.Context, obj Object) any
 
 func WithClientCacheKeyFromLabelOrResource(label string) WithClientCacheKeyOption {
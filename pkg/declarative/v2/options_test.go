@@ -0,0 +1,38 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSkipReconcileOptions(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	skipLabeled := testObj{&unstructured.Unstructured{}}
+	skipLabeled.SetLabels(map[string]string{DefaultSkipReconcileLabel: "true"})
+	inMaintenance := testObj{&unstructured.Unstructured{}}
+	inMaintenance.SetLabels(map[string]string{"maintenance": "true"})
+	regular := testObj{&unstructured.Unstructured{}}
+
+	maintenance := SkipReconcileOnLabel("maintenance", "true")
+
+	options := DefaultOptions().Apply(WithAdditionalSkipReconcileOn(maintenance))
+	assertions.True(options.ShouldSkip(ctx, skipLabeled))
+	assertions.True(options.ShouldSkip(ctx, inMaintenance))
+	assertions.False(options.ShouldSkip(ctx, regular))
+
+	options = DefaultOptions().Apply(WithSkipReconcileOn(maintenance))
+	assertions.False(options.ShouldSkip(ctx, skipLabeled), "default predicate should be replaced")
+	assertions.True(options.ShouldSkip(ctx, inMaintenance))
+
+	options = DefaultOptions().Apply(WithSkipReconcileOn())
+	assertions.False(options.ShouldSkip(ctx, skipLabeled))
+}
@@ -0,0 +1,63 @@
+package v2
+
+import (
+	"reflect"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ResyncAnnotation requests a reconciliation of an object whose spec did not change, e.g. to correct drifted
+// resources before the next consistency check. Every change of its value triggers one reconciliation that renders
+// and applies the resources, even if they would be skipped as unchanged, served from the manifest cache or only
+// checked for drift otherwise, see Status.Resync.
+const ResyncAnnotation = "declarative.kyma-project.io/resync"
+
+// SpecChangedPredicate drops updates of objects that change neither the spec nor any of the given labels and
+// annotations, so that changes of other metadata or of the status do not render the object again.
+// Updates are passed if the generation, the deletion timestamp or the finalizers change.
+// Create, delete and generic events are always passed.
+type SpecChangedPredicate struct {
+	predicate.Funcs
+	Labels      []string
+	Annotations []string
+}
+
+// DefaultSpecChangedPredicate passes changes of the labels and annotations interpreted by the Reconciler.
+func DefaultSpecChangedPredicate() SpecChangedPredicate {
+	return SpecChangedPredicate{
+		Labels: []string{DefaultSkipReconcileLabel},
+		Annotations: []string{
+			ResyncAnnotation,
+			DeletionProtectionAnnotation,
+			ConfirmDeletionAnnotation,
+			ForceDeletionAnnotation,
+			MaintenanceWindowAnnotation,
+		},
+	}
+}
+
+func (p SpecChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return true
+	}
+	oldObj, newObj := e.ObjectOld, e.ObjectNew
+	return oldObj.GetGeneration() != newObj.GetGeneration() ||
+		!oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp()) ||
+		!reflect.DeepEqual(oldObj.GetFinalizers(), newObj.GetFinalizers()) ||
+		keysChanged(p.Labels, oldObj.GetLabels(), newObj.GetLabels()) ||
+		keysChanged(p.Annotations, oldObj.GetAnnotations(), newObj.GetAnnotations())
+}
+
+func keysChanged(keys []string, oldValues, newValues map[string]string) bool {
+	for _, key := range keys {
+		oldValue, oldFound := oldValues[key]
+		newValue, newFound := newValues[key]
+		if oldFound != newFound || oldValue != newValue {
+			return true
+		}
+	}
+	return false
+}
+
+var _ predicate.Predicate = SpecChangedPredicate{}
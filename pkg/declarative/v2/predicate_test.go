@@ -0,0 +1,54 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestSpecChangedPredicate(t *testing.T) {
+	t.Parallel()
+	specChanged := DefaultSpecChangedPredicate()
+
+	oldObj := &unstructured.Unstructured{}
+	oldObj.SetGeneration(1)
+	oldObj.SetLabels(map[string]string{"team": "a"})
+	oldObj.SetAnnotations(map[string]string{"note": "a"})
+
+	changes := map[string]struct {
+		mutate func(obj *unstructured.Unstructured)
+		passed bool
+	}{
+		"unrelated label": {func(obj *unstructured.Unstructured) {
+			obj.SetLabels(map[string]string{"team": "b"})
+		}, false},
+		"unrelated annotation": {func(obj *unstructured.Unstructured) { obj.SetAnnotations(nil) }, false},
+		"status":               {func(obj *unstructured.Unstructured) { obj.Object["status"] = "changed" }, false},
+		"generation":           {func(obj *unstructured.Unstructured) { obj.SetGeneration(2) }, true},
+		"finalizers": {func(obj *unstructured.Unstructured) {
+			obj.SetFinalizers([]string{FinalizerDefault})
+		}, true},
+		"deletion": {func(obj *unstructured.Unstructured) {
+			now := metav1.Now()
+			obj.SetDeletionTimestamp(&now)
+		}, true},
+		"resync": {func(obj *unstructured.Unstructured) {
+			obj.SetAnnotations(map[string]string{"note": "a", ResyncAnnotation: "1"})
+		}, true},
+		"skip label": {func(obj *unstructured.Unstructured) {
+			obj.SetLabels(map[string]string{"team": "a", DefaultSkipReconcileLabel: "true"})
+		}, true},
+	}
+	for name, change := range changes {
+		newObj := oldObj.DeepCopy()
+		change.mutate(newObj)
+		assert.Equal(t, change.passed, specChanged.Update(event.UpdateEvent{ObjectOld: oldObj, ObjectNew: newObj}), name)
+	}
+	assert.True(t, specChanged.Create(event.CreateEvent{Object: oldObj}))
+}
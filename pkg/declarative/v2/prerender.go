@@ -0,0 +1,56 @@
+package v2
+
+import (
+	"context"
+
+	"github.com/kyma-project/module-manager/internal"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Prerender resolves the Spec of obj and renders it into the ManifestCache without changing obj or its target
+// cluster, e.g. by installing prerequisites or applying resources. It is used by render-only instances that share
+// the ManifestCache with the instance reconciling obj on a volume mounted at the same path, so that the
+// reconciliation is served from the cache. Raw manifests are not rendered, as they are not cached, and neither are
+// objects with GeneratedValues, as their values are only known once they are reconciled. Objects are only rendered
+// once the reconciliation claimed their release in the Status and installed the CRDs of their chart, as
+// render-only instances neither claim releases nor install prerequisites. It returns false for skipped objects.
+func (r *Reconciler) Prerender(ctx context.Context, obj Object) (bool, error) {
+	ctx, cancel := r.operationContext(ctx, obj)
+	defer cancel()
+	logger := log.FromContext(ctx)
+
+	spec, err := r.Spec(ctx, obj)
+	if err != nil {
+		return false, err
+	}
+	if spec.Mode == RenderModeRaw || len(spec.GeneratedValues) > 0 {
+		logger.V(internal.DebugLogLevel).Info("skipping prerender", "mode", spec.Mode)
+		return false, nil
+	}
+	if spec.ReleaseName, err = executeReleaseNameTemplate(r.ReleaseNameTemplate, obj, spec); err != nil {
+		return false, err
+	}
+	status := obj.GetStatus()
+	if status.ReleaseName != spec.ReleaseName {
+		logger.V(internal.DebugLogLevel).Info("skipping prerender of unclaimed release", "release", spec.ReleaseName)
+		return false, nil
+	}
+	if spec.Mode == RenderModeHelm && !meta.IsStatusConditionTrue(status.Conditions, string(ConditionTypeHelmCRDs)) {
+		logger.V(internal.DebugLogLevel).Info("skipping prerender until the crds are installed")
+		return false, nil
+	}
+
+	clnt, err := r.targetClient(ctx, obj)
+	if err != nil {
+		return false, err
+	}
+	renderer := r.newRenderer(spec, clnt)
+	if err := renderer.Initialize(obj); err != nil {
+		return false, err
+	}
+	if _, err := renderer.Render(ctx, obj); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,54 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+type staticSpecResolver struct {
+	spec *Spec
+}
+
+func (s staticSpecResolver) Spec(context.Context, Object) (*Spec, error) {
+	spec := *s.spec
+	return &spec, nil
+}
+
+func TestPrerenderSkipsUncachedRenders(t *testing.T) {
+	t.Parallel()
+	targetClusterResolved := false
+	targetCluster := func(context.Context, Object) (*types.ClusterInfo, error) {
+		targetClusterResolved = true
+		return nil, nil //nolint:nilnil
+	}
+
+	claimed := Status{ReleaseName: "chart"}
+	for _, testCase := range []struct {
+		spec   *Spec
+		status Status
+	}{
+		{&Spec{ManifestName: "raw", Path: "manifest.yaml", Mode: RenderModeRaw}, Status{ReleaseName: "raw"}},
+		{&Spec{ManifestName: "chart", Path: "chart", Mode: RenderModeHelm, GeneratedValues: []GeneratedValue{{}}}, claimed},
+		{&Spec{ManifestName: "chart", Path: "chart", Mode: RenderModeHelm}, Status{}},
+		{&Spec{ManifestName: "chart", Path: "chart", Mode: RenderModeHelm}, claimed},
+	} {
+		r := &Reconciler{Options: &Options{
+			EventRecorder: record.NewFakeRecorder(10),
+			SpecResolver:  staticSpecResolver{spec: testCase.spec},
+			TargetCluster: targetCluster,
+		}}
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}, status: testCase.status}
+		rendered, err := r.Prerender(context.Background(), obj)
+		assert.NoError(t, err)
+		assert.False(t, rendered)
+	}
+	assert.False(t, targetClusterResolved, "skipped renders do not connect to the target cluster")
+}
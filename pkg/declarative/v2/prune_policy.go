@@ -0,0 +1,145 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// PruneAllowlistAnnotation restricts the pruning of resources that are no longer rendered for an object to the
+	// listed kinds, e.g. "Deployment.apps,ConfigMap". Kinds of the PruneDenylistAnnotation are never pruned.
+	PruneAllowlistAnnotation = "declarative.kyma-project.io/prune-allowlist"
+	// PruneDenylistAnnotation lists the kinds that are never pruned for an object, it replaces the
+	// DefaultPruneDenylist and an empty value allows to prune all kinds.
+	PruneDenylistAnnotation = "declarative.kyma-project.io/prune-denylist"
+)
+
+// DefaultPruneDenylist are the kinds that are not pruned unless the PruneDenylistAnnotation is set, as their
+// deletion loses data or every resource in them. Resources that are not pruned stay synced, so that they are still
+// removed on the uninstall of their object.
+func DefaultPruneDenylist() []schema.GroupKind {
+	return []schema.GroupKind{{Kind: "Secret"}, {Kind: "PersistentVolumeClaim"}, {Kind: "Namespace"}}
+}
+
+// PrunePolicy determines which kinds of the resources that are no longer rendered are deleted.
+type PrunePolicy struct {
+	// Allow restricts pruning to the listed kinds if it is not nil.
+	Allow []schema.GroupKind
+	// Deny are never pruned.
+	Deny []schema.GroupKind
+}
+
+// PrunePolicyOf parses the PruneAllowlistAnnotation and PruneDenylistAnnotation of the annotations of an object.
+func PrunePolicyOf(annotations map[string]string) (PrunePolicy, error) {
+	policy := PrunePolicy{Deny: DefaultPruneDenylist()}
+	if value, found := annotations[PruneAllowlistAnnotation]; found {
+		allow, err := parseGroupKinds(PruneAllowlistAnnotation, value)
+		if err != nil {
+			return policy, err
+		}
+		policy.Allow = allow
+	}
+	if value, found := annotations[PruneDenylistAnnotation]; found {
+		deny, err := parseGroupKinds(PruneDenylistAnnotation, value)
+		if err != nil {
+			return policy, err
+		}
+		policy.Deny = deny
+	}
+	return policy, nil
+}
+
+func parseGroupKinds(annotation, value string) ([]schema.GroupKind, error) {
+	groupKinds := []schema.GroupKind{}
+	for _, groupKind := range strings.Split(value, ",") {
+		groupKind = strings.TrimSpace(groupKind)
+		if groupKind == "" {
+			continue
+		}
+		parsed := schema.ParseGroupKind(groupKind)
+		if parsed.Kind == "" {
+			return nil, fmt.Errorf("invalid kind %q in %s", groupKind, annotation)
+		}
+		groupKinds = append(groupKinds, parsed)
+	}
+	return groupKinds, nil
+}
+
+// Allows determines if resources of groupKind may be pruned.
+func (p PrunePolicy) Allows(groupKind schema.GroupKind) bool {
+	if containsGroupKind(p.Deny, groupKind) {
+		return false
+	}
+	return p.Allow == nil || containsGroupKind(p.Allow, groupKind)
+}
+
+func containsGroupKind(groupKinds []schema.GroupKind, groupKind schema.GroupKind) bool {
+	for _, listed := range groupKinds {
+		if listed == groupKind {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutUnprunableResources removes the resources from diff that the PrunePolicy of obj does not allow to prune.
+// Unlike with the ResourcePolicyAnnotation they stay synced, see syncedResources.
+func (r *Reconciler) withoutUnprunableResources(
+	ctx context.Context, obj Object, diff []*resource.Info,
+) ([]*resource.Info, error) {
+	if len(diff) == 0 {
+		return diff, nil
+	}
+	policy, err := PrunePolicyOf(obj.GetAnnotations())
+	if err != nil {
+		r.Event(obj, "Warning", "PrunePolicy", err.Error())
+		obj.SetStatus(obj.GetStatus().WithState(StateError).WithErr(err))
+		return nil, err
+	}
+	prunable := make([]*resource.Info, 0, len(diff))
+	var kept []string
+	for _, info := range diff {
+		if policy.Allows(info.Object.GetObjectKind().GroupVersionKind().GroupKind()) {
+			prunable = append(prunable, info)
+			continue
+		}
+		kept = append(kept, info.ObjectName())
+	}
+	if len(kept) > 0 {
+		log.FromContext(ctx).Info("keeping resources excluded from pruning", "resources", kept)
+		r.Event(obj, "Normal", "PrunePolicy",
+			fmt.Sprintf("keeping resources excluded from pruning: %s", strings.Join(kept, ", ")))
+	}
+	return prunable, nil
+}
+
+// syncedResources returns the resources of target together with the resources of synced that are no longer rendered,
+// but kept by the PrunePolicy of obj, so that they are still deleted on the uninstall of obj.
+func syncedResources(obj Object, synced []Resource, target []*resource.Info) []Resource {
+	targetResources := NewInfoToResourceConverter().InfosToResources(target)
+	return mergeResources(targetResources, unprunedResources(obj, withoutResources(synced, targetResources)))
+}
+
+// unprunedResources returns the resources of removed that the PrunePolicy of obj does not allow to prune.
+func unprunedResources(obj Object, removed []Resource) []Resource {
+	if len(removed) == 0 {
+		return nil
+	}
+	policy, err := PrunePolicyOf(obj.GetAnnotations())
+	if err != nil {
+		// an invalid policy fails the reconciliation before resources are pruned or synced
+		return nil
+	}
+	var kept []Resource
+	for _, removedResource := range removed {
+		if !policy.Allows(schema.GroupKind{Group: removedResource.Group, Kind: removedResource.Kind}) {
+			kept = append(kept, removedResource)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,163 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/kube"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeTargetClient serves the reads and deletions in a target cluster from a fake client.
+type fakeTargetClient struct {
+	Client
+	fake client.Client
+}
+
+func (c *fakeTargetClient) Get(
+	ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption,
+) error {
+	return c.fake.Get(ctx, key, obj, opts...)
+}
+
+func (c *fakeTargetClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.fake.List(ctx, list, opts...)
+}
+
+func (c *fakeTargetClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.fake.Delete(ctx, obj, opts...)
+}
+
+func TestPrunePolicy(t *testing.T) {
+	t.Parallel()
+	deployment := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+	secret := schema.GroupKind{Kind: "Secret"}
+
+	defaults, err := PrunePolicyOf(nil)
+	require.NoError(t, err)
+	assert.True(t, defaults.Allows(deployment))
+	assert.False(t, defaults.Allows(secret))
+	assert.False(t, defaults.Allows(schema.GroupKind{Kind: "PersistentVolumeClaim"}))
+
+	allowlisted, err := PrunePolicyOf(map[string]string{PruneAllowlistAnnotation: "Deployment.apps, Secret"})
+	require.NoError(t, err)
+	assert.True(t, allowlisted.Allows(deployment))
+	assert.False(t, allowlisted.Allows(schema.GroupKind{Kind: "ConfigMap"}))
+	assert.False(t, allowlisted.Allows(secret), "the default denylist takes precedence")
+
+	unrestricted, err := PrunePolicyOf(map[string]string{PruneDenylistAnnotation: ""})
+	require.NoError(t, err)
+	assert.True(t, unrestricted.Allows(secret))
+
+	_, err = PrunePolicyOf(map[string]string{PruneDenylistAnnotation: ".apps"})
+	assert.Error(t, err)
+}
+
+func TestWithoutUnprunableResources(t *testing.T) {
+	t.Parallel()
+	r := &Reconciler{Options: &Options{EventRecorder: record.NewFakeRecorder(10)}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	info := func(kind, name string) *resource.Info {
+		return &resource.Info{Name: name, Namespace: "keda", Object: renderedObject(kind, name, nil)}
+	}
+	diff := []*resource.Info{info("ConfigMap", "keda-config"), info("Secret", "keda-tls")}
+
+	prunable, err := r.withoutUnprunableResources(context.Background(), obj, diff)
+	require.NoError(t, err)
+	assert.Equal(t, diff[:1], prunable)
+
+	obj.SetAnnotations(map[string]string{PruneAllowlistAnnotation: "Deployment.apps"})
+	prunable, err = r.withoutUnprunableResources(context.Background(), obj, diff)
+	require.NoError(t, err)
+	assert.Empty(t, prunable)
+}
+
+func TestUnprunedResourcesAreUninstalled(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := &Reconciler{Options: &Options{EventRecorder: record.NewFakeRecorder(10)}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("keda")
+	obj.SetNamespace("kcp-system")
+	info := func(kind, name string) *resource.Info {
+		return &resource.Info{Name: name, Namespace: "keda", Object: renderedObject(kind, name, nil)}
+	}
+	configMap, secret := info("ConfigMap", "keda-config"), info("Secret", "keda-tls")
+	obj.SetStatus(Status{Synced: NewInfoToResourceConverter().InfosToResources([]*resource.Info{configMap, secret})})
+
+	synced := syncedResources(obj, obj.GetStatus().Synced, []*resource.Info{configMap})
+	assert.Equal(t, obj.GetStatus().Synced, synced, "resources excluded from pruning stay synced")
+	assert.Empty(t, pendingUpdate(obj, &Spec{}, []*resource.Info{configMap}))
+
+	current := make([]*resource.Info, 0, len(synced))
+	for _, syncedResource := range synced {
+		current = append(current, &resource.Info{
+			Name: syncedResource.Name, Namespace: syncedResource.Namespace, Object: syncedResource.ToUnstructured(),
+		})
+	}
+	clnt := &fakeTargetClient{fake: fake.NewClientBuilder().WithObjects(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "keda-config", Namespace: "keda"}},
+		&v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "keda-tls", Namespace: "keda"}},
+	).Build()}
+	assert.ErrorIs(t, r.uninstall(ctx, clnt, obj, nil, current), ErrDeletionNotFinished)
+	require.NoError(t, r.uninstall(ctx, clnt, obj, nil, current))
+	err := clnt.Get(ctx, client.ObjectKey{Name: "keda-tls", Namespace: "keda"}, &v1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err), "the secret is deleted on uninstall")
+}
+
+func TestResourcesDroppedFromRenderArePruned(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	r := &Reconciler{Options: &Options{EventRecorder: record.NewFakeRecorder(10)}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	info := func(gvk schema.GroupVersionKind, name string) *resource.Info {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(gvk)
+		object.SetName(name)
+		object.SetNamespace("keda")
+		return &resource.Info{
+			Name: name, Namespace: "keda", Object: object, Mapping: &meta.RESTMapping{GroupVersionKind: gvk},
+		}
+	}
+	deployment := info(appsv1.SchemeGroupVersion.WithKind("Deployment"), "keda-metrics")
+	configMap := info(v1.SchemeGroupVersion.WithKind("ConfigMap"), "keda-config")
+	obj.SetStatus(Status{Synced: NewInfoToResourceConverter().InfosToResources([]*resource.Info{deployment, configMap})})
+	clnt := &fakeTargetClient{fake: fake.NewClientBuilder().WithObjects(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "keda-metrics", Namespace: "keda"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "keda-config", Namespace: "keda"}},
+	).Build()}
+
+	// a newer render no longer contains the deployment, the synced resources of the status are the inventory
+	target := []*resource.Info{configMap}
+	current := make([]*resource.Info, 0, len(obj.GetStatus().Synced))
+	for _, synced := range obj.GetStatus().Synced {
+		current = append(current, info(schema.GroupVersionKind(synced.GroupVersionKind), synced.Name))
+	}
+	diff, err := r.withoutUnprunableResources(ctx, obj, kube.ResourceList(current).Difference(target))
+	require.NoError(t, err)
+	require.Len(t, diff, 1)
+	assert.Equal(t, "keda-metrics", diff[0].Name)
+
+	assert.ErrorIs(t, r.deleteResources(ctx, clnt, obj, diff), ErrDeletionNotFinished)
+	require.NoError(t, r.deleteResources(ctx, clnt, obj, diff))
+	err = clnt.Get(ctx, client.ObjectKey{Name: "keda-metrics", Namespace: "keda"}, &appsv1.Deployment{})
+	assert.True(t, apierrors.IsNotFound(err), "the dropped deployment is pruned")
+	require.NoError(t, clnt.Get(ctx, client.ObjectKey{Name: "keda-config", Namespace: "keda"}, &v1.ConfigMap{}))
+	assert.Equal(t, NewInfoToResourceConverter().InfosToResources(target),
+		syncedResources(obj, obj.GetStatus().Synced, target), "the pruned deployment is no longer synced")
+}
@@ -0,0 +1,95 @@
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+var (
+	ErrInvalidReadinessExpression = errors.New("invalid readiness expression")
+	ErrReadinessEvaluation        = errors.New("readiness expression could not be evaluated")
+)
+
+// ReadinessExpressionCostLimit bounds the evaluation cost of a readiness expression for a single resource,
+// so that macros over large lists cannot block a readiness check.
+const ReadinessExpressionCostLimit = 1000000
+
+// readinessExpressionFields are the top level fields of resources that are declared as variables.
+var readinessExpressionFields = []string{"apiVersion", "kind", "metadata", "spec", "status", "data"}
+
+var (
+	readinessEnvOnce sync.Once
+	readinessEnv     *cel.Env
+	errReadinessEnv  error
+)
+
+// readinessExpressionEnv declares the readinessExpressionFields and self with the dynamic type,
+// as the schema of the resources is not known when the expressions are compiled.
+func readinessExpressionEnv() (*cel.Env, error) {
+	readinessEnvOnce.Do(func() {
+		options := []cel.EnvOption{cel.Variable("self", cel.DynType), cel.CrossTypeNumericComparisons(true)}
+		for _, field := range readinessExpressionFields {
+			options = append(options, cel.Variable(field, cel.DynType))
+		}
+		readinessEnv, errReadinessEnv = cel.NewEnv(options...)
+	})
+	return readinessEnv, errReadinessEnv
+}
+
+// ReadinessExpression decides if a resource is ready based on its content, e.g. `status.phase == "Running"`.
+// Expressions are written in the Common Expression Language (CEL). The fields apiVersion, kind, metadata, spec,
+// status and data of the resource are available as variables and the whole resource as self, e.g.
+// `has(status.conditions) && status.conditions.exists(c, c.type == "Ready" && c.status == "True")`.
+type ReadinessExpression struct {
+	source  string
+	program cel.Program
+}
+
+// CompileReadinessExpression parses and checks source so that it can be evaluated for many resources.
+func CompileReadinessExpression(source string) (*ReadinessExpression, error) {
+	env, err := readinessExpressionEnv()
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(source)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrInvalidReadinessExpression, source, issues.Err().Error())
+	}
+	if !ast.OutputType().IsAssignableType(cel.BoolType) {
+		return nil, fmt.Errorf("%w %q: evaluates to %s instead of bool",
+			ErrInvalidReadinessExpression, source, ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(ReadinessExpressionCostLimit))
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrInvalidReadinessExpression, source, err.Error())
+	}
+	return &ReadinessExpression{source: source, program: program}, nil
+}
+
+func (e *ReadinessExpression) String() string {
+	return e.source
+}
+
+// Ready evaluates the expression for the content of a resource, it fails with ErrReadinessEvaluation if the
+// expression does not evaluate to a bool, e.g. because a selected field does not exist.
+func (e *ReadinessExpression) Ready(obj map[string]any) (bool, error) {
+	vars := map[string]any{"self": obj}
+	for _, field := range readinessExpressionFields {
+		if value, found := obj[field]; found {
+			vars[field] = value
+		}
+	}
+	value, _, err := e.program.Eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q: %s", ErrReadinessEvaluation, e.source, err.Error())
+	}
+	ready, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: %q evaluated to %s instead of bool", ErrReadinessEvaluation, e.source,
+			value.Type().TypeName())
+	}
+	return ready, nil
+}
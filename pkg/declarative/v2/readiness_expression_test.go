@@ -0,0 +1,67 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessExpression(t *testing.T) {
+	t.Parallel()
+	obj := map[string]any{
+		"metadata": map[string]any{"name": "keda", "labels": map[string]any{"app": "keda"}},
+		"spec":     map[string]any{"replicas": int64(2)},
+		"status": map[string]any{
+			"phase":         "Running",
+			"readyReplicas": int64(2),
+			"conditions": []any{
+				map[string]any{"type": "Available", "status": "True"},
+				map[string]any{"type": "Ready", "status": "False"},
+			},
+		},
+	}
+	tests := []struct {
+		expression string
+		ready      bool
+	}{
+		{`status.phase == "Running"`, true},
+		{`status.phase in ['Running', 'Succeeded']`, true},
+		{`status.readyReplicas >= spec.replicas && self.metadata.name != "other"`, true},
+		{`status.conditions.exists(c, c.type == "Ready" && c.status == "True")`, false},
+		{`status.conditions.all(c, has(c.status))`, true},
+		{`size(status.conditions) == 2 && status.conditions[0].type == "Available"`, true},
+		{`metadata.labels["app"] == "keda" && !has(status.observedGeneration)`, true},
+		{`has(status.missing) && status.missing.ready`, false},
+		{`status.missing == true || status.phase == "Running"`, true},
+		{`spec.replicas > -1.5`, true},
+	}
+	for _, test := range tests {
+		expression, err := CompileReadinessExpression(test.expression)
+		require.NoError(t, err, test.expression)
+		ready, err := expression.Ready(obj)
+		require.NoError(t, err, test.expression)
+		assert.Equal(t, test.ready, ready, test.expression)
+	}
+}
+
+func TestReadinessExpressionErrors(t *testing.T) {
+	t.Parallel()
+	for _, source := range []string{
+		`status.phase ==`, `status.phase = "Running"`, `has(status)`, `matches(x)`, `"open`, `size(status) + 1`,
+	} {
+		_, err := CompileReadinessExpression(source)
+		assert.ErrorIs(t, err, ErrInvalidReadinessExpression, source)
+	}
+
+	obj := map[string]any{"status": map[string]any{"phase": "Running"}}
+	for _, source := range []string{`status.state == "Ready"`, `status.phase`, `status.phase > 1`} {
+		expression, err := CompileReadinessExpression(source)
+		require.NoError(t, err, source)
+		_, err = expression.Ready(obj)
+		assert.ErrorIs(t, err, ErrReadinessEvaluation, source)
+	}
+}
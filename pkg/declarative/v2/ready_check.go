@@ -9,6 +9,7 @@ import (
 	"github.com/kyma-project/module-manager/internal"
 	"github.com/kyma-project/module-manager/pkg/types"
 	"helm.sh/helm/v3/pkg/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/resource"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,7 +42,7 @@ func (c *HelmReadyCheck) Run(ctx context.Context, _ Client, _ Object, resources
 	checker := kube.NewReadyChecker(
 		c.clientSet, func(format string, args ...interface{}) {
 			logger.V(internal.DebugLogLevel).Info(fmt.Sprintf(format, args...))
-		}, kube.PausedAsReady(false), kube.CheckJobs(true),
+		}, kube.PausedAsReady(false), kube.CheckJobs(readyCheckWaitStrategy(ctx).waitsForJobs()),
 	)
 
 	readyCheckResults := make(chan error, len(resources))
@@ -91,7 +92,10 @@ func (c *ExistsReadyCheck) Run(ctx context.Context, clnt Client, _ Object, resou
 		if !ok {
 			return errors.New("object in resource info is not a valid client object")
 		}
-		if err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), obj); client.IgnoreNotFound(err) != nil {
+		// only the metadata is read, so that the check can be served by a MetadataInformerCache
+		metadata := &metav1.PartialObjectMetadata{}
+		metadata.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), metadata); client.IgnoreNotFound(err) != nil {
 			return err
 		}
 	}
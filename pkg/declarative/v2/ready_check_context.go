@@ -0,0 +1,59 @@
+package v2
+
+import (
+	"context"
+
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// ReadyCheckContext describes the install whose resources are checked, so that a ReadyCheck can correlate its
+// checks with what was actually applied, e.g. only wait for workloads enabled by the values.
+// It is available to ReadyCheck.Run through ReadyCheckContextFrom.
+type ReadyCheckContext struct {
+	ManifestName string
+	// Revision is the revision of the checked resources, which is the installed revision and not the revision
+	// of the Spec while an update is deferred to a maintenance window.
+	Revision    string
+	ReleaseName string
+	Mode        RenderMode
+	// Values are the values the resources were rendered with, including GeneratedValues.
+	Values any
+	// Resources are all rendered resources of the install, independent of the resources passed to the check.
+	Resources []*resource.Info
+	// Wait determines how the readiness of the resources is awaited, e.g. if Jobs have to complete.
+	Wait *WaitStrategy
+}
+
+type readyCheckContextKey struct{}
+
+// WithReadyCheckContext returns a copy of ctx carrying the ReadyCheckContext.
+func WithReadyCheckContext(ctx context.Context, readyCheckContext *ReadyCheckContext) context.Context {
+	return context.WithValue(ctx, readyCheckContextKey{}, readyCheckContext)
+}
+
+// ReadyCheckContextFrom returns the ReadyCheckContext of the install checked by a ReadyCheck,
+// it is not found if the check runs outside the Reconciler.
+func ReadyCheckContextFrom(ctx context.Context) (*ReadyCheckContext, bool) {
+	readyCheckContext, found := ctx.Value(readyCheckContextKey{}).(*ReadyCheckContext)
+	return readyCheckContext, found
+}
+
+func newReadyCheckContext(spec *Spec, revision string, resources []*resource.Info) *ReadyCheckContext {
+	return &ReadyCheckContext{
+		ManifestName: spec.ManifestName,
+		Revision:     revision,
+		ReleaseName:  spec.ReleaseName,
+		Mode:         spec.Mode,
+		Values:       spec.Values,
+		Resources:    resources,
+		Wait:         spec.Wait,
+	}
+}
+
+// readyCheckWaitStrategy returns the WaitStrategy of the install checked by a ReadyCheck, if any.
+func readyCheckWaitStrategy(ctx context.Context) *WaitStrategy {
+	if readyCheckContext, found := ReadyCheckContextFrom(ctx); found {
+		return readyCheckContext.Wait
+	}
+	return nil
+}
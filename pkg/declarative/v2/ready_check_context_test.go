@@ -0,0 +1,49 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/tools/record"
+)
+
+type contextRecordingReadyCheck struct {
+	readyCheckContext *ReadyCheckContext
+}
+
+func (c *contextRecordingReadyCheck) Run(ctx context.Context, _ Client, _ Object, _ []*resource.Info) error {
+	c.readyCheckContext, _ = ReadyCheckContextFrom(ctx)
+	return nil
+}
+
+func TestReadyCheckContext(t *testing.T) {
+	t.Parallel()
+	_, found := ReadyCheckContextFrom(context.Background())
+	assert.False(t, found)
+
+	readyCheck := &contextRecordingReadyCheck{}
+	r := &Reconciler{Options: &Options{CustomReadyCheck: readyCheck, EventRecorder: record.NewFakeRecorder(10)}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("keda")
+	spec := &Spec{
+		ManifestName: "keda", Revision: "2.9.0", ReleaseName: "keda", Mode: RenderModeHelm,
+		Values: map[string]any{"webhooks": map[string]any{"enabled": false}},
+	}
+	rendered := []*resource.Info{{Name: "keda-operator", Namespace: "keda"}}
+
+	ctx := WithReadyCheckContext(context.Background(), newReadyCheckContext(spec, "2.8.1", rendered))
+	err := r.checkTargetReadiness(ctx, nil, obj, rendered)
+	require.ErrorIs(t, err, ErrInstallationConditionRequiresUpdate)
+	require.NotNil(t, readyCheck.readyCheckContext)
+	assert.Equal(t, &ReadyCheckContext{
+		ManifestName: "keda", Revision: "2.8.1", ReleaseName: "keda", Mode: RenderModeHelm,
+		Values: spec.Values, Resources: rendered,
+	}, readyCheck.readyCheckContext)
+}
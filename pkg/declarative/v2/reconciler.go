@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/kyma-project/module-manager/internal"
 	manifestClient "github.com/kyma-project/module-manager/pkg/client"
 	"github.com/kyma-project/module-manager/pkg/types"
 	"helm.sh/helm/v3/pkg/kube"
-	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -36,6 +40,11 @@ func NewFromManager(mgr manager.Manager, prototype Object, options ...Option) *R
 type Reconciler struct {
 	prototype Object
 	*Options
+
+	// clientVersions holds the types.ClusterInfo Version each cached client was created from,
+	// or invalidatedClient if the client has to be replaced.
+	clientVersions sync.Map
+	versionResyncs versionResyncs
 }
 
 type ConditionType string
@@ -72,16 +81,29 @@ func newResourcesCondition(obj Object) metav1.Condition {
 	}
 }
 
-func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+// Reconcile recovers from panics in renderers, transforms, hooks and checks and returns them as errors,
+// so that the object is retried with the rate limiter of the controller instead of losing the worker.
+func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	defer recoverPanic("reconcile", &err)
+	return r.reconcile(ctx, req)
+}
+
+func (r *Reconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	obj := r.prototype.DeepCopyObject().(Object)
 	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
 		log.FromContext(ctx).Info(req.NamespacedName.String() + " got deleted!")
+		if apierrors.IsNotFound(err) {
+			r.versionResyncs.release(req.NamespacedName)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
 	if r.ShouldSkip(ctx, obj) {
 		return ctrl.Result{}, nil
 	}
+	ctx = withObservedStatus(ctx, obj)
+
+	defer recordReconcile(obj, time.Now())
 
 	if err := r.initialize(obj); err != nil {
 		return r.ssaStatus(ctx, obj)
@@ -94,52 +116,144 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	}
 
-	spec, err := r.Spec(ctx, obj)
-	if err != nil {
+	if !obj.GetDeletionTimestamp().IsZero() && IsDeletionBlocked(obj) {
+		return r.blockDeletion(ctx, obj)
+	}
+
+	if r.isRetryBudgetExhausted(obj) {
+		return ctrl.Result{}, nil
+	}
+	r.retryAfterExhaustedBudget(obj)
+
+	opCtx, cancel := r.operationContext(ctx, obj)
+	defer cancel()
+
+	spec, err := r.Spec(opCtx, obj)
+	if err != nil && !obj.GetDeletionTimestamp().IsZero() {
+		spec, err = r.uninstallSpec(obj, err), nil
+	}
+	if isRetryableSpecError(err) {
+		return r.retryDownload(ctx, req, obj)
+	} else if err != nil {
 		return r.ssaStatus(ctx, obj)
 	}
+	r.DownloadRetryRateLimiter.Forget(req)
 
-	clnt, err := r.getTargetClient(ctx, obj, spec)
+	if result, deferred := r.deferVersionResync(ctx, obj, spec); deferred {
+		return result, nil
+	}
+
+	spec.Hash = specHash(spec)
+	if r.isUnchanged(obj, spec) || isRolledBack(obj, spec) {
+		return r.successResult()
+	}
+
+	clnt, err := r.getTargetClient(opCtx, obj, spec)
 	if err != nil {
+		err = types.NewClassifiedError(types.ErrClusterUnreachable, err)
 		r.Event(obj, "Warning", "ClientInitialization", err.Error())
 		obj.SetStatus(obj.GetStatus().WithState(StateError).WithErr(err))
-		return r.ssaStatus(ctx, obj)
+		return r.ssaInstallStatus(ctx, obj, spec)
 	}
 
-	converter := NewResourceToInfoConverter(clnt, r.Namespace)
+	if err := r.checkClusterReadiness(opCtx, clnt, obj); errors.Is(err, ErrClusterNotReady) {
+		return r.awaitDependency(ctx, obj, spec)
+	} else if err != nil {
+		return r.ssaInstallStatus(ctx, obj, spec)
+	}
 
-	renderer, err := r.initializeRenderer(ctx, obj, spec, clnt)
-	if err != nil {
-		return r.ssaStatus(ctx, obj)
+	if err := r.claimRelease(ctx, obj, spec); err != nil {
+		r.Event(obj, "Warning", "ReleaseName", err.Error())
+		obj.SetStatus(obj.GetStatus().WithState(StateError).WithErr(err))
+		return r.ssaInstallStatus(ctx, obj, spec)
+	}
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		if err := r.resolveGeneratedValues(opCtx, clnt, obj, spec); err != nil {
+			r.Event(obj, "Warning", "GeneratedValues", err.Error())
+			obj.SetStatus(obj.GetStatus().WithState(StateError).WithErr(err))
+			return r.ssaInstallStatus(ctx, obj, spec)
+		}
 	}
 
-	target, current, err := r.renderResources(ctx, obj, spec, renderer, converter)
+	converter := NewResourceToInfoConverter(clnt, r.Namespace)
+
+	renderer, err := r.initializeRenderer(opCtx, obj, spec, clnt)
 	if err != nil {
-		return r.ssaStatus(ctx, obj)
+		return r.ssaInstallStatus(ctx, obj, spec)
 	}
 
-	diff := kube.ResourceList(current).Difference(target)
-	if err := r.pruneDiff(ctx, clnt, obj, renderer, diff); errors.Is(err, ErrDeletionNotFinished) {
-		return ctrl.Result{Requeue: true}, nil
-	} else if err != nil {
-		return r.ssaStatus(ctx, obj)
+	target, current, err := r.renderResources(opCtx, obj, spec, renderer, converter)
+	if err != nil {
+		return r.ssaInstallStatus(ctx, obj, spec)
 	}
 
 	if !obj.GetDeletionTimestamp().IsZero() {
-		if controllerutil.RemoveFinalizer(obj, r.Finalizer) {
-			return ctrl.Result{}, r.Update(ctx, obj) // no SSA since delete does not work for finalizers.
+		statusBeforeUninstall := obj.GetStatus()
+		if err := r.uninstall(opCtx, clnt, obj, renderer, current); errors.Is(err, ErrDeletionNotFinished) {
+			return ctrl.Result{Requeue: true}, nil
+		} else if err != nil {
+			r.auditDeletion(ctx, obj, spec, statusBeforeUninstall, err)
+			return r.ssaInstallStatus(ctx, obj, spec)
+		}
+		if controllerutil.ContainsFinalizer(obj, r.Finalizer) {
+			if _, err := RemoveFinalizer(ctx, r.Client, obj, r.Finalizer); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.auditDeletion(ctx, obj, spec, statusBeforeUninstall, nil)
+			return ctrl.Result{}, nil
 		}
 		msg := fmt.Sprintf("waiting as other finalizers are present: %s", obj.GetFinalizers())
 		r.Event(obj, "Normal", "FinalizerRemoval", msg)
 		obj.SetStatus(obj.GetStatus().WithState(StateDeleting).WithOperation(msg))
-		return r.ssaStatus(ctx, obj)
+		return r.ssaInstallStatus(ctx, obj, spec)
+	}
+
+	if result, deferred, err := r.deferToMaintenanceWindow(opCtx, clnt, obj, spec, current, target); deferred {
+		return result, err
+	}
+
+	diff := kube.ResourceList(current).Difference(target)
+	if !obj.GetStatus().Journal.InFlight() {
+		r.warnReplacedResources(obj, diff, target)
+	}
+	if diff, err = r.withoutUnprunableResources(opCtx, obj, diff); err != nil {
+		return r.ssaInstallStatus(ctx, obj, spec)
+	}
+	if err := r.deleteResources(opCtx, clnt, obj, diff); errors.Is(err, ErrDeletionNotFinished) {
+		return ctrl.Result{Requeue: true}, nil
+	} else if err != nil {
+		return r.ssaInstallStatus(ctx, obj, spec)
 	}
 
-	if err := r.syncResources(ctx, clnt, obj, target); err != nil {
+	journaled, started := obj.GetStatus().WithJournalStart(
+		spec.Revision, NewInfoToResourceConverter().InfosToResources(target),
+	)
+	if started {
+		obj.SetStatus(journaled)
+		withPendingUpdateCondition(obj, "")
 		return r.ssaStatus(ctx, obj)
 	}
 
-	return r.CtrlOnSuccess, nil
+	err = r.syncResources(opCtx, clnt, obj, spec, target)
+	r.auditSync(ctx, obj, spec, journaled, err)
+	if errors.Is(err, ErrResourcesNotReady) {
+		return r.awaitReadiness(ctx, obj, spec)
+	} else if errors.Is(err, ErrWaitingForDependency) {
+		return r.awaitDependency(ctx, obj, spec)
+	} else if err != nil {
+		return r.ssaInstallStatus(ctx, obj, spec)
+	}
+
+	status := obj.GetStatus()
+	if journaled.Journal.InFlight() || status.ReconcilerVersion != journaled.ReconcilerVersion ||
+		status.ObservedGeneration != journaled.ObservedGeneration || status.SpecHash != journaled.SpecHash ||
+		!installsEqual(status.Installs, status.WithInstall(spec.ManifestName, spec.Revision).Installs) ||
+		conditionsChanged(ctx, status) {
+		return r.ssaInstallStatus(ctx, obj, spec)
+	}
+
+	return r.successResult()
 }
 
 func (r *Reconciler) partialObjectMetadata(obj Object) *metav1.PartialObjectMetadata {
@@ -187,8 +301,12 @@ func (r *Reconciler) Spec(ctx context.Context, obj Object) (*Spec, error) {
 	if err != nil {
 		r.Event(obj, "Warning", "Spec", err.Error())
 		obj.SetStatus(obj.GetStatus().WithState(StateError).WithErr(err))
+		return spec, err
 	}
-	return spec, err
+	status := obj.GetStatus()
+	status.Provenance = spec.Provenance
+	obj.SetStatus(status)
+	return spec, nil
 }
 
 func (r *Reconciler) renderResources(
@@ -204,7 +322,12 @@ func (r *Reconciler) renderResources(
 		return nil, nil, err
 	}
 
-	current, err = converter.ResourcesToInfos(status.Synced)
+	if status.Journal.InFlight() {
+		log.FromContext(ctx).V(internal.DebugLogLevel).Info("resuming operation in flight",
+			"revision", status.Journal.Revision, "started", status.Journal.StartedAt)
+	}
+
+	current, err = converter.ResourcesToInfos(journaledResources(status))
 	if err != nil {
 		r.Event(obj, "Warning", "CurrentResourceParsing", err.Error())
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
@@ -222,51 +345,147 @@ func (r *Reconciler) renderResources(
 }
 
 func (r *Reconciler) syncResources(
-	ctx context.Context, clnt Client, obj Object, target []*resource.Info,
+	ctx context.Context, clnt Client, obj Object, spec *Spec, target []*resource.Info,
 ) error {
 	status := obj.GetStatus()
 
-	if err := ConcurrentSSA(clnt, r.FieldOwner).Run(ctx, target); err != nil {
+	if err := markCreatedNamespaces(ctx, clnt, target); err != nil {
+		r.Event(obj, "Warning", "NamespaceOwnership", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return err
+	}
+
+	verifier := r.verificationClient(ctx, obj, clnt)
+	if r.MetadataDriftCheck && status.State == StateReady {
+		r.checkMetadataDrift(ctx, verifier, obj, target)
+	}
+
+	if err := keepGeneratedValuesFromCluster(ctx, clnt, target); err != nil {
+		r.Event(obj, "Warning", "GeneratedValues", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return err
+	}
+
+	if r.remediateDrift(ctx, r.verificationClient(ctx, obj, clnt), obj, spec, target) {
+		if err := r.applyResources(ctx, clnt, obj, spec, target); err != nil {
+			return err
+		}
+	}
+	status = obj.GetStatus()
+
+	for i := range r.PostRuns {
+		if err := r.PostRuns[i](ctx, clnt, r.Client, obj); isMissingDependency(err) {
+			return r.waitForDependency(obj, status, err)
+		} else if err != nil {
+			r.Event(obj, "Warning", "PostRun", err.Error())
+			obj.SetStatus(status.WithState(StateError).WithErr(err))
+			return err
+		}
+	}
+
+	readyCheckCtx := WithReadyCheckContext(ctx, newReadyCheckContext(spec, spec.Revision, target))
+	return r.checkTargetReadiness(readyCheckCtx, verifier, obj, target)
+}
+
+// applyResources applies target with server-side apply and records it as synced resources of obj.
+func (r *Reconciler) applyResources(
+	ctx context.Context, clnt Client, obj Object, spec *Spec, target []*resource.Info,
+) error {
+	status := obj.GetStatus()
+	applier := NewConcurrentSSA(clnt, r.FieldOwner, SSAOptions{
+		ForceConflicts: r.ForceConflicts, PreviousFieldOwners: r.PreviousFieldOwners,
+		StrictFieldValidation: r.StrictFieldValidation,
+	})
+	if err := applier.Run(ctx, target); isMissingDependency(err) {
+		return r.waitForDependency(obj, status, err)
+	} else if err != nil {
 		r.Event(obj, "Warning", "ServerSideApply", err.Error())
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		r.rollback(ctx, clnt, applier, NewConcurrentCleanup(clnt), obj, spec, target, err)
 		return err
 	}
 
 	oldSynced := status.Synced
-	newSynced := NewInfoToResourceConverter().InfosToResources(target)
+	newSynced := syncedResources(obj, oldSynced, target)
 	status.Synced = newSynced
+	meta.RemoveStatusCondition(&status.Conditions, string(ConditionTypeRollback))
+	status.RolledBack = nil
+	status = status.WithJournalFinish()
+	if r.ReconcilerVersion != "" {
+		status.ReconcilerVersion = r.ReconcilerVersion
+	}
+	status = withObservedSpec(status, obj, spec)
+	obj.SetStatus(status)
+
+	if r.LastAppliedConfiguration || r.rollbackOnFailure(obj) {
+		r.recordLastAppliedConfiguration(ctx, obj, target)
+	}
 
 	if len(ResourcesDiff(oldSynced, newSynced)) > 0 {
 		obj.SetStatus(status.WithState(StateProcessing).WithOperation(ErrResourceSyncStateDiff.Error()))
 		return ErrResourceSyncStateDiff
 	}
+	return nil
+}
 
-	for i := range r.PostRuns {
-		if err := r.PostRuns[i](ctx, clnt, r.Client, obj); err != nil {
-			r.Event(obj, "Warning", "PostRun", err.Error())
-			obj.SetStatus(status.WithState(StateError).WithErr(err))
-			return err
-		}
+// verificationClient serves the metadata reads of the consistency checks from the MetadataInformerCache if configured.
+func (r *Reconciler) verificationClient(ctx context.Context, obj Object, clnt Client) Client {
+	if r.MetadataInformers == nil {
+		return clnt
 	}
+	return r.MetadataInformers.Client(r.ClientCacheKeyFn(ctx, obj), clnt)
+}
 
-	return r.checkTargetReadiness(ctx, clnt, obj, target)
+// checkMetadataDrift reports labels and annotations that were removed or changed in the cluster.
+// The drift itself is corrected by the following apply, so failures of the check do not fail the reconciliation.
+func (r *Reconciler) checkMetadataDrift(ctx context.Context, clnt Client, obj Object, target []*resource.Info) {
+	drifted, err := detectMetadataDrift(ctx, clnt, target)
+	if err != nil {
+		log.FromContext(ctx).V(internal.DebugLogLevel).Info("metadata drift check failed", "error", err.Error())
+		return
+	}
+	if len(drifted) == 0 {
+		return
+	}
+	recordMetadataDrift(obj)
+	r.Event(obj, "Warning", "MetadataDrift",
+		fmt.Sprintf("restoring drifted metadata: %s", strings.Join(drifted, ", ")))
 }
 
 func (r *Reconciler) checkTargetReadiness(
 	ctx context.Context, clnt Client, obj Object, target []*resource.Info,
 ) error {
-	status := obj.GetStatus()
-
 	resourceReadyCheck := r.CustomReadyCheck
 	if resourceReadyCheck == nil {
 		resourceReadyCheck = NewHelmReadyCheck(clnt)
 	}
+	if r.WaitForWebhooks {
+		resourceReadyCheck = NewWebhookReadyCheck(resourceReadyCheck)
+	}
 
-	if err := resourceReadyCheck.Run(ctx, clnt, obj, target); errors.Is(err, ErrResourcesNotReady) {
+	// ready checks may report the readiness of single resources in the status, e.g. as conditions
+	var err error
+	wait := readyCheckWaitStrategy(ctx)
+	if wait.awaitsReadiness() {
+		err = resourceReadyCheck.Run(ctx, clnt, obj, target)
+	}
+	if errors.Is(err, ErrResourcesNotReady) {
+		if waitErr := wait.checkProgressDeadlines(ctx, clnt, target); waitErr != nil {
+			err = waitErr
+		} else if waitErr := wait.withResourcesReadyCondition(obj, false, time.Now()); waitErr != nil {
+			err = waitErr
+		}
+	} else if err == nil {
+		_ = wait.withResourcesReadyCondition(obj, true, time.Now())
+	}
+	status := obj.GetStatus()
+	if errors.Is(err, ErrResourcesNotReady) {
 		waitingMsg := fmt.Sprintf("waiting for resources to become ready: %s", err.Error())
 		r.Event(obj, "Normal", "ResourceReadyCheck", waitingMsg)
 		obj.SetStatus(status.WithState(StateProcessing).WithOperation(waitingMsg))
 		return err
+	} else if isMissingDependency(err) {
+		return r.waitForDependency(obj, status, err)
 	} else if err != nil {
 		r.Event(obj, "Warning", "ReadyCheck", err.Error())
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
@@ -278,6 +497,7 @@ func (r *Reconciler) checkTargetReadiness(
 		r.Event(obj, "Normal", installationCondition.Reason, installationCondition.Message)
 		installationCondition.Status = metav1.ConditionTrue
 		meta.SetStatusCondition(&status.Conditions, installationCondition)
+		status.InstalledOnce = true
 		obj.SetStatus(status.WithState(StateReady).WithOperation(installationCondition.Message))
 		return ErrInstallationConditionRequiresUpdate
 	}
@@ -290,14 +510,18 @@ func (r *Reconciler) deleteResources(
 ) error {
 	status := obj.GetStatus()
 
-	if !obj.GetDeletionTimestamp().IsZero() {
-		for _, preDelete := range r.PreDeletes {
-			if err := preDelete(ctx, clnt, r.Client, obj); err != nil {
-				r.Event(obj, "Warning", "PreDelete", err.Error())
-				// we do not set a status here since it will be deleting if timestamp is set.
-				return err
-			}
-		}
+	diff, err := withoutAdoptedNamespaces(ctx, clnt, diff)
+	if err != nil {
+		r.Event(obj, "Warning", "NamespaceOwnership", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return err
+	}
+
+	diff, err = withoutKeptResources(ctx, clnt, diff)
+	if err != nil {
+		r.Event(obj, "Warning", "ResourcePolicy", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return err
 	}
 
 	if err := NewConcurrentCleanup(clnt).Run(ctx, diff); errors.Is(err, ErrDeletionNotFinished) {
@@ -312,6 +536,56 @@ func (r *Reconciler) deleteResources(
 	return nil
 }
 
+// blockDeletion keeps all resources of an object with the DeletionProtectionAnnotation until the deletion
+// is confirmed. The object is not requeued, as the confirmation is an update that triggers the next reconciliation.
+func (r *Reconciler) blockDeletion(ctx context.Context, obj Object) (ctrl.Result, error) {
+	msg := fmt.Sprintf("deletion is blocked by %s, set %s to \"true\" to uninstall",
+		DeletionProtectionAnnotation, ConfirmDeletionAnnotation)
+	r.Event(obj, "Warning", "DeletionProtection", msg)
+	obj.SetStatus(obj.GetStatus().WithState(StateDeleting).WithOperation(msg))
+	_, err := r.ssaStatus(ctx, obj)
+	return ctrl.Result{}, err
+}
+
+// uninstallSpec replaces a spec that could not be resolved during deletion, e.g. because the registry of a
+// decommissioned module is gone. Uninstalling does not render the resources, as the synced resources of the
+// status are deleted, so the spec is derived from the status. Prerequisites are not removed in this case,
+// as they are only known to the renderer of the original spec.
+func (r *Reconciler) uninstallSpec(obj Object, specErr error) *Spec {
+	r.Event(obj, "Warning", "UninstallWithoutSource",
+		fmt.Sprintf("uninstalling synced resources without rendering, as the spec could not be resolved: %s", specErr))
+	spec := &Spec{ManifestName: obj.GetName(), Mode: RenderModeRaw}
+	if installs := obj.GetStatus().Installs; len(installs) > 0 {
+		spec.ManifestName, spec.Revision = installs[0].Name, installs[0].Revision
+	}
+	return spec
+}
+
+// verifyDeletion confirms that no resources owned by obj are left in the target cluster before the finalizer
+// is removed, as resources may have been orphaned by earlier reconciliations or recreated after the cleanup.
+// The verification is skipped for objects with the ForceDeletionAnnotation.
+func (r *Reconciler) verifyDeletion(ctx context.Context, clnt Client, obj Object) error {
+	status := obj.GetStatus()
+	if isForcedDeletion(obj) {
+		r.Event(obj, "Warning", "ForcedDeletion", "removing finalizer without verifying the deletion of owned resources")
+		return nil
+	}
+
+	remaining, err := remainingOwnedResources(ctx, clnt, obj)
+	if err != nil {
+		r.Event(obj, "Warning", "DeletionVerification", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return err
+	}
+	if len(remaining) > 0 {
+		msg := fmt.Sprintf("%s: owned resources remain: %s", ErrDeletionNotFinished, strings.Join(remaining, ", "))
+		r.Event(obj, "Normal", "DeletionVerification", msg)
+		obj.SetStatus(status.WithState(StateDeleting).WithOperation(msg))
+		return ErrDeletionNotFinished
+	}
+	return nil
+}
+
 func (r *Reconciler) renderTargetResources(
 	ctx context.Context, renderer Renderer, converter ResourceToInfoConverter, obj Object, spec *Spec,
 ) ([]*resource.Info, error) {
@@ -327,6 +601,14 @@ func (r *Reconciler) renderTargetResources(
 
 	targetResources, err := r.ManifestParser.Parse(ctx, renderer, obj, spec)
 	if err != nil {
+		err = types.NewClassifiedError(types.ErrRenderFailed, err)
+		r.Event(obj, "Warning", "ManifestParsing", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return nil, err
+	}
+
+	if err := checkObjectLimit(len(targetResources.Items), r.MaxRenderedObjects); err != nil {
+		err = types.NewClassifiedError(types.ErrRenderFailed, err)
 		r.Event(obj, "Warning", "ManifestParsing", err.Error())
 		obj.SetStatus(status.WithState(StateError).WithErr(err))
 		return nil, err
@@ -334,12 +616,22 @@ func (r *Reconciler) renderTargetResources(
 
 	for _, transform := range r.PostRenderTransforms {
 		if err := transform(ctx, obj, targetResources.Items); err != nil {
+			err = types.NewClassifiedError(types.ErrRenderFailed, err)
 			r.Event(obj, "Warning", "PostRenderTransform", err.Error())
 			obj.SetStatus(status.WithState(StateError).WithErr(err))
 			return nil, err
 		}
 	}
 
+	if err := spec.Wait.withProgressDeadline(targetResources.Items); err != nil {
+		err = types.NewClassifiedError(types.ErrRenderFailed, err)
+		r.Event(obj, "Warning", "PostRenderTransform", err.Error())
+		obj.SetStatus(status.WithState(StateError).WithErr(err))
+		return nil, err
+	}
+
+	internal.SortObjects(targetResources.Items)
+
 	target, err := converter.UnstructuredToInfos(targetResources.Items)
 	if err != nil {
 		r.Event(obj, "Warning", "TargetResourceParsing", err.Error())
@@ -350,19 +642,26 @@ func (r *Reconciler) renderTargetResources(
 	return target, nil
 }
 
-func (r *Reconciler) initializeRenderer(ctx context.Context, obj Object, spec *Spec, client Client) (Renderer, error) {
+func (r *Reconciler) newRenderer(spec *Spec, client Client) Renderer {
 	var renderer Renderer
 
 	switch spec.Mode {
 	case RenderModeHelm:
 		renderer = NewHelmRenderer(spec, client, r.Options)
+		renderer = wrapWithSharedRenderCache(renderer, spec, client, r.clusterIdentity(ctx, obj), r.SharedRenderCache)
 		renderer = WrapWithRendererCache(renderer, spec, r.Options)
 	case RenderModeKustomize:
 		renderer = NewKustomizeRenderer(spec, r.Options)
+		renderer = wrapWithSharedRenderCache(renderer, spec, client, r.clusterIdentity(ctx, obj), r.SharedRenderCache)
 		renderer = WrapWithRendererCache(renderer, spec, r.Options)
 	case RenderModeRaw:
 		renderer = NewRawRenderer(spec, r.Options)
 	}
+	return wrapWithSizeLimit(renderer, r.MaxRenderedBytes)
+}
+
+func (r *Reconciler) initializeRenderer(ctx context.Context, obj Object, spec *Spec, client Client) (Renderer, error) {
+	renderer := r.newRenderer(spec, client)
 
 	if err := renderer.Initialize(obj); err != nil {
 		return nil, err
@@ -374,28 +673,82 @@ func (r *Reconciler) initializeRenderer(ctx context.Context, obj Object, spec *S
 	return renderer, nil
 }
 
-func (r *Reconciler) pruneDiff(
-	ctx context.Context, clnt Client, obj Object, renderer Renderer, diff []*resource.Info,
+// uninstall is the explicit deletion path of an object that is being deleted, independent of what the
+// renderer would install. It runs the PreDeletes, deletes all currently synced resources except adopted
+// namespaces and resources with a keep policy, and returns ErrDeletionNotFinished until they are gone.
+// Only then the prerequisites are removed (if DeletePrerequisites is set) and it is verified that no
+// owned resources remain, so that the finalizer can be removed.
+func (r *Reconciler) uninstall(
+	ctx context.Context, clnt Client, obj Object, renderer Renderer, current []*resource.Info,
 ) error {
-	if err := r.deleteResources(ctx, clnt, obj, diff); err != nil {
-		return err
+	for _, preDelete := range r.PreDeletes {
+		if err := preDelete(ctx, clnt, r.Client, obj); err != nil {
+			r.Event(obj, "Warning", "PreDelete", err.Error())
+			// we do not set a status here since it will be deleting if timestamp is set.
+			return err
+		}
 	}
 
-	if obj.GetDeletionTimestamp().IsZero() || !r.DeletePrerequisites {
-		return nil
+	// every phase is deleted completely before the next one starts
+	for _, phase := range uninstallPhases(current) {
+		if len(phase) == 0 {
+			continue
+		}
+		if err := r.deleteResources(ctx, clnt, obj, phase); err != nil {
+			return err
+		}
 	}
 
-	return renderer.RemovePrerequisites(ctx, obj)
+	if r.DeletePrerequisites {
+		if err := renderer.RemovePrerequisites(ctx, obj); err != nil {
+			return err
+		}
+	}
+
+	return r.verifyDeletion(ctx, clnt, obj)
 }
 
 func (r *Reconciler) getTargetClient(
 	ctx context.Context, obj Object, spec *Spec,
 ) (Client, error) {
+	clnt, err := r.targetClient(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Namespace != metav1.NamespaceNone && !isProtectedNamespace(r.Namespace) &&
+		clnt.Install().CreateNamespace {
+		if err := ensureNamespace(ctx, clnt, r.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	return clnt, nil
+}
+
+// targetClient returns the cached client of the target cluster of obj without changing the cluster.
+func (r *Reconciler) targetClient(ctx context.Context, obj Object) (Client, error) {
 	var err error
 
 	clientsCacheKey := r.ClientCacheKeyFn(ctx, obj)
 
 	clnt := r.GetClientFromCache(clientsCacheKey)
+	if version, _ := r.clientVersions.Load(clientsCacheKey); version == (invalidatedClient{}) {
+		clnt = nil
+	}
+
+	if clnt != nil && r.ClusterVersion != nil {
+		version, err := r.ClusterVersion(ctx, obj)
+		if err != nil {
+			return nil, err
+		}
+		if cachedVersion, _ := r.clientVersions.Load(clientsCacheKey); cachedVersion != version {
+			log.FromContext(ctx).Info("cluster version changed, recreating client",
+				"cacheKey", clientsCacheKey, "version", version)
+			r.InvalidateClient(clientsCacheKey)
+			clnt = nil
+		}
+	}
 
 	if clnt == nil {
 		cluster := &types.ClusterInfo{
@@ -424,35 +777,84 @@ func (r *Reconciler) getTargetClient(
 		if clnt.Install().Version == "" && clnt.Install().Devel {
 			clnt.Install().Version = ">0.0.0-0"
 		}
-		clnt.Install().ReleaseName = spec.ManifestName
 		r.SetClientInCache(clientsCacheKey, clnt)
+		r.clientVersions.Store(clientsCacheKey, cluster.Version)
 	}
 
 	clnt.Install().Namespace = r.Namespace
 	clnt.KubeClient().Namespace = r.Namespace
 
-	if r.Namespace != metav1.NamespaceNone && r.Namespace != metav1.NamespaceDefault &&
-		clnt.Install().CreateNamespace {
-		err := clnt.Patch(
-			ctx, &v1.Namespace{
-				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
-				ObjectMeta: metav1.ObjectMeta{Name: r.Namespace},
-			}, client.Apply, client.ForceOwnership, r.FieldOwner,
-		)
+	return clnt, nil
+}
+
+// clusterIdentity identifies the target cluster of obj by its ClientCacheKeyFn key.
+func (r *Reconciler) clusterIdentity(ctx context.Context, obj Object) string {
+	return fmt.Sprintf("%v", r.ClientCacheKeyFn(ctx, obj))
+}
+
+// claimRelease derives the release name of obj from the ReleaseNameTemplate and records it in spec and the Status.
+// Objects that are not deleting fail with ErrReleaseNameCollision if another object targeting the same cluster
+// claimed the release before in its Status, as the release namespace is the same for all objects of the Reconciler.
+func (r *Reconciler) claimRelease(ctx context.Context, obj Object, spec *Spec) error {
+	releaseName, err := executeReleaseNameTemplate(r.ReleaseNameTemplate, obj, spec)
+	if err != nil {
+		return err
+	}
+	spec.ReleaseName = releaseName
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		objects, err := r.listObjects(ctx)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("listing release claims: %w", err)
+		}
+		cluster := r.clusterIdentity(ctx, obj)
+		for _, other := range objects {
+			if releaseClaimedBefore(other, obj, releaseName) && r.clusterIdentity(ctx, other) == cluster {
+				return fmt.Errorf("%w: release %s in namespace %q is already used by %s",
+					ErrReleaseNameCollision, releaseName, r.Namespace, client.ObjectKeyFromObject(other))
+			}
 		}
 	}
 
-	return clnt, nil
+	status := obj.GetStatus()
+	status.ReleaseName = releaseName
+	obj.SetStatus(status)
+	return nil
 }
 
+// invalidatedClient marks clients in clientVersions that are replaced by the next reconciliation, as they cannot be
+// removed from a ClientCache that is not an InvalidatingClientCache.
+type invalidatedClient struct{}
+
+// InvalidateClient removes the cached client of the ClientCacheKeyFn key,
+// so that the next reconciliation resolves the target cluster again.
+func (r *Reconciler) InvalidateClient(key any) {
+	if cache, ok := r.ClientCache.(InvalidatingClientCache); ok {
+		cache.DeleteClientFromCache(key)
+		r.clientVersions.Delete(key)
+	} else {
+		r.clientVersions.Store(key, invalidatedClient{})
+	}
+	if r.MetadataInformers != nil {
+		r.MetadataInformers.Invalidate(key)
+	}
+}
+
+// ssaStatus applies the status of obj and requeues it, unless obj exhausted its retry budget with the status.
+// A status that did not change during the reconciliation is not applied again.
 func (r *Reconciler) ssaStatus(ctx context.Context, obj client.Object) (ctrl.Result, error) {
+	exhausted := false
+	if obj, ok := obj.(Object); ok {
+		exhausted = r.recordFailures(obj)
+		if isStatusUnchanged(ctx, obj.GetStatus()) {
+			return ctrl.Result{Requeue: !exhausted}, nil
+		}
+	}
 	obj.SetUID("")
 	obj.SetManagedFields(nil)
 	obj.SetResourceVersion("")
 	// TODO: replace the SubResourcePatchOptions with  client.ForceOwnership, r.FieldOwner in later compatible version
-	return ctrl.Result{Requeue: true}, r.Status().Patch(
+	return ctrl.Result{Requeue: !exhausted}, r.Status().Patch(
 		ctx, obj, client.Apply, subResourceOpts(client.ForceOwnership, r.FieldOwner),
 	)
 }
@@ -461,6 +863,68 @@ func subResourceOpts(opts ...client.PatchOption) client.SubResourcePatchOption {
 	return &client.SubResourcePatchOptions{PatchOptions: *(&client.PatchOptions{}).ApplyOptions(opts)}
 }
 
+// isRetryableSpecError determines if the spec resolution failed for reasons that are likely resolved by retrying,
+// failures that need user interaction, e.g. missing credentials or charts, are not retried faster.
+func isRetryableSpecError(err error) bool {
+	if errors.Is(err, types.ErrRegistryUnauthorized) || errors.Is(err, types.ErrChartNotFound) {
+		return false
+	}
+	return types.IsDownloadError(err) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// retryDownload requeues the object with the download retry backoff after updating its status.
+// As failed downloads never leave partial artifacts in the cache, the next attempt renders from scratch.
+// Resolutions that exceeded the operation timeout are retried the same way.
+func (r *Reconciler) retryDownload(ctx context.Context, req ctrl.Request, obj Object) (ctrl.Result, error) {
+	if result, err := r.ssaStatus(ctx, obj); err != nil || !result.Requeue {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: r.DownloadRetryRateLimiter.When(req)}, nil
+}
+
+// operationContext bounds the operations of a single reconciliation by the OperationTimeout,
+// so that a stuck request to a cluster or registry cannot block a worker forever.
+// Objects that were never ready are bounded by the InstallOperationTimeout instead, if configured.
+// Status updates use the parent context and are still written after the timeout was exceeded.
+func (r *Reconciler) operationContext(ctx context.Context, obj Object) (context.Context, context.CancelFunc) {
+	timeout := r.OperationTimeout
+	if !obj.GetStatus().InstalledOnce && r.InstallOperationTimeout > 0 {
+		timeout = r.InstallOperationTimeout
+	}
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// awaitReadiness updates the status of an object whose resources are not ready yet. Until the object was
+// ready once, it is requeued after the InstallRequeueInterval if configured instead of the backoff of the queue.
+func (r *Reconciler) awaitReadiness(ctx context.Context, obj Object, spec *Spec) (ctrl.Result, error) {
+	result, err := r.ssaInstallStatus(ctx, obj, spec)
+	if err != nil || obj.GetStatus().InstalledOnce || r.InstallRequeueInterval <= 0 {
+		return result, err
+	}
+	return ctrl.Result{RequeueAfter: r.InstallRequeueInterval}, nil
+}
+
+// ssaInstallStatus reflects the current State in the status of the install of spec before applying the status.
+func (r *Reconciler) ssaInstallStatus(ctx context.Context, obj Object, spec *Spec) (ctrl.Result, error) {
+	obj.SetStatus(obj.GetStatus().WithInstall(spec.ManifestName, spec.Revision))
+	return r.ssaStatus(ctx, obj)
+}
+
+func installsEqual(installsA, installsB []InstallStatus) bool {
+	if len(installsA) != len(installsB) {
+		return false
+	}
+	for i := range installsA {
+		if installsA[i] != installsB[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (r *Reconciler) ssa(ctx context.Context, obj client.Object) (ctrl.Result, error) {
 	obj.SetUID("")
 	obj.SetManagedFields(nil)
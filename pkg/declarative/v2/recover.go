@@ -0,0 +1,32 @@
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var ErrPanicRecovered = errors.New("recovered from panic")
+
+// withPanicRecovery runs fn and converts a panic into an ErrPanicRecovered error,
+// so that a panicking renderer, transform, hook or check cannot kill the worker or leave
+// concurrent operations waiting for a result forever.
+func withPanicRecovery(source string, fn func() error) (err error) {
+	defer recoverPanic(source, &err)
+	return fn()
+}
+
+// recoverPanic must be deferred directly, it sets err if a panic was recovered.
+func recoverPanic(source string, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	RecoveredPanics.WithLabelValues(source).Inc()
+	ctrl.Log.WithName("declarative").Error(
+		fmt.Errorf("%v", recovered), "recovered from panic", "source", source, "stack", string(debug.Stack()),
+	)
+	*err = fmt.Errorf("%w in %s: %v", ErrPanicRecovered, source, recovered)
+}
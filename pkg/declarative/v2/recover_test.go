@@ -0,0 +1,29 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var errTest = errors.New("test")
+
+func TestWithPanicRecovery(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	asserts.NoError(withPanicRecovery("test-ok", func() error { return nil }))
+	asserts.ErrorIs(withPanicRecovery("test-error", func() error { return errTest }), errTest)
+
+	recovered := RecoveredPanics.WithLabelValues("test-panic")
+	before := testutil.ToFloat64(recovered)
+	err := withPanicRecovery("test-panic", func() error { panic("boom") })
+	asserts.ErrorIs(err, ErrPanicRecovered)
+	asserts.ErrorContains(err, "boom")
+	asserts.Equal(before+1, testutil.ToFloat64(recovered))
+}
@@ -0,0 +1,107 @@
+package v2
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"helm.sh/helm/v3/pkg/chartutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// DefaultReleaseNameTemplate uses the ManifestName of the Spec verbatim as release name.
+const DefaultReleaseNameTemplate = "{{ .ManifestName }}"
+
+const releaseNameHashLength = 8
+
+var (
+	ErrReleaseNameCollision = errors.New("release name collision")
+	ErrListKind             = errors.New("list kind is not registered")
+)
+
+// ReleaseNameData is available in release name templates.
+type ReleaseNameData struct {
+	// ManifestName is the ManifestName of the Spec, e.g. the name of an install.
+	ManifestName string
+	// Name and Namespace of the reconciled object.
+	Name      string
+	Namespace string
+	// Hash is a short hash of the namespace and name of the reconciled object.
+	Hash string
+}
+
+// ParseReleaseNameTemplate parses a text/template for release names, e.g. "{{ .Name }}-{{ .ManifestName }}".
+func ParseReleaseNameTemplate(text string) (*template.Template, error) {
+	return template.New("release-name").Option("missingkey=error").Parse(text)
+}
+
+func executeReleaseNameTemplate(tmpl *template.Template, obj Object, spec *Spec) (string, error) {
+	if tmpl == nil {
+		return spec.ManifestName, nil
+	}
+	hash := sha256.Sum256([]byte(client.ObjectKeyFromObject(obj).String()))
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, ReleaseNameData{
+		ManifestName: spec.ManifestName,
+		Name:         obj.GetName(),
+		Namespace:    obj.GetNamespace(),
+		Hash:         hex.EncodeToString(hash[:])[:releaseNameHashLength],
+	}); err != nil {
+		return "", fmt.Errorf("templating release name: %w", err)
+	}
+	name := buf.String()
+	if err := chartutil.ValidateReleaseName(name); err != nil {
+		return "", fmt.Errorf("templated release name %q is invalid: %w", name, err)
+	}
+	return name, nil
+}
+
+// releaseClaimedBefore reports whether other claimed releaseName in its Status before obj, ordered by creation and
+// then by key, so that only one of several objects claiming the same release at once wins.
+func releaseClaimedBefore(other, obj Object, releaseName string) bool {
+	otherKey, key := client.ObjectKeyFromObject(other), client.ObjectKeyFromObject(obj)
+	if otherKey == key || other.GetStatus().ReleaseName != releaseName {
+		return false
+	}
+	otherCreated, created := other.GetCreationTimestamp(), obj.GetCreationTimestamp()
+	if !otherCreated.Equal(&created) {
+		return otherCreated.Before(&created)
+	}
+	return otherKey.String() < key.String()
+}
+
+// listObjects lists all objects of the kind of the prototype through the client of the manager.
+func (r *Reconciler) listObjects(ctx context.Context) ([]Object, error) {
+	gvk, err := apiutil.GVKForObject(r.prototype, r.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	list, err := r.Scheme().New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	objectList, ok := list.(client.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a list: %w", gvk.Kind+"List", ErrListKind)
+	}
+	if err := r.List(ctx, objectList); err != nil {
+		return nil, err
+	}
+	items, err := meta.ExtractList(objectList)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]Object, 0, len(items))
+	for _, item := range items {
+		if obj, ok := item.(Object); ok {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,67 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestExecuteReleaseNameTemplate(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("manifest")
+	obj.SetNamespace("kcp-system")
+	spec := &Spec{ManifestName: "install"}
+
+	tmpl, err := ParseReleaseNameTemplate(DefaultReleaseNameTemplate)
+	asserts.NoError(err)
+	name, err := executeReleaseNameTemplate(tmpl, obj, spec)
+	asserts.NoError(err)
+	asserts.Equal("install", name)
+
+	tmpl, err = ParseReleaseNameTemplate("{{ .ManifestName }}-{{ .Hash }}")
+	asserts.NoError(err)
+	name, err = executeReleaseNameTemplate(tmpl, obj, spec)
+	asserts.NoError(err)
+	asserts.Regexp("^install-[0-9a-f]{8}$", name)
+
+	tmpl, err = ParseReleaseNameTemplate("{{ .Name }}_{{ .ManifestName }}")
+	asserts.NoError(err)
+	_, err = executeReleaseNameTemplate(tmpl, obj, spec)
+	asserts.Error(err, "release names must be valid helm release names")
+}
+
+func TestReleaseClaimedBefore(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	newObj := func(name string, created time.Time, releaseName string) *statusObj {
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+		obj.SetName(name)
+		obj.SetNamespace("kcp-system")
+		obj.SetCreationTimestamp(metav1.NewTime(created))
+		obj.SetStatus(Status{ReleaseName: releaseName})
+		return obj
+	}
+	now := time.Now().Truncate(time.Second)
+	first := newObj("first", now.Add(-time.Minute), "install")
+	second := newObj("second", now, "install")
+
+	asserts.True(releaseClaimedBefore(first, second, "install"))
+	asserts.False(releaseClaimedBefore(second, first, "install"), "older objects keep their claim")
+	asserts.False(releaseClaimedBefore(first, first, "install"), "objects do not collide with themselves")
+	asserts.False(releaseClaimedBefore(first, second, "renamed"), "other releases are not claimed")
+
+	concurrent := newObj("concurrent", now, "install")
+	asserts.True(releaseClaimedBefore(concurrent, second, "install"))
+	asserts.False(releaseClaimedBefore(second, concurrent, "install"),
+		"claims at the same time are ordered by key")
+}
@@ -0,0 +1,63 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+)
+
+var ErrRenderLimitExceeded = errors.New("rendered manifest exceeds limit")
+
+// WithRenderLimits rejects rendered manifests larger than maxBytes or with more than maxObjects objects
+// before they are parsed or applied, so that pathological module images cannot exhaust the memory of the
+// controller or the API servers of the target clusters. A limit of 0 disables the respective check.
+func WithRenderLimits(maxBytes, maxObjects int) WithRenderLimitsOption {
+	return WithRenderLimitsOption{maxBytes: maxBytes, maxObjects: maxObjects}
+}
+
+type WithRenderLimitsOption struct {
+	maxBytes   int
+	maxObjects int
+}
+
+func (o WithRenderLimitsOption) Apply(options *Options) {
+	options.MaxRenderedBytes = o.maxBytes
+	options.MaxRenderedObjects = o.maxObjects
+}
+
+// sizeLimitedRenderer rejects rendered manifests above the maximum size, including manifests
+// served from a cache that were rendered before the limit was configured.
+type sizeLimitedRenderer struct {
+	Renderer
+	maxBytes int
+}
+
+func wrapWithSizeLimit(renderer Renderer, maxBytes int) Renderer {
+	if maxBytes <= 0 {
+		return renderer
+	}
+	return &sizeLimitedRenderer{Renderer: renderer, maxBytes: maxBytes}
+}
+
+func (r *sizeLimitedRenderer) Render(ctx context.Context, obj Object) ([]byte, error) {
+	rendered, err := r.Renderer.Render(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(rendered) > r.maxBytes {
+		return nil, types.NewClassifiedError(types.ErrRenderFailed, fmt.Errorf(
+			"%w: %d bytes are more than the maximum of %d bytes", ErrRenderLimitExceeded, len(rendered), r.maxBytes,
+		))
+	}
+	return rendered, nil
+}
+
+func checkObjectLimit(objects, maxObjects int) error {
+	if maxObjects > 0 && objects > maxObjects {
+		return fmt.Errorf("%w: %d objects are more than the maximum of %d objects",
+			ErrRenderLimitExceeded, objects, maxObjects)
+	}
+	return nil
+}
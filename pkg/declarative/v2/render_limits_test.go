@@ -0,0 +1,40 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRenderLimits(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	manifestFile := filepath.Join(t.TempDir(), "manifest.yaml")
+	assertions.NoError(os.WriteFile(manifestFile, []byte("apiVersion: v1\nkind: ConfigMap\n"), 0o600))
+	renderer := NewRawRenderer(&Spec{Path: manifestFile}, &Options{})
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+
+	rendered, err := wrapWithSizeLimit(renderer, 0).Render(context.Background(), obj)
+	assertions.NoError(err)
+	assertions.NotEmpty(rendered)
+
+	_, err = wrapWithSizeLimit(renderer, len(rendered)).Render(context.Background(), obj)
+	assertions.NoError(err)
+
+	_, err = wrapWithSizeLimit(renderer, len(rendered)-1).Render(context.Background(), obj)
+	assertions.ErrorIs(err, ErrRenderLimitExceeded)
+	assertions.ErrorIs(err, types.ErrRenderFailed)
+
+	assertions.NoError(checkObjectLimit(3000, 0))
+	assertions.NoError(checkObjectLimit(3000, 3000))
+	assertions.ErrorIs(checkObjectLimit(3001, 3000), ErrRenderLimitExceeded)
+}
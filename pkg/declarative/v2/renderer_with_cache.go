@@ -2,10 +2,14 @@ package v2
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/module-manager/internal"
@@ -15,9 +19,21 @@ import (
 )
 
 const (
-	manifest = "manifest"
+	manifest       = "manifest"
+	checksumSuffix = ".sha256"
+	// tmpInfix marks the temporary files of internal.WriteFileAtomically.
+	tmpInfix = ".tmp-"
 )
 
+// ManifestCacheTmpGracePeriod keeps temporary files of the manifest cache that may still be written by a
+// concurrent render, only older temporary files are left behind by failed writes and are removed.
+const ManifestCacheTmpGracePeriod = 10 * time.Minute
+
+var ErrManifestCacheChecksumMismatch = errors.New("cached manifest does not match its checksum")
+
+// errResyncRequested bypasses the cached manifest of objects whose ResyncAnnotation changed.
+var errResyncRequested = errors.New("resync requested")
+
 func WrapWithRendererCache(
 	renderer Renderer,
 	spec *Spec,
@@ -27,10 +43,14 @@ func WrapWithRendererCache(
 		return renderer
 	}
 
+	baseDir := string(options.ManifestCache)
 	return &RendererWithCache{
 		Renderer:      renderer,
 		recorder:      options.EventRecorder,
-		manifestCache: newManifestCache(string(options.ManifestCache), spec),
+		manifestCache: newManifestCache(baseDir, spec),
+		stats: internal.RegisterCache("rendered-manifests", func() internal.CacheSnapshot {
+			return renderedManifestsSnapshot(filepath.Join(baseDir, manifest))
+		}),
 	}
 }
 
@@ -38,6 +58,7 @@ type RendererWithCache struct {
 	Renderer
 	recorder record.EventRecorder
 	*manifestCache
+	stats *internal.CacheStats
 }
 
 func (k *RendererWithCache) Render(ctx context.Context, obj Object) ([]byte, error) {
@@ -53,7 +74,20 @@ func (k *RendererWithCache) Render(ctx context.Context, obj Object) ([]byte, err
 
 	cacheFile := k.ReadYAML()
 
+	if cacheFile.GetRawError() == nil && resyncRequested(obj) {
+		logger.Info("resync requested, rendering again")
+		cacheFile = types.NewParsedFile("", errResyncRequested)
+	} else if cacheFile.GetRawError() == nil {
+		if err := k.Verify(cacheFile.GetContent()); err != nil {
+			ManifestCacheCorruptions.Inc()
+			k.recorder.Event(obj, "Warning", "ManifestCacheVerification", err.Error())
+			logger.Info("cached manifest failed verification, rendering again", "error", err.Error())
+			cacheFile = types.NewParsedFile("", err)
+		}
+	}
+
 	if cacheFile.GetRawError() != nil {
+		k.stats.Miss()
 		renderStart := time.Now()
 		logger.Info("no cached manifest, rendering again")
 		manifest, err := k.Renderer.Render(ctx, obj)
@@ -62,8 +96,14 @@ func (k *RendererWithCache) Render(ctx context.Context, obj Object) ([]byte, err
 			obj.SetStatus(status.WithState(StateError).WithErr(err))
 			return nil, fmt.Errorf("rendering new manifest failed: %w", err)
 		}
-		logger.Info("rendering finished", "time", time.Since(renderStart))
-		if err := internal.WriteToFile(k.manifestCache.String(), manifest); err != nil {
+		// documents are cached in a stable order, so that equal renders result in equal cache content
+		if manifest, err = internal.SortManifestDocuments(manifest); err != nil {
+			k.recorder.Event(obj, "Warning", "RenderNonCached", err.Error())
+			obj.SetStatus(status.WithState(StateError).WithErr(err))
+			return nil, fmt.Errorf("sorting rendered manifest failed: %w", err)
+		}
+		logger.Info("rendering finished", "time", time.Since(renderStart), "checksum", checksum(manifest))
+		if err := k.Write(manifest); err != nil {
 			k.recorder.Event(obj, "Warning", "ManifestCacheWrite", err.Error())
 			obj.SetStatus(status.WithState(StateError).WithErr(err))
 			return nil, err
@@ -71,43 +111,118 @@ func (k *RendererWithCache) Render(ctx context.Context, obj Object) ([]byte, err
 		return manifest, nil
 	}
 
+	k.stats.Hit()
 	logger.V(internal.DebugLogLevel).Info("reuse manifest from cache")
 
 	return []byte(cacheFile.GetContent()), nil
 }
 
+// renderedManifestsSnapshot describes the cached manifests below dir, without their checksums.
+func renderedManifestsSnapshot(dir string) internal.CacheSnapshot {
+	var snapshot internal.CacheSnapshot
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasSuffix(path, checksumSuffix) {
+			return nil //nolint:nilerr
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		snapshot.Entries++
+		snapshot.SizeBytes += info.Size()
+		if snapshot.Oldest.IsZero() || info.ModTime().Before(snapshot.Oldest) {
+			snapshot.Oldest = info.ModTime()
+		}
+		return nil
+	})
+	return snapshot
+}
+
 type manifestCache struct {
 	root string
 	file string
 	hash string
 }
 
+// cacheRelativePath maps the path or URL of a spec to a relative path below the cache directory that is valid on
+// all platforms, e.g. without the volume name of Windows paths or the ':' of URLs.
+func cacheRelativePath(specPath string) string {
+	specPath = filepath.ToSlash(strings.TrimPrefix(specPath, filepath.VolumeName(specPath)))
+	segments := strings.Split(specPath, "/")
+	for i := range segments {
+		segments[i] = internal.EncodePathSegment(segments[i])
+	}
+	return filepath.Join(segments...)
+}
+
 func newManifestCache(baseDir string, spec *Spec) *manifestCache {
-	root := filepath.Join(baseDir, manifest, spec.Path)
-	file := filepath.Join(root, spec.ManifestName)
-	hashedValues, _ := internal.CalculateHash(spec.Values)
-	hash := fmt.Sprintf("%v", hashedValues)
+	root := filepath.Join(baseDir, manifest, cacheRelativePath(spec.Path))
+	name := spec.ManifestName
+	if spec.ReleaseName != "" {
+		name = spec.ReleaseName
+	}
+	file := filepath.Join(root, name)
+	hash := renderInputHash(spec)
 	file = fmt.Sprintf("%s-%s-%s.yaml", file, spec.Mode, hash)
 
 	return &manifestCache{
 		root: root,
 		file: file,
-		hash: fmt.Sprintf("%v", hashedValues),
+		hash: hash,
+	}
+}
+
+// renderInputHash identifies the inputs of a rendering besides its path. These are the values, the install flags
+// and, for kustomizations, either the resolved revision (e.g. the commit of a remote) or the content of the
+// kustomization directory, as local kustomizations can change in place.
+func renderInputHash(spec *Spec) string {
+	hashedValues, _ := internal.CalculateHash(spec.Values)
+	hash := fmt.Sprintf("%v", hashedValues)
+	if len(spec.InstallFlags) > 0 {
+		hashedFlags, _ := internal.CalculateHash(spec.InstallFlags)
+		hash = fmt.Sprintf("%s-%v", hash, hashedFlags)
+	}
+	if spec.Mode != RenderModeKustomize {
+		return hash
+	}
+
+	// without a resolved revision, the revision defaults to the name of the directory
+	if spec.Revision != "" && spec.Revision != filepath.Base(spec.Path) {
+		hashedRevision, _ := internal.CalculateHash(spec.Revision)
+		return fmt.Sprintf("%s-%v", hash, hashedRevision)
+	}
+	hashedDir, err := internal.CalculateDirHash(spec.Path)
+	if err != nil {
+		// the kustomization cannot be rendered either, so the render error is reported instead
+		return hash
 	}
+	return fmt.Sprintf("%s-%v", hash, hashedDir)
 }
 
 func (c *manifestCache) String() string {
 	return c.file
 }
 
+func (c *manifestCache) checksumFile() string {
+	return c.file + checksumSuffix
+}
+
+// Clean removes the cached manifests of other render inputs below the root of the cache, temporary files are only
+// removed after the ManifestCacheTmpGracePeriod.
 func (c *manifestCache) Clean() error {
 	removeAllOld := func(path string, info fs.FileInfo, err error) error {
 		if info == nil || info.IsDir() {
 			return nil
 		}
+		if strings.Contains(info.Name(), tmpInfix) && time.Since(info.ModTime()) < ManifestCacheTmpGracePeriod {
+			return nil
+		}
 		oldFile := filepath.Join(c.root, info.Name())
-		if oldFile != c.file {
-			return os.Remove(oldFile)
+		if oldFile == c.file || oldFile == c.checksumFile() {
+			return nil
+		}
+		if err := os.Remove(oldFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
 		}
 		return nil
 	}
@@ -117,3 +232,30 @@ func (c *manifestCache) Clean() error {
 func (c *manifestCache) ReadYAML() *types.ParsedFile {
 	return types.NewParsedFile(internal.GetStringifiedYamlFromFilePath(c.String()))
 }
+
+// Write stores the manifest together with its checksum, so that modifications of the cached file can be detected.
+func (c *manifestCache) Write(manifest []byte) error {
+	if err := internal.WriteToFile(c.String(), manifest); err != nil {
+		return err
+	}
+	return internal.WriteToFile(c.checksumFile(), []byte(checksum(manifest)))
+}
+
+// Verify checks the cached content against the checksum stored alongside it. A missing checksum fails the
+// verification as well, as the cached content can no longer be trusted once its checksum was removed.
+func (c *manifestCache) Verify(content string) error {
+	stored, err := os.ReadFile(c.checksumFile())
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrManifestCacheChecksumMismatch, err.Error())
+	}
+	if actual := checksum([]byte(content)); actual != string(stored) {
+		return fmt.Errorf("%w: expected %s but got %s for %s",
+			ErrManifestCacheChecksumMismatch, stored, actual, c.String())
+	}
+	return nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -3,11 +3,15 @@ package v2_test
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	. "github.com/kyma-project/module-manager/pkg/declarative/v2"
 	mockV2 "github.com/kyma-project/module-manager/pkg/declarative/v2/mock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/client-go/tools/record"
 )
@@ -131,3 +135,146 @@ func TestWrapWithRendererCache(t *testing.T) {
 		)
 	}
 }
+
+func TestRendererWithCacheRendersAgainOnTamperedCache(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheDir := t.TempDir()
+	spec := &Spec{ManifestName: "test-manifest", Path: "test-path", Mode: RenderModeHelm}
+	renderer := &stubRenderer{Data: []byte("test-data")}
+	recorder := record.NewFakeRecorder(1)
+
+	mockObject := mockV2.NewMockObject(ctrl)
+	mockObject.EXPECT().GetStatus().AnyTimes().Return(Status{})
+	mockObject.EXPECT().SetStatus(gomock.Any()).AnyTimes()
+
+	cachedRenderer := WrapWithRendererCache(
+		renderer, spec, &Options{EventRecorder: recorder, ManifestCache: ManifestCache(cacheDir)},
+	)
+
+	_, err := cachedRenderer.Render(context.Background(), mockObject)
+	assertions.NoError(err)
+
+	cached, err := filepath.Glob(filepath.Join(cacheDir, "manifest", spec.Path, "*.yaml"))
+	assertions.NoError(err)
+	assertions.Len(cached, 1)
+	assertions.NoError(os.WriteFile(cached[0], []byte("tampered-data"), 0o600))
+
+	manifest, err := cachedRenderer.Render(context.Background(), mockObject)
+	assertions.NoError(err)
+	assertions.Equal([]byte("test-data"), manifest)
+	assertions.Equal(2, renderer.RenderCount)
+	assertions.Contains(<-recorder.Events, "ManifestCacheVerification")
+
+	_, err = cachedRenderer.Render(context.Background(), mockObject)
+	assertions.NoError(err)
+	assertions.Equal(2, renderer.RenderCount, "restored cache should be reused")
+}
+
+func TestRendererWithCacheRendersAgainOnChangedKustomization(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheDir, kustomization := t.TempDir(), t.TempDir()
+	kustomizationFile := filepath.Join(kustomization, "kustomization.yaml")
+	assertions.NoError(os.WriteFile(kustomizationFile, []byte("resources: []"), 0o600))
+	spec := &Spec{ManifestName: "test-manifest", Path: kustomization, Mode: RenderModeKustomize}
+	renderer := &stubRenderer{Data: []byte("test-data")}
+	options := &Options{EventRecorder: record.NewFakeRecorder(1), ManifestCache: ManifestCache(cacheDir)}
+
+	mockObject := mockV2.NewMockObject(ctrl)
+	mockObject.EXPECT().GetStatus().AnyTimes().Return(Status{})
+	mockObject.EXPECT().SetStatus(gomock.Any()).AnyTimes()
+
+	render := func() {
+		_, err := WrapWithRendererCache(renderer, spec, options).Render(context.Background(), mockObject)
+		assertions.NoError(err)
+	}
+
+	render()
+	render()
+	assertions.Equal(1, renderer.RenderCount, "unchanged kustomization should be reused")
+
+	assertions.NoError(os.WriteFile(kustomizationFile, []byte("resources: [deployment.yaml]"), 0o600))
+	render()
+	assertions.Equal(2, renderer.RenderCount, "changed kustomization should be rendered again")
+
+	spec.Revision = "0123456789abcdef"
+	render()
+	render()
+	assertions.Equal(3, renderer.RenderCount, "resolved revisions should be reused")
+}
+
+func TestRendererWithCacheRendersAgainOnMissingChecksum(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheDir := t.TempDir()
+	spec := &Spec{ManifestName: "test-manifest", Path: "test-path", Mode: RenderModeHelm}
+	renderer := &stubRenderer{Data: []byte("test-data")}
+	recorder := record.NewFakeRecorder(1)
+	options := &Options{EventRecorder: recorder, ManifestCache: ManifestCache(cacheDir)}
+
+	mockObject := mockV2.NewMockObject(ctrl)
+	mockObject.EXPECT().GetStatus().AnyTimes().Return(Status{})
+	mockObject.EXPECT().SetStatus(gomock.Any()).AnyTimes()
+
+	cachedRenderer := WrapWithRendererCache(renderer, spec, options)
+	_, err := cachedRenderer.Render(context.Background(), mockObject)
+	assertions.NoError(err)
+
+	cached, err := filepath.Glob(filepath.Join(cacheDir, "manifest", spec.Path, "*.yaml"))
+	assertions.NoError(err)
+	assertions.Len(cached, 1)
+	assertions.NoError(os.WriteFile(cached[0], []byte("tampered-data"), 0o600))
+	assertions.NoError(os.Remove(cached[0] + ".sha256"))
+
+	corruptions := testutil.ToFloat64(ManifestCacheCorruptions)
+	manifest, err := cachedRenderer.Render(context.Background(), mockObject)
+	assertions.NoError(err)
+	assertions.Equal([]byte("test-data"), manifest, "manifests cached without checksum should not be trusted")
+	assertions.Equal(2, renderer.RenderCount)
+	assertions.Contains(<-recorder.Events, "ManifestCacheVerification")
+	assertions.GreaterOrEqual(testutil.ToFloat64(ManifestCacheCorruptions), corruptions+1)
+	assertions.FileExists(cached[0] + ".sha256")
+}
+
+func TestRendererWithCacheKeepsRecentTemporaryFiles(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheDir := t.TempDir()
+	spec := &Spec{ManifestName: "test-manifest", Path: "test-path", Mode: RenderModeHelm}
+	options := &Options{EventRecorder: record.NewFakeRecorder(1), ManifestCache: ManifestCache(cacheDir)}
+
+	mockObject := mockV2.NewMockObject(ctrl)
+	mockObject.EXPECT().GetStatus().AnyTimes().Return(Status{})
+	mockObject.EXPECT().SetStatus(gomock.Any()).AnyTimes()
+
+	root := filepath.Join(cacheDir, "manifest", spec.Path)
+	assertions.NoError(os.MkdirAll(root, 0o700))
+	recent := filepath.Join(root, ".other-helm-hash.yaml.tmp-1")
+	stale := filepath.Join(root, ".other-helm-hash.yaml.tmp-2")
+	outdated := filepath.Join(root, "other-helm-hash.yaml")
+	for _, file := range []string{recent, stale, outdated} {
+		assertions.NoError(os.WriteFile(file, []byte("data"), 0o600))
+	}
+	staleTime := time.Now().Add(-2 * ManifestCacheTmpGracePeriod)
+	assertions.NoError(os.Chtimes(stale, staleTime, staleTime))
+
+	_, err := WrapWithRendererCache(&stubRenderer{Data: []byte("test-data")}, spec, options).
+		Render(context.Background(), mockObject)
+	assertions.NoError(err)
+	assertions.FileExists(recent, "temporary files of concurrent renders should be kept")
+	assertions.NoFileExists(stale)
+	assertions.NoFileExists(outdated)
+}
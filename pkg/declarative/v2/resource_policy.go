@@ -0,0 +1,47 @@
+package v2
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ResourcePolicyAnnotation set to "keep" excludes a resource from pruning and from the uninstall of its object,
+// e.g. for PersistentVolumeClaims or Secrets that must survive the removal of a module.
+// The helm.sh/resource-policy annotation of helm charts is honored the same way.
+const ResourcePolicyAnnotation = "declarative.kyma-project.io/resource-policy"
+
+func isKeptResource(annotations map[string]string) bool {
+	return annotations[ResourcePolicyAnnotation] == kube.KeepPolicy ||
+		annotations[kube.ResourcePolicyAnno] == kube.KeepPolicy
+}
+
+// withoutKeptResources removes all resources from infos that are annotated to be kept in the cluster.
+// The annotations are read from the cluster, as infos are usually derived from the synced resources.
+func withoutKeptResources(
+	ctx context.Context, clnt client.Client, infos []*resource.Info,
+) ([]*resource.Info, error) {
+	filtered := make([]*resource.Info, 0, len(infos))
+	for _, info := range infos {
+		obj := &metav1.PartialObjectMetadata{}
+		obj.SetGroupVersionKind(info.Object.GetObjectKind().GroupVersionKind())
+		err := clnt.Get(ctx, client.ObjectKey{Name: info.Name, Namespace: info.Namespace}, obj)
+		if apierrors.IsNotFound(err) {
+			filtered = append(filtered, info)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if isKeptResource(obj.GetAnnotations()) {
+			log.FromContext(ctx).Info("keeping resource due to resource policy", "resource", info.ObjectName())
+			continue
+		}
+		filtered = append(filtered, info)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,48 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/kube"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWithoutKeptResources(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	clnt := fake.NewClientBuilder().WithObjects(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "helm-kept", Namespace: metav1.NamespaceDefault,
+			Annotations: map[string]string{kube.ResourcePolicyAnno: kube.KeepPolicy},
+		}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "kept", Namespace: metav1.NamespaceDefault,
+			Annotations: map[string]string{ResourcePolicyAnnotation: kube.KeepPolicy},
+		}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "pruned", Namespace: metav1.NamespaceDefault,
+			Annotations: map[string]string{ResourcePolicyAnnotation: "delete"},
+		}},
+	).Build()
+
+	infos := []*resource.Info{
+		configMapInfo("helm-kept"), configMapInfo("kept"), configMapInfo("pruned"), configMapInfo("missing"),
+	}
+	deletable, err := withoutKeptResources(ctx, clnt, infos)
+	assertions.NoError(err)
+	var names []string
+	for _, info := range deletable {
+		names = append(names, info.Name)
+	}
+	assertions.ElementsMatch([]string{"pruned", "missing"}, names)
+}
@@ -0,0 +1,33 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+)
+
+// collectResults receives expected results from the concurrent workers of an operation.
+// It stops waiting once ctx is done, so a worker that never responds cannot block the reconciliation
+// beyond the operation timeout. All result channels are buffered for every worker,
+// which lets late workers finish without a receiver instead of leaking.
+func collectResults(ctx context.Context, source string, results <-chan error, expected int) ([]error, error) {
+	collected := make([]error, 0, expected)
+	for len(collected) < expected {
+		// prefer responses that are already available over an ended context
+		select {
+		case err := <-results:
+			collected = append(collected, err)
+			continue
+		default:
+		}
+		select {
+		case err := <-results:
+			collected = append(collected, err)
+		case <-ctx.Done():
+			missing := expected - len(collected)
+			AbandonedResponses.WithLabelValues(source).Add(float64(missing))
+			return collected, fmt.Errorf("%s: %d of %d responses missing: %w",
+				source, missing, expected, ctx.Err())
+		}
+	}
+	return collected, nil
+}
@@ -0,0 +1,35 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectResults(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	results := make(chan error, 2)
+	results <- nil
+	results <- errTest
+	collected, err := collectResults(context.Background(), "test-complete", results, 2)
+	asserts.NoError(err)
+	asserts.Equal([]error{nil, errTest}, collected)
+
+	abandoned := AbandonedResponses.WithLabelValues("test-missing")
+	before := testutil.ToFloat64(abandoned)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = make(chan error, 3)
+	results <- nil
+	collected, err = collectResults(ctx, "test-missing", results, 3)
+	asserts.ErrorIs(err, context.Canceled)
+	asserts.Len(collected, 1)
+	asserts.Equal(before+2, testutil.ToFloat64(abandoned))
+}
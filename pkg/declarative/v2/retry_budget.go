@@ -0,0 +1,116 @@
+package v2
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeFailed is true once an object exhausted its retry budget, see WithRetryBudget.
+	ConditionTypeFailed ConditionType = "Failed"
+	// ConditionReasonRetryBudgetExhausted is the reason of the ConditionTypeFailed.
+	ConditionReasonRetryBudgetExhausted ConditionReason = "RetryBudgetExhausted"
+)
+
+// FailureStreak counts the consecutive reconciliations of an object that ended in the Error state.
+// +k8s:deepcopy-gen=true
+type FailureStreak struct {
+	// Count of the consecutive failures since the streak started.
+	Count int `json:"count"`
+	// Since is the time of the first failure of the streak.
+	Since metav1.Time `json:"since"`
+	// Generation of the CustomObject that failed.
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+	// Resync is the value of the ResyncAnnotation when the retry budget was exhausted.
+	// +optional
+	Resync string `json:"resync,omitempty"`
+}
+
+// WithRetryBudget stops retrying objects that failed the given number of consecutive reconciliations within the
+// window, so that chronic failures do not hot-loop forever. Such objects keep the Error state with the
+// ConditionTypeFailed and are only reconciled again once their generation or their ResyncAnnotation changes.
+// The window is measured from the first failure of a streak: a failure more than the window after it, or of a
+// new generation, starts a new streak, with a window of 0 only a new generation does.
+// A budget of 0 failures disables the retry budget.
+func WithRetryBudget(failures int, window time.Duration) WithRetryBudgetOption {
+	return WithRetryBudgetOption{failures: failures, window: window}
+}
+
+type WithRetryBudgetOption struct {
+	failures int
+	window   time.Duration
+}
+
+func (o WithRetryBudgetOption) Apply(options *Options) {
+	options.RetryBudgetFailures = o.failures
+	options.RetryBudgetWindow = o.window
+}
+
+// isRetryBudgetExhausted is true if obj exhausted its retry budget and was neither changed nor resynced since.
+func (r *Reconciler) isRetryBudgetExhausted(obj Object) bool {
+	status := obj.GetStatus()
+	return r.RetryBudgetFailures > 0 && obj.GetDeletionTimestamp().IsZero() &&
+		meta.IsStatusConditionTrue(status.Conditions, string(ConditionTypeFailed)) &&
+		status.Failures != nil && status.Failures.Count >= r.RetryBudgetFailures &&
+		status.Failures.Generation == obj.GetGeneration() &&
+		status.Failures.Resync == obj.GetAnnotations()[ResyncAnnotation]
+}
+
+// retryAfterExhaustedBudget resets the failures of an object that exhausted its retry budget,
+// so that it is retried with a new budget after it was changed or resynced.
+func (r *Reconciler) retryAfterExhaustedBudget(obj Object) {
+	status := obj.GetStatus()
+	if !meta.IsStatusConditionTrue(status.Conditions, string(ConditionTypeFailed)) {
+		return
+	}
+	r.Event(obj, "Normal", string(ConditionReasonRetryBudgetExhausted), "retrying after the object was updated")
+	meta.RemoveStatusCondition(&status.Conditions, string(ConditionTypeFailed))
+	status.Failures = nil
+	obj.SetStatus(status)
+}
+
+// recordFailures counts the failures of obj with the status that is written next and resets them once obj is
+// ready. It returns true if obj exhausted its retry budget with this failure, so that it is not requeued.
+func (r *Reconciler) recordFailures(obj Object) bool {
+	if r.RetryBudgetFailures <= 0 {
+		return false
+	}
+	status := obj.GetStatus()
+	if status.State == StateReady && status.Failures != nil {
+		status.Failures = nil
+		meta.RemoveStatusCondition(&status.Conditions, string(ConditionTypeFailed))
+		obj.SetStatus(status)
+	}
+	if status.State != StateError {
+		return false
+	}
+
+	now := time.Now()
+	failures := FailureStreak{Since: metav1.NewTime(now), Generation: obj.GetGeneration()}
+	if status.Failures != nil && status.Failures.Generation == obj.GetGeneration() &&
+		(r.RetryBudgetWindow <= 0 || now.Sub(status.Failures.Since.Time) <= r.RetryBudgetWindow) {
+		failures = *status.Failures
+	}
+	failures.Count++
+	status.Failures = &failures
+	exhausted := failures.Count >= r.RetryBudgetFailures
+	if exhausted {
+		failures.Resync = obj.GetAnnotations()[ResyncAnnotation]
+		msg := fmt.Sprintf("stopped retrying after %d consecutive failures since %s, "+
+			"update the object or %s to retry", failures.Count, failures.Since.Format(time.RFC3339), ResyncAnnotation)
+		r.Event(obj, "Warning", string(ConditionReasonRetryBudgetExhausted), msg)
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               string(ConditionTypeFailed),
+			Reason:             string(ConditionReasonRetryBudgetExhausted),
+			Status:             metav1.ConditionTrue,
+			Message:            msg,
+			ObservedGeneration: obj.GetGeneration(),
+		})
+	}
+	obj.SetStatus(status)
+	return exhausted
+}
@@ -0,0 +1,94 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordFailures(t *testing.T) {
+	t.Parallel()
+	r := &Reconciler{Options: (&Options{EventRecorder: record.NewFakeRecorder(10)}).Apply(
+		WithRetryBudget(3, time.Hour),
+	)}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetGeneration(1)
+	fail := func() bool {
+		obj.SetStatus(obj.GetStatus().WithState(StateError))
+		return r.recordFailures(obj)
+	}
+
+	assert.False(t, fail())
+	assert.False(t, fail())
+	obj.SetStatus(obj.GetStatus().WithState(StateProcessing))
+	assert.False(t, r.recordFailures(obj), "objects that are not ready do not reset the failures")
+	assert.True(t, fail())
+	assert.Equal(t, 3, obj.GetStatus().Failures.Count)
+	assert.True(t, meta.IsStatusConditionTrue(obj.GetStatus().Conditions, string(ConditionTypeFailed)))
+	assert.True(t, r.isRetryBudgetExhausted(obj))
+
+	obj.SetStatus(obj.GetStatus().WithState(StateReady))
+	assert.False(t, r.recordFailures(obj))
+	assert.Nil(t, obj.GetStatus().Failures)
+	assert.Nil(t, meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeFailed)))
+}
+
+func TestRecordFailuresWindow(t *testing.T) {
+	t.Parallel()
+	r := &Reconciler{Options: &Options{EventRecorder: record.NewFakeRecorder(10)}}
+	WithRetryBudget(2, time.Minute).Apply(r.Options)
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetStatus(Status{State: StateError, Failures: &FailureStreak{
+		Count: 1, Since: metav1.NewTime(time.Now().Add(-time.Hour)),
+	}})
+
+	assert.False(t, r.recordFailures(obj), "failures outside of the window start a new streak")
+	assert.Equal(t, 1, obj.GetStatus().Failures.Count)
+
+	obj.SetGeneration(2)
+	assert.False(t, r.recordFailures(obj), "failures of a new generation start a new streak")
+	assert.Equal(t, int64(2), obj.GetStatus().Failures.Generation)
+	assert.True(t, r.recordFailures(obj))
+
+	assert.False(t, (&Reconciler{Options: &Options{}}).recordFailures(obj), "the retry budget is opt-in")
+}
+
+func TestRetryAfterExhaustedBudget(t *testing.T) {
+	t.Parallel()
+	r := &Reconciler{Options: &Options{EventRecorder: record.NewFakeRecorder(10)}}
+	WithRetryBudget(1, 0).Apply(r.Options)
+	exhausted := func() *statusObj {
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+		obj.SetGeneration(1)
+		obj.SetAnnotations(map[string]string{ResyncAnnotation: "1"})
+		obj.SetStatus(Status{State: StateError})
+		require.True(t, r.recordFailures(obj))
+		return obj
+	}
+
+	assert.True(t, r.isRetryBudgetExhausted(exhausted()))
+
+	updated := exhausted()
+	updated.SetGeneration(2)
+	assert.False(t, r.isRetryBudgetExhausted(updated))
+
+	resynced := exhausted()
+	resynced.SetAnnotations(map[string]string{ResyncAnnotation: "2"})
+	assert.False(t, r.isRetryBudgetExhausted(resynced))
+	r.retryAfterExhaustedBudget(resynced)
+	assert.Nil(t, resynced.GetStatus().Failures)
+	assert.Nil(t, meta.FindStatusCondition(resynced.GetStatus().Conditions, string(ConditionTypeFailed)))
+
+	deleting := exhausted()
+	deleting.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	assert.False(t, r.isRetryBudgetExhausted(deleting), "deletions are always retried")
+}
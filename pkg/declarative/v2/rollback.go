@@ -0,0 +1,159 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+const (
+	ConditionTypeRollback         ConditionType   = "Rollback"
+	ConditionReasonRolledBack     ConditionReason = "RolledBack"
+	ConditionReasonRollbackFailed ConditionReason = "RollbackFailed"
+)
+
+// WithRollbackOnFailure determines for every object if its resources are rolled back to the resources applied
+// last when applying the rendered resources fails, instead of leaving them partially applied.
+// Rolled back objects are not applied again until they, their resolved Spec or their ResyncAnnotation change.
+// The resources applied last are recorded for these objects as with WithLastAppliedConfiguration.
+type WithRollbackOnFailure func(obj Object) bool
+
+func (o WithRollbackOnFailure) Apply(options *Options) {
+	options.RollbackOnFailure = o
+}
+
+func (r *Reconciler) rollbackOnFailure(obj Object) bool {
+	return r.RollbackOnFailure != nil && r.RollbackOnFailure(obj)
+}
+
+// RolledBackSpec identifies the resources that were rolled back after their apply failed.
+// +k8s:deepcopy-gen=true
+type RolledBackSpec struct {
+	// Generation of the CustomObject whose resources were rolled back.
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+	// SpecHash of the resolved specification whose resources were rolled back, see Status.SpecHash.
+	SpecHash string `json:"specHash"`
+	// Resync is the value of the ResyncAnnotation when the resources were rolled back.
+	// +optional
+	Resync string `json:"resync,omitempty"`
+}
+
+// isRolledBack is true if the resources of spec were rolled back and neither obj, spec nor the ResyncAnnotation
+// of obj changed since, so that the apply that failed is not repeated on every reconciliation.
+func isRolledBack(obj Object, spec *Spec) bool {
+	rolledBack := obj.GetStatus().RolledBack
+	return rolledBack != nil && obj.GetDeletionTimestamp().IsZero() && spec.Hash != "" &&
+		rolledBack.SpecHash == spec.Hash && rolledBack.Generation == obj.GetGeneration() &&
+		rolledBack.Resync == obj.GetAnnotations()[ResyncAnnotation]
+}
+
+// isTransientApplyError determines if applying failed for reasons that are likely resolved by applying the same
+// resources again, e.g. timeouts, throttling or conflicts, so that they are retried instead of rolled back.
+func isTransientApplyError(err error) bool {
+	var multiErr *types.MultiError
+	if errors.As(err, &multiErr) {
+		for _, err := range multiErr.Errs {
+			if isTransientApplyError(err) {
+				return true
+			}
+		}
+		return false
+	}
+	return errors.Is(err, types.ErrResourceConflict) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, context.Canceled) || apierrors.IsConflict(err) || apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) || apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsInternalError(err) || utilnet.IsConnectionRefused(err) || utilnet.IsConnectionReset(err)
+}
+
+// rollback applies the resources applied last for obj after applying target failed with applyErr and deletes the
+// resources that only exist in target with cleanup. The outcome is reported in the Rollback condition and
+// successful rollbacks are recorded in Status.RolledBack. Objects without recorded resources, e.g. on their initial
+// install, objects whose target was recorded already, e.g. in consistency checks, and transient failures are left
+// as they are.
+func (r *Reconciler) rollback(
+	ctx context.Context, converter ResourceInfoConverter, applier SSA, cleanup Cleanup, obj Object, spec *Spec,
+	target []*resource.Info, applyErr error,
+) {
+	if !r.rollbackOnFailure(obj) || isTransientApplyError(applyErr) {
+		return
+	}
+	manifest, err := lastAppliedManifest(target)
+	if err != nil || obj.GetAnnotations()[LastAppliedConfigurationChecksumAnnotation] == lastAppliedChecksum(manifest) {
+		return
+	}
+	resources, err := LastAppliedConfiguration(ctx, r.Client, obj)
+	if errors.Is(err, ErrNoLastAppliedConfiguration) {
+		return
+	}
+
+	var previous []*resource.Info
+	if err == nil {
+		previous, err = NewResourceToInfoConverter(converter, r.Namespace).UnstructuredToInfos(resources)
+	}
+	if err == nil {
+		err = applier.Run(ctx, previous)
+	}
+	added := addedResources(target, previous)
+	if err == nil {
+		if err = cleanup.Run(ctx, added); errors.Is(err, ErrDeletionNotFinished) {
+			err = nil
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               string(ConditionTypeRollback),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(ConditionReasonRolledBack),
+		ObservedGeneration: obj.GetGeneration(),
+		Message: internal.RedactString(fmt.Sprintf(
+			"restored the %d resources applied last and deleted %d new resources after the apply failed, "+
+				"update the object or %s to apply again: %s",
+			len(previous), len(added), ResyncAnnotation, applyErr.Error(),
+		)),
+	}
+	status := obj.GetStatus()
+	status.RolledBack = &RolledBackSpec{
+		Generation: obj.GetGeneration(), SpecHash: spec.Hash, Resync: obj.GetAnnotations()[ResyncAnnotation],
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(ConditionReasonRollbackFailed)
+		condition.Message = internal.RedactString(fmt.Sprintf(
+			"resources applied last could not be restored: %s", err.Error(),
+		))
+		status.RolledBack = nil
+	}
+	r.Event(obj, "Warning", condition.Reason, condition.Message)
+	meta.SetStatusCondition(&status.Conditions, condition)
+	obj.SetStatus(status)
+}
+
+// addedResources returns the resources of target that are not part of previous, except for namespaces and
+// resources that are annotated to be kept, as they are never pruned.
+func addedResources(target, previous []*resource.Info) []*resource.Info {
+	converter := NewInfoToResourceConverter()
+	existing := make(map[string]struct{}, len(previous))
+	for _, res := range converter.InfosToResources(previous) {
+		existing[res.ID()] = struct{}{}
+	}
+	var added []*resource.Info
+	for i, res := range converter.InfosToResources(target) {
+		if _, found := existing[res.ID()]; found || res.Kind == "Namespace" {
+			continue
+		}
+		if obj, ok := target[i].Object.(metav1.Object); ok && isKeptResource(obj.GetAnnotations()) {
+			continue
+		}
+		added = append(added, target[i])
+	}
+	return added
+}
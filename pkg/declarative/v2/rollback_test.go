@@ -0,0 +1,129 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type infoConverter struct{}
+
+func (infoConverter) ResourceInfo(obj *unstructured.Unstructured, _ bool) (*resource.Info, error) {
+	return &resource.Info{Name: obj.GetName(), Namespace: obj.GetNamespace(), Object: obj}, nil
+}
+
+type recordingSSA struct {
+	applied []*resource.Info
+	err     error
+}
+
+func (s *recordingSSA) Run(_ context.Context, infos []*resource.Info) error {
+	s.applied = infos
+	return s.err
+}
+
+type recordingCleanup struct {
+	deleted []*resource.Info
+}
+
+func (c *recordingCleanup) Run(_ context.Context, infos []*resource.Info) error {
+	c.deleted = infos
+	return ErrDeletionNotFinished
+}
+
+func TestRollback(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	previous := []*resource.Info{{
+		Name: "keda", Namespace: "keda", Object: renderedObject("Pod", "keda", map[string]any{"image": "keda:2.8.1"}),
+	}}
+	manifest, err := lastAppliedManifest(previous)
+	require.NoError(t, err)
+	compressed, err := compressLastAppliedManifest(manifest)
+	require.NoError(t, err)
+	clnt := fake.NewClientBuilder().WithObjects(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda" + lastAppliedConfigurationSuffix, Namespace: "kcp-system"},
+		Data:       map[string][]byte{LastAppliedConfigurationKey: compressed},
+	}).Build()
+
+	rollbackEnabled := true
+	reconciler := &Reconciler{Options: &Options{
+		Client:            clnt,
+		EventRecorder:     record.NewFakeRecorder(4),
+		RollbackOnFailure: func(Object) bool { return rollbackEnabled },
+	}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("keda")
+	obj.SetNamespace("kcp-system")
+	target := []*resource.Info{{
+		Name: "keda", Namespace: "keda", Object: renderedObject("Pod", "keda", map[string]any{"image": "keda:2.9.0"}),
+	}, {
+		Name: "keda-metrics", Namespace: "keda", Object: renderedObject("Service", "keda-metrics", nil),
+	}}
+	spec := &Spec{Hash: "2.9.0"}
+	applyErr := errors.New("admission webhook denied the request")
+
+	applier, cleanup := &recordingSSA{}, &recordingCleanup{}
+	reconciler.rollback(ctx, infoConverter{}, applier, cleanup, obj, spec, target, applyErr)
+	assert.Empty(t, applier.applied, "objects without recorded resources are not rolled back")
+
+	obj.SetAnnotations(map[string]string{
+		LastAppliedConfigurationAnnotation:         "keda" + lastAppliedConfigurationSuffix,
+		LastAppliedConfigurationChecksumAnnotation: lastAppliedChecksum(manifest),
+	})
+	reconciler.rollback(ctx, infoConverter{}, applier, cleanup, obj, spec, previous, applyErr)
+	assert.Empty(t, applier.applied, "failures of the recorded resources are not rolled back")
+
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, "keda", errors.New("modified"))
+	reconciler.rollback(ctx, infoConverter{}, applier, cleanup, obj, spec, target,
+		fmt.Errorf("ServerSideApply failed: %w", types.NewMultiError([]error{applyErr, conflict})))
+	assert.Empty(t, applier.applied, "transient failures are retried instead of rolled back")
+
+	reconciler.rollback(ctx, infoConverter{}, applier, cleanup, obj, spec, target, applyErr)
+	require.Len(t, applier.applied, 1)
+	image, _, _ := unstructured.NestedString(applier.applied[0].Object.(*unstructured.Unstructured).Object,
+		"spec", "image")
+	assert.Equal(t, "keda:2.8.1", image)
+	condition := meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeRollback))
+	require.NotNil(t, condition)
+	assert.Equal(t, string(ConditionReasonRolledBack), condition.Reason)
+	assert.Contains(t, condition.Message, applyErr.Error())
+	require.Len(t, cleanup.deleted, 1, "resources of the failed revision only are deleted")
+	assert.Equal(t, "keda-metrics", cleanup.deleted[0].Name)
+
+	assert.True(t, isRolledBack(obj, spec), "rolled back objects are held")
+	assert.False(t, isRolledBack(obj, &Spec{Hash: "2.9.1"}), "changed specs are applied again")
+	annotations := obj.GetAnnotations()
+	annotations[ResyncAnnotation] = "1"
+	obj.SetAnnotations(annotations)
+	assert.False(t, isRolledBack(obj, spec), "resynced objects are applied again")
+
+	applier.err = errors.New("apply failed")
+	reconciler.rollback(ctx, infoConverter{}, applier, cleanup, obj, spec, target, applyErr)
+	condition = meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeRollback))
+	assert.Equal(t, string(ConditionReasonRollbackFailed), condition.Reason)
+	assert.Nil(t, obj.GetStatus().RolledBack, "failed rollbacks are retried")
+
+	rollbackEnabled = false
+	applier.applied = nil
+	reconciler.rollback(ctx, infoConverter{}, applier, cleanup, obj, spec, target, applyErr)
+	assert.Empty(t, applier.applied)
+}
@@ -0,0 +1,300 @@
+package v2
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"golang.org/x/sync/singleflight"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultCapabilitiesTTL is the time the capabilities of a cluster are kept for the keys of the SharedRenderCache.
+const DefaultCapabilitiesTTL = time.Minute
+
+// SharedRenderCache keeps rendered manifests in memory, shared by all objects of a controller process, so that
+// objects rendering the same content with the same flags, e.g. one module installed into many clusters, are
+// rendered only once. Concurrent renders of the same key wait for the first one instead of rendering again.
+// Entries are evicted least recently used first once the cache exceeds its size.
+type SharedRenderCache struct {
+	maxBytes int64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// recent holds the sharedRenderEntry values, the most recently used first.
+	recent *list.List
+	size   int64
+
+	// capabilities holds the clusterCapabilities of every cluster identity for the capabilitiesTTL.
+	capabilitiesMu  sync.Mutex
+	capabilities    map[string]cachedCapabilities
+	capabilitiesTTL time.Duration
+
+	renders singleflight.Group
+	stats   *internal.CacheStats
+}
+
+type cachedCapabilities struct {
+	capabilities []string
+	fetched      time.Time
+}
+
+type sharedRenderEntry struct {
+	key      string
+	manifest []byte
+	added    time.Time
+}
+
+// NewSharedRenderCache returns a cache holding rendered manifests of up to maxBytes in total.
+func NewSharedRenderCache(maxBytes int64) *SharedRenderCache {
+	cache := &SharedRenderCache{
+		maxBytes:        maxBytes,
+		entries:         make(map[string]*list.Element),
+		recent:          list.New(),
+		capabilities:    make(map[string]cachedCapabilities),
+		capabilitiesTTL: DefaultCapabilitiesTTL,
+	}
+	cache.stats = internal.RegisterCache("shared-rendered-manifests", cache.snapshot)
+	return cache
+}
+
+// WithSharedRenderCache serves the helm and kustomize renders of all objects from the SharedRenderCache before
+// rendering them. Renders are shared if they have the same content, i.e. the Revision or Path of their Spec,
+// the same values, release name and namespace and, for helm, the same version and API groups of their cluster.
+// As helm renders can look up resources of their cluster, they are only shared between clusters if the install
+// action of the client is ClientOnly.
+func WithSharedRenderCache(cache *SharedRenderCache) WithSharedRenderCacheOption {
+	return WithSharedRenderCacheOption{cache: cache}
+}
+
+type WithSharedRenderCacheOption struct {
+	cache *SharedRenderCache
+}
+
+func (o WithSharedRenderCacheOption) Apply(options *Options) {
+	options.SharedRenderCache = o.cache
+}
+
+func (c *SharedRenderCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*sharedRenderEntry).manifest, true
+}
+
+func (c *SharedRenderCache) add(key string, manifest []byte) {
+	if int64(len(manifest)) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, found := c.entries[key]; found {
+		c.size -= int64(len(element.Value.(*sharedRenderEntry).manifest))
+		c.recent.Remove(element)
+	}
+	c.entries[key] = c.recent.PushFront(&sharedRenderEntry{key: key, manifest: manifest, added: time.Now()})
+	c.size += int64(len(manifest))
+	for c.size > c.maxBytes {
+		oldest := c.recent.Back()
+		entry := oldest.Value.(*sharedRenderEntry)
+		c.recent.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.size -= int64(len(entry.manifest))
+	}
+}
+
+func (c *SharedRenderCache) snapshot() internal.CacheSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := internal.CacheSnapshot{Entries: len(c.entries), SizeBytes: c.size}
+	for element := c.recent.Front(); element != nil; element = element.Next() {
+		added := element.Value.(*sharedRenderEntry).added
+		if snapshot.Oldest.IsZero() || added.Before(snapshot.Oldest) {
+			snapshot.Oldest = added
+		}
+	}
+	return snapshot
+}
+
+var errNoCapabilitiesClient = errors.New("no client to determine the cluster capabilities")
+
+// rendererWithSharedCache looks up the render of spec in the SharedRenderCache. The key is derived when rendering,
+// as it needs the capabilities of the cluster, which are not read if the render is served from the ManifestCache.
+type rendererWithSharedCache struct {
+	Renderer
+	cache *SharedRenderCache
+	spec  *Spec
+	clnt  Client
+	// cluster identifies the target cluster of the render, see Reconciler.clusterIdentity.
+	cluster string
+}
+
+func wrapWithSharedRenderCache(
+	renderer Renderer, spec *Spec, clnt Client, cluster string, cache *SharedRenderCache,
+) Renderer {
+	if cache == nil || cache.maxBytes <= 0 {
+		return renderer
+	}
+	return &rendererWithSharedCache{Renderer: renderer, cache: cache, spec: spec, clnt: clnt, cluster: cluster}
+}
+
+func (r *rendererWithSharedCache) Render(ctx context.Context, obj Object) ([]byte, error) {
+	key, err := r.cache.key(r.spec, r.clnt, r.cluster)
+	if err != nil {
+		log.FromContext(ctx).V(internal.DebugLogLevel).Info("render is not shared", "error", err.Error())
+		return r.Renderer.Render(ctx, obj)
+	}
+	// objects whose ResyncAnnotation changed render again and refresh the shared render
+	if manifest, found := r.cache.get(key); found && !resyncRequested(obj) {
+		r.cache.stats.Hit()
+		return manifest, nil
+	}
+	r.cache.stats.Miss()
+	// the render is shared by all waiting objects, so it neither ends with the context of the object that started
+	// it nor changes that object, which may have stopped waiting
+	rendering := obj.DeepCopyObject().(Object)
+	results := r.cache.renders.DoChan(key, func() (any, error) {
+		renderCtx, cancel := detachedContext(ctx)
+		defer cancel()
+		manifest, err := r.Renderer.Render(renderCtx, rendering)
+		if err != nil {
+			return nil, err
+		}
+		r.cache.add(key, manifest)
+		return manifest, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-results:
+		if result.Err != nil {
+			obj.SetStatus(obj.GetStatus().WithState(StateError).WithErr(result.Err))
+			return nil, result.Err
+		}
+		return result.Val.([]byte), nil
+	}
+}
+
+// valuesContext keeps the values of its parent, e.g. the logger, but neither its deadline nor its cancellation.
+type valuesContext struct {
+	context.Context //nolint:containedctx
+}
+
+func (valuesContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (valuesContext) Done() <-chan struct{}       { return nil }
+func (valuesContext) Err() error                  { return nil }
+
+// detachedContext returns a context with the values of ctx that is not canceled with ctx.
+// It is bounded by the time left until the deadline of ctx, if any.
+func detachedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.Context(valuesContext{Context: ctx})
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithTimeout(detached, time.Until(deadline))
+	}
+	return context.WithCancel(detached)
+}
+
+// key identifies a render by its content and all flags it depends on.
+func (c *SharedRenderCache) key(spec *Spec, clnt Client, cluster string) (string, error) {
+	key := struct {
+		Mode         RenderMode
+		Content      string
+		Values       any
+		ReleaseName  string
+		Namespace    string
+		Cluster      string   `json:",omitempty"`
+		InstallFlags any      `json:",omitempty"`
+		Capabilities []string `json:",omitempty"`
+	}{
+		Mode: spec.Mode, Content: spec.Revision, Values: spec.Values, ReleaseName: spec.ReleaseName,
+		InstallFlags: spec.InstallFlags,
+	}
+	if key.Content == "" {
+		key.Content = spec.Path
+	}
+	if clnt != nil {
+		key.Namespace = clnt.Install().Namespace
+	}
+	switch spec.Mode {
+	case RenderModeKustomize:
+		// local kustomizations can change in place, see renderInputHash
+		key.Content = fmt.Sprintf("%s-%s", spec.Path, renderInputHash(spec))
+	case RenderModeHelm:
+		if clnt == nil {
+			return "", errNoCapabilitiesClient
+		}
+		if install := clnt.Install(); install.ClientOnly {
+			key.Capabilities = append([]string{}, install.APIVersions...)
+			if install.KubeVersion != nil {
+				key.Capabilities = append(key.Capabilities, install.KubeVersion.Version)
+			}
+			break
+		}
+		capabilities, err := c.clusterCapabilities(cluster, clnt)
+		if err != nil {
+			return "", err
+		}
+		key.Cluster, key.Capabilities = cluster, capabilities
+	}
+	hashed, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(hashed)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// clusterCapabilities are the version and the API group versions of the cluster of clnt,
+// which are available to helm charts as .Capabilities. They are kept for the capabilitiesTTL per cluster.
+func (c *SharedRenderCache) clusterCapabilities(cluster string, clnt Client) ([]string, error) {
+	c.capabilitiesMu.Lock()
+	cached, found := c.capabilities[cluster]
+	c.capabilitiesMu.Unlock()
+	if found && time.Since(cached.fetched) < c.capabilitiesTTL {
+		return cached.capabilities, nil
+	}
+
+	discoveryClient, err := clnt.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	version, err := discoveryClient.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("reading cluster version: %w", err)
+	}
+	groups, err := discoveryClient.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("reading cluster API groups: %w", err)
+	}
+	capabilities := []string{}
+	for _, group := range groups.Groups {
+		for _, groupVersion := range group.Versions {
+			capabilities = append(capabilities, groupVersion.GroupVersion)
+		}
+	}
+	sort.Strings(capabilities)
+	capabilities = append([]string{version.GitVersion}, capabilities...)
+
+	c.capabilitiesMu.Lock()
+	defer c.capabilitiesMu.Unlock()
+	// capabilities of clusters that are no longer rendered for are dropped once they expired
+	for key, cached := range c.capabilities {
+		if time.Since(cached.fetched) >= c.capabilitiesTTL {
+			delete(c.capabilities, key)
+		}
+	}
+	c.capabilities[cluster] = cachedCapabilities{capabilities: capabilities, fetched: time.Now()}
+	return capabilities, nil
+}
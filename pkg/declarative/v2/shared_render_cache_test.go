@@ -0,0 +1,135 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type countingRenderer struct {
+	Renderer
+	manifest []byte
+	err      error
+	renders  int
+}
+
+func (c *countingRenderer) Render(_ context.Context, _ Object) ([]byte, error) {
+	c.renders++
+	return c.manifest, c.err
+}
+
+func TestSharedRenderCache(t *testing.T) {
+	t.Parallel()
+	cache := NewSharedRenderCache(1 << 10)
+	renderer := &countingRenderer{manifest: []byte("kind: ConfigMap")}
+	render := func(spec *Spec) []byte {
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+		manifest, err := wrapWithSharedRenderCache(renderer, spec, nil, "kyma", cache).Render(context.Background(), obj)
+		require.NoError(t, err)
+		return manifest
+	}
+	spec := func(values map[string]any) *Spec {
+		return &Spec{ManifestName: "keda", Path: "keda", Mode: RenderModeKustomize, Revision: "v1", Values: values}
+	}
+
+	assert.Equal(t, renderer.manifest, render(spec(map[string]any{"replicas": 1})))
+	assert.Equal(t, renderer.manifest, render(spec(map[string]any{"replicas": 1})))
+	assert.Equal(t, 1, renderer.renders, "identical renders of different objects are shared")
+
+	render(spec(map[string]any{"replicas": 2}))
+	assert.Equal(t, 2, renderer.renders, "renders with other values are not shared")
+
+	released := spec(map[string]any{"replicas": 1})
+	released.ReleaseName = "keda-release"
+	render(released)
+	assert.Equal(t, 3, renderer.renders, "renders of other releases are not shared")
+}
+
+func TestSharedRenderCacheEviction(t *testing.T) {
+	t.Parallel()
+	cache := NewSharedRenderCache(10)
+	cache.add("a", []byte("12345"))
+	cache.add("b", []byte("12345"))
+	_, found := cache.get("a")
+	assert.True(t, found)
+
+	cache.add("c", []byte("12345"))
+	_, found = cache.get("b")
+	assert.False(t, found, "the least recently used entry is evicted")
+	_, found = cache.get("a")
+	assert.True(t, found)
+
+	cache.add("d", []byte("12345678901"))
+	_, found = cache.get("d")
+	assert.False(t, found, "manifests larger than the cache are not cached")
+	assert.Equal(t, 2, cache.snapshot().Entries)
+	assert.Equal(t, int64(10), cache.snapshot().SizeBytes)
+}
+
+func TestSharedRenderCacheErrors(t *testing.T) {
+	t.Parallel()
+	cache := NewSharedRenderCache(1 << 10)
+	renderer := &countingRenderer{err: errors.New("render failed")}
+	spec := &Spec{ManifestName: "keda", Path: "keda", Mode: RenderModeKustomize, Revision: "v1"}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+
+	for i := 0; i < 2; i++ {
+		_, err := wrapWithSharedRenderCache(renderer, spec, nil, "kyma", cache).Render(context.Background(), obj)
+		require.ErrorIs(t, err, renderer.err)
+	}
+	assert.Equal(t, 2, renderer.renders, "failed renders are not cached")
+
+	assert.Same(t, Renderer(renderer), wrapWithSharedRenderCache(renderer, spec, nil, "kyma", nil))
+}
+
+type blockingRenderer struct {
+	Renderer
+	started, release chan struct{}
+}
+
+func (b *blockingRenderer) Render(ctx context.Context, _ Object) ([]byte, error) {
+	close(b.started)
+	select {
+	case <-b.release:
+		return []byte("kind: ConfigMap"), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestSharedRenderCacheCancellation(t *testing.T) {
+	t.Parallel()
+	cache := NewSharedRenderCache(1 << 10)
+	renderer := &blockingRenderer{started: make(chan struct{}), release: make(chan struct{})}
+	spec := &Spec{ManifestName: "keda", Path: "keda", Mode: RenderModeKustomize, Revision: "v1"}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+		_, err := wrapWithSharedRenderCache(renderer, spec, nil, "kyma", cache).Render(canceled, obj)
+		first <- err
+	}()
+	<-renderer.started
+
+	second := make(chan []byte)
+	go func() {
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+		manifest, _ := wrapWithSharedRenderCache(renderer, spec, nil, "other-kyma", cache).
+			Render(context.Background(), obj)
+		second <- manifest
+	}()
+
+	cancel()
+	assert.ErrorIs(t, <-first, context.Canceled)
+	close(renderer.release)
+	assert.Equal(t, []byte("kind: ConfigMap"), <-second,
+		"waiting renders are not canceled with the object that started the render")
+}
@@ -2,6 +2,8 @@ package v2
 
 import (
 	"context"
+
+	"github.com/kyma-project/module-manager/pkg/types"
 )
 
 type SpecResolver interface {
@@ -13,6 +15,21 @@ type Spec struct {
 	Path         string
 	Values       any
 	Mode         RenderMode
+	// Revision identifies the rendered input, e.g. the layer digest of an OCI chart.
+	Revision string
+	// Provenance is propagated into the Status of the Object if set.
+	Provenance map[string]string
+	// ReleaseName is the helm release name, it is derived from the ReleaseNameTemplate by the Reconciler.
+	ReleaseName string
+	// GeneratedValues are set in the Values by the Reconciler, see GeneratedValue.
+	GeneratedValues []GeneratedValue
+	// Hash identifies the resolved Spec for Status.SpecHash, it is set by the Reconciler.
+	Hash string
+	// Wait determines how the readiness of the resources is awaited, see WaitStrategy.
+	Wait *WaitStrategy
+	// InstallFlags set fields of the helm install action that renders the Spec,
+	// only the types.SupportedConfigFlags are accepted.
+	InstallFlags types.Flags
 }
 
 func DefaultSpec(path string, values any, mode RenderMode) *CustomSpecFns {
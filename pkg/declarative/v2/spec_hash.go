@@ -0,0 +1,70 @@
+package v2
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// WithSkipUnchangedSpec skips the rendering and apply of ready objects whose generation and resolved Spec did not
+// change since they were applied last, see Status.ObservedGeneration and Status.SpecHash.
+// Skipped objects are not checked for drift in the cluster, so it should only be enabled if resources are not
+// expected to be changed by others or if drift is corrected differently, e.g. by periodically clearing the SpecHash.
+type WithSkipUnchangedSpec bool
+
+func (o WithSkipUnchangedSpec) Apply(options *Options) {
+	options.SkipUnchangedSpec = bool(o)
+}
+
+// specHash identifies the resolved spec. It includes the GeneratedValues, which declare the values to generate,
+// but it is computed before the Reconciler sets the generated values in the Values, so that they are not part of it,
+// just as the ReleaseName. Specs whose Values cannot be marshaled have no hash, so that they are never skipped.
+func specHash(spec *Spec) string {
+	hashed, err := json.Marshal(struct {
+		ManifestName    string
+		Path            string
+		Values          any
+		Mode            RenderMode
+		Revision        string
+		GeneratedValues []GeneratedValue
+		InstallFlags    types.Flags `json:",omitempty"`
+	}{spec.ManifestName, spec.Path, spec.Values, spec.Mode, spec.Revision, spec.GeneratedValues, spec.InstallFlags})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(hashed)
+	return hex.EncodeToString(sum[:])
+}
+
+// withObservedSpec records the generation, the ResyncAnnotation of obj and the hash of spec that are applied.
+func withObservedSpec(status Status, obj Object, spec *Spec) Status {
+	status.ObservedGeneration = obj.GetGeneration()
+	status.SpecHash = spec.Hash
+	status.Resync = obj.GetAnnotations()[ResyncAnnotation]
+	return status
+}
+
+// resyncRequested is true if the ResyncAnnotation of obj changed since its resources were applied last.
+func resyncRequested(obj Object) bool {
+	return obj.GetAnnotations()[ResyncAnnotation] != obj.GetStatus().Resync
+}
+
+// isUnchanged is true if obj is ready and spec was already applied for the current generation of obj.
+func (r *Reconciler) isUnchanged(obj Object, spec *Spec) bool {
+	status := obj.GetStatus()
+	return r.SkipUnchangedSpec && obj.GetDeletionTimestamp().IsZero() && status.State == StateReady &&
+		!resyncRequested(obj) &&
+		!status.Journal.InFlight() && spec.Hash != "" && status.SpecHash == spec.Hash &&
+		status.ObservedGeneration == obj.GetGeneration() &&
+		(r.ReconcilerVersion == "" || status.ReconcilerVersion == r.ReconcilerVersion)
+}
+
+func (r *Reconciler) successResult() (ctrl.Result, error) {
+	if r.CtrlOnSuccessFn != nil {
+		return r.CtrlOnSuccessFn(), nil
+	}
+	return r.CtrlOnSuccess, nil
+}
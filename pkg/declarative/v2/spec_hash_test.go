@@ -0,0 +1,74 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSpecHash(t *testing.T) {
+	t.Parallel()
+	spec := &Spec{ManifestName: "keda", Values: map[string]any{"replicas": 1, "image": "keda"}, Revision: "2.8.1"}
+	reordered := &Spec{ManifestName: "keda", Values: map[string]any{"image": "keda", "replicas": 1}, Revision: "2.8.1"}
+	assert.NotEmpty(t, specHash(spec))
+	assert.Equal(t, specHash(spec), specHash(reordered))
+
+	released := *spec
+	released.ReleaseName = "keda-release"
+	assert.Equal(t, specHash(spec), specHash(&released), "the release name is derived by the reconciler")
+
+	upgraded := *spec
+	upgraded.Revision = "2.9.0"
+	assert.NotEqual(t, specHash(spec), specHash(&upgraded))
+
+	generated := *spec
+	generated.GeneratedValues = []GeneratedValue{
+		{Generator: GeneratorPassword, Secret: "keda", TargetPath: "auth.password"},
+	}
+	assert.NotEqual(t, specHash(spec), specHash(&generated), "added generated values are applied")
+
+	assert.Empty(t, specHash(&Spec{Values: func() {}}), "values that cannot be marshaled have no hash")
+}
+
+func TestIsUnchanged(t *testing.T) {
+	t.Parallel()
+	spec := &Spec{ManifestName: "keda", Revision: "2.8.1"}
+	spec.Hash = specHash(spec)
+	applied := func() *statusObj {
+		obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+		obj.SetGeneration(2)
+		obj.SetStatus(withObservedSpec(Status{State: StateReady}, obj, spec))
+		return obj
+	}
+	r := &Reconciler{Options: &Options{SkipUnchangedSpec: true}}
+
+	assert.True(t, r.isUnchanged(applied(), spec))
+	assert.False(t, (&Reconciler{Options: &Options{}}).isUnchanged(applied(), spec), "skipping is opt-in")
+
+	changedGeneration := applied()
+	changedGeneration.SetGeneration(3)
+	assert.False(t, r.isUnchanged(changedGeneration, spec))
+
+	changedSpec := &Spec{ManifestName: "keda", Revision: "2.9.0"}
+	changedSpec.Hash = specHash(changedSpec)
+	assert.False(t, r.isUnchanged(applied(), changedSpec))
+
+	notReady := applied()
+	notReady.SetStatus(notReady.GetStatus().WithState(StateProcessing))
+	assert.False(t, r.isUnchanged(notReady, spec))
+
+	upgraded := &Reconciler{Options: &Options{SkipUnchangedSpec: true, ReconcilerVersion: "v2"}}
+	assert.False(t, upgraded.isUnchanged(applied(), spec), "objects applied by another version are resynced")
+
+	resynced := applied()
+	resynced.SetAnnotations(map[string]string{ResyncAnnotation: "2026-10-16T12:00:00Z"})
+	assert.False(t, r.isUnchanged(resynced, spec), "a changed resync annotation renders the object again")
+	assert.False(t, isConsistencyCheck(resynced, spec), "a changed resync annotation applies the resources")
+	resynced.SetStatus(withObservedSpec(resynced.GetStatus(), resynced, spec))
+	assert.True(t, r.isUnchanged(resynced, spec), "every resync is applied once")
+	assert.True(t, isConsistencyCheck(resynced, spec))
+}
@@ -2,15 +2,22 @@ package v2
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/kyma-project/module-manager/internal"
 	"github.com/kyma-project/module-manager/pkg/types"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	apimachinerytypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/csaupgrade"
 
 	"k8s.io/cli-runtime/pkg/resource"
 
@@ -18,20 +25,55 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+var ErrFieldOwnershipConflict = errors.New("field ownership conflict")
+
 type SSA interface {
 	Run(context.Context, []*resource.Info) error
 }
 
+// SSAOptions determine how the applier behaves when other field managers co-manage the applied resources.
+type SSAOptions struct {
+	// ForceConflicts takes over the ownership of all conflicting fields from other managers.
+	// If disabled, conflicts are reported as ErrFieldOwnershipConflict and the resource is not changed.
+	ForceConflicts bool
+	// PreviousFieldOwners are managers that changed the resources with client-side apply or update requests before,
+	// e.g. a legacy reconciler. Their fields are merged into the ownership of the applier before applying,
+	// so that fields which are no longer rendered get removed instead of being left behind by the old manager.
+	PreviousFieldOwners []client.FieldOwner
+	// StrictFieldValidation rejects resources with unknown or duplicate fields, e.g. typos in chart templates,
+	// instead of silently dropping them. Resources are applied as rendered without conversion to typed objects,
+	// as the conversion already drops unknown fields.
+	StrictFieldValidation bool
+}
+
+// ErrStrictFieldValidation is returned for resources rejected because of unknown or duplicate fields.
+var ErrStrictFieldValidation = errors.New("strict field validation failed")
+
 type concurrentDefaultSSA struct {
-	clnt      client.Client
-	owner     client.FieldOwner
-	versioner runtime.GroupVersioner
-	converter runtime.ObjectConvertor
+	clnt           client.Client
+	owner          client.FieldOwner
+	force          bool
+	previousOwners sets.Set[string]
+	strict         bool
+	versioner      runtime.GroupVersioner
+	converter      runtime.ObjectConvertor
 }
 
+// ConcurrentSSA applies resources with the owner and forces the ownership of conflicting fields.
 func ConcurrentSSA(clnt client.Client, owner client.FieldOwner) SSA {
+	return NewConcurrentSSA(clnt, owner, SSAOptions{ForceConflicts: true})
+}
+
+func NewConcurrentSSA(clnt client.Client, owner client.FieldOwner, opts SSAOptions) SSA {
+	previousOwners := sets.New[string]()
+	for _, previousOwner := range opts.PreviousFieldOwners {
+		if previousOwner != owner {
+			previousOwners.Insert(string(previousOwner))
+		}
+	}
 	return &concurrentDefaultSSA{
-		clnt: clnt, owner: owner,
+		clnt: clnt, owner: owner, force: opts.ForceConflicts, previousOwners: previousOwners,
+		strict:    opts.StrictFieldValidation,
 		versioner: schema.GroupVersions(clnt.Scheme().PrioritizedVersionsAllGroups()),
 		converter: clnt.Scheme(),
 	}
@@ -49,15 +91,19 @@ func (c *concurrentDefaultSSA) Run(ctx context.Context, resources []*resource.In
 		go c.serverSideApply(ctx, resources[i], results)
 	}
 
+	collected, err := collectResults(ctx, "ssa", results, len(resources))
+	ssaFinish := time.Since(ssaStart)
+	if err != nil {
+		return fmt.Errorf("ServerSideApply aborted (after %s): %w", ssaFinish, err)
+	}
+
 	var errs []error
-	for i := 0; i < len(resources); i++ {
-		if err := <-results; err != nil {
+	for _, err := range collected {
+		if err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	ssaFinish := time.Since(ssaStart)
-
 	if errs != nil {
 		return fmt.Errorf("ServerSideApply failed (after %s): %w", ssaFinish, types.NewMultiError(errs))
 	}
@@ -73,14 +119,17 @@ func (c *concurrentDefaultSSA) serverSideApply(
 	start := time.Now()
 	logger := log.FromContext(ctx, "owner", c.owner)
 
-	// this converts unstructured to typed objects if possible, leveraging native APIs
-	resource.Object = c.convertUnstructuredToTyped(resource.Object, resource.Mapping)
-
 	logger.V(internal.TraceLogLevel).Info(
 		fmt.Sprintf("apply %s", resource.ObjectName()),
 	)
 
-	results <- c.serverSideApplyResourceInfo(ctx, resource)
+	results <- withPanicRecovery("ssa", func() error {
+		// this converts unstructured to typed objects if possible, leveraging native APIs
+		if !c.strict {
+			resource.Object = c.convertUnstructuredToTyped(resource.Object, resource.Mapping)
+		}
+		return c.serverSideApplyResourceInfo(ctx, resource)
+	})
 
 	logger.V(internal.TraceLogLevel).Info(
 		fmt.Sprintf("apply %s finished", resource.ObjectName()),
@@ -100,7 +149,29 @@ func (c *concurrentDefaultSSA) serverSideApplyResourceInfo(
 		)
 	}
 
-	err := c.clnt.Patch(ctx, obj, client.Apply, client.ForceOwnership, c.owner)
+	if err := c.migrateFieldOwnership(ctx, obj); err != nil {
+		return fmt.Errorf("field ownership migration for %s failed: %w", info.ObjectName(), err)
+	}
+
+	opts := []client.PatchOption{c.owner}
+	if c.force {
+		opts = append(opts, client.ForceOwnership)
+	}
+	if c.strict {
+		opts = append(opts, &client.PatchOptions{Raw: &metav1.PatchOptions{FieldValidation: metav1.FieldValidationStrict}})
+	}
+
+	err := internal.InjectFault(ctx, internal.FaultPointServerSideApply)
+	if err == nil {
+		err = c.clnt.Patch(ctx, obj, client.Apply, opts...)
+	}
+	if apierrors.IsConflict(err) && !c.force {
+		return fieldOwnershipConflict(info.ObjectName(), err)
+	}
+	if c.strict && isStrictDecodingError(err) {
+		return types.NewClassifiedError(types.ErrRenderFailed,
+			fmt.Errorf("%w for %s: %s", ErrStrictFieldValidation, info.ObjectName(), err.Error()))
+	}
 	if err != nil {
 		return fmt.Errorf(
 			"patch for %s failed: %w", info.ObjectName(), err,
@@ -110,6 +181,47 @@ func (c *concurrentDefaultSSA) serverSideApplyResourceInfo(
 	return nil
 }
 
+// migrateFieldOwnership merges the fields managed by previous owners into the fields of the applier.
+// It costs an additional request per resource and should only be configured until all resources are migrated.
+func (c *concurrentDefaultSSA) migrateFieldOwnership(ctx context.Context, obj client.Object) error {
+	if c.previousOwners.Len() == 0 {
+		return nil
+	}
+	live := &metav1.PartialObjectMetadata{}
+	live.SetGroupVersionKind(obj.GetObjectKind().GroupVersionKind())
+	if err := c.clnt.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	patch, err := csaupgrade.UpgradeManagedFieldsPatch(live, c.previousOwners, string(c.owner))
+	if err != nil || patch == nil {
+		return err
+	}
+	return c.clnt.Patch(ctx, live, client.RawPatch(apimachinerytypes.JSONPatchType, patch))
+}
+
+// fieldOwnershipConflict lists the conflicting fields and their managers reported by the API server.
+func fieldOwnershipConflict(name string, err error) error {
+	var conflicts []string
+	var statusErr apierrors.APIStatus
+	if errors.As(err, &statusErr) && statusErr.Status().Details != nil {
+		for _, cause := range statusErr.Status().Details.Causes {
+			if cause.Type == metav1.CauseTypeFieldManagerConflict {
+				conflicts = append(conflicts, cause.Message)
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		conflicts = append(conflicts, err.Error())
+	}
+	return types.NewClassifiedError(types.ErrResourceConflict,
+		fmt.Errorf("%w for %s: %s", ErrFieldOwnershipConflict, name, strings.Join(conflicts, ", ")))
+}
+
+// isStrictDecodingError determines if err is the rejection of unknown or duplicate fields by the API server.
+func isStrictDecodingError(err error) bool {
+	return apierrors.IsBadRequest(err) && strings.Contains(err.Error(), "strict decoding error")
+}
+
 // convertWithMapper converts the given object with the optional provided
 // RESTMapping. If no mapping is provided, the default schema versioner is used.
 func (c *concurrentDefaultSSA) convertUnstructuredToTyped(
@@ -0,0 +1,67 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFieldOwnershipConflict(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	err := fieldOwnershipConflict("default/config", apierrors.NewApplyConflict([]metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Message: `conflict with "legacy-reconciler": .data.key`,
+		Field:   ".data.key",
+	}}, "Apply failed with 1 conflict"))
+
+	assertions.ErrorIs(err, ErrFieldOwnershipConflict)
+	assertions.Contains(err.Error(), `conflict with "legacy-reconciler": .data.key`)
+}
+
+func TestMigrateFieldOwnership(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	ctx := context.Background()
+
+	configMap := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "config", Namespace: metav1.NamespaceDefault,
+		ManagedFields: []metav1.ManagedFieldsEntry{{
+			Manager:    "legacy-reconciler",
+			Operation:  metav1.ManagedFieldsOperationUpdate,
+			APIVersion: "v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:key":{}}}`)},
+		}},
+	}, Data: map[string]string{"key": "value"}}
+	clnt := fake.NewClientBuilder().WithObjects(configMap).Build()
+
+	applier := NewConcurrentSSA(clnt, "declarative", SSAOptions{
+		PreviousFieldOwners: []client.FieldOwner{"legacy-reconciler"},
+	}).(*concurrentDefaultSSA)
+	desired := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: metav1.NamespaceDefault}}
+	desired.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ConfigMap"))
+	assertions.NoError(applier.migrateFieldOwnership(ctx, desired))
+
+	migrated := &v1.ConfigMap{}
+	assertions.NoError(clnt.Get(ctx, client.ObjectKeyFromObject(configMap), migrated))
+	var managers []string
+	for _, entry := range migrated.GetManagedFields() {
+		managers = append(managers, entry.Manager)
+	}
+	assertions.Equal([]string{"declarative"}, managers)
+
+	missing := desired.DeepCopy()
+	missing.SetName("missing")
+	assertions.NoError(applier.migrateFieldOwnership(ctx, missing), "missing resources need no migration")
+}
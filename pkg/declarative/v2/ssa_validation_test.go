@@ -0,0 +1,52 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// strictValidatingClient rejects all patches like an API server rejecting unknown fields.
+type strictValidatingClient struct {
+	client.Client
+	patched client.Object
+	options *client.PatchOptions
+}
+
+func (c *strictValidatingClient) Patch(
+	_ context.Context, obj client.Object, _ client.Patch, opts ...client.PatchOption,
+) error {
+	c.patched = obj
+	c.options = (&client.PatchOptions{}).ApplyOptions(opts)
+	return apierrors.NewBadRequest(`Deployment in version "v1" cannot be handled as a Deployment: ` +
+		`strict decoding error: unknown field "spec.replica"`)
+}
+
+func TestStrictFieldValidation(t *testing.T) {
+	t.Parallel()
+	clnt := &strictValidatingClient{Client: fake.NewClientBuilder().Build()}
+	applier := NewConcurrentSSA(clnt, "declarative", SSAOptions{
+		ForceConflicts: true, StrictFieldValidation: true,
+	})
+	deployment := renderedObject("Deployment", "keda", map[string]any{"replica": int64(2)})
+	deployment.SetAPIVersion("apps/v1")
+
+	err := applier.Run(context.Background(), []*resource.Info{{Name: "keda", Namespace: "keda", Object: deployment}})
+	require.ErrorIs(t, err, ErrStrictFieldValidation)
+	assert.ErrorIs(t, err, types.ErrRenderFailed)
+	assert.IsType(t, &unstructured.Unstructured{}, clnt.patched, "rendered resources are not converted to typed")
+	require.NotNil(t, clnt.options.Raw)
+	assert.Equal(t, metav1.FieldValidationStrict, clnt.options.Raw.FieldValidation)
+}
@@ -0,0 +1,124 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// DefaultVersionSuffixPattern matches versions that charts put into generated names, e.g. the "-2.8.1" of
+// "keda-2.8.1-operator", "-v1.2.3" or "-1-2-3-rc.1".
+const DefaultVersionSuffixPattern = `-v?\d+[.-]\d+[.-]\d+(-(alpha|beta|rc)[.-]?\d*)?`
+
+var ErrStableNameCollision = errors.New("stable names collide")
+
+// versionSuffix detects resources that are replaced by a resource with another version in its name on upgrades.
+var versionSuffix = regexp.MustCompile(DefaultVersionSuffixPattern) //nolint:gochecknoglobals
+
+// WithStableNames removes the matches of patterns from the names of all rendered resources, so that charts
+// deriving names from their version do not replace their resources on every upgrade. Without patterns,
+// the DefaultVersionSuffixPattern is removed. String values equal to a renamed name, e.g. references of
+// workloads to their ConfigMaps or subjects of RoleBindings, are renamed as well.
+func WithStableNames(patterns ...*regexp.Regexp) PostRenderTransformOption {
+	if len(patterns) == 0 {
+		patterns = []*regexp.Regexp{versionSuffix}
+	}
+	return WithPostRenderTransform(stableNamesTransform(patterns))
+}
+
+func stableNamesTransform(patterns []*regexp.Regexp) ObjectTransform {
+	return func(_ context.Context, _ Object, resources []*unstructured.Unstructured) error {
+		renamed := map[string]string{}
+		stable := map[string]string{}
+		for _, resource := range resources {
+			name := resource.GetName()
+			stableName := name
+			for _, pattern := range patterns {
+				stableName = pattern.ReplaceAllString(stableName, "")
+			}
+			key := resourceNameKey(resource, stableName)
+			if existing, found := stable[key]; found && existing != name {
+				return fmt.Errorf("%w: %s and %s are both named %s", ErrStableNameCollision, existing, name, stableName)
+			}
+			stable[key] = name
+			if stableName != name && stableName != "" {
+				renamed[name] = stableName
+			}
+		}
+		if len(renamed) == 0 {
+			return nil
+		}
+		for _, resource := range resources {
+			resource.Object = renameValues(resource.Object, renamed).(map[string]any)
+		}
+		return nil
+	}
+}
+
+func resourceNameKey(resource *unstructured.Unstructured, name string) string {
+	return strings.Join([]string{resource.GroupVersionKind().GroupKind().String(), resource.GetNamespace(), name}, "/")
+}
+
+// renameValues replaces all string values equal to a key of renamed, map keys are kept.
+func renameValues(value any, renamed map[string]string) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			typed[key] = renameValues(nested, renamed)
+		}
+	case []any:
+		for i, nested := range typed {
+			typed[i] = renameValues(nested, renamed)
+		}
+	case string:
+		if stableName, found := renamed[typed]; found {
+			return stableName
+		}
+	}
+	return value
+}
+
+// replacedResources pairs the removed resources with the added resources of the same kind and namespace whose
+// names only differ in their version, e.g. a Deployment that is recreated under a new name on every upgrade.
+func replacedResources(removed, target []*resource.Info) []string {
+	added := map[string]string{}
+	for _, info := range target {
+		key := infoNameKey(info, versionSuffix.ReplaceAllString(info.Name, ""))
+		added[key] = info.Name
+	}
+	var replaced []string
+	for _, info := range removed {
+		stableName := versionSuffix.ReplaceAllString(info.Name, "")
+		if stableName == info.Name {
+			continue
+		}
+		if replacement, found := added[infoNameKey(info, stableName)]; found && replacement != info.Name {
+			replaced = append(replaced, fmt.Sprintf("%s %s by %s",
+				info.Object.GetObjectKind().GroupVersionKind().Kind, info.Name, replacement))
+		}
+	}
+	sort.Strings(replaced)
+	return replaced
+}
+
+func infoNameKey(info *resource.Info, name string) string {
+	return strings.Join([]string{
+		info.Object.GetObjectKind().GroupVersionKind().GroupKind().String(), info.Namespace, name,
+	}, "/")
+}
+
+// warnReplacedResources reports resources that are deleted and recreated under another name on upgrades,
+// which loses their state, e.g. the volumes of StatefulSets, and can be prevented with WithStableNames.
+func (r *Reconciler) warnReplacedResources(obj Object, removed, target []*resource.Info) {
+	if replaced := replacedResources(removed, target); len(replaced) > 0 {
+		r.Event(obj, "Warning", "ResourcesReplaced", fmt.Sprintf(
+			"upgrade replaces resources with versioned names, consider stable names: %s",
+			strings.Join(replaced, ", ")))
+	}
+}
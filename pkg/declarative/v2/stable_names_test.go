@@ -0,0 +1,60 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func renderedObject(kind, name string, spec map[string]any) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{"apiVersion": "v1", "kind": kind, "spec": spec}}
+	obj.SetName(name)
+	obj.SetNamespace("keda")
+	return obj
+}
+
+func TestStableNamesTransform(t *testing.T) {
+	t.Parallel()
+	configMap := renderedObject("ConfigMap", "keda-2.8.1-config", nil)
+	pod := renderedObject("Pod", "keda-v2.8.1-rc.1", map[string]any{
+		"volumes": []any{map[string]any{"configMap": map[string]any{"name": "keda-2.8.1-config"}}},
+		"image":   "keda:2.8.1",
+	})
+	service := renderedObject("Service", "keda-metrics", nil)
+
+	transform := WithStableNames().ObjectTransforms[0]
+	require.NoError(t, transform(context.Background(), nil, []*unstructured.Unstructured{configMap, pod, service}))
+	assert.Equal(t, "keda-config", configMap.GetName())
+	assert.Equal(t, "keda", pod.GetName())
+	assert.Equal(t, "keda-metrics", service.GetName())
+	volumes, _, _ := unstructured.NestedSlice(pod.Object, "spec", "volumes")
+	assert.Equal(t, "keda-config", volumes[0].(map[string]any)["configMap"].(map[string]any)["name"],
+		"references are renamed")
+	image, _, _ := unstructured.NestedString(pod.Object, "spec", "image")
+	assert.Equal(t, "keda:2.8.1", image, "values that are no renamed names are kept")
+
+	colliding := WithStableNames(regexp.MustCompile(`-\d+$`)).ObjectTransforms[0]
+	err := colliding(context.Background(), nil, []*unstructured.Unstructured{
+		renderedObject("ConfigMap", "keda-1", nil), renderedObject("ConfigMap", "keda-2", nil),
+	})
+	assert.ErrorIs(t, err, ErrStableNameCollision)
+}
+
+func TestReplacedResources(t *testing.T) {
+	t.Parallel()
+	info := func(kind, name string) *resource.Info {
+		return &resource.Info{Name: name, Namespace: "keda", Object: renderedObject(kind, name, nil)}
+	}
+	removed := []*resource.Info{info("StatefulSet", "keda-2.8.1"), info("ConfigMap", "keda-2.8.1"), info("Secret", "old")}
+	target := []*resource.Info{info("StatefulSet", "keda-2.9.0"), info("Secret", "new"), info("ConfigMap", "other")}
+
+	assert.Equal(t, []string{"StatefulSet keda-2.8.1 by keda-2.9.0"}, replacedResources(removed, target))
+}
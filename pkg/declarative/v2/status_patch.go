@@ -0,0 +1,41 @@
+package v2
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+type observedStatusKey struct{}
+
+// withObservedStatus returns a copy of ctx carrying a copy of the status obj was read with, so that all
+// status and condition updates of the reconciliation are compared against it and applied in a single patch.
+func withObservedStatus(ctx context.Context, obj Object) context.Context {
+	status := obj.GetStatus()
+	return context.WithValue(ctx, observedStatusKey{}, status.DeepCopy())
+}
+
+func observedStatusFrom(ctx context.Context) (*Status, bool) {
+	observed, found := ctx.Value(observedStatusKey{}).(*Status)
+	return observed, found
+}
+
+// isStatusUnchanged is true if status equals the status the object was read with, apart from the update time
+// of an unchanged last operation. Applying such a status would only rewrite the object in etcd.
+func isStatusUnchanged(ctx context.Context, status Status) bool {
+	observed, found := observedStatusFrom(ctx)
+	if !found {
+		return false
+	}
+	if observed.LastOperation.Operation == status.LastOperation.Operation {
+		status.LastOperation.LastUpdateTime = observed.LastOperation.LastUpdateTime
+	}
+	return equality.Semantic.DeepEqual(*observed, status)
+}
+
+// conditionsChanged is true if the conditions of status were updated during the reconciliation,
+// e.g. by the ReadyCheck or the drift detection, and have to be applied although the resources did not change.
+func conditionsChanged(ctx context.Context, status Status) bool {
+	observed, found := observedStatusFrom(ctx)
+	return !found || !equality.Semantic.DeepEqual(observed.Conditions, status.Conditions)
+}
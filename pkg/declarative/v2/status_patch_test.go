@@ -0,0 +1,55 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObservedStatus(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetStatus(Status{
+		State: StateProcessing, Synced: []Resource{},
+		Conditions: []metav1.Condition{{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady"}},
+	}.WithOperation("waiting"))
+	assertions.False(isStatusUnchanged(context.Background(), obj.GetStatus()))
+	assertions.True(conditionsChanged(context.Background(), obj.GetStatus()))
+
+	ctx := withObservedStatus(context.Background(), obj)
+	assertions.True(isStatusUnchanged(ctx, obj.GetStatus().WithOperation("waiting")),
+		"repeating the last operation does not change the status")
+	assertions.False(isStatusUnchanged(ctx, obj.GetStatus().WithOperation("still waiting")))
+	assertions.False(isStatusUnchanged(ctx, obj.GetStatus().WithState(StateError)))
+
+	status := obj.GetStatus()
+	meta.SetStatusCondition(&status.Conditions,
+		metav1.Condition{Type: "Ready", Status: metav1.ConditionFalse, Reason: "NotReady", Message: "1 of 2"})
+	assertions.True(conditionsChanged(ctx, status), "condition updates are compared against a copy")
+	assertions.False(isStatusUnchanged(ctx, status))
+}
+
+func TestSsaStatusSkipsUnchangedStatus(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetStatus(Status{State: StateProcessing}.WithErr(errors.New("not ready")))
+	ctx := withObservedStatus(context.Background(), obj)
+	reconciler := &Reconciler{Options: &Options{Client: fake.NewClientBuilder().Build()}}
+
+	result, err := reconciler.ssaStatus(ctx, obj)
+	assertions.NoError(err, "the status is not applied to the object missing in the fake client")
+	assertions.True(result.Requeue)
+}
@@ -0,0 +1,234 @@
+// Package scale_test contains the scale benchmark of the reconcile pipeline. It reconciles a configurable number of
+// synthetic objects in an envtest control plane and installs their charts into a second envtest acting as the
+// target cluster, so that the render and apply path is measured against real API servers. It only runs with
+// `make benchmark` or `go test -run=^$ -bench=. ./pkg/declarative/v2/test/scale` and KUBEBUILDER_ASSETS set.
+package scale_test
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	declarative "github.com/kyma-project/module-manager/pkg/declarative/v2"
+	testv1 "github.com/kyma-project/module-manager/pkg/declarative/v2/test/v1"
+	"github.com/kyma-project/module-manager/pkg/types"
+	"golang.org/x/time/rate"
+	v1 "k8s.io/api/core/v1"
+	apiExtensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+)
+
+const benchRunLabel = "declarative.kyma-project.io/bench-run"
+
+//nolint:gochecknoglobals
+var (
+	manifests = flag.Int("scale.manifests", 50, "number of objects reconciled per benchmark iteration")
+	workers   = flag.Int("scale.workers", 10, "number of concurrent reconciles")
+	timeout   = flag.Duration("scale.timeout", 5*time.Minute, "maximum duration of a benchmark iteration")
+
+	root = filepath.Join("..", "..", "..", "..", "..")
+)
+
+// countingTransport counts the requests against an API server.
+type countingTransport struct {
+	http.RoundTripper
+	requests *atomic.Int64
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return t.RoundTripper.RoundTrip(req)
+}
+
+func countRequests(cfg *rest.Config, requests *atomic.Int64) *rest.Config {
+	counted := rest.CopyConfig(cfg)
+	counted.QPS, counted.Burst = -1, -1
+	counted.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &countingTransport{RoundTripper: rt, requests: requests}
+	})
+	return counted
+}
+
+func startEnv(b *testing.B, crds ...*apiExtensionsv1.CustomResourceDefinition) *rest.Config {
+	b.Helper()
+	env := &envtest.Environment{CRDs: crds, Scheme: scheme.Scheme}
+	cfg, err := env.Start()
+	if err != nil {
+		b.Fatalf("starting envtest: %v", err)
+	}
+	b.Cleanup(func() { _ = env.Stop() })
+	return cfg
+}
+
+func BenchmarkReconcile(b *testing.B) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		b.Skip("KUBEBUILDER_ASSETS is required to start the control plane, use make benchmark")
+	}
+	if err := testv1.AddToScheme(scheme.Scheme); err != nil {
+		b.Fatal(err)
+	}
+	testAPICRD := &apiExtensionsv1.CustomResourceDefinition{}
+	testAPICRDRaw, err := os.ReadFile(
+		filepath.Join(root, "config", "crd", "bases", "test.declarative.kyma-project.io_testapis.yaml"),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := yaml.Unmarshal(testAPICRDRaw, testAPICRD); err != nil {
+		b.Fatal(err)
+	}
+
+	var kcpRequests, skrRequests atomic.Int64
+	kcpCfg := countRequests(startEnv(b, testAPICRD), &kcpRequests)
+	skrCfg := countRequests(startEnv(b), &skrRequests)
+	kcpClient, err := client.New(kcpCfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		b.Fatal(err)
+	}
+	skrClient, err := client.New(skrCfg, client.Options{Scheme: scheme.Scheme})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runID := rand.String(4)
+	startReconciler(ctx, b, runID, kcpCfg, &types.ClusterInfo{Config: skrCfg, Client: skrClient})
+
+	namespace := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bench-" + runID}}
+	if err := kcpClient.Create(ctx, namespace); err != nil {
+		b.Fatal(err)
+	}
+
+	var elapsed time.Duration
+	var allocated uint64
+	kcpRequests.Store(0)
+	skrRequests.Store(0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+
+		objs := make([]*testv1.TestAPI, 0, *manifests)
+		for j := 0; j < *manifests; j++ {
+			obj := &testv1.TestAPI{}
+			obj.SetName(fmt.Sprintf("bench-%d-%d", i, j))
+			obj.SetNamespace(namespace.GetName())
+			obj.SetLabels(labels.Set{benchRunLabel: runID})
+			if err := kcpClient.Create(ctx, obj); err != nil {
+				b.Fatal(err)
+			}
+			objs = append(objs, obj)
+		}
+		if err := awaitReady(ctx, kcpClient, objs); err != nil {
+			b.Fatal(err)
+		}
+
+		elapsed += time.Since(start)
+		runtime.ReadMemStats(&after)
+		allocated += after.TotalAlloc - before.TotalAlloc
+	}
+	b.StopTimer()
+
+	reconciled := float64(b.N * *manifests)
+	b.ReportMetric(reconciled/elapsed.Seconds(), "manifests/s")
+	b.ReportMetric(float64(allocated)/reconciled, "B/manifest")
+	b.ReportMetric(float64(kcpRequests.Load())/reconciled, "kcp-requests/manifest")
+	b.ReportMetric(float64(skrRequests.Load())/reconciled, "skr-requests/manifest")
+}
+
+func startReconciler(
+	ctx context.Context, b *testing.B, runID string, cfg *rest.Config, skr *types.ClusterInfo,
+) {
+	b.Helper()
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		HealthProbeBindAddress: "0",
+		MetricsBindAddress:     "0",
+		Scheme:                 scheme.Scheme,
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	chart := filepath.Join(root, "pkg", "test_samples", "module-chart")
+	reconciler := declarative.NewFromManager(
+		mgr, &testv1.TestAPI{},
+		declarative.WithSpecResolver(&declarative.CustomSpecFns{
+			// every object is its own release, so that the rendered resources do not collide
+			ManifestNameFn: func(_ context.Context, obj declarative.Object) string { return obj.GetName() },
+			PathFn:         func(_ context.Context, _ declarative.Object) string { return chart },
+			ValuesFn:       func(_ context.Context, _ declarative.Object) any { return map[string]any{} },
+			ModeFn: func(_ context.Context, _ declarative.Object) declarative.RenderMode {
+				return declarative.RenderModeHelm
+			},
+		}),
+		declarative.WithRemoteTargetCluster(func(context.Context, declarative.Object) (*types.ClusterInfo, error) {
+			return skr, nil
+		}),
+		declarative.WithNamespace("bench-"+runID, true),
+		declarative.WithManifestCache(b.TempDir()),
+		// envtest does not run controllers, so resources are ready once they exist.
+		declarative.WithCustomReadyCheck(declarative.NewExistsReadyCheck()),
+		declarative.WithCustomResourceLabels(labels.Set{benchRunLabel: runID}),
+	)
+
+	runPredicate, err := predicate.LabelSelectorPredicate(
+		metav1.LabelSelector{MatchLabels: labels.Set{benchRunLabel: runID}},
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := ctrl.NewControllerManagedBy(mgr).WithEventFilter(runPredicate).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: *workers,
+			RateLimiter: workqueue.NewMaxOfRateLimiter(
+				&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Inf, 0)},
+			),
+		}).
+		For(&testv1.TestAPI{}).Complete(reconciler); err != nil {
+		b.Fatal(err)
+	}
+	go func() {
+		if err := mgr.Start(ctx); err != nil {
+			b.Error(err)
+		}
+	}()
+}
+
+func awaitReady(ctx context.Context, clnt client.Client, objs []*testv1.TestAPI) error {
+	deadline := time.Now().Add(*timeout)
+	for _, obj := range objs {
+		for {
+			if err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+			if obj.GetStatus().State == declarative.StateReady {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("%s is %s after %s", obj.GetName(), obj.GetStatus().State, *timeout)
+			}
+			time.Sleep(50 * time.Millisecond) //nolint:gomnd
+		}
+	}
+	return nil
+}
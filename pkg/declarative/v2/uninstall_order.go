@@ -0,0 +1,34 @@
+package v2
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// uninstallPhases orders resources for their deletion during an uninstallation. Custom resources of the
+// CustomResourceDefinitions in infos are deleted first, while the operator handling their finalizers still runs.
+// All other resources, e.g. the operator itself, follow once the custom resources are gone.
+// CustomResourceDefinitions are deleted last, as their deletion removes all remaining custom resources
+// without waiting for the operator.
+func uninstallPhases(infos []*resource.Info) [][]*resource.Info {
+	crdGroupKind := apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition").GroupKind()
+	crdNames := make(map[string]bool)
+	for _, info := range infos {
+		if info.Object.GetObjectKind().GroupVersionKind().GroupKind() == crdGroupKind {
+			crdNames[info.Name] = true
+		}
+	}
+
+	var customResources, others, crds []*resource.Info
+	for _, info := range infos {
+		switch {
+		case info.Object.GetObjectKind().GroupVersionKind().GroupKind() == crdGroupKind:
+			crds = append(crds, info)
+		case info.Mapping != nil && crdNames[info.Mapping.Resource.GroupResource().String()]:
+			customResources = append(customResources, info)
+		default:
+			others = append(others, info)
+		}
+	}
+	return [][]*resource.Info{customResources, others, crds}
+}
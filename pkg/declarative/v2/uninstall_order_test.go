@@ -0,0 +1,47 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+func mappedInfo(gvr schema.GroupVersionResource, kind, name string) *resource.Info {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvr.GroupVersion().WithKind(kind))
+	obj.SetName(name)
+	return &resource.Info{Name: name, Object: obj, Mapping: &meta.RESTMapping{Resource: gvr}}
+}
+
+func TestUninstallPhases(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	crd := mappedInfo(schema.GroupVersionResource{
+		Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions",
+	}, "CustomResourceDefinition", "scaledobjects.keda.sh")
+	scaledObject := mappedInfo(schema.GroupVersionResource{
+		Group: "keda.sh", Version: "v1alpha1", Resource: "scaledobjects",
+	}, "ScaledObject", "default")
+	triggerAuth := mappedInfo(schema.GroupVersionResource{
+		Group: "keda.sh", Version: "v1alpha1", Resource: "triggerauthentications",
+	}, "TriggerAuthentication", "default")
+	operator := mappedInfo(schema.GroupVersionResource{
+		Group: "apps", Version: "v1", Resource: "deployments",
+	}, "Deployment", "keda-operator")
+	unmapped := configMapInfo("config")
+
+	phases := uninstallPhases([]*resource.Info{crd, operator, scaledObject, unmapped, triggerAuth})
+	assertions.Equal([][]*resource.Info{
+		{scaledObject},
+		{operator, unmapped, triggerAuth},
+		{crd},
+	}, phases, "custom resources of CRDs that are not part of the install are deleted with the operator")
+}
@@ -0,0 +1,31 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestUninstallSpec(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+
+	recorder := record.NewFakeRecorder(2)
+	reconciler := &Reconciler{Options: &Options{EventRecorder: recorder}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("manifest")
+
+	spec := reconciler.uninstallSpec(obj, errors.New("registry unreachable"))
+	assertions.Equal(&Spec{ManifestName: "manifest", Mode: RenderModeRaw}, spec)
+	assertions.Contains(<-recorder.Events, "registry unreachable")
+
+	obj.SetStatus(Status{Installs: []InstallStatus{{Name: "keda", Revision: "2.8.1"}}})
+	spec = reconciler.uninstallSpec(obj, errors.New("registry unreachable"))
+	assertions.Equal(&Spec{ManifestName: "keda", Revision: "2.8.1", Mode: RenderModeRaw}, spec)
+}
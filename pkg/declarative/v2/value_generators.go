@@ -0,0 +1,300 @@
+package v2
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/labels"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ValueGenerator generates the data of a GeneratedValue.
+// +kubebuilder:validation:Enum=password;rsa-key;self-signed-cert
+type ValueGenerator string
+
+const (
+	// GeneratorPassword generates a random alphanumeric password of Length characters at the TargetPath.
+	GeneratorPassword ValueGenerator = "password"
+	// GeneratorRSAKey generates an RSA key of Length bits, the TargetPath is set to a map with the PEM encoded
+	// privateKey and publicKey.
+	GeneratorRSAKey ValueGenerator = "rsa-key"
+	// GeneratorSelfSignedCert generates a self-signed certificate for the DNSNames, the TargetPath is set to a map
+	// with the PEM encoded certificate and privateKey.
+	GeneratorSelfSignedCert ValueGenerator = "self-signed-cert"
+)
+
+const (
+	DefaultPasswordLength = 32
+	DefaultRSAKeyBits     = 2048
+	// DefaultCertValidity is the validity of self-signed certificates, they are not rotated once generated.
+	DefaultCertValidity = 10 * 365 * 24 * time.Hour
+
+	// GeneratedValueLabel marks the Secrets storing GeneratedValues, its value is the name of the generating object.
+	GeneratedValueLabel = "declarative.kyma-project.io/generated-value-of"
+
+	passwordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+var (
+	ErrUnknownValueGenerator        = errors.New("unknown value generator")
+	ErrGeneratedValuesSecretForeign = errors.New("secret is not a generated values secret of the object")
+)
+
+// +k8s:deepcopy-gen=true
+// GeneratedValue is generated once into a Secret of the target cluster and set in the values of every render,
+// so that charts do not need to generate values, e.g. with randAlphaNum, that change with every render.
+// The Secret is kept when the object is deleted, so that reinstalls reuse the values, e.g. for retained volumes.
+type GeneratedValue struct {
+	// Generator is one of "password", "rsa-key" or "self-signed-cert".
+	Generator ValueGenerator `json:"generator"`
+
+	// Secret is the name of the Secret in the namespace of the install storing the generated value.
+	// Several generated values can share a Secret if their Keys differ.
+	Secret string `json:"secret"`
+
+	// Key is the key of the generated value in the Secret, it defaults to the Generator.
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// TargetPath is the dot separated path of the value that is set, e.g. "database.password".
+	TargetPath string `json:"targetPath"`
+
+	// Length is the number of characters of passwords or the bits of RSA keys.
+	// +optional
+	Length int `json:"length,omitempty"`
+
+	// DNSNames are the subject alternative names of self-signed certificates, the first one is the common name.
+	// +optional
+	DNSNames []string `json:"dnsNames,omitempty"`
+}
+
+func (g GeneratedValue) key() string {
+	if g.Key != "" {
+		return g.Key
+	}
+	return string(g.Generator)
+}
+
+// generate returns the data stored in the Secret, which is a PEM bundle for keys and certificates.
+func (g GeneratedValue) generate() ([]byte, error) {
+	switch g.Generator {
+	case GeneratorPassword:
+		length := g.Length
+		if length <= 0 {
+			length = DefaultPasswordLength
+		}
+		return generatePassword(length)
+	case GeneratorRSAKey:
+		bits := g.Length
+		if bits <= 0 {
+			bits = DefaultRSAKeyBits
+		}
+		key, err := rsa.GenerateKey(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), nil
+	case GeneratorSelfSignedCert:
+		return generateSelfSignedCert(g.DNSNames)
+	}
+	return nil, fmt.Errorf("%w %q for %s", ErrUnknownValueGenerator, g.Generator, g.TargetPath)
+}
+
+// value converts the stored data into the value set at the TargetPath.
+func (g GeneratedValue) value(data []byte) (any, error) {
+	if g.Generator == GeneratorPassword {
+		return string(data), nil
+	}
+	blocks := map[string][]byte{}
+	for rest := data; ; {
+		var block *pem.Block
+		if block, rest = pem.Decode(rest); block == nil {
+			break
+		}
+		blocks[block.Type] = pem.EncodeToMemory(block)
+	}
+	key, found := blocks["RSA PRIVATE KEY"]
+	if !found {
+		return nil, fmt.Errorf("generated value %s of secret %s contains no private key", g.key(), g.Secret)
+	}
+	if g.Generator == GeneratorSelfSignedCert {
+		return map[string]any{"certificate": string(blocks["CERTIFICATE"]), "privateKey": string(key)}, nil
+	}
+	block, _ := pem.Decode(key)
+	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing generated value %s of secret %s: %w", g.key(), g.Secret, err)
+	}
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"privateKey": string(key),
+		"publicKey":  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})),
+	}, nil
+}
+
+func generatePassword(length int) ([]byte, error) {
+	password := make([]byte, length)
+	limit := big.NewInt(int64(len(passwordCharset)))
+	for i := range password {
+		index, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return nil, err
+		}
+		password[i] = passwordCharset[index.Int64()]
+	}
+	return password, nil
+}
+
+func generateSelfSignedCert(dnsNames []string) ([]byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, DefaultRSAKeyBits)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128)) //nolint:gomnd
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		DNSNames:              dnsNames,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(DefaultCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if len(dnsNames) > 0 {
+		template.Subject = pkix.Name{CommonName: dnsNames[0]}
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	return append(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})...,
+	), nil
+}
+
+// resolveGeneratedValues sets the GeneratedValues of spec in its values. Values are read from their Secret in the
+// target cluster, missing ones are generated and stored before they are used, so that every render uses them.
+func (r *Reconciler) resolveGeneratedValues(ctx context.Context, clnt client.Client, obj Object, spec *Spec) error {
+	if len(spec.GeneratedValues) == 0 {
+		return nil
+	}
+	resolved, ok := spec.Values.(map[string]any)
+	if !ok && spec.Values != nil {
+		return fmt.Errorf("generated values require map values, but got %T", spec.Values)
+	}
+	// the values of a resolver can be shared between objects, so they are copied before they are modified
+	values := copyValueMaps(resolved)
+
+	secrets := map[string]*v1.Secret{}
+	for _, generated := range spec.GeneratedValues {
+		secret, found := secrets[generated.Secret]
+		if !found {
+			var err error
+			if secret, err = r.generatedValuesSecret(ctx, clnt, obj, generated.Secret); err != nil {
+				return err
+			}
+			secrets[generated.Secret] = secret
+		}
+
+		data, found := secret.Data[generated.key()]
+		if !found {
+			var err error
+			if data, err = r.storeGeneratedValue(ctx, clnt, secret, generated); err != nil {
+				return err
+			}
+		}
+		value, err := generated.value(data)
+		if err != nil {
+			return err
+		}
+		if err := internal.SetValueAtPath(values, generated.TargetPath, value); err != nil {
+			return err
+		}
+	}
+	spec.Values = values
+	return nil
+}
+
+// copyValueMaps copies the nested maps of values, all other values are shared with the copy.
+func copyValueMaps(values map[string]any) map[string]any {
+	copied := make(map[string]any, len(values))
+	for key, value := range values {
+		if nested, ok := value.(map[string]any); ok {
+			value = copyValueMaps(nested)
+		}
+		copied[key] = value
+	}
+	return copied
+}
+
+// generatedValuesSecret returns the Secret storing generated values, it is created if it does not exist yet.
+// Existing Secrets are only used if they are labelled as generated values of obj, so that other Secrets of the
+// namespace are neither exposed in the values nor overwritten.
+func (r *Reconciler) generatedValuesSecret(
+	ctx context.Context, clnt client.Client, obj Object, name string,
+) (*v1.Secret, error) {
+	owner := fmt.Sprintf(labels.OwnedByFormat, obj.GetNamespace(), obj.GetName())
+	secret := &v1.Secret{}
+	err := clnt.Get(ctx, client.ObjectKey{Namespace: r.Namespace, Name: name}, secret)
+	if err == nil {
+		if secret.GetLabels()[GeneratedValueLabel] != obj.GetName() || secret.GetLabels()[labels.OwnedByLabel] != owner {
+			return nil, fmt.Errorf("%w: %s/%s is not labelled with %s=%s and %s=%s", ErrGeneratedValuesSecretForeign,
+				r.Namespace, name, GeneratedValueLabel, obj.GetName(), labels.OwnedByLabel, owner)
+		}
+		return secret, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	secret.SetName(name)
+	secret.SetNamespace(r.Namespace)
+	secret.SetLabels(map[string]string{
+		ManagedByLabel:      managedByLabelValue,
+		GeneratedValueLabel: obj.GetName(),
+		labels.OwnedByLabel: owner,
+	})
+	secret.Type = v1.SecretTypeOpaque
+	if err := clnt.Create(ctx, secret); err != nil {
+		return nil, fmt.Errorf("creating secret %s for generated values: %w", name, err)
+	}
+	r.Event(obj, "Normal", "GeneratedValues", fmt.Sprintf("created secret %s for generated values", name))
+	return secret, nil
+}
+
+// storeGeneratedValue generates the value and stores it in secret. The update fails with a conflict if another
+// reconciliation stored a value in the meantime, so that the value in the Secret is the only one ever used.
+func (r *Reconciler) storeGeneratedValue(
+	ctx context.Context, clnt client.Client, secret *v1.Secret, generated GeneratedValue,
+) ([]byte, error) {
+	data, err := generated.generate()
+	if err != nil {
+		return nil, err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[generated.key()] = data
+	if err := clnt.Update(ctx, secret); err != nil {
+		return nil, fmt.Errorf("storing generated value %s in secret %s: %w", generated.key(), secret.GetName(), err)
+	}
+	return data, nil
+}
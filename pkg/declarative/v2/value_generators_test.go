@@ -0,0 +1,78 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestResolveGeneratedValues(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	clnt := fake.NewClientBuilder().Build()
+	r := &Reconciler{Options: &Options{Namespace: metav1.NamespaceDefault, EventRecorder: record.NewFakeRecorder(10)}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetName("keda")
+	obj.SetNamespace("kcp-system")
+	generatedValues := []GeneratedValue{
+		{Generator: GeneratorPassword, Secret: "keda-generated", TargetPath: "db.password", Length: 16},
+		{Generator: GeneratorRSAKey, Secret: "keda-generated", TargetPath: "signing", Length: 1024},
+		{Generator: GeneratorSelfSignedCert, Secret: "keda-tls", TargetPath: "tls", DNSNames: []string{"keda.svc"}},
+	}
+	resolverValues := map[string]any{"db": map[string]any{"user": "admin"}}
+
+	spec := &Spec{Values: resolverValues, GeneratedValues: generatedValues}
+	require.NoError(t, r.resolveGeneratedValues(ctx, clnt, obj, spec))
+	values := spec.Values.(map[string]any)
+	db := values["db"].(map[string]any)
+	assert.Equal(t, "admin", db["user"])
+	assert.Len(t, db["password"], 16)
+	assert.Equal(t, map[string]any{"user": "admin"}, resolverValues["db"], "the values of the resolver are kept")
+	assert.Contains(t, values["signing"].(map[string]any)["publicKey"], "BEGIN PUBLIC KEY")
+
+	block, _ := pem.Decode([]byte(values["tls"].(map[string]any)["certificate"].(string)))
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"keda.svc"}, cert.DNSNames)
+
+	secret := &v1.Secret{}
+	require.NoError(t, clnt.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "keda-generated"}, secret))
+	assert.Equal(t, "keda", secret.GetLabels()[GeneratedValueLabel])
+	assert.Len(t, secret.Data, 2)
+
+	respec := &Spec{Values: map[string]any{}, GeneratedValues: generatedValues}
+	require.NoError(t, r.resolveGeneratedValues(ctx, clnt, obj, respec))
+	assert.Equal(t, values, respec.Values, "generated values are reused on subsequent renders")
+
+	invalid := &Spec{GeneratedValues: []GeneratedValue{{Generator: "uuid", Secret: "keda-generated", TargetPath: "id"}}}
+	assert.ErrorIs(t, r.resolveGeneratedValues(ctx, clnt, obj, invalid), ErrUnknownValueGenerator)
+
+	other := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	other.SetName("keda")
+	other.SetNamespace("other-kcp")
+	assert.ErrorIs(t, r.resolveGeneratedValues(ctx, clnt, other, respec), ErrGeneratedValuesSecretForeign,
+		"secrets generated for other objects are not shared")
+
+	foreign := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: metav1.NamespaceDefault}}
+	require.NoError(t, clnt.Create(ctx, foreign))
+	unlabelled := &Spec{GeneratedValues: []GeneratedValue{
+		{Generator: GeneratorPassword, Secret: "credentials", TargetPath: "password"},
+	}}
+	assert.ErrorIs(t, r.resolveGeneratedValues(ctx, clnt, obj, unlabelled), ErrGeneratedValuesSecretForeign)
+	require.NoError(t, clnt.Get(ctx, client.ObjectKeyFromObject(foreign), foreign))
+	assert.Empty(t, foreign.Data, "secrets without the labels are not overwritten")
+}
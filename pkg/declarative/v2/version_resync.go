@@ -0,0 +1,90 @@
+package v2
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"golang.org/x/time/rate"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// WithReconcilerVersion records version in the ReconcilerVersion of the status of every object it applied.
+// Ready objects applied by another version are resynced as soon as limiter permits, so that after an upgrade
+// of the reconciler all objects are applied again with the new version without applying all of them at once.
+// Objects with a pending revision, operations in flight and deletions are never deferred.
+// If limiter is nil, objects applied by another version are resynced without delay.
+func WithReconcilerVersion(version string, limiter *rate.Limiter) WithReconcilerVersionOption {
+	return WithReconcilerVersionOption{version: version, limiter: limiter}
+}
+
+type WithReconcilerVersionOption struct {
+	version string
+	limiter *rate.Limiter
+}
+
+func (o WithReconcilerVersionOption) Apply(options *Options) {
+	options.ReconcilerVersion = o.version
+	options.VersionResyncLimiter = o.limiter
+}
+
+// versionResyncs holds the times at which objects applied by another version are resynced.
+type versionResyncs struct {
+	mu  sync.Mutex
+	due map[client.ObjectKey]time.Time
+}
+
+// reserve returns the time until key may be resynced, it reserves a slot of limiter on the first call for key.
+func (v *versionResyncs) reserve(key client.ObjectKey, limiter *rate.Limiter, now time.Time) time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.due == nil {
+		v.due = make(map[client.ObjectKey]time.Time)
+	}
+	due, reserved := v.due[key]
+	if !reserved {
+		due = now
+		// limiters without burst never permit an event, so they do not delay resyncs
+		if reservation := limiter.ReserveN(now, 1); reservation.OK() {
+			due = now.Add(reservation.DelayFrom(now))
+		}
+	}
+	if !due.After(now) {
+		delete(v.due, key)
+		return 0
+	}
+	v.due[key] = due
+	return due.Sub(now)
+}
+
+func (v *versionResyncs) release(key client.ObjectKey) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.due, key)
+}
+
+// needsVersionResync is true if obj is ready and up to date with spec, but was applied by another version.
+func (r *Reconciler) needsVersionResync(obj Object, spec *Spec) bool {
+	status := obj.GetStatus()
+	return r.ReconcilerVersion != "" && status.ReconcilerVersion != r.ReconcilerVersion &&
+		obj.GetDeletionTimestamp().IsZero() && status.State == StateReady && status.InstalledOnce &&
+		!status.Journal.InFlight() && installedRevision(status, spec.ManifestName) == spec.Revision
+}
+
+// deferVersionResync requeues obj until the VersionResyncLimiter permits to resync it with the ReconcilerVersion.
+// It returns false if the resync is not deferred.
+func (r *Reconciler) deferVersionResync(ctx context.Context, obj Object, spec *Spec) (ctrl.Result, bool) {
+	if r.VersionResyncLimiter == nil || !r.needsVersionResync(obj, spec) {
+		return ctrl.Result{}, false
+	}
+	delay := r.versionResyncs.reserve(client.ObjectKeyFromObject(obj), r.VersionResyncLimiter, time.Now())
+	if delay == 0 {
+		return ctrl.Result{}, false
+	}
+	log.FromContext(ctx).V(internal.DebugLogLevel).Info("deferring resync of resources applied by another version",
+		"version", obj.GetStatus().ReconcilerVersion, "reconcilerVersion", r.ReconcilerVersion, "after", delay)
+	return ctrl.Result{RequeueAfter: delay}, true
+}
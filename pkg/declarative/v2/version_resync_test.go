@@ -0,0 +1,64 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestVersionResyncsReserve(t *testing.T) {
+	t.Parallel()
+	limiter := rate.NewLimiter(rate.Every(time.Second), 1)
+	resyncs := &versionResyncs{}
+	now := time.Now()
+	first, second := client.ObjectKey{Name: "first"}, client.ObjectKey{Name: "second"}
+
+	assert.Zero(t, resyncs.reserve(first, limiter, now), "the burst is resynced immediately")
+	assert.Equal(t, time.Second, resyncs.reserve(second, limiter, now))
+	assert.Equal(t, 500*time.Millisecond, resyncs.reserve(second, limiter, now.Add(500*time.Millisecond)),
+		"requeues keep their reservation")
+	assert.Zero(t, resyncs.reserve(second, limiter, now.Add(time.Second)))
+	assert.Empty(t, resyncs.due)
+
+	resyncs.reserve(client.ObjectKey{Name: "third"}, limiter, now.Add(time.Second))
+	resyncs.release(client.ObjectKey{Name: "third"})
+	assert.Empty(t, resyncs.due)
+
+	assert.Zero(t, resyncs.reserve(first, rate.NewLimiter(rate.Every(time.Second), 0), now),
+		"limiters without burst do not delay resyncs")
+}
+
+func TestNeedsVersionResync(t *testing.T) {
+	t.Parallel()
+	r := &Reconciler{Options: &Options{ReconcilerVersion: "v2"}}
+	spec := &Spec{ManifestName: "install", Revision: "sha256:a"}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	obj.SetStatus(Status{
+		State:             StateReady,
+		InstalledOnce:     true,
+		ReconcilerVersion: "v1",
+		Installs:          []InstallStatus{{Name: "install", Revision: "sha256:a", Ready: true}},
+	})
+	assert.True(t, r.needsVersionResync(obj, spec))
+
+	spec.Revision = "sha256:b"
+	assert.False(t, r.needsVersionResync(obj, spec), "pending revisions are applied immediately")
+	spec.Revision = "sha256:a"
+
+	status := obj.GetStatus()
+	status.ReconcilerVersion = "v2"
+	obj.SetStatus(status)
+	assert.False(t, r.needsVersionResync(obj, spec))
+
+	status.ReconcilerVersion = "v1"
+	status.State = StateError
+	obj.SetStatus(status)
+	assert.False(t, r.needsVersionResync(obj, spec), "objects that are not ready are retried by the rate limiter")
+}
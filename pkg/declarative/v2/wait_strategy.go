@@ -0,0 +1,152 @@
+package v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	ErrReadinessTimeout         = errors.New("resources did not become ready within the wait timeout")
+	ErrProgressDeadlineExceeded = errors.New("deployments exceeded their progress deadline")
+)
+
+const (
+	// ConditionTypeResourcesReady reports since when the resources of objects with a WaitStrategy timeout
+	// are awaited, its last transition is the start of the timeout.
+	ConditionTypeResourcesReady   ConditionType   = "ResourcesReady"
+	ConditionReasonResourcesReady ConditionReason = "Ready"
+	ConditionReasonWaiting        ConditionReason = "Waiting"
+	ConditionReasonWaitTimeout    ConditionReason = "WaitTimeout"
+
+	deploymentProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+)
+
+// +k8s:deepcopy-gen=true
+// WaitStrategy determines how the readiness of the resources of an install is awaited, as rollout times differ
+// a lot between modules. Without a WaitStrategy, resources are awaited without a timeout, including Jobs.
+type WaitStrategy struct {
+	// Wait determines if the readiness of the resources is awaited, resources are ready once applied if false.
+	// +optional
+	Wait *bool `json:"wait,omitempty"`
+
+	// WaitForJobs determines if Jobs have to complete before the resources are ready, true if unset.
+	// +optional
+	WaitForJobs *bool `json:"waitForJobs,omitempty"`
+
+	// Timeout fails the install if its resources are not ready within the duration after they were applied,
+	// they are still awaited afterwards.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// ProgressDeadline sets the progressDeadlineSeconds of Deployments that do not define it.
+	// Deployments that exceed their progress deadline fail the install instead of being awaited silently.
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// awaitsReadiness is false if the resources are ready once applied.
+func (w *WaitStrategy) awaitsReadiness() bool {
+	return w == nil || w.Wait == nil || *w.Wait
+}
+
+// waitsForJobs is true if Jobs have to complete before the resources are ready.
+func (w *WaitStrategy) waitsForJobs() bool {
+	return w == nil || w.WaitForJobs == nil || *w.WaitForJobs
+}
+
+// withProgressDeadline sets the ProgressDeadline in all Deployments of resources that do not define one.
+func (w *WaitStrategy) withProgressDeadline(resources []*unstructured.Unstructured) error {
+	if w == nil || w.ProgressDeadline == nil {
+		return nil
+	}
+	seconds := int64(w.ProgressDeadline.Seconds())
+	for _, res := range resources {
+		if res.GroupVersionKind().GroupKind() != appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+			continue
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(res.Object, "spec", "progressDeadlineSeconds"); found {
+			continue
+		}
+		if err := unstructured.SetNestedField(res.Object, seconds, "spec", "progressDeadlineSeconds"); err != nil {
+			return fmt.Errorf("setting progress deadline of deployment %s: %w", res.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// checkProgressDeadlines fails with ErrProgressDeadlineExceeded if a Deployment of target stopped progressing.
+func (w *WaitStrategy) checkProgressDeadlines(ctx context.Context, clnt client.Reader, target []*resource.Info) error {
+	if w == nil || w.ProgressDeadline == nil {
+		return nil
+	}
+	var exceeded []string
+	for _, info := range target {
+		obj, ok := info.Object.(*unstructured.Unstructured)
+		if !ok || obj.GroupVersionKind().GroupKind() != appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind() {
+			continue
+		}
+		deployment := &appsv1.Deployment{}
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(obj), deployment); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("checking progress of deployment %s: %w", obj.GetName(), err)
+		}
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Type == appsv1.DeploymentProgressing && condition.Reason == deploymentProgressDeadlineExceeded {
+				exceeded = append(exceeded, deployment.GetName())
+			}
+		}
+	}
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%w: %s", ErrProgressDeadlineExceeded, strings.Join(exceeded, ", "))
+	}
+	return nil
+}
+
+// withResourcesReadyCondition reports in the ResourcesReady condition of objects with a WaitStrategy timeout
+// if their resources are ready. It fails with ErrReadinessTimeout once the resources are awaited longer than the
+// timeout, counting from the later of the start of the wait and the end of the last apply.
+func (w *WaitStrategy) withResourcesReadyCondition(obj Object, ready bool, now time.Time) error {
+	if w == nil || w.Timeout == nil {
+		return nil
+	}
+	status := obj.GetStatus()
+	condition := metav1.Condition{
+		Type:               string(ConditionTypeResourcesReady),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(ConditionReasonResourcesReady),
+		Message:            "resources are ready",
+		ObservedGeneration: obj.GetGeneration(),
+	}
+	var err error
+	if !ready {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = string(ConditionReasonWaiting)
+		condition.Message = fmt.Sprintf("waiting up to %s for resources to become ready", w.Timeout.Duration)
+		meta.SetStatusCondition(&status.Conditions, condition)
+		waitingSince := meta.FindStatusCondition(status.Conditions, condition.Type).LastTransitionTime.Time
+		if status.Journal != nil && status.Journal.FinishedAt != nil && status.Journal.FinishedAt.After(waitingSince) {
+			waitingSince = status.Journal.FinishedAt.Time
+		}
+		if now.Sub(waitingSince) > w.Timeout.Duration {
+			condition.Reason = string(ConditionReasonWaitTimeout)
+			condition.Message = fmt.Sprintf("resources are not ready since %s", waitingSince.Format(time.RFC3339))
+			err = fmt.Errorf("%w: waiting since %s", ErrReadinessTimeout, waitingSince.Format(time.RFC3339))
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+	obj.SetStatus(status)
+	return err
+}
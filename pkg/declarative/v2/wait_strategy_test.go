@@ -0,0 +1,87 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func deployment(name string, spec map[string]any) *unstructured.Unstructured {
+	obj := renderedObject("Deployment", name, spec)
+	obj.SetAPIVersion("apps/v1")
+	return obj
+}
+
+func TestWaitStrategyDefaults(t *testing.T) {
+	t.Parallel()
+	disabled := false
+	var unset *WaitStrategy
+	assert.True(t, unset.awaitsReadiness())
+	assert.True(t, unset.waitsForJobs())
+	assert.False(t, (&WaitStrategy{Wait: &disabled}).awaitsReadiness())
+	assert.False(t, (&WaitStrategy{WaitForJobs: &disabled}).waitsForJobs())
+}
+
+func TestWaitStrategyProgressDeadline(t *testing.T) {
+	t.Parallel()
+	wait := &WaitStrategy{ProgressDeadline: &metav1.Duration{Duration: 2 * time.Minute}}
+	defaulted := deployment("defaulted", map[string]any{})
+	explicit := deployment("explicit", map[string]any{"progressDeadlineSeconds": int64(30)})
+	require.NoError(t, wait.withProgressDeadline([]*unstructured.Unstructured{defaulted, explicit}))
+	seconds, _, _ := unstructured.NestedInt64(defaulted.Object, "spec", "progressDeadlineSeconds")
+	assert.Equal(t, int64(120), seconds)
+	seconds, _, _ = unstructured.NestedInt64(explicit.Object, "spec", "progressDeadlineSeconds")
+	assert.Equal(t, int64(30), seconds)
+
+	clnt := fake.NewClientBuilder().WithObjects(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaulted", Namespace: "keda"},
+		Status: appsv1.DeploymentStatus{Conditions: []appsv1.DeploymentCondition{{
+			Type: appsv1.DeploymentProgressing, Reason: deploymentProgressDeadlineExceeded,
+		}}},
+	}).Build()
+	missing := deployment("missing", map[string]any{})
+	target := []*resource.Info{
+		{Name: "missing", Namespace: "keda", Object: missing}, {Name: "defaulted", Namespace: "keda", Object: defaulted},
+	}
+	assert.ErrorIs(t, wait.checkProgressDeadlines(context.Background(), clnt, target), ErrProgressDeadlineExceeded)
+	assert.NoError(t, (&WaitStrategy{}).checkProgressDeadlines(context.Background(), clnt, target))
+}
+
+func TestWaitStrategyTimeout(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	wait := &WaitStrategy{Timeout: &metav1.Duration{Duration: 5 * time.Minute}}
+	obj := &statusObj{Unstructured: &unstructured.Unstructured{}}
+	now := time.Now()
+
+	assertions.NoError(wait.withResourcesReadyCondition(obj, false, now))
+	condition := meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeResourcesReady))
+	require.NotNil(t, condition)
+	assertions.Equal(string(ConditionReasonWaiting), condition.Reason)
+
+	assertions.ErrorIs(wait.withResourcesReadyCondition(obj, false, now.Add(10*time.Minute)), ErrReadinessTimeout)
+	condition = meta.FindStatusCondition(obj.GetStatus().Conditions, string(ConditionTypeResourcesReady))
+	assertions.Equal(string(ConditionReasonWaitTimeout), condition.Reason)
+
+	status := obj.GetStatus()
+	finishedAt := metav1.NewTime(now.Add(8 * time.Minute))
+	status.Journal = &OperationJournal{Phase: JournalPhaseFinished, FinishedAt: &finishedAt}
+	obj.SetStatus(status)
+	assertions.NoError(wait.withResourcesReadyCondition(obj, false, now.Add(10*time.Minute)),
+		"the timeout restarts with every apply")
+
+	assertions.NoError(wait.withResourcesReadyCondition(obj, true, now))
+	assertions.True(meta.IsStatusConditionTrue(obj.GetStatus().Conditions, string(ConditionTypeResourcesReady)))
+}
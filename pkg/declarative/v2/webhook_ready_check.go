@@ -0,0 +1,117 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const conversionStrategyWebhook = "Webhook"
+
+// WebhookReadyCheck extends a ReadyCheck for module operators that report their Deployment as available
+// before their webhooks are serving, e.g. while they wait for leader election or for certificates.
+// Rendered webhook configurations and CRDs with conversion webhooks are only ready once the caBundle of every
+// webhook is set and the Service of every webhook has ready endpoints.
+type WebhookReadyCheck struct {
+	ReadyCheck
+}
+
+func NewWebhookReadyCheck(check ReadyCheck) ReadyCheck {
+	return &WebhookReadyCheck{ReadyCheck: check}
+}
+
+func (c *WebhookReadyCheck) Run(ctx context.Context, clnt Client, obj Object, resources []*resource.Info) error {
+	if err := c.ReadyCheck.Run(ctx, clnt, obj, resources); err != nil {
+		return err
+	}
+	return checkWebhooksServing(ctx, clnt, resources)
+}
+
+func checkWebhooksServing(ctx context.Context, clnt client.Reader, resources []*resource.Info) error {
+	for _, info := range resources {
+		rendered, ok := info.Object.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if !isWebhookResource(rendered) {
+			continue
+		}
+		// the caBundle is usually injected after the apply, so it is read from the cluster
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(rendered.GroupVersionKind())
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(rendered), live); err != nil {
+			return fmt.Errorf("webhook readiness of %s could not be determined: %w", info.ObjectName(), err)
+		}
+		for _, clientConfig := range webhookClientConfigs(live) {
+			if err := checkWebhookClientConfig(ctx, clnt, info.ObjectName(), clientConfig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isWebhookResource(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration", "CustomResourceDefinition":
+		return true
+	}
+	return false
+}
+
+// webhookClientConfigs returns the clientConfig of all webhooks of a webhook configuration
+// or of the conversion webhook of a CRD.
+func webhookClientConfigs(obj *unstructured.Unstructured) []map[string]any {
+	var clientConfigs []map[string]any
+	if obj.GetKind() == "CustomResourceDefinition" {
+		strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "conversion", "strategy")
+		if strategy != conversionStrategyWebhook {
+			return nil
+		}
+		clientConfig, found, _ := unstructured.NestedMap(obj.Object, "spec", "conversion", "webhook", "clientConfig")
+		if found {
+			clientConfigs = append(clientConfigs, clientConfig)
+		}
+		return clientConfigs
+	}
+
+	webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+	for _, webhook := range webhooks {
+		webhook, ok := webhook.(map[string]any)
+		if !ok {
+			continue
+		}
+		if clientConfig, found, _ := unstructured.NestedMap(webhook, "clientConfig"); found {
+			clientConfigs = append(clientConfigs, clientConfig)
+		}
+	}
+	return clientConfigs
+}
+
+// checkWebhookClientConfig verifies webhooks backed by a Service, webhooks called by URL are not checked.
+func checkWebhookClientConfig(ctx context.Context, clnt client.Reader, name string, clientConfig map[string]any) error {
+	serviceName, found, _ := unstructured.NestedString(clientConfig, "service", "name")
+	if !found {
+		return nil
+	}
+	serviceNamespace, _, _ := unstructured.NestedString(clientConfig, "service", "namespace")
+	if caBundle, _, _ := unstructured.NestedString(clientConfig, "caBundle"); caBundle == "" {
+		return fmt.Errorf("%w: caBundle of webhook in %s is not set", ErrResourcesNotReady, name)
+	}
+
+	endpoints := &corev1.Endpoints{}
+	key := client.ObjectKey{Name: serviceName, Namespace: serviceNamespace}
+	if err := clnt.Get(ctx, key, endpoints); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: webhook service %s of %s has no ready endpoints", ErrResourcesNotReady, key, name)
+}
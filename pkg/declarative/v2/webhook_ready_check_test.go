@@ -0,0 +1,70 @@
+// contains internal tests that should not be exposed, thus no v2_test
+//
+//nolint:testpackage
+package v2
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func webhookConfiguration(caBundle string) *unstructured.Unstructured {
+	clientConfig := map[string]any{"service": map[string]any{
+		"name": "operator-webhook", "namespace": "kyma-system", "port": int64(443),
+	}}
+	if caBundle != "" {
+		clientConfig["caBundle"] = caBundle
+	}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"webhooks": []any{map[string]any{"name": "validate.operator.kyma-project.io", "clientConfig": clientConfig}},
+	}}
+	obj.SetAPIVersion("admissionregistration.k8s.io/v1")
+	obj.SetKind("ValidatingWebhookConfiguration")
+	obj.SetName("operator")
+	return obj
+}
+
+func TestWebhookReadyCheck(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	rendered := []*resource.Info{{Name: "operator", Object: webhookConfiguration("")}}
+	notReadyEndpoints := &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "operator-webhook", Namespace: "kyma-system"}}
+	readyEndpoints := notReadyEndpoints.DeepCopy()
+	readyEndpoints.Subsets = []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}}
+
+	tests := []struct {
+		name    string
+		live    *unstructured.Unstructured
+		ready   bool
+		message string
+	}{
+		{"caBundle not injected", webhookConfiguration(""), false, "caBundle"},
+		{"no ready endpoints", webhookConfiguration("Y2E="), false, "no ready endpoints"},
+		{"serving", webhookConfiguration("Y2E="), true, ""},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			endpoints := notReadyEndpoints.DeepCopy()
+			if test.ready {
+				endpoints = readyEndpoints.DeepCopy()
+			}
+			clnt := fake.NewClientBuilder().WithObjects(endpoints).WithRuntimeObjects(test.live).Build()
+			err := checkWebhooksServing(ctx, clnt, rendered)
+			if test.ready {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrResourcesNotReady)
+			assert.ErrorContains(t, err, test.message)
+		})
+	}
+}
@@ -25,6 +25,42 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FailureStreak) DeepCopyInto(out *FailureStreak) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FailureStreak.
+func (in *FailureStreak) DeepCopy() *FailureStreak {
+	if in == nil {
+		return nil
+	}
+	out := new(FailureStreak)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedValue) DeepCopyInto(out *GeneratedValue) {
+	*out = *in
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedValue.
+func (in *GeneratedValue) DeepCopy() *GeneratedValue {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LastOperation) DeepCopyInto(out *LastOperation) {
 	*out = *in
@@ -41,6 +77,46 @@ func (in *LastOperation) DeepCopy() *LastOperation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationJournal) DeepCopyInto(out *OperationJournal) {
+	*out = *in
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]Resource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationJournal.
+func (in *OperationJournal) DeepCopy() *OperationJournal {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationJournal)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolledBackSpec) DeepCopyInto(out *RolledBackSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolledBackSpec.
+func (in *RolledBackSpec) DeepCopy() *RolledBackSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RolledBackSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Status) DeepCopyInto(out *Status) {
 	*out = *in
@@ -57,6 +133,33 @@ func (in *Status) DeepCopyInto(out *Status) {
 		copy(*out, *in)
 	}
 	in.LastOperation.DeepCopyInto(&out.LastOperation)
+	if in.Installs != nil {
+		in, out := &in.Installs, &out.Installs
+		*out = make([]InstallStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Provenance != nil {
+		in, out := &in.Provenance, &out.Provenance
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Journal != nil {
+		in, out := &in.Journal, &out.Journal
+		*out = new(OperationJournal)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = new(FailureStreak)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = new(RolledBackSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Status.
@@ -68,3 +171,38 @@ func (in *Status) DeepCopy() *Status {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WaitStrategy) DeepCopyInto(out *WaitStrategy) {
+	*out = *in
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WaitForJobs != nil {
+		in, out := &in.WaitForJobs, &out.WaitForJobs
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ProgressDeadline != nil {
+		in, out := &in.ProgressDeadline, &out.ProgressDeadline
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WaitStrategy.
+func (in *WaitStrategy) DeepCopy() *WaitStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(WaitStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
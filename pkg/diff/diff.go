@@ -0,0 +1,48 @@
+// Package diff compares the content of resources, e.g. the result of a server-side dry-run apply with the
+// resource in the cluster, and returns the paths of the fields that differ.
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// IgnoredFields are the paths of fields that change with every apply without changing the resource.
+var IgnoredFields = []string{"metadata.managedFields", "metadata.resourceVersion"}
+
+// Fields returns the sorted paths of the fields that differ between live and desired, e.g. "spec.replicas" or
+// "data.config", excluding IgnoredFields. Lists are compared as a whole and reported with the path of the list.
+func Fields(live, desired *unstructured.Unstructured) []string {
+	var paths []string
+	compare(nil, live.Object, desired.Object, &paths)
+	sort.Strings(paths)
+	return paths
+}
+
+func compare(path []string, live, desired any, paths *[]string) {
+	joined := strings.Join(path, ".")
+	for _, ignored := range IgnoredFields {
+		if joined == ignored {
+			return
+		}
+	}
+	liveMap, liveIsMap := live.(map[string]any)
+	desiredMap, desiredIsMap := desired.(map[string]any)
+	if !liveIsMap || !desiredIsMap {
+		if !equality.Semantic.DeepEqual(live, desired) {
+			*paths = append(*paths, joined)
+		}
+		return
+	}
+	for key, value := range desiredMap {
+		compare(append(path[:len(path):len(path)], key), liveMap[key], value, paths)
+	}
+	for key, value := range liveMap {
+		if _, found := desiredMap[key]; !found {
+			compare(append(path[:len(path):len(path)], key), value, nil, paths)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/diff"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+	live := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":            "app",
+			"resourceVersion": "1",
+			"managedFields":   []any{map[string]any{"manager": "kubectl"}},
+			"labels":          map[string]any{"app": "app", "extra": "label"},
+		},
+		"spec": map[string]any{
+			"replicas": int64(1),
+			"template": map[string]any{"spec": map[string]any{"containers": []any{"app:1"}}},
+		},
+	}}
+	desired := live.DeepCopy()
+	desired.SetResourceVersion("2")
+	desired.SetManagedFields(nil)
+	assert.Empty(t, diff.Fields(live, desired), "ignored fields do not differ")
+
+	assert.NoError(t, unstructured.SetNestedField(desired.Object, int64(3), "spec", "replicas"))
+	assert.NoError(t, unstructured.SetNestedSlice(desired.Object, []any{"app:2"},
+		"spec", "template", "spec", "containers"))
+	assert.NoError(t, unstructured.SetNestedField(desired.Object, "value", "data", "added"))
+	desired.SetLabels(map[string]string{"app": "app"})
+	assert.Equal(t, []string{
+		"data",
+		"metadata.labels.extra",
+		"spec.replicas",
+		"spec.template.spec.containers",
+	}, diff.Fields(live, desired))
+}
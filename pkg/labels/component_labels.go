@@ -10,4 +10,19 @@ const (
 	OwnedByLabel     = OperatorPrefix + Separator + "owned-by"
 	OwnedByFormat    = "%s__%s"
 	WatchedByLabel   = OperatorPrefix + Separator + "watched-by"
+	ModuleName       = OperatorPrefix + Separator + "module-name"
+	Channel          = OperatorPrefix + Separator + "channel"
+	FailureBaseDelay = OperatorPrefix + Separator + "failure-base-delay"
+	FailureMaxDelay  = OperatorPrefix + Separator + "failure-max-delay"
+	ManifestRef      = OperatorPrefix + Separator + "manifest"
+	ModuleVersion    = OperatorPrefix + Separator + "module-version"
+	Revision         = OperatorPrefix + Separator + "revision"
+	ClusterProfile   = OperatorPrefix + Separator + "cluster-profile"
+	FlagOverlay      = OperatorPrefix + Separator + "flag-overlay"
+
+	// TLSCABundle, TLSInsecureSkipVerify and TLSServerName are annotations of kubeconfig secrets
+	// that override the TLS settings of the kubeconfig.
+	TLSCABundle           = OperatorPrefix + Separator + "tls-ca-bundle"
+	TLSInsecureSkipVerify = OperatorPrefix + Separator + "tls-insecure-skip-verify"
+	TLSServerName         = OperatorPrefix + Separator + "tls-server-name"
 )
@@ -2,6 +2,7 @@ package log
 
 import (
 	"os"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -10,22 +11,51 @@ import (
 	zap2 "sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
+// Options configures sampling of the logger.
+type Options struct {
+	// SamplingInitial is the number of identical entries (same level and message) logged per second,
+	// afterwards only every SamplingThereafter-th entry is logged within that second.
+	// Sampling is disabled if SamplingInitial is 0.
+	SamplingInitial    int
+	SamplingThereafter int
+}
+
 func ConfigLogger(level int8) logr.Logger {
+	return ConfigLoggerWithLevel(NewAtomicLevel(level), Options{})
+}
+
+// NewAtomicLevel converts the verbosity into a zap level that can be changed while the logger is in use.
+// Positive and negative verbosity are treated equally, as zap uses negative levels for increased verbosity.
+func NewAtomicLevel(level int8) zap.AtomicLevel {
+	return zap.NewAtomicLevelAt(ToZapLevel(level))
+}
+
+// ToZapLevel converts the verbosity into the corresponding zap level.
+func ToZapLevel(level int8) zapcore.Level {
 	if level > 0 {
 		level = -level
 	}
+	return zapcore.Level(level)
+}
+
+// ConfigLoggerWithLevel creates a logger whose level is controlled by atomicLevel.
+// Values of sensitive keys (e.g. passwords or tokens in overrides) are always redacted.
+func ConfigLoggerWithLevel(atomicLevel zap.AtomicLevel, opts Options) logr.Logger {
 	// The following settings is based on kyma community Improvement of log messages usability
 	//nolint:lll
 	// https://github.com/kyma-project/community/blob/main/concepts/observability-consistent-logging/improvement-of-log-messages-usability.md#log-structure
-	atomicLevel := zap.NewAtomicLevelAt(zapcore.Level(level))
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.TimeKey = "date"
 	encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
 	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
 
 	core := zapcore.NewCore(
-		&zap2.KubeAwareEncoder{Encoder: zapcore.NewJSONEncoder(encoderConfig)}, zapcore.Lock(os.Stdout), atomicLevel,
+		&redactingEncoder{Encoder: &zap2.KubeAwareEncoder{Encoder: zapcore.NewJSONEncoder(encoderConfig)}},
+		zapcore.Lock(os.Stdout), atomicLevel,
 	)
+	if opts.SamplingInitial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, opts.SamplingInitial, opts.SamplingThereafter)
+	}
 	zapLog := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 	logger := zapr.NewLogger(zapLog.With(zap.Namespace("context")))
 
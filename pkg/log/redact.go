@@ -0,0 +1,60 @@
+package log
+
+import (
+	"github.com/kyma-project/module-manager/internal"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactingEncoder masks values of sensitive keys (e.g. passwords or tokens from overrides)
+// in messages and fields before they are encoded.
+type redactingEncoder struct {
+	zapcore.Encoder
+}
+
+func (e *redactingEncoder) Clone() zapcore.Encoder {
+	return &redactingEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e *redactingEncoder) AddString(key, value string) {
+	e.Encoder.AddString(key, redactField(zap.String(key, value)).String)
+}
+
+func (e *redactingEncoder) AddByteString(key string, value []byte) {
+	e.AddString(key, string(value))
+}
+
+func (e *redactingEncoder) AddReflected(key string, value interface{}) error {
+	if internal.IsSensitiveKey(key) {
+		e.Encoder.AddString(key, internal.RedactedValue)
+		return nil
+	}
+	return e.Encoder.AddReflected(key, internal.RedactValues(value))
+}
+
+func (e *redactingEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	entry.Message = internal.RedactString(entry.Message)
+	redacted := make([]zapcore.Field, len(fields))
+	for i := range fields {
+		redacted[i] = redactField(fields[i])
+	}
+	return e.Encoder.EncodeEntry(entry, redacted)
+}
+
+func redactField(field zapcore.Field) zapcore.Field {
+	if internal.IsSensitiveKey(field.Key) {
+		return zap.String(field.Key, internal.RedactedValue)
+	}
+	switch field.Type { //nolint:exhaustive
+	case zapcore.StringType:
+		field.String = internal.RedactString(field.String)
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			return zap.String(field.Key, internal.RedactString(err.Error()))
+		}
+	case zapcore.ReflectType:
+		field.Interface = internal.RedactValues(field.Interface)
+	}
+	return field
+}
@@ -0,0 +1,270 @@
+// Package resource provides the handling of CustomResourceDefinitions of modules, so that operators
+// installing modules reuse the same parsing, installation and removal as the module-manager.
+package resource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/kyma-project/module-manager/pkg/types"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultCRDFieldManager is the field manager of CRDs applied with CRDUpdatePolicyApply.
+const DefaultCRDFieldManager client.FieldOwner = "module-manager-crds"
+
+// DefaultEstablishedPollInterval is the interval in which CRDs are checked while waiting for them to be established.
+const DefaultEstablishedPollInterval = time.Second
+
+var (
+	ErrCRDsNotEstablished = errors.New("crds are not yet established")
+	ErrCRDsNotRemoved     = errors.New("crds are not yet removed")
+)
+
+// CRDUpdatePolicy determines how InstallCRDs treats CRDs that already exist in the cluster.
+type CRDUpdatePolicy string
+
+const (
+	// CRDUpdatePolicyCreateOnly keeps existing CRDs untouched, like helm does for the crds folder of a chart.
+	CRDUpdatePolicyCreateOnly CRDUpdatePolicy = "CreateOnly"
+	// CRDUpdatePolicyApply applies all CRDs with server-side apply, so that existing CRDs are updated.
+	CRDUpdatePolicyApply CRDUpdatePolicy = "Apply"
+)
+
+// CRDOptions configure InstallCRDs.
+type CRDOptions struct {
+	FieldManager       client.FieldOwner
+	UpdatePolicy       CRDUpdatePolicy
+	WaitForEstablished bool
+	PollInterval       time.Duration
+}
+
+// DefaultCRDOptions only creates missing CRDs and does not wait for them to be established.
+func DefaultCRDOptions() *CRDOptions {
+	return &CRDOptions{
+		FieldManager: DefaultCRDFieldManager,
+		UpdatePolicy: CRDUpdatePolicyCreateOnly,
+		PollInterval: DefaultEstablishedPollInterval,
+	}
+}
+
+type CRDOption interface {
+	Apply(options *CRDOptions)
+}
+
+// WithFieldManager sets the field manager of CRDs applied with CRDUpdatePolicyApply.
+type WithFieldManager client.FieldOwner
+
+func (o WithFieldManager) Apply(options *CRDOptions) {
+	options.FieldManager = client.FieldOwner(o)
+}
+
+// WithUpdatePolicy determines how existing CRDs are treated.
+type WithUpdatePolicy CRDUpdatePolicy
+
+func (o WithUpdatePolicy) Apply(options *CRDOptions) {
+	options.UpdatePolicy = CRDUpdatePolicy(o)
+}
+
+// WithWaitForEstablished blocks InstallCRDs until all CRDs are established or the context is done.
+type WithWaitForEstablished bool
+
+func (o WithWaitForEstablished) Apply(options *CRDOptions) {
+	options.WaitForEstablished = bool(o)
+}
+
+// WithPollInterval sets the interval in which CRDs are checked while waiting for them to be established.
+type WithPollInterval time.Duration
+
+func (o WithPollInterval) Apply(options *CRDOptions) {
+	options.PollInterval = time.Duration(o)
+}
+
+// GetCRDsFromPath parses the CRDs of the YAML file at path or of all .yaml, .yml and .json files directly in the
+// directory at path, in the order of their names. Documents of other kinds are ignored.
+func GetCRDsFromPath(path string) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(path, entry.Name()))
+				}
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := ParseCRDs(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing crds of %s: %w", file, err)
+		}
+		crds = append(crds, parsed...)
+	}
+	return crds, nil
+}
+
+// ParseCRDs parses the CRDs of a multi-document YAML manifest. Documents of other kinds are ignored.
+func ParseCRDs(manifest []byte) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	objects, err := internal.ParseManifestStringToObjects(string(manifest))
+	if err != nil {
+		return nil, err
+	}
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	for _, obj := range objects.Items {
+		if obj.GroupVersionKind() != apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition") {
+			continue
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, crd); err != nil {
+			return nil, fmt.Errorf("converting crd %s: %w", obj.GetName(), err)
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+// InstallCRDs creates or applies the CRDs depending on the CRDUpdatePolicy of the options,
+// and optionally waits for them to be established.
+func InstallCRDs(
+	ctx context.Context, clnt client.Client, crds []*apiextensionsv1.CustomResourceDefinition, opts ...CRDOption,
+) error {
+	options := DefaultCRDOptions()
+	for _, opt := range opts {
+		opt.Apply(options)
+	}
+
+	var errs []error
+	for _, crd := range crds {
+		if err := installCRD(ctx, clnt, crd.DeepCopy(), options); err != nil {
+			errs = append(errs, fmt.Errorf("installing crd %s: %w", crd.GetName(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return types.NewMultiError(errs)
+	}
+
+	if !options.WaitForEstablished {
+		return nil
+	}
+	return wait.PollImmediateUntilWithContext(ctx, options.PollInterval, func(ctx context.Context) (bool, error) {
+		err := CheckCRDsEstablished(ctx, clnt, crds)
+		if errors.Is(err, ErrCRDsNotEstablished) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+func installCRD(
+	ctx context.Context, clnt client.Client, crd *apiextensionsv1.CustomResourceDefinition, options *CRDOptions,
+) error {
+	switch options.UpdatePolicy {
+	case CRDUpdatePolicyApply:
+		crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+		crd.SetResourceVersion("")
+		crd.SetManagedFields(nil)
+		crd.Status = apiextensionsv1.CustomResourceDefinitionStatus{}
+		return clnt.Patch(ctx, crd, client.Apply, client.ForceOwnership, options.FieldManager)
+	case CRDUpdatePolicyCreateOnly, "":
+		if err := clnt.Create(ctx, crd); err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported crd update policy %q", options.UpdatePolicy)
+	}
+}
+
+// CheckCRDsEstablished returns ErrCRDsNotEstablished if any of the CRDs is missing
+// or its names are not accepted or it is not established.
+func CheckCRDsEstablished(
+	ctx context.Context, clnt client.Reader, crds []*apiextensionsv1.CustomResourceDefinition,
+) error {
+	var pending []string
+	for _, crd := range crds {
+		onCluster := &apiextensionsv1.CustomResourceDefinition{}
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(crd), onCluster); apierrors.IsNotFound(err) {
+			pending = append(pending, crd.GetName())
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !isCRDConditionTrue(onCluster, apiextensionsv1.NamesAccepted) ||
+			!isCRDConditionTrue(onCluster, apiextensionsv1.Established) {
+			pending = append(pending, crd.GetName())
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: %s", ErrCRDsNotEstablished, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+func isCRDConditionTrue(
+	crd *apiextensionsv1.CustomResourceDefinition, conditionType apiextensionsv1.CustomResourceDefinitionConditionType,
+) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// RemoveCRDs deletes the CRDs and returns ErrCRDsNotRemoved until all of them are gone from the cluster.
+// Deleting a CRD deletes all of its custom resources, so the custom resources should be removed before.
+func RemoveCRDs(ctx context.Context, clnt client.Client, crds []*apiextensionsv1.CustomResourceDefinition) error {
+	var remaining []string
+	var errs []error
+	for _, crd := range crds {
+		onCluster := &apiextensionsv1.CustomResourceDefinition{}
+		if err := clnt.Get(ctx, client.ObjectKeyFromObject(crd), onCluster); apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		remaining = append(remaining, crd.GetName())
+		if !onCluster.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		if err := clnt.Delete(ctx, onCluster); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("deleting crd %s: %w", crd.GetName(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return types.NewMultiError(errs)
+	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("%w: %s", ErrCRDsNotRemoved, strings.Join(remaining, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package resource_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const crdTemplate = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %[1]ss.operator.kyma-project.io
+spec:
+  group: operator.kyma-project.io
+  names:
+    kind: %[1]s
+    plural: %[1]ss
+  scope: Namespaced
+  versions:
+  - name: v1alpha1
+    served: true
+    storage: true
+`
+
+func writeCRDs(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"b-crds.yaml": crd("second") + "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: ignored\n",
+		"a-crd.yml":   crd("first"),
+		"README.md":   "not a manifest",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+	return dir
+}
+
+func crd(kind string) string {
+	return fmt.Sprintf(crdTemplate, kind)
+}
+
+func TestGetCRDsFromPath(t *testing.T) {
+	t.Parallel()
+	dir := writeCRDs(t)
+
+	crds, err := resource.GetCRDsFromPath(dir)
+	require.NoError(t, err)
+	require.Len(t, crds, 2)
+	assert.Equal(t, "firsts.operator.kyma-project.io", crds[0].GetName())
+	assert.Equal(t, "seconds.operator.kyma-project.io", crds[1].GetName())
+	assert.Equal(t, apiextensionsv1.NamespaceScoped, crds[1].Spec.Scope)
+
+	crds, err = resource.GetCRDsFromPath(filepath.Join(dir, "a-crd.yml"))
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+
+	_, err = resource.GetCRDsFromPath(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInstallAndRemoveCRDs(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiextensionsv1.AddToScheme(scheme))
+	clnt := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	crds, err := resource.GetCRDsFromPath(writeCRDs(t))
+	require.NoError(t, err)
+
+	require.NoError(t, resource.InstallCRDs(ctx, clnt, crds))
+	require.NoError(t, resource.InstallCRDs(ctx, clnt, crds), "existing crds are kept")
+	assert.ErrorIs(t, resource.CheckCRDsEstablished(ctx, clnt, crds), resource.ErrCRDsNotEstablished)
+
+	for _, crd := range crds {
+		onCluster := &apiextensionsv1.CustomResourceDefinition{}
+		require.NoError(t, clnt.Get(ctx, client.ObjectKeyFromObject(crd), onCluster))
+		onCluster.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1.NamesAccepted, Status: apiextensionsv1.ConditionTrue},
+			{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+		}
+		require.NoError(t, clnt.Status().Update(ctx, onCluster))
+	}
+	require.NoError(t, resource.InstallCRDs(ctx, clnt, crds, resource.WithWaitForEstablished(true)))
+
+	assert.ErrorIs(t, resource.RemoveCRDs(ctx, clnt, crds), resource.ErrCRDsNotRemoved)
+	assert.NoError(t, resource.RemoveCRDs(ctx, clnt, crds))
+
+	assert.Error(t, resource.InstallCRDs(ctx, clnt, crds, resource.WithUpdatePolicy("Replace")))
+}
@@ -2,7 +2,6 @@ package types
 
 import (
 	"encoding/json"
-	"fmt"
 
 	"github.com/invopop/jsonschema"
 	"github.com/xeipuuv/gojsonschema"
@@ -14,6 +13,10 @@ type Codec struct {
 	imageSpecSchema     *gojsonschema.Schema
 	helmChartSpecSchema *gojsonschema.Schema
 	kustomizeSpecSchema *gojsonschema.Schema
+	gitSpecSchema       *gojsonschema.Schema
+	rawManifestSchema   *gojsonschema.Schema
+	// installConfigsSchema accepts unknown fields, so that config layers of older formats keep working.
+	installConfigsSchema *gojsonschema.Schema
 }
 
 func NewCodec() (*Codec, error) {
@@ -50,10 +53,46 @@ func NewCodec() (*Codec, error) {
 		return nil, err
 	}
 
+	gitSpecJSONBytes := jsonschema.Reflect(GitSpec{})
+	bytes, err = gitSpecJSONBytes.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	gitSpecSchema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(bytes))
+	if err != nil {
+		return nil, err
+	}
+
+	rawManifestJSONBytes := jsonschema.Reflect(RawManifestSpec{})
+	bytes, err = rawManifestJSONBytes.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	rawManifestSchema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(bytes))
+	if err != nil {
+		return nil, err
+	}
+
+	installConfigsJSONBytes := (&jsonschema.Reflector{AllowAdditionalProperties: true}).Reflect(InstallConfigs{})
+	bytes, err = installConfigsJSONBytes.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	installConfigsSchema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(bytes))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Codec{
-		imageSpecSchema:     imageSpecSchema,
-		helmChartSpecSchema: helmChartSpecSchema,
-		kustomizeSpecSchema: kustomizeSpecSchema,
+		imageSpecSchema:      imageSpecSchema,
+		helmChartSpecSchema:  helmChartSpecSchema,
+		kustomizeSpecSchema:  kustomizeSpecSchema,
+		gitSpecSchema:        gitSpecSchema,
+		rawManifestSchema:    rawManifestSchema,
+		installConfigsSchema: installConfigsSchema,
 	}, nil
 }
 
@@ -83,6 +122,7 @@ func (c *Codec) Decode(data []byte, obj interface{}, refType RefTypeMetadata) er
 	return nil
 }
 
+// Validate validates data against the schema of refType. Violations are returned as ValidationError.
 func (c *Codec) Validate(data []byte, refType RefTypeMetadata) error {
 	dataBytes := gojsonschema.NewBytesLoader(data)
 	var result *gojsonschema.Result
@@ -104,16 +144,36 @@ func (c *Codec) Validate(data []byte, refType RefTypeMetadata) error {
 		if err != nil {
 			return err
 		}
-	case NilRefType:
-		return fmt.Errorf("unsupported %s passed as installation type", refType)
+	case GitType:
+		result, err = c.gitSpecSchema.Validate(dataBytes)
+		if err != nil {
+			return err
+		}
+	case RawManifestType:
+		result, err = c.rawManifestSchema.Validate(dataBytes)
+		if err != nil {
+			return err
+		}
+	default:
+		return unsupportedRefTypeError(refType)
 	}
 
-	if !result.Valid() {
-		errorString := ""
-		for _, err := range result.Errors() {
-			errorString = fmt.Sprintf("%s: %s", errorString, err.String())
-		}
-		return fmt.Errorf(errorString)
+	return validationError(string(refType)+" source", result)
+}
+
+// DecodeInstallConfigs validates data against the schema of the config layer and decodes it.
+func (c *Codec) DecodeInstallConfigs(data []byte) (*InstallConfigs, error) {
+	result, err := c.installConfigsSchema.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if err := validationError("install configs", result); err != nil {
+		return nil, err
+	}
+
+	configs := &InstallConfigs{}
+	if err := yaml.Unmarshal(data, configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
 }
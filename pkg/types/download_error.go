@@ -0,0 +1,27 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DownloadError signals that an artifact (e.g. a chart or a layer) could not be fetched from its source.
+// Such errors are usually transient and can be retried faster than other errors.
+type DownloadError struct {
+	Ref string
+	Err error
+}
+
+func (m *DownloadError) Error() string {
+	return fmt.Sprintf("download of %s failed: %s", m.Ref, m.Err)
+}
+
+func (m *DownloadError) Unwrap() error {
+	return m.Err
+}
+
+// IsDownloadError checks if a DownloadError is part of the error chain.
+func IsDownloadError(err error) bool {
+	var downloadErr *DownloadError
+	return errors.As(err, &downloadErr)
+}
@@ -0,0 +1,42 @@
+package types
+
+import (
+	"errors"
+)
+
+// Failure classes of common errors. Errors returned for these failures match the class with errors.Is,
+// while the original error stays part of the chain, so callers do not have to match error messages.
+var (
+	ErrChartNotFound        = errors.New("chart not found")
+	ErrRegistryUnauthorized = errors.New("registry access unauthorized")
+	ErrClusterUnreachable   = errors.New("cluster unreachable")
+	ErrRenderFailed         = errors.New("rendering failed")
+	ErrResourceConflict     = errors.New("resource conflict")
+)
+
+// ClassifiedError attaches a failure class to an error without changing its message.
+type ClassifiedError struct {
+	Class error
+	Err   error
+}
+
+// NewClassifiedError marks err with class. Errors that already match class are returned unchanged.
+func NewClassifiedError(class, err error) error {
+	if err == nil || errors.Is(err, class) {
+		return err
+	}
+	return &ClassifiedError{Class: class, Err: err}
+}
+
+func (m *ClassifiedError) Error() string {
+	return m.Err.Error()
+}
+
+func (m *ClassifiedError) Unwrap() error {
+	return m.Err
+}
+
+// Is matches the failure class, the wrapped error is matched through Unwrap.
+func (m *ClassifiedError) Is(target error) bool {
+	return errors.Is(m.Class, target)
+}
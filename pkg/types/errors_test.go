@@ -0,0 +1,36 @@
+package types_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClassifiedError(t *testing.T) {
+	t.Parallel()
+	asserts := assert.New(t)
+
+	cause := errors.New("unexpected status code 401 Unauthorized")
+	err := fmt.Errorf("pulling layer: %w", types.NewClassifiedError(types.ErrRegistryUnauthorized, cause))
+
+	asserts.ErrorIs(err, types.ErrRegistryUnauthorized)
+	asserts.ErrorIs(err, cause)
+	asserts.NotErrorIs(err, types.ErrChartNotFound)
+	asserts.Equal("pulling layer: unexpected status code 401 Unauthorized", err.Error())
+
+	asserts.Same(err, types.NewClassifiedError(types.ErrRegistryUnauthorized, err))
+	asserts.NoError(types.NewClassifiedError(types.ErrRenderFailed, nil))
+
+	var downloadErr *types.DownloadError
+	asserts.ErrorAs(types.NewClassifiedError(types.ErrChartNotFound, &types.DownloadError{Ref: "ref", Err: cause}),
+		&downloadErr)
+
+	multiErr := fmt.Errorf("ServerSideApply failed: %w", types.NewMultiError([]error{
+		cause, types.NewClassifiedError(types.ErrResourceConflict, errors.New("conflict")),
+	}))
+	asserts.ErrorIs(multiErr, types.ErrResourceConflict, "classes are kept in collected errors")
+	asserts.NotErrorIs(multiErr, types.ErrRenderFailed)
+}
@@ -0,0 +1,138 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidFlagValue = errors.New("invalid flag value")
+	ErrUnsupportedFlag  = errors.New("unsupported flag")
+)
+
+// FlagType is the type of the value of a supported flag.
+type FlagType string
+
+const (
+	FlagTypeBool   FlagType = "bool"
+	FlagTypeInt    FlagType = "int"
+	FlagTypeString FlagType = "string"
+)
+
+// SupportedConfigFlags are the fields of the helm install action that the ConfigFlags field of ChartFlags may set,
+// with the type of their value. The ClientConfig of the config layer is parsed into them by
+// InstallConfig.ConfigFlags. All other fields, e.g. DryRun, Replace or ClientOnly, determine how resources are
+// rendered and applied by the reconciler and are rejected by Flags.ValidateConfigFlags.
+var SupportedConfigFlags = map[string]FlagType{
+	"Description": FlagTypeString,
+	"Devel":       FlagTypeBool,
+	"SkipCRDs":    FlagTypeBool,
+	"SubNotes":    FlagTypeBool,
+	"Version":     FlagTypeString,
+}
+
+// GetBool returns the flag as bool, accepting bool values as well as strings parsable by strconv.ParseBool.
+// A missing flag results in false without an error.
+func (f Flags) GetBool(name string) (bool, error) {
+	switch value := f[name].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return value, nil
+	case string:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("%w: %s=%q is not a bool", ErrInvalidFlagValue, name, value)
+		}
+		return parsed, nil
+	default:
+		return false, fmt.Errorf("%w: %s has type %T instead of bool", ErrInvalidFlagValue, name, value)
+	}
+}
+
+// GetInt64 returns the flag as int64, accepting all integer types, floats without fraction
+// (as produced by JSON decoding) and strings parsable by strconv.ParseInt.
+// A missing flag results in 0 without an error.
+func (f Flags) GetInt64(name string) (int64, error) {
+	switch value := f[name].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(value), nil
+	case int32:
+		return int64(value), nil
+	case int64:
+		return value, nil
+	case float64:
+		if value != float64(int64(value)) {
+			return 0, fmt.Errorf("%w: %s=%v is not an integer", ErrInvalidFlagValue, name, value)
+		}
+		return int64(value), nil
+	case string:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s=%q is not an integer", ErrInvalidFlagValue, name, value)
+		}
+		return parsed, nil
+	default:
+		return 0, fmt.Errorf("%w: %s has type %T instead of int", ErrInvalidFlagValue, name, value)
+	}
+}
+
+// GetString returns the flag as string, other types than string are rejected.
+// A missing flag results in an empty string without an error.
+func (f Flags) GetString(name string) (string, error) {
+	switch value := f[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	default:
+		return "", fmt.Errorf("%w: %s has type %T instead of string", ErrInvalidFlagValue, name, value)
+	}
+}
+
+// ValidateConfigFlags checks that all flags are SupportedConfigFlags with a value of their type
+// and returns all violations at once, ordered by flag name.
+func (f Flags) ValidateConfigFlags() error {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		var err error
+		switch SupportedConfigFlags[name] {
+		case FlagTypeBool:
+			_, err = f.GetBool(name)
+		case FlagTypeInt:
+			_, err = f.GetInt64(name)
+		case FlagTypeString:
+			_, err = f.GetString(name)
+		default:
+			err = fmt.Errorf("%w: %s, supported flags are %s", ErrUnsupportedFlag, name, SupportedConfigFlagNames())
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return NewMultiError(errs)
+	}
+	return nil
+}
+
+// SupportedConfigFlagNames lists the names of the SupportedConfigFlags in order.
+func SupportedConfigFlagNames() string {
+	names := make([]string, 0, len(SupportedConfigFlags))
+	for name := range SupportedConfigFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,74 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlags(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	flags := types.Flags{
+		"wait":     "true",
+		"atomic":   true,
+		"timeout":  float64(300),
+		"replicas": "3",
+		"fraction": 1.5,
+		"name":     "release",
+	}
+
+	wait, err := flags.GetBool("wait")
+	assertions.NoError(err)
+	assertions.True(wait)
+	atomic, err := flags.GetBool("atomic")
+	assertions.NoError(err)
+	assertions.True(atomic)
+	timeout, err := flags.GetInt64("timeout")
+	assertions.NoError(err)
+	assertions.Equal(int64(300), timeout)
+	replicas, err := flags.GetInt64("replicas")
+	assertions.NoError(err)
+	assertions.Equal(int64(3), replicas)
+	missing, err := flags.GetBool("missing")
+	assertions.NoError(err)
+	assertions.False(missing)
+
+	_, err = flags.GetBool("name")
+	assertions.ErrorIs(err, types.ErrInvalidFlagValue)
+	_, err = flags.GetInt64("fraction")
+	assertions.ErrorIs(err, types.ErrInvalidFlagValue)
+	_, err = flags.GetString("atomic")
+	assertions.ErrorIs(err, types.ErrInvalidFlagValue)
+}
+
+func TestValidateConfigFlags(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	assertions.NoError(types.Flags{"Devel": true, "Version": "1.2.x", "SkipCRDs": "false"}.ValidateConfigFlags())
+
+	err := types.Flags{"DryRun": false, "Replace": true, "Devel": "sometimes"}.ValidateConfigFlags()
+	assertions.ErrorIs(err, types.ErrUnsupportedFlag)
+	assertions.ErrorIs(err, types.ErrInvalidFlagValue)
+	var multiErr *types.MultiError
+	assertions.True(errors.As(err, &multiErr))
+	assertions.Len(multiErr.Errs, 3)
+	assertions.Contains(multiErr.Errs[1].Error(), "DryRun")
+
+	flags, ignored, err := (&types.InstallConfig{Name: "keda", ClientConfig: "Devel=true,Version=2"}).ConfigFlags()
+	assertions.NoError(err)
+	assertions.Empty(ignored)
+	assertions.Equal(types.Flags{"Devel": "true", "Version": "2"}, flags)
+
+	flags, ignored, err = (&types.InstallConfig{
+		Name: "keda", ClientConfig: "Namespace=keda,DryRun=false,Version=2",
+	}).ConfigFlags()
+	assertions.NoError(err, "unsupported flags of existing config layers are still accepted")
+	assertions.Equal([]string{"DryRun", "Namespace"}, ignored)
+	assertions.Equal(types.Flags{"Version": "2"}, flags)
+
+	_, _, err = (&types.InstallConfig{Name: "keda", ClientConfig: "Devel=sometimes"}).ConfigFlags()
+	assertions.ErrorIs(err, types.ErrInvalidFlagValue)
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/strvals"
+)
+
+// InstallConfigs is the content of the config layer of a Manifest.
+// +kubebuilder:object:generate=false
+type InstallConfigs struct {
+	// Configs holds the configuration of the installs, selected by their name.
+	Configs []InstallConfig `json:"configs,omitempty"`
+}
+
+// InstallConfig defines the values of an install in the config layer.
+// +kubebuilder:object:generate=false
+type InstallConfig struct {
+	// Name selects the install the configuration applies to.
+	Name string `json:"name"`
+
+	// Values are the chart values of the install, e.g. the content of a values file.
+	Values map[string]interface{} `json:"values,omitempty"`
+
+	// Overrides are chart values in the strvals format, e.g. "replicaCount=1,image.tag=v1".
+	// They are applied on top of Values.
+	Overrides string `json:"overrides,omitempty"`
+
+	// ClientConfig defines client flags of the install in the strvals format, e.g. "Devel=true,Version=1.2.x".
+	// Only the SupportedConfigFlags are applied.
+	ClientConfig string `json:"clientConfig,omitempty"`
+}
+
+// ConfigFlags parses the ClientConfig and returns the SupportedConfigFlags validated with
+// Flags.ValidateConfigFlags. Other flags were accepted before the flags were restricted, so they are still
+// accepted for compatibility but ignored, and returned for a deprecation warning ordered by name.
+func (c *InstallConfig) ConfigFlags() (Flags, []string, error) {
+	parsed, err := strvals.ParseString(c.ClientConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: client config of install %s: %s", ErrInvalidFlagValue, c.Name, err.Error())
+	}
+	flags := make(Flags, len(parsed))
+	var ignored []string
+	for name, value := range parsed {
+		if _, supported := SupportedConfigFlags[name]; supported {
+			flags[name] = value
+		} else {
+			ignored = append(ignored, name)
+		}
+	}
+	sort.Strings(ignored)
+	if err := flags.ValidateConfigFlags(); err != nil {
+		return nil, nil, fmt.Errorf("client config of install %s: %w", c.Name, err)
+	}
+	return flags, ignored, nil
+}
+
+// ForInstall returns the configuration of the install with the given name, or nil if there is none.
+func (c *InstallConfigs) ForInstall(name string) *InstallConfig {
+	for i := range c.Configs {
+		if c.Configs[i].Name == name {
+			return &c.Configs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeInstallConfigs(t *testing.T) {
+	t.Parallel()
+	assertions := assert.New(t)
+	codec, err := types.NewCodec()
+	assertions.NoError(err)
+
+	configs, err := codec.DecodeInstallConfigs([]byte(`
+configs:
+- name: keda
+  overrides: replicaCount=2
+  clientConfig: Namespace=keda
+  values:
+    image:
+      tag: 2.8.1
+    resources:
+      limits:
+        cpu: 100m
+- name: legacy
+  overrides: replicaCount=1
+  unknownField: kept for older layers
+`))
+	assertions.NoError(err)
+	keda := configs.ForInstall("keda")
+	assertions.NotNil(keda)
+	assertions.Equal("replicaCount=2", keda.Overrides)
+	assertions.Equal("Namespace=keda", keda.ClientConfig)
+	assertions.Equal(map[string]interface{}{
+		"image":     map[string]interface{}{"tag": "2.8.1"},
+		"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "100m"}},
+	}, keda.Values)
+	legacy := configs.ForInstall("legacy")
+	assertions.NotNil(legacy)
+	assertions.Nil(legacy.Values)
+	assertions.Nil(configs.ForInstall("missing"))
+
+	_, err = codec.DecodeInstallConfigs([]byte(`{"configs": [{"name": "keda", "values": "replicaCount=2"}]}`))
+	assertions.Error(err, "values must be a map")
+	_, err = codec.DecodeInstallConfigs([]byte(`{"configs": [{"overrides": "replicaCount=2"}]}`))
+	assertions.Error(err, "configs must be named")
+}
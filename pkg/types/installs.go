@@ -11,7 +11,7 @@ import (
 // RefTypeMetadata specifies the type of installation specification
 // that could be provided as part of a custom resource.
 // This time is used in codec to successfully decode from raw extensions.
-// +kubebuilder:validation:Enum=helm-chart;oci-ref;"kustomize";""
+// +kubebuilder:validation:Enum=helm-chart;oci-ref;"kustomize";git;raw-manifest;""
 type RefTypeMetadata string
 
 func (r RefTypeMetadata) NotEmpty() bool {
@@ -19,10 +19,12 @@ func (r RefTypeMetadata) NotEmpty() bool {
 }
 
 const (
-	HelmChartType RefTypeMetadata = "helm-chart"
-	OciRefType    RefTypeMetadata = "oci-ref"
-	KustomizeType RefTypeMetadata = "kustomize"
-	NilRefType    RefTypeMetadata = ""
+	HelmChartType   RefTypeMetadata = "helm-chart"
+	OciRefType      RefTypeMetadata = "oci-ref"
+	KustomizeType   RefTypeMetadata = "kustomize"
+	GitType         RefTypeMetadata = "git"
+	RawManifestType RefTypeMetadata = "raw-manifest"
+	NilRefType      RefTypeMetadata = ""
 )
 
 // Flags define a set of configurable flags.
@@ -30,7 +32,8 @@ type Flags map[string]interface{}
 
 // ChartFlags define flag based configurations for helm chart processing.
 type ChartFlags struct {
-	// ConfigFlags support string, bool and int types as helm chart flags
+	// ConfigFlags set fields of the helm install action, only the SupportedConfigFlags are accepted:
+	// Description, Devel, SkipCRDs, SubNotes and Version, see Flags.ValidateConfigFlags.
 	// check: https://github.com/helm/helm/blob/d7b4c38c42cb0b77f1bcebf9bb4ae7695a10da0b/pkg/action/install.go#L67
 	ConfigFlags Flags
 
@@ -57,12 +60,28 @@ type ImageSpec struct {
 	// use it to indicate the secret which contains registry credentials,
 	// must exist in the namespace same as manifest
 	CredSecretSelector *metav1.LabelSelector `json:"credSecretSelector,omitempty"`
+
+	// LayerSelector is an optional field, for tagged images with several layers,
+	// use it to select the layer by its title or annotations instead of the default title of the layer.
+	// It is ignored if Ref is the digest of a layer.
+	LayerSelector *LayerSelector `json:"layerSelector,omitempty"`
+}
+
+// +k8s:deepcopy-gen=true
+// LayerSelector selects a single layer of an OCI image by the annotations of its descriptor.
+type LayerSelector struct {
+	// Title matches the org.opencontainers.image.title annotation of the layer, e.g. "chart" or "crds"
+	Title string `json:"title,omitempty"`
+
+	// Annotations match the annotations of the layer, all of them have to be present with the given value
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
 // HelmChartSpec defines the specification for a helm chart.
 type HelmChartSpec struct {
-	// URL defines the helm repo URL
+	// URL defines the helm repo URL, either of a classic HTTP repository or of an OCI registry,
+	// e.g. "oci://ghcr.io/org/charts"
 	// +kubebuilder:validation:Optional
 	URL string `json:"url"`
 
@@ -70,9 +89,29 @@ type HelmChartSpec struct {
 	// +kubebuilder:validation:Optional
 	ChartName string `json:"chartName"`
 
+	// Version defines the chart version, which is the tag of charts in OCI registries.
+	// The latest version is installed if empty.
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// CredSecretSelector is an optional field, for charts in private OCI registries,
+	// use it to indicate the secret which contains registry credentials,
+	// must exist in the namespace same as manifest
+	// +kubebuilder:validation:Optional
+	CredSecretSelector *metav1.LabelSelector `json:"credSecretSelector,omitempty"`
+
 	// Type defines the chart as "helm-chart"
 	// +kubebuilder:validation:Optional
 	Type RefTypeMetadata `json:"type"`
+
+	// Digest pins the sha256 digest of the chart archive, e.g. "sha256:<hex>".
+	// Charts with a different digest are rejected.
+	// +kubebuilder:validation:Optional
+	Digest string `json:"digest,omitempty"`
+
+	// Verify requires a valid provenance (.prov) file signed by a key of the configured keyring.
+	// +kubebuilder:validation:Optional
+	Verify bool `json:"verify,omitempty"`
 }
 
 // KustomizeSpec defines the specification for a Kustomize specification.
@@ -80,14 +119,86 @@ type KustomizeSpec struct {
 	// Path defines the Kustomize local path
 	Path string `json:"path"`
 
-	// URL defines the Kustomize remote URL
+	// URL defines the Kustomize remote URL, e.g. "github.com/org/repo//overlays/prod?ref=v1.0.0"
 	URL string `json:"url"`
 
+	// Commit pins the commit sha the ref of the remote URL has to resolve to.
+	// If the URL has no ref, the commit itself is fetched.
+	// +kubebuilder:validation:Optional
+	Commit string `json:"commit,omitempty"`
+
+	// CredSecretSelector is an optional field to select the secret with git credentials of private remotes,
+	// either "username" and "password" (or token) for HTTPS or "identity" and "known_hosts" for SSH,
+	// must exist in the namespace same as manifest
+	// +kubebuilder:validation:Optional
+	CredSecretSelector *metav1.LabelSelector `json:"credSecretSelector,omitempty"`
+
 	// Type defines the chart as "kustomize"
 	// +kubebuilder:validation:Optional
 	Type RefTypeMetadata `json:"type"`
 }
 
+// +k8s:deepcopy-gen=true
+// GitSpec defines the specification for a helm chart or kustomization in a git repository.
+type GitSpec struct {
+	// URL defines the git repository, e.g. "https://github.com/org/repo" or "git@github.com:org/repo"
+	URL string `json:"url"`
+
+	// Ref defines the branch, tag or commit to check out, the default branch if empty
+	// +kubebuilder:validation:Optional
+	Ref string `json:"ref,omitempty"`
+
+	// Path defines the path of the chart or kustomization inside the repository, the root if empty
+	// +kubebuilder:validation:Optional
+	Path string `json:"path,omitempty"`
+
+	// Commit pins the commit sha the ref has to resolve to.
+	// +kubebuilder:validation:Optional
+	Commit string `json:"commit,omitempty"`
+
+	// Renderer is either "helm" or "kustomize". If empty, paths with a Chart.yaml are rendered with helm
+	// and all others with kustomize.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=helm;kustomize;""
+	Renderer string `json:"renderer,omitempty"`
+
+	// CredSecretSelector is an optional field to select the secret with git credentials of private repositories,
+	// either "username" and "password" (or token) for HTTPS or "identity" and "known_hosts" for SSH,
+	// must exist in the namespace same as manifest
+	// +kubebuilder:validation:Optional
+	CredSecretSelector *metav1.LabelSelector `json:"credSecretSelector,omitempty"`
+
+	// Type defines the chart as "git"
+	// +kubebuilder:validation:Optional
+	Type RefTypeMetadata `json:"type"`
+}
+
+// +k8s:deepcopy-gen=true
+// RawManifestSpec defines the specification for a plain multi-document YAML manifest,
+// which is applied as is without rendering. Exactly one of Manifest, URL and Image has to be set.
+type RawManifestSpec struct {
+	// Manifest defines the manifest inline
+	// +kubebuilder:validation:Optional
+	Manifest string `json:"manifest,omitempty"`
+
+	// URL defines the HTTP(S) URL the manifest is downloaded from
+	// +kubebuilder:validation:Optional
+	URL string `json:"url,omitempty"`
+
+	// Image defines the OCI layer containing the manifest
+	// +kubebuilder:validation:Optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// Digest pins the sha256 digest of the manifest, e.g. "sha256:<hex>".
+	// Manifests with a different digest are rejected.
+	// +kubebuilder:validation:Optional
+	Digest string `json:"digest,omitempty"`
+
+	// Type defines the manifest as "raw-manifest"
+	// +kubebuilder:validation:Optional
+	Type RefTypeMetadata `json:"type"`
+}
+
 // ManifestResources holds a collection of objects, so that we can filter / sequence them.
 type ManifestResources struct {
 	Items []*unstructured.Unstructured
@@ -98,6 +209,9 @@ type ManifestResources struct {
 type ClusterInfo struct {
 	Config *rest.Config
 	Client client.Client
+	// Version identifies the source the Config was created from, e.g. the resourceVersion of a kubeconfig secret.
+	// Clients created from a ClusterInfo are replaced once the version changes.
+	Version string
 }
 
 // IsEmpty indicates if ClusterInfo is empty.
@@ -118,4 +232,16 @@ type ChartInfo struct {
 	URL         string
 	ChartName   string
 	ReleaseName string
+	// Revision identifies the version of the chart, e.g. the layer digest of an OCI chart.
+	Revision string
+	// Provenance contains the propagated annotations of the OCI manifest the chart was pulled from.
+	Provenance map[string]string
+	// Digest pins the sha256 digest of the chart archive of a repository chart.
+	Digest string
+	// Version is the version of a repository chart, the latest version if empty.
+	Version string
+	// CredSecretSelector selects the registry credentials of a chart in an OCI registry.
+	CredSecretSelector *metav1.LabelSelector
+	// Verify requires the provenance file of a repository chart to be verified.
+	Verify bool
 }
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const yamlDocumentSeparator = "---\n"
+
+// MarshalYAMLStream is the inverse of parsing a manifest into ManifestResources. It encodes the Items as
+// multi-document YAML sorted by apiVersion, kind, namespace and name, so that the same resources always result
+// in the same stream, followed by the Blobs that could not be parsed in their original order.
+// Comments of the parsed manifest are not preserved.
+func (m *ManifestResources) MarshalYAMLStream() ([]byte, error) {
+	items := make([]*unstructured.Unstructured, len(m.Items))
+	copy(items, m.Items)
+	sort.SliceStable(items, func(i, j int) bool {
+		return resourceSortKey(items[i]) < resourceSortKey(items[j])
+	})
+
+	var stream bytes.Buffer
+	for _, item := range items {
+		document, err := yaml.Marshal(item.Object)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s %s/%s: %w", item.GetKind(), item.GetNamespace(), item.GetName(), err)
+		}
+		stream.WriteString(yamlDocumentSeparator)
+		stream.Write(document)
+	}
+	for _, blob := range m.Blobs {
+		stream.WriteString(yamlDocumentSeparator)
+		stream.Write(blob)
+		if !bytes.HasSuffix(blob, []byte("\n")) {
+			stream.WriteByte('\n')
+		}
+	}
+	return stream.Bytes(), nil
+}
+
+func resourceSortKey(obj *unstructured.Unstructured) string {
+	return obj.GetAPIVersion() + "\x00" + obj.GetKind() + "\x00" + obj.GetNamespace() + "\x00" + obj.GetName()
+}
@@ -0,0 +1,61 @@
+package types_test
+
+import (
+	"testing"
+
+	"github.com/kyma-project/module-manager/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalYAMLStream(t *testing.T) {
+	t.Parallel()
+	manifest := `---
+apiVersion: v1
+kind: Service
+metadata:
+  name: keda
+  namespace: keda
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: keda-config
+  namespace: keda
+data:
+  level: debug
+---
+{{ not yaml
+`
+	resources, err := internal.ParseManifestStringToObjects(manifest)
+	require.NoError(t, err)
+	require.Len(t, resources.Items, 2)
+	require.Len(t, resources.Blobs, 1)
+
+	stream, err := resources.MarshalYAMLStream()
+	require.NoError(t, err)
+	assert.Equal(t, `---
+apiVersion: v1
+data:
+  level: debug
+kind: ConfigMap
+metadata:
+  name: keda-config
+  namespace: keda
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: keda
+  namespace: keda
+---
+{{ not yaml
+`, string(stream))
+	assert.Equal(t, "Service", resources.Items[0].GetKind(), "the items are not reordered")
+
+	parsed, err := internal.ParseManifestStringToObjects(string(stream))
+	require.NoError(t, err)
+	reencoded, err := parsed.MarshalYAMLStream()
+	require.NoError(t, err)
+	assert.Equal(t, stream, reencoded)
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 )
 
@@ -20,3 +21,23 @@ func (m MultiError) Error() string {
 	}
 	return buf.String()
 }
+
+// Is reports whether any of the errors matches target, so that failure classes are kept when errors are collected.
+func (m MultiError) Is(target error) bool {
+	for _, err := range m.Errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (m MultiError) As(target any) bool {
+	for _, err := range m.Errs {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ErrInvalidSource is matched with errors.Is by every ValidationError.
+var ErrInvalidSource = errors.New("invalid source")
+
+// supportedRefTypes are the types of install sources the Codec decodes.
+var supportedRefTypes = []RefTypeMetadata{HelmChartType, OciRefType, KustomizeType, GitType, RawManifestType}
+
+// Types of FieldError reported on the missing or unknown field, they do not carry a Value.
+const (
+	FieldErrorRequired   = "required"
+	FieldErrorNotAllowed = "additional_property_not_allowed"
+)
+
+// FieldError is a single violation of the schema of a source.
+type FieldError struct {
+	// Field is the dot separated path of the field relative to the source, empty for the source itself.
+	Field string
+	// Value is the offending value, nil for missing fields.
+	Value interface{}
+	// Expected describes what the schema expects instead.
+	Expected string
+	// Type is the kind of violation, e.g. "required" or "invalid_type".
+	Type string
+}
+
+// ValidationError lists the fields of a source that do not match the schema of its type,
+// so that they can be fixed without reading the schema.
+type ValidationError struct {
+	// Source is what was validated, e.g. "helm-chart source".
+	Source string
+	// Path is prefixed to the fields, e.g. "spec.installs[keda].source".
+	Path   string
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	violations := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		violation := e.FieldPath(field)
+		if field.Value != nil {
+			violation = fmt.Sprintf("%s: invalid value %s", violation, formatValue(field.Value))
+		}
+		violations = append(violations, fmt.Sprintf("%s: %s", violation, field.Expected))
+	}
+	return fmt.Sprintf("invalid %s: %s", e.Source, strings.Join(violations, "; "))
+}
+
+// FieldPath returns the full path of field including Path.
+func (e *ValidationError) FieldPath(field FieldError) string {
+	switch {
+	case e.Path == "" && field.Field == "":
+		return "(root)"
+	case e.Path == "":
+		return field.Field
+	case field.Field == "":
+		return e.Path
+	default:
+		return e.Path + "." + field.Field
+	}
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidSource
+}
+
+// WithFieldPath sets path as Path of a ValidationError in err, other errors are returned unchanged.
+func WithFieldPath(err error, path string) error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	prefixed := *validationErr
+	prefixed.Path = path
+	return &prefixed
+}
+
+func unsupportedRefTypeError(refType RefTypeMetadata) error {
+	supported := make([]string, 0, len(supportedRefTypes))
+	for _, supportedType := range supportedRefTypes {
+		supported = append(supported, string(supportedType))
+	}
+	var value interface{}
+	if refType.NotEmpty() {
+		value = string(refType)
+	}
+	fieldErr := FieldError{Field: "type", Value: value, Expected: "must be one of " + strings.Join(supported, ", ")}
+	if value == nil {
+		fieldErr.Type = FieldErrorRequired
+	}
+	return &ValidationError{Source: "install source", Fields: []FieldError{fieldErr}}
+}
+
+func validationError(source string, result *gojsonschema.Result) error {
+	if result.Valid() {
+		return nil
+	}
+	fields := make([]FieldError, 0, len(result.Errors()))
+	for _, resultErr := range result.Errors() {
+		field := FieldError{
+			Field: resultErr.Field(), Value: resultErr.Value(), Expected: resultErr.Description(), Type: resultErr.Type(),
+		}
+		if field.Field == gojsonschema.STRING_CONTEXT_ROOT {
+			field.Field = ""
+		}
+		// missing and unknown fields are reported on their parent, the value of which is not of interest
+		if property, ok := resultErr.Details()["property"].(string); ok &&
+			(field.Type == FieldErrorRequired || field.Type == FieldErrorNotAllowed) {
+			field.Field = strings.TrimPrefix(field.Field+"."+property, ".")
+			field.Value = nil
+		}
+		if field.Field == "" {
+			field.Value = nil
+		}
+		fields = append(fields, field)
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return &ValidationError{Source: source, Fields: fields}
+}
+
+func formatValue(value interface{}) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(formatted)
+}
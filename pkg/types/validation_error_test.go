@@ -0,0 +1,38 @@
+package types_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kyma-project/module-manager/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateReportsFieldPaths(t *testing.T) {
+	t.Parallel()
+	codec, err := types.NewCodec()
+	require.NoError(t, err)
+
+	err = codec.Validate([]byte(`{"type": "helm-chart", "chartName": 1, "url": "https://charts.example.com"}`),
+		types.HelmChartType)
+	require.ErrorIs(t, err, types.ErrInvalidSource)
+	var validationErr *types.ValidationError
+	require.True(t, errors.As(types.WithFieldPath(err, "spec.installs[keda].source"), &validationErr))
+	require.Len(t, validationErr.Fields, 1)
+	assert.Equal(t, "chartName", validationErr.Fields[0].Field)
+	assert.Equal(t, "spec.installs[keda].source.chartName", validationErr.FieldPath(validationErr.Fields[0]))
+	assert.Contains(t, validationErr.Error(), "spec.installs[keda].source.chartName: invalid value 1: ")
+	assert.Contains(t, validationErr.Error(), "string")
+
+	err = codec.Validate([]byte(`{"type": "helm"}`), "helm")
+	require.ErrorIs(t, err, types.ErrInvalidSource)
+	assert.EqualError(t, err, `invalid install source: type: invalid value "helm": `+
+		`must be one of helm-chart, oci-ref, kustomize, git, raw-manifest`)
+
+	err = codec.Validate([]byte(`{}`), types.NilRefType)
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, types.FieldErrorRequired, validationErr.Fields[0].Type)
+
+	assert.Equal(t, errors.New("unchanged"), types.WithFieldPath(errors.New("unchanged"), "spec"))
+}